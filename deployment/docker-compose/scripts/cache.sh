@@ -15,6 +15,7 @@ create_cache_dir() {
     mkdir -p "$CCNP_CACHE_DIR"
     mkdir -p "$CCNP_CACHE_DIR/run/ccnp-eventlog"
     mkdir -p "$CCNP_CACHE_DIR/run/ccnp/uds"
+    mkdir -p "$CCNP_CACHE_DIR/run/ccnp/runtime"
     mkdir -p "$CCNP_CACHE_DIR/eventlog-entry-dir"
     mkdir -p "$CCNP_CACHE_DIR/eventlog-data-dir"
     mkdir -p "$COMPOSE_CACHE_DIR"
@@ -0,0 +1,36 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package eventlog
+
+import (
+	pb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/eventlog/proto"
+	el "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+)
+
+// EncodeCel encodes the event logs in the TCG Canonical Event Log format, CEL-JSON, CEL-CBOR
+// or CEL-TLV, for verifiers consuming CEL. The records are numbered from 0.
+//
+//	eventlogs, _ := GetPlatformEventlog()
+//	cel, err := EncodeCel(eventlogs, pb.FORMAT_CEL_JSON)
+func EncodeCel(eventlogs []CCEventLogEntry, format pb.FORMAT) ([]uint8, error) {
+	return el.MarshalCel(el.GetCelRecords(toRawEventlogs(eventlogs), 0), int(format))
+}
+
+// DecodeCel decodes the event logs from the TCG Canonical Event Log format. Only records
+// with PCCLIENT_STD content, the TDX and TPM events, can be decoded into entries.
+func DecodeCel(data []uint8, format pb.FORMAT) ([]CCEventLogEntry, error) {
+	records, err := el.UnmarshalCel(data, int(format))
+	if err != nil {
+		return nil, err
+	}
+
+	rawEventlogs, err := el.GetEventlogsFromCel(records)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromRawEventlogs(rawEventlogs), nil
+}
@@ -0,0 +1,186 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package eventlog
+
+import (
+	"context"
+	"io"
+	"log"
+	"time"
+
+	pb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/eventlog/proto"
+	el "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+	"google.golang.org/grpc"
+)
+
+type ContainerEventLogEntry struct {
+	Sequence    uint32 // position of the event in the log of all containers
+	ContainerId string
+	PodId       string
+	EvtType     uint32
+	EvtTypeName string
+	Timestamp   time.Time
+	AlgId       uint16
+	Digest      []uint8
+	Event       []uint8
+}
+
+// WithContainerId selects the events of a container or a pod for GetContainerEventlog.
+func WithContainerId(containerId string) func(*GetPlatformEventlogOptions) {
+	return func(opts *GetPlatformEventlogOptions) {
+		opts.containerId = containerId
+	}
+}
+
+// GetContainerEventlog fetches the runtime event log of the containers, or of the container
+// or pod selected by WithContainerId. The eventlog category option is ignored.
+func GetContainerEventlog(opts ...func(*GetPlatformEventlogOptions)) ([]ContainerEventLogEntry, error) {
+
+	input := GetPlatformEventlogOptions{startPosition: 0, count: 0}
+	for _, opt := range opts {
+		opt(&input)
+	}
+
+	if input.startPosition < 0 {
+		log.Fatalf("[GetContainerEventlog] Invalid startPosition specified")
+	}
+
+	if input.count < 0 {
+		log.Fatalf("[GetContainerEventlog] Invalid count specified")
+	}
+
+	channel, err := grpc.Dial(UDS_PATH, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("[GetContainerEventlog] can not connect to UDS: %v", err)
+	}
+	defer channel.Close()
+
+	client := pb.NewEventlogClient(channel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	request := &pb.GetEventlogRequest{
+		EventlogLevel: pb.LEVEL_SAAS,
+		StartPosition: input.startPosition,
+		Count:         input.count,
+		ContainerId:   input.containerId,
+	}
+
+	if !input.sharedFile {
+		return getStreamContainerEventlogs(ctx, client, request)
+	}
+
+	response, err := client.GetEventlog(ctx, request)
+	if err != nil {
+		log.Fatalf("[GetContainerEventlog] fail to get Container Eventlog: %v", err)
+	}
+
+	rawEventlog, err := getRawEventlogs(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseContainerEventlog(rawEventlog)
+}
+
+// RecordContainerEvent appends an event to the runtime event log of the container, e.g. the
+// image reference with its digest for CONTAINER_IMAGE. It returns the sequence of the event.
+// Only the container runtime, connected to the container runtime socket as the user configured
+// in the eventlog server, can record events.
+func RecordContainerEvent(containerId string, podId string, eventType pb.CONTAINER_EVENT_TYPE, event []uint8) (uint32, error) {
+	channel, err := grpc.Dial(CONTAINER_RUNTIME_UDS_PATH, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("[RecordContainerEvent] can not connect to UDS: %v", err)
+	}
+	defer channel.Close()
+
+	client := pb.NewEventlogClient(channel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	response, err := client.RecordContainerEvent(ctx, &pb.RecordContainerEventRequest{
+		ContainerId: containerId,
+		PodId:       podId,
+		EventType:   eventType,
+		Event:       event,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return response.Sequence, nil
+}
+
+func getStreamContainerEventlogs(ctx context.Context, client pb.EventlogClient, request *pb.GetEventlogRequest) ([]ContainerEventLogEntry, error) {
+	stream, err := client.GetEventlogStream(ctx, request)
+	if err != nil {
+		log.Fatalf("[getStreamContainerEventlogs] fail to get Container Eventlog: %v", err)
+	}
+
+	var parsedEventLogList []ContainerEventLogEntry
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("[getStreamContainerEventlogs] fail to receive Container Eventlog: %v", err)
+		}
+
+		containerEventLog, ok := getStreamContainerEventlogEntry(entry)
+		if !ok {
+			continue
+		}
+		parsedEventLogList = append(parsedEventLogList, containerEventLog)
+	}
+
+	return parsedEventLogList, nil
+}
+
+func getStreamContainerEventlogEntry(entry *pb.EventlogEntry) (ContainerEventLogEntry, bool) {
+	containerEntry := entry.GetContainerEntry()
+	if containerEntry == nil || len(entry.GetDigests()) < 1 {
+		return ContainerEventLogEntry{}, false
+	}
+
+	return ContainerEventLogEntry{
+		Sequence:    containerEntry.Sequence,
+		ContainerId: containerEntry.ContainerId,
+		PodId:       containerEntry.PodId,
+		EvtType:     entry.EventType,
+		EvtTypeName: el.GetContainerEventTypeName(entry.EventType),
+		Timestamp:   time.Unix(0, containerEntry.Timestamp).UTC(),
+		AlgId:       uint16(entry.Digests[0].AlgorithmId),
+		Digest:      entry.Digests[0].Digest,
+		Event:       entry.Event,
+	}, true
+}
+
+func parseContainerEventlog(rawEventlog []byte) ([]ContainerEventLogEntry, error) {
+	jsonEventlog, err := el.UnmarshalContainerEventlogs(rawEventlog)
+	if err != nil {
+		log.Fatalf("[parseContainerEventlog] Error unmarshal raw eventlog: %v", err)
+	}
+
+	var parsedEventLogList []ContainerEventLogEntry
+	for _, rawEventlog := range jsonEventlog.EventLogs {
+		parsedEventLogList = append(parsedEventLogList, ContainerEventLogEntry{
+			Sequence:    rawEventlog.Sequence,
+			ContainerId: rawEventlog.ContainerId,
+			PodId:       rawEventlog.PodId,
+			EvtType:     rawEventlog.EventType,
+			EvtTypeName: rawEventlog.EventTypeName,
+			Timestamp:   rawEventlog.Timestamp,
+			AlgId:       rawEventlog.AlgorithmId,
+			Digest:      rawEventlog.Digest,
+			Event:       rawEventlog.Event,
+		})
+	}
+
+	return parsedEventLogList, nil
+}
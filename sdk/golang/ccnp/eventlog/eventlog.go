@@ -7,34 +7,140 @@ package eventlog
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	pb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/eventlog/proto"
 	el "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
 	pkgerrors "github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 const (
 	UDS_PATH = "unix:/run/ccnp/uds/eventlog.sock"
+	// Container events are only recorded by the container runtime on its own socket
+	CONTAINER_RUNTIME_UDS_PATH = "unix:/run/ccnp/runtime/eventlog-container-runtime.sock"
+	// The eventlog directory of the server, only read by the client with WithSharedFile
+	EVENTLOG_DIR = "/run/ccnp-eventlog"
 )
 
-type CCEventLogEntry struct {
-	RegIdx  uint32
-	EvtType uint32
-	EvtSize uint32
+var (
+	EventlogDigestMismatchErr = pkgerrors.New("Eventlog data does not match the digest returned by server")
+	EventlogNotReturnedErr    = pkgerrors.New("No eventlog returned by server")
+	EventlogDirNotMountedErr  = pkgerrors.New("Eventlog file of server not found, " + EVENTLOG_DIR + " is not mounted")
+)
+
+type CCDigest struct {
 	AlgId   uint16
-	Event   []uint8
+	AlgName string
 	Digest  []uint8
 }
 
+// Decoded event payloads, see DecodedEvent of CCEventLogEntry
+type (
+	UefiVariableData         = el.UefiVariableData
+	UefiImageLoadEvent       = el.UefiImageLoadEvent
+	UefiPlatformFirmwareBlob = el.UefiPlatformFirmwareBlob
+	StringEvent              = el.StringEvent
+	SeparatorEvent           = el.SeparatorEvent
+	TaggedEvent              = el.TaggedEvent
+)
+
+type CCEventLogEntry struct {
+	RegIdx      uint32
+	EvtType     uint32
+	EvtTypeName string
+	EvtSize     uint32
+	AlgId       uint16 // algorithm of the last digest in Digests
+	Event       []uint8
+	Digest      []uint8 // the last digest in Digests
+	Digests     []CCDigest
+	// DecodedEvent is one of the decoded event types above, or nil if the event type has no decoder
+	DecodedEvent interface{}
+}
+
+func (e *CCEventLogEntry) addDigest(algId uint16, digest []uint8) {
+	e.Digests = append(e.Digests, CCDigest{
+		AlgId:   algId,
+		AlgName: el.GetAlgorithmName(algId),
+		Digest:  digest,
+	})
+	e.AlgId = algId
+	e.Digest = digest
+}
+
+func (e *CCEventLogEntry) decodeEvent() {
+	e.EvtTypeName = el.GetEventTypeName(e.EvtType)
+
+	decoded, err := el.DecodeEvent(e.EvtType, e.Event)
+	if err != nil {
+		log.Printf("[decodeEvent] Failed to decode event of type %s: %v", e.EvtTypeName, err)
+		return
+	}
+	e.DecodedEvent = decoded
+}
+
+// GetDigest returns the digest of the given algorithm, verifiers can use it to pick the bank they trust.
+func (e *CCEventLogEntry) GetDigest(algId uint16) ([]uint8, bool) {
+	for _, digest := range e.Digests {
+		if digest.AlgId == algId {
+			return digest.Digest, true
+		}
+	}
+	return nil, false
+}
+
+// toRawEventlogs converts the entries back to the server side eventlog type used by the
+// replay and Secure Boot checks.
+func toRawEventlogs(eventlogs []CCEventLogEntry) []el.TDEventLog {
+	var rawEventlogs []el.TDEventLog
+	for _, eventlog := range eventlogs {
+		rawEventlog := el.TDEventLog{
+			Rtmr:        eventlog.RegIdx,
+			Etype:       eventlog.EvtType,
+			DigestCount: uint32(len(eventlog.Digests)),
+			EventSize:   eventlog.EvtSize,
+			Event:       eventlog.Event,
+		}
+		for _, digest := range eventlog.Digests {
+			rawEventlog.Digests = append(rawEventlog.Digests, el.TDEventLogDigest{AlgorithmId: digest.AlgId, Digest: digest.Digest})
+		}
+		rawEventlogs = append(rawEventlogs, rawEventlog)
+	}
+	return rawEventlogs
+}
+
 type GetPlatformEventlogOptions struct {
 	eventlogCategory pb.CATEGORY
 	startPosition    int32
 	count            int32
+	sharedFile       bool
+	containerId      string
+	registerIndexes  []uint32
+	eventTypes       []uint32
+	eventTypeRange   *pb.EventTypeRange
+	algorithmId      uint16
+	pageToken        string
+	eventlogLevel    pb.LEVEL
+	snapshot         bool
+	startSequence    uint64
+	resume           bool
+}
+
+// EventlogPage is the range of the event logs returned by the server. The start position and
+// count apply to the events matching the filters, whose number is the total count.
+type EventlogPage struct {
+	TotalCount    int32
+	StartPosition int32
+	Count         int32
+	// NextPageToken fetches the next page with WithPageToken, it is empty after the last page
+	NextPageToken string
 }
 
 func WithEventlogCategory(eventlogCategory pb.CATEGORY) func(*GetPlatformEventlogOptions) {
@@ -55,6 +161,54 @@ func WithCount(count int32) func(*GetPlatformEventlogOptions) {
 	}
 }
 
+// WithRegisterIndexes only returns the TDX or TPM events extended into one of the registers.
+func WithRegisterIndexes(registerIndexes ...uint32) func(*GetPlatformEventlogOptions) {
+	return func(opts *GetPlatformEventlogOptions) {
+		opts.registerIndexes = registerIndexes
+	}
+}
+
+// WithEventTypes only returns the TDX or TPM events of one of the event types.
+func WithEventTypes(eventTypes ...uint32) func(*GetPlatformEventlogOptions) {
+	return func(opts *GetPlatformEventlogOptions) {
+		opts.eventTypes = eventTypes
+	}
+}
+
+// WithEventTypeRange only returns the TDX or TPM events with an event type from min to max inclusive.
+func WithEventTypeRange(min uint32, max uint32) func(*GetPlatformEventlogOptions) {
+	return func(opts *GetPlatformEventlogOptions) {
+		opts.eventTypeRange = &pb.EventTypeRange{Min: min, Max: max}
+	}
+}
+
+// WithAlgorithmId only returns the digests of the algorithm, the events without it are left out.
+func WithAlgorithmId(algorithmId uint16) func(*GetPlatformEventlogOptions) {
+	return func(opts *GetPlatformEventlogOptions) {
+		opts.algorithmId = algorithmId
+	}
+}
+
+// WithPageToken fetches the page following the one the token was returned with, in place of the
+// start position. The other options must be the same as for that page.
+func WithPageToken(pageToken string) func(*GetPlatformEventlogOptions) {
+	return func(opts *GetPlatformEventlogOptions) {
+		opts.pageToken = pageToken
+	}
+}
+
+// WithSharedFile fetches the event log through the file written by the server
+// instead of streaming it, for GetPlatformEventlog, GetImaEventlog and
+// GetContainerEventlog. The client reads the file at the location of the reply,
+// so the eventlog directory of the server, EVENTLOG_DIR on the host, must be
+// mounted at the same path in the client, otherwise EventlogDirNotMountedErr is
+// returned. The event logs are streamed by default, without any mount.
+func WithSharedFile(sharedFile bool) func(*GetPlatformEventlogOptions) {
+	return func(opts *GetPlatformEventlogOptions) {
+		opts.sharedFile = sharedFile
+	}
+}
+
 func isEventlogCategoryValid(eventlogCategory pb.CATEGORY) bool {
 	return eventlogCategory == pb.CATEGORY_TDX_EVENTLOG || eventlogCategory == pb.CATEGORY_TPM_EVENTLOG
 }
@@ -62,73 +216,130 @@ func isEventlogCategoryValid(eventlogCategory pb.CATEGORY) bool {
 func getRawEventlogs(response *pb.GetEventlogReply) ([]byte, error) {
 	path := response.EventlogDataLoc
 	if path == "" {
-		log.Fatalf("[getRawEventlogs] Failed to get eventlog from server")
+		return nil, EventlogNotReturnedErr
 	}
 
 	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, pkgerrors.Wrapf(EventlogDirNotMountedErr, "reading eventlog from %v", path)
+	}
 	if err != nil {
-		log.Fatalf("[getRawEventlogs] Error reading data from  %v: %v", path, err)
+		return nil, pkgerrors.Wrapf(err, "reading eventlog from %v", path)
+	}
+
+	// the server returns the digest of the eventlog file content written for this request
+	digest := sha256.Sum256(data)
+	if response.EventlogDataDigest != "" && hex.EncodeToString(digest[:]) != response.EventlogDataDigest {
+		return nil, EventlogDigestMismatchErr
 	}
 
 	return data, nil
 }
 
 func parseTdxEventlog(rawEventlog []byte) ([]CCEventLogEntry, error) {
-	var jsonEventlog = el.TDEventLogs{}
-	err := json.Unmarshal(rawEventlog, &jsonEventlog)
+	// UnmarshalEventlogs accepts both the versioned schema and the legacy format
+	jsonEventlog, err := el.UnmarshalEventlogs(rawEventlog)
 	if err != nil {
 		log.Fatalf("[parseEventlog] Error unmarshal raw eventlog: %v", err)
 	}
 
-	rawEventLogList := jsonEventlog.EventLogs
+	return fromRawEventlogs(jsonEventlog.EventLogs), nil
+}
+
+// fromRawEventlogs converts the server side eventlog type into entries, events without
+// digest are skipped.
+func fromRawEventlogs(rawEventLogList []el.TDEventLog) []CCEventLogEntry {
 	var parsedEventLogList []CCEventLogEntry
 	for i := 0; i < len(rawEventLogList); i++ {
 		rawEventlog := rawEventLogList[i]
 		eventLog := CCEventLogEntry{}
 
-		if rawEventlog.DigestCount < 1 {
+		if len(rawEventlog.Digests) < 1 {
 			continue
 		}
 
 		eventLog.RegIdx = rawEventlog.Rtmr
 		eventLog.EvtType = rawEventlog.Etype
 		eventLog.EvtSize = rawEventlog.EventSize
-		eventLog.AlgId = rawEventlog.AlgorithmId
 		eventLog.Event = rawEventlog.Event
-		eventLog.Digest = []uint8(rawEventlog.Digests[rawEventlog.DigestCount-1])
+		eventLog.decodeEvent()
+		for _, digest := range rawEventlog.Digests {
+			eventLog.addDigest(digest.AlgorithmId, digest.Digest)
+		}
 		parsedEventLogList = append(parsedEventLogList, eventLog)
 
 	}
 
-	return parsedEventLogList, nil
+	return parsedEventLogList
 }
 
 func GetPlatformEventlog(opts ...func(*GetPlatformEventlogOptions)) ([]CCEventLogEntry, error) {
+	eventlogs, _, err := GetPlatformEventlogPage(opts...)
+	return eventlogs, err
+}
 
-	input := GetPlatformEventlogOptions{eventlogCategory: pb.CATEGORY_TDX_EVENTLOG, startPosition: 0, count: 0}
-	for _, opt := range opts {
-		opt(&input)
-	}
+// GetPlatformEventlogPage fetches the event logs like GetPlatformEventlog along with their range, see EventlogIterator
+// to walk a large event log page by page.
+func GetPlatformEventlogPage(opts ...func(*GetPlatformEventlogOptions)) ([]CCEventLogEntry, EventlogPage, error) {
 
-	if !isEventlogCategoryValid(input.eventlogCategory) {
-		log.Fatalf("[GetPlatformEventlog] Invalid eventlogCategory specified")
+	input := getPlatformEventlogOptions("GetPlatformEventlog", opts...)
+
+	channel, err := grpc.Dial(UDS_PATH, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("[GetPlatformEventlog] can not connect to UDS: %v", err)
 	}
+	defer channel.Close()
 
-	if input.eventlogCategory == pb.CATEGORY_TPM_EVENTLOG {
-		log.Fatalf("[GetPlatformEventlog] TPM to be supported later")
+	client := pb.NewEventlogClient(channel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	request := getEventlogRequest(input)
+
+	if !input.sharedFile {
+		return getStreamEventlogs(ctx, client, request)
 	}
 
-	if input.startPosition < 0 {
-		log.Fatalf("[GetPlatformEventlog] Invalid startPosition specified")
+	response, err := client.GetEventlog(ctx, request)
+	if err != nil {
+		log.Fatalf("[GetPlatformEventlog] fail to get Platform Eventlog: %v", err)
 	}
 
-	if input.count < 0 {
-		log.Fatalf("[GetPlatformEventlog] Invalid count specified")
+	page := getReplyPage(response)
+
+	switch input.eventlogCategory {
+	case pb.CATEGORY_TDX_EVENTLOG, pb.CATEGORY_TPM_EVENTLOG:
+		// TPM eventlog shares the same format with TDX eventlog
+		rawEventlog, err := getRawEventlogs(response)
+		if err != nil {
+			return nil, EventlogPage{}, err
+		}
+
+		eventlogs, err := parseTdxEventlog(rawEventlog)
+		return eventlogs, page, err
+
+	default:
+		log.Fatalf("[GetPlatformEventlog] unknown TEE enviroment!")
 	}
 
+	return nil, EventlogPage{}, nil
+}
+
+// WriteRawEventlog writes the original binary TDX or TPM event log to w, the Spec ID event
+// followed by the events, so that the same evidence can be checked with independent tools
+// such as tpm2_eventlog. The event log is returned in the reply of the server. The algorithm
+// option is not supported, the events keep all their digests.
+//
+//	file, _ := os.Create("eventlog.bin")
+//	page, err := WriteRawEventlog(file, WithEventlogCategory(pb.CATEGORY_TDX_EVENTLOG))
+func WriteRawEventlog(w io.Writer, opts ...func(*GetPlatformEventlogOptions)) (EventlogPage, error) {
+
+	input := getPlatformEventlogOptions("WriteRawEventlog", opts...)
+
 	channel, err := grpc.Dial(UDS_PATH, grpc.WithInsecure())
 	if err != nil {
-		log.Fatalf("[GetPlatformEventlog] can not connect to UDS: %v", err)
+		log.Fatalf("[WriteRawEventlog] can not connect to UDS: %v", err)
 	}
 	defer channel.Close()
 
@@ -137,30 +348,139 @@ func GetPlatformEventlog(opts ...func(*GetPlatformEventlogOptions)) ([]CCEventLo
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
-	response, err := client.GetEventlog(ctx, &pb.GetEventlogRequest{
+	request := getEventlogRequest(input)
+	request.EventlogFormat = pb.FORMAT_RAW
+
+	response, err := client.GetEventlog(ctx, request)
+	if err != nil {
+		log.Fatalf("[WriteRawEventlog] fail to get Platform Eventlog: %v", err)
+	}
+
+	/* the binary event log holds at least the Spec ID event */
+	if len(response.EventlogData) == 0 {
+		return EventlogPage{}, EventlogNotReturnedErr
+	}
+
+	if _, err = w.Write(response.EventlogData); err != nil {
+		return EventlogPage{}, err
+	}
+
+	return getReplyPage(response), nil
+}
+
+func getPlatformEventlogOptions(caller string, opts ...func(*GetPlatformEventlogOptions)) GetPlatformEventlogOptions {
+
+	input := GetPlatformEventlogOptions{eventlogCategory: pb.CATEGORY_TDX_EVENTLOG, startPosition: 0, count: 0}
+	for _, opt := range opts {
+		opt(&input)
+	}
+
+	if !isEventlogCategoryValid(input.eventlogCategory) {
+		log.Fatalf("[%s] Invalid eventlogCategory specified", caller)
+	}
+
+	if input.startPosition < 0 {
+		log.Fatalf("[%s] Invalid startPosition specified", caller)
+	}
+
+	if input.count < 0 {
+		log.Fatalf("[%s] Invalid count specified", caller)
+	}
+
+	return input
+}
+
+func getEventlogRequest(input GetPlatformEventlogOptions) *pb.GetEventlogRequest {
+	return &pb.GetEventlogRequest{
 		EventlogLevel:    pb.LEVEL_PAAS,
 		EventlogCategory: input.eventlogCategory,
 		StartPosition:    input.startPosition,
 		Count:            input.count,
-	})
+		RegisterIndexes:  input.registerIndexes,
+		EventTypes:       input.eventTypes,
+		EventTypeRange:   input.eventTypeRange,
+		AlgorithmId:      uint32(input.algorithmId),
+		PageToken:        input.pageToken,
+	}
+}
+
+func getReplyPage(response *pb.GetEventlogReply) EventlogPage {
+	return EventlogPage{
+		TotalCount:    response.TotalCount,
+		StartPosition: response.StartPosition,
+		Count:         response.Count,
+		NextPageToken: response.NextPageToken,
+	}
+}
+
+func getStreamEventlogs(ctx context.Context, client pb.EventlogClient, request *pb.GetEventlogRequest) ([]CCEventLogEntry, EventlogPage, error) {
+	stream, err := client.GetEventlogStream(ctx, request)
 	if err != nil {
-		log.Fatalf("[GetPlatformEventlog] fail to get Platform Eventlog: %v", err)
+		log.Fatalf("[getStreamEventlogs] fail to get Platform Eventlog: %v", err)
 	}
 
-	switch input.eventlogCategory {
-	case pb.CATEGORY_TDX_EVENTLOG:
-		rawEventlog, err := getRawEventlogs(response)
+	header, err := stream.Header()
+	if err != nil {
+		log.Fatalf("[getStreamEventlogs] fail to get Platform Eventlog: %v", err)
+	}
+	page := getStreamEventlogPage(header)
+
+	var parsedEventLogList []CCEventLogEntry
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			log.Fatalf("[GetPlatformEventlog] fail to get raw eventlog: %v", err)
+			log.Fatalf("[getStreamEventlogs] fail to receive Platform Eventlog: %v", err)
 		}
 
-		return parseTdxEventlog(rawEventlog)
+		eventLog, ok := getStreamEventlogEntry(entry)
+		if !ok {
+			continue
+		}
+		parsedEventLogList = append(parsedEventLogList, eventLog)
+	}
 
-	case pb.CATEGORY_TPM_EVENTLOG:
-		return nil, pkgerrors.New("[GetPlatformEventlog] vTPM to be supported later")
-	default:
-		log.Fatalf("[GetPlatformEventlog] unknown TEE enviroment!")
+	return parsedEventLogList, page, nil
+}
+
+/* Entries without a digest are left out */
+func getStreamEventlogEntry(entry *pb.EventlogEntry) (CCEventLogEntry, bool) {
+	digests := entry.GetDigests()
+	if len(digests) < 1 {
+		return CCEventLogEntry{}, false
 	}
 
-	return nil, nil
+	eventLog := CCEventLogEntry{}
+	eventLog.RegIdx = entry.RegisterIndex
+	eventLog.EvtType = entry.EventType
+	eventLog.EvtSize = entry.EventSize
+	eventLog.Event = entry.Event
+	eventLog.decodeEvent()
+	for _, digest := range digests {
+		eventLog.addDigest(uint16(digest.AlgorithmId), digest.Digest)
+	}
+	return eventLog, true
+}
+
+/* The server returns the page of the stream in the header metadata */
+func getStreamEventlogPage(header metadata.MD) EventlogPage {
+	getValue := func(key string) string {
+		if values := header.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	getInt32 := func(key string) int32 {
+		value, _ := strconv.ParseInt(getValue(key), 10, 32)
+		return int32(value)
+	}
+
+	return EventlogPage{
+		TotalCount:    getInt32(el.EVENTLOG_TOTAL_COUNT_KEY),
+		StartPosition: getInt32(el.EVENTLOG_START_POSITION_KEY),
+		Count:         getInt32(el.EVENTLOG_COUNT_KEY),
+		NextPageToken: getValue(el.EVENTLOG_NEXT_PAGE_TOKEN_KEY),
+	}
 }
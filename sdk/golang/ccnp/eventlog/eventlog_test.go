@@ -6,9 +6,30 @@
 package eventlog
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	pb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/eventlog/proto"
+	"github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/replay"
+	el "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+	pkgerrors "github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 func TestGetPlatformEventlogDefault(t *testing.T) {
@@ -79,3 +100,561 @@ func TestGetPlatformEventlogWithAllOptions(t *testing.T) {
 	}
 
 }
+
+func TestGetPlatformEventlogWithSharedFile(t *testing.T) {
+
+	eventlogs, err := GetPlatformEventlog(WithSharedFile(true), WithStartPosition(2), WithCount(3))
+
+	if err != nil {
+		t.Fatalf("[TestGetPlatformEventlogWithSharedFile] get Platform Eventlog error: %v", err)
+	}
+
+	if len(eventlogs) != 3 {
+		t.Fatalf("[TestGetPlatformEventlogWithSharedFile] error: expected number of logs is 3, retrieved %v", len(eventlogs))
+	}
+
+}
+
+func TestWriteRawEventlog(t *testing.T) {
+	var buf bytes.Buffer
+
+	page, err := WriteRawEventlog(&buf, WithCount(3))
+
+	if err != nil {
+		t.Fatalf("[TestWriteRawEventlog] write raw Eventlog error: %v", err)
+	}
+
+	// the binary event log starts with the Spec ID event
+	data := buf.Bytes()
+	if page.Count != 3 || len(data) < 8 || binary.LittleEndian.Uint32(data[4:8]) != el.EVENT_TYPE_EV_NO_ACTION {
+		t.Fatalf("[TestWriteRawEventlog] error: expected Spec ID event and 3 events, retrieved %v events", page.Count)
+	}
+}
+
+func TestGetPlatformEventlogWithFilters(t *testing.T) {
+
+	eventlogs, err := GetPlatformEventlog(WithRegisterIndexes(1, 2), WithEventTypeRange(el.EVENT_TYPE_EV_EFI_EVENT_BASE, el.EVENT_TYPE_EV_EFI_HCRTM_EVENT),
+		WithAlgorithmId(el.TPM_ALG_SHA384))
+
+	if err != nil {
+		t.Fatalf("[TestGetPlatformEventlogWithFilters] get Platform Eventlog error: %v", err)
+	}
+
+	for _, eventlog := range eventlogs {
+		if eventlog.RegIdx < 1 || eventlog.RegIdx > 2 || eventlog.EvtType < el.EVENT_TYPE_EV_EFI_EVENT_BASE ||
+			eventlog.EvtType > el.EVENT_TYPE_EV_EFI_HCRTM_EVENT || len(eventlog.Digests) != 1 || eventlog.AlgId != el.TPM_ALG_SHA384 {
+			t.Fatalf("[TestGetPlatformEventlogWithFilters] error: event not matching the filters %+v", eventlog)
+		}
+	}
+}
+
+func TestEventlogIterator(t *testing.T) {
+	var requests []GetPlatformEventlogOptions
+	total := int32(5)
+
+	it := NewEventlogIterator(2, WithEventlogCategory(pb.CATEGORY_TPM_EVENTLOG))
+	it.getPage = func(opts ...func(*GetPlatformEventlogOptions)) ([]CCEventLogEntry, EventlogPage, error) {
+		input := GetPlatformEventlogOptions{}
+		for _, opt := range opts {
+			opt(&input)
+		}
+		requests = append(requests, input)
+
+		start := int32(0)
+		if input.pageToken != "" {
+			value, _ := strconv.Atoi(input.pageToken)
+			start = int32(value)
+		}
+		end := start + input.count
+		if end > total {
+			end = total
+		}
+
+		page := EventlogPage{TotalCount: total, StartPosition: start, Count: end - start}
+		if end < total {
+			page.NextPageToken = strconv.Itoa(int(end))
+		}
+
+		var eventlogs []CCEventLogEntry
+		for i := start; i < end; i++ {
+			eventlogs = append(eventlogs, CCEventLogEntry{RegIdx: uint32(i)})
+		}
+		return eventlogs, page, nil
+	}
+
+	var regIdxs []uint32
+	for it.Next() {
+		if it.Page().StartPosition != int32(len(regIdxs)) || it.Page().TotalCount != total {
+			t.Fatalf("[TestEventlogIterator] error: unexpected page %+v", it.Page())
+		}
+		for _, eventlog := range it.Eventlogs() {
+			regIdxs = append(regIdxs, eventlog.RegIdx)
+		}
+	}
+
+	if it.Err() != nil || len(regIdxs) != 5 || regIdxs[4] != 4 || len(requests) != 3 {
+		t.Fatalf("[TestEventlogIterator] error: retrieved %v in %d requests, %v", regIdxs, len(requests), it.Err())
+	}
+	for _, request := range requests {
+		if request.eventlogCategory != pb.CATEGORY_TPM_EVENTLOG || request.count != 2 {
+			t.Fatalf("[TestEventlogIterator] error: options not kept across pages %+v", request)
+		}
+	}
+}
+
+func TestGetStreamEventlogPage(t *testing.T) {
+	header := metadata.Pairs(el.EVENTLOG_TOTAL_COUNT_KEY, "10", el.EVENTLOG_START_POSITION_KEY, "4",
+		el.EVENTLOG_COUNT_KEY, "2", el.EVENTLOG_NEXT_PAGE_TOKEN_KEY, "token")
+
+	page := getStreamEventlogPage(header)
+	if page.TotalCount != 10 || page.StartPosition != 4 || page.Count != 2 || page.NextPageToken != "token" {
+		t.Fatalf("[TestGetStreamEventlogPage] error: unexpected page %+v", page)
+	}
+}
+
+func TestGetPlatformEventlogWithIterator(t *testing.T) {
+	all, err := GetPlatformEventlog()
+	if err != nil {
+		t.Fatalf("[TestGetPlatformEventlogWithIterator] get Platform Eventlog error: %v", err)
+	}
+
+	count := 0
+	it := NewEventlogIterator(7)
+	for it.Next() {
+		count += len(it.Eventlogs())
+	}
+
+	if it.Err() != nil || count != len(all) {
+		t.Fatalf("[TestGetPlatformEventlogWithIterator] error: expected %d logs, retrieved %d, %v", len(all), count, it.Err())
+	}
+}
+
+func TestGetRawEventlogsWithDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eventlog.log")
+	data := []byte("{}")
+	digest := sha256.Sum256(data)
+
+	err := os.WriteFile(path, data, 0644)
+	if err != nil {
+		t.Fatalf("[TestGetRawEventlogsWithDigest] write eventlog error: %v", err)
+	}
+
+	rawEventlog, err := getRawEventlogs(&pb.GetEventlogReply{EventlogDataLoc: path, EventlogDataDigest: hex.EncodeToString(digest[:])})
+	if err != nil || string(rawEventlog) != "{}" {
+		t.Fatalf("[TestGetRawEventlogsWithDigest] get raw eventlog error: %v", err)
+	}
+
+	_, err = getRawEventlogs(&pb.GetEventlogReply{EventlogDataLoc: path, EventlogDataDigest: "00"})
+	if err != EventlogDigestMismatchErr {
+		t.Fatalf("[TestGetRawEventlogsWithDigest] error: expected %v, retrieved %v", EventlogDigestMismatchErr, err)
+	}
+	_, err = getRawEventlogs(&pb.GetEventlogReply{})
+	if err != EventlogNotReturnedErr {
+		t.Fatalf("[TestGetRawEventlogsWithDigest] error: expected %v, retrieved %v", EventlogNotReturnedErr, err)
+	}
+
+	_, err = getRawEventlogs(&pb.GetEventlogReply{EventlogDataLoc: filepath.Join(t.TempDir(), "missing.log")})
+	if pkgerrors.Cause(err) != EventlogDirNotMountedErr {
+		t.Fatalf("[TestGetRawEventlogsWithDigest] error: expected %v, retrieved %v", EventlogDirNotMountedErr, err)
+	}
+}
+
+func TestParseTdxEventlogWithMultipleDigests(t *testing.T) {
+	eventlogs := el.TDEventLogs{
+		Header: el.TDEventLogSpecIdHeader{
+			DigestSizes: map[uint16]uint16{el.TPM_ALG_SHA256: 2, el.TPM_ALG_SHA384: 3},
+		},
+		EventLogs: []el.TDEventLog{
+			{
+				Rtmr:        1,
+				Etype:       0x80000001,
+				DigestCount: 2,
+				Digests: []el.TDEventLogDigest{
+					{AlgorithmId: el.TPM_ALG_SHA256, Digest: []byte{0x1, 0x2}},
+					{AlgorithmId: el.TPM_ALG_SHA384, Digest: []byte{0x3, 0x4, 0x5}},
+				},
+			},
+		},
+	}
+
+	for _, legacy := range []bool{false, true} {
+		rawEventlog, _ := el.MarshalEventlogs(eventlogs, legacy)
+		parsedEventlogs, err := parseTdxEventlog([]byte(rawEventlog))
+		if err != nil || len(parsedEventlogs) != 1 {
+			t.Fatalf("[TestParseTdxEventlogWithMultipleDigests] parse eventlog error: %v", err)
+		}
+
+		checkParsedEventlogDigests(t, parsedEventlogs[0])
+	}
+}
+
+func checkParsedEventlogDigests(t *testing.T, eventlog CCEventLogEntry) {
+	if len(eventlog.Digests) != 2 || eventlog.Digests[0].AlgName != "SHA256" || eventlog.Digests[1].AlgName != "SHA384" {
+		t.Fatalf("[TestParseTdxEventlogWithMultipleDigests] error: expected SHA256 and SHA384 digests, retrieved %v", eventlog.Digests)
+	}
+
+	digest, ok := eventlog.GetDigest(el.TPM_ALG_SHA256)
+	if !ok || !bytes.Equal(digest, []byte{0x1, 0x2}) {
+		t.Fatalf("[TestParseTdxEventlogWithMultipleDigests] error: expected SHA256 digest, retrieved %v", digest)
+	}
+
+	if eventlog.AlgId != el.TPM_ALG_SHA384 || !bytes.Equal(eventlog.Digest, []byte{0x3, 0x4, 0x5}) {
+		t.Fatalf("[TestParseTdxEventlogWithMultipleDigests] error: expected last digest SHA384, retrieved %v", eventlog.Digest)
+	}
+}
+
+func TestParseTdxEventlogWithDecodedEvent(t *testing.T) {
+	eventlogs := el.TDEventLogs{
+		Header: el.TDEventLogSpecIdHeader{
+			DigestSizes: map[uint16]uint16{el.TPM_ALG_SHA384: 1},
+		},
+		EventLogs: []el.TDEventLog{
+			{
+				Rtmr:        1,
+				Etype:       el.EVENT_TYPE_EV_SEPARATOR,
+				DigestCount: 1,
+				Digests:     []el.TDEventLogDigest{{AlgorithmId: el.TPM_ALG_SHA384, Digest: []byte{0x1}}},
+				EventSize:   4,
+				Event:       []byte{0, 0, 0, 0},
+			},
+		},
+	}
+
+	rawEventlog, _ := el.MarshalEventlogs(eventlogs, false)
+	parsedEventlogs, err := parseTdxEventlog([]byte(rawEventlog))
+	if err != nil || len(parsedEventlogs) != 1 {
+		t.Fatalf("[TestParseTdxEventlogWithDecodedEvent] parse eventlog error: %v", err)
+	}
+
+	separator, ok := parsedEventlogs[0].DecodedEvent.(SeparatorEvent)
+	if parsedEventlogs[0].EvtTypeName != "EV_SEPARATOR" || !ok || separator.Error {
+		t.Fatalf("[TestParseTdxEventlogWithDecodedEvent] error: expected EV_SEPARATOR event, retrieved %v %v",
+			parsedEventlogs[0].EvtTypeName, parsedEventlogs[0].DecodedEvent)
+	}
+}
+
+func TestEncodeDecodeCel(t *testing.T) {
+	eventlogs := []CCEventLogEntry{{RegIdx: 1, EvtType: el.EVENT_TYPE_EV_SEPARATOR, EvtSize: 4, Event: []uint8{0, 0, 0, 0}}}
+	eventlogs[0].addDigest(el.TPM_ALG_SHA256, []byte{0x1, 0x2})
+	eventlogs[0].addDigest(el.TPM_ALG_SHA384, []byte{0x3, 0x4, 0x5})
+
+	for _, format := range []pb.FORMAT{pb.FORMAT_CEL_JSON, pb.FORMAT_CEL_CBOR, pb.FORMAT_CEL_TLV} {
+		cel, err := EncodeCel(eventlogs, format)
+		if err != nil {
+			t.Fatalf("[TestEncodeDecodeCel] encode %v error: %v", format, err)
+		}
+
+		decoded, err := DecodeCel(cel, format)
+		if err != nil || len(decoded) != 1 {
+			t.Fatalf("[TestEncodeDecodeCel] decode %v error: %v", format, err)
+		}
+
+		checkParsedEventlogDigests(t, decoded[0])
+		if decoded[0].RegIdx != 1 || decoded[0].EvtSize != 4 || decoded[0].EvtTypeName != "EV_SEPARATOR" || decoded[0].DecodedEvent == nil {
+			t.Fatalf("[TestEncodeDecodeCel] error: expected EV_SEPARATOR event of RTMR 1, retrieved %+v", decoded[0])
+		}
+	}
+
+	if _, err := DecodeCel([]uint8("{}"), pb.FORMAT_CEL_JSON); err == nil {
+		t.Fatalf("[TestEncodeDecodeCel] error: expected error decoding invalid CEL")
+	}
+}
+
+func TestGetSecureBootStateFromEventlog(t *testing.T) {
+	// UEFI_VARIABLE_DATA of SecureBoot variable with value 1
+	event := append([]byte{}, el.EFI_GLOBAL_VARIABLE_GUID[:]...)
+	event = append(event, 10, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0)
+	for _, c := range "SecureBoot" {
+		event = append(event, byte(c), 0)
+	}
+	event = append(event, 1)
+
+	eventlogs := []CCEventLogEntry{{EvtType: el.EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG, Event: event}}
+	state, err := GetSecureBootStateFromEventlog(eventlogs)
+	if err != nil || !state.SecureBootMeasured || !state.SecureBootEnabled {
+		t.Fatalf("[TestGetSecureBootStateFromEventlog] error: expected Secure Boot enabled, retrieved %+v, %v", state, err)
+	}
+}
+
+func TestEvaluatePolicyFromEventlog(t *testing.T) {
+	referencePolicy, err := LoadPolicy([]byte("digests:\n  - {register_index: 1, event_type: EV_IPL, algorithm: SHA384, digests: ['" +
+		strings.Repeat("ab", 48) + "']}\nkernel_cmdlines: ['/vmlinuz console=hvc0']\n"))
+	if err != nil {
+		t.Fatalf("[TestEvaluatePolicyFromEventlog] load policy error: %v", err)
+	}
+
+	eventlogs := []CCEventLogEntry{{RegIdx: 1, EvtType: el.EVENT_TYPE_EV_IPL, Event: []byte("kernel_cmdline: /vmlinuz console=hvc0\x00"),
+		Digests: []CCDigest{{AlgId: el.TPM_ALG_SHA384, Digest: bytes.Repeat([]byte{0xab}, 48)}}}}
+	result, err := EvaluatePolicyFromEventlog(referencePolicy, eventlogs)
+	if err != nil || !result.Passed || len(result.Checks) != 2 {
+		t.Fatalf("[TestEvaluatePolicyFromEventlog] error: expected 2 passed checks, retrieved %+v, %v", result, err)
+	}
+
+	eventlogs[0].Digests[0].Digest = bytes.Repeat([]byte{0xcd}, 48)
+	result, err = EvaluatePolicyFromEventlog(referencePolicy, eventlogs)
+	if err != nil || result.Passed || len(result.Checks[0].Violations) != 1 {
+		t.Fatalf("[TestEvaluatePolicyFromEventlog] error: expected a digest violation, retrieved %+v, %v", result, err)
+	}
+}
+
+func TestEvaluatePolicyOnServer(t *testing.T) {
+	result, err := EvaluatePolicyOnServer("kernel_cmdlines: ['/vmlinuz console=hvc0']\n")
+	if err != nil {
+		t.Fatalf("[TestEvaluatePolicyOnServer] evaluate policy error: %v", err)
+	}
+
+	if len(result.Checks) != 1 || result.Checks[0].Rule != "kernel_cmdline" {
+		t.Fatalf("[TestEvaluatePolicyOnServer] error: expected the kernel command line check, retrieved %+v", result)
+	}
+}
+
+func TestParseImaEventlog(t *testing.T) {
+	eventlogs := el.ImaEventLogs{
+		Version: el.IMA_EVENTLOG_VERSION,
+		EventLogs: []el.ImaEventLog{
+			{
+				PcrIndex:            10,
+				RtmrIndex:           el.GetRtmrIndexByPcr(10),
+				TemplateHash:        []byte{0xa, 0xb},
+				TemplateName:        el.IMA_TEMPLATE_IMA_SIG,
+				FileDigestAlgorithm: "sha256",
+				FileDigest:          []byte{0x1, 0x2},
+				FileName:            "/usr/bin/ls",
+				Signature:           []byte{0x3, 0x2},
+			},
+		},
+	}
+
+	rawEventlog, _ := el.MarshalImaEventlogs(eventlogs)
+	parsedEventlogs, err := parseImaEventlog([]byte(rawEventlog))
+	if err != nil || len(parsedEventlogs) != 1 {
+		t.Fatalf("[TestParseImaEventlog] parse eventlog error: %v", err)
+	}
+
+	entry := parsedEventlogs[0]
+	if entry.PcrIdx != 10 || entry.RtmrIdx != 2 || entry.TemplateName != "ima-sig" || entry.FileName != "/usr/bin/ls" ||
+		!bytes.Equal(entry.TemplateHash, []byte{0xa, 0xb}) || !bytes.Equal(entry.Signature, []byte{0x3, 0x2}) {
+		t.Fatalf("[TestParseImaEventlog] error: unexpected IMA entry %+v", entry)
+	}
+}
+
+func TestGetImaEventlog(t *testing.T) {
+	eventlogs, err := GetImaEventlog(WithCount(1))
+	if err != nil || len(eventlogs) != 1 {
+		t.Fatalf("[TestGetImaEventlog] error: expected 1 IMA entry, retrieved %d, %v", len(eventlogs), err)
+	}
+}
+
+func TestParseContainerEventlog(t *testing.T) {
+	store, _ := el.NewContainerEventStore("", func([]byte, []byte) error { return nil })
+	store.Record("c1", "default/nginx", el.CONTAINER_EVENT_IMAGE, []byte("nginx@sha256:00"))
+	store.Record("c1", "default/nginx", el.CONTAINER_EVENT_START, []byte("nginx"))
+
+	eventlogs, _ := store.GetEventlogs("default/nginx", 0, 0)
+	rawEventlog, _ := el.MarshalContainerEventlogs(eventlogs)
+	parsedEventlogs, err := parseContainerEventlog([]byte(rawEventlog))
+	if err != nil || len(parsedEventlogs) != 2 {
+		t.Fatalf("[TestParseContainerEventlog] parse eventlog error: %v", err)
+	}
+
+	entry := parsedEventlogs[1]
+	if entry.Sequence != 1 || entry.ContainerId != "c1" || entry.PodId != "default/nginx" || entry.EvtTypeName != "CONTAINER_START" ||
+		entry.AlgId != el.TPM_ALG_SHA384 || len(entry.Digest) != 48 || string(entry.Event) != "nginx" || entry.Timestamp.IsZero() {
+		t.Fatalf("[TestParseContainerEventlog] error: unexpected container entry %+v", entry)
+	}
+}
+
+func TestGetContainerEventlog(t *testing.T) {
+	sequence, err := RecordContainerEvent("ccnp-sdk-test", "", pb.CONTAINER_EVENT_TYPE_CONTAINER_START, []byte("test"))
+	if err != nil {
+		t.Fatalf("[TestGetContainerEventlog] record event error: %v", err)
+	}
+
+	eventlogs, err := GetContainerEventlog(WithContainerId("ccnp-sdk-test"))
+	if err != nil || len(eventlogs) == 0 || eventlogs[len(eventlogs)-1].Sequence != sequence {
+		t.Fatalf("[TestGetContainerEventlog] error: expected event %d, retrieved %v, %v", sequence, eventlogs, err)
+	}
+}
+
+/* watchTestServer serves a container event log of 5 events with 3 in the log when watched */
+type watchTestServer struct {
+	pb.UnimplementedEventlogServer
+	requests chan *pb.WatchEventlogRequest
+	// number of watches interrupted after one event, as by a restart of the server
+	failures int
+	err      error
+}
+
+func (s *watchTestServer) WatchEventlog(req *pb.WatchEventlogRequest, stream pb.Eventlog_WatchEventlogServer) error {
+	s.requests <- req
+	if s.err != nil {
+		return s.err
+	}
+
+	stream.SendHeader(metadata.Pairs(el.EVENTLOG_WATCH_SEQUENCE_KEY, "3"))
+	start := req.StartSequence
+	if !req.Snapshot && !req.Resume {
+		start = 3
+	}
+
+	for i := start; i < 5; i++ {
+		if s.failures > 0 && i == start+1 {
+			s.failures--
+			return status.Error(codes.Unavailable, "server restarting")
+		}
+		stream.Send(&pb.WatchEventlogReply{Sequence: i, Entry: &pb.EventlogEntry{
+			Digests:        []*pb.EventlogDigest{{AlgorithmId: uint32(el.TPM_ALG_SHA384), Digest: []byte{0x1}}},
+			ContainerEntry: &pb.ContainerEventlogEntry{Sequence: uint32(i), ContainerId: "c1"},
+		}})
+	}
+
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestEventlogSubscription(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []func(*GetPlatformEventlogOptions)
+		server       *watchTestServer
+		wantSequence []uint64
+		wantResume   *pb.WatchEventlogRequest
+		wantErr      error
+	}{
+		{"Snapshot resumed", []func(*GetPlatformEventlogOptions){WithSnapshot(true)},
+			&watchTestServer{failures: 1}, []uint64{0, 1, 2, 3, 4},
+			&pb.WatchEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, Snapshot: true, Resume: true, StartSequence: 1}, nil},
+		{"Appended events resumed", nil,
+			&watchTestServer{failures: 1}, []uint64{3, 4},
+			&pb.WatchEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, Resume: true, StartSequence: 4}, nil},
+		{"Start sequence", []func(*GetPlatformEventlogOptions){WithStartSequence(2)},
+			&watchTestServer{}, []uint64{2, 3, 4}, nil, nil},
+		{"Start sequence 0", []func(*GetPlatformEventlogOptions){WithStartSequence(0)},
+			&watchTestServer{}, []uint64{0, 1, 2, 3, 4}, nil, nil},
+		{"Watch failed", nil,
+			&watchTestServer{err: el.InvalidWatchSequenceErr}, nil, nil,
+			status.Error(codes.Unknown, el.InvalidWatchSequenceErr.Error())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.server.requests = make(chan *pb.WatchEventlogRequest, 4)
+			lis := bufconn.Listen(1024 * 1024)
+			server := grpc.NewServer()
+			pb.RegisterEventlogServer(server, tt.server)
+			go server.Serve(lis)
+			defer server.Stop()
+
+			conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+				return lis.Dial()
+			}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatalf("[TestEventlogSubscription] can not connect to server: %v", err)
+			}
+			defer conn.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			input := GetPlatformEventlogOptions{eventlogLevel: pb.LEVEL_SAAS}
+			for _, opt := range tt.opts {
+				opt(&input)
+			}
+			subscription := newEventlogSubscription(input)
+			subscription.retryInterval = time.Millisecond
+			go subscription.run(ctx, pb.NewEventlogClient(conn))
+
+			var sequences []uint64
+			for len(sequences) < len(tt.wantSequence) {
+				event, ok := <-subscription.Events()
+				if !ok {
+					t.Fatalf("[TestEventlogSubscription] subscription ended after %v: %v", sequences, subscription.Err())
+				}
+				if event.ContainerEventlog == nil || uint64(event.ContainerEventlog.Sequence) != event.Sequence ||
+					event.Snapshot != (event.Sequence < 3) {
+					t.Errorf("[TestEventlogSubscription] unexpected event %+v", event)
+				}
+				sequences = append(sequences, event.Sequence)
+			}
+			if !reflect.DeepEqual(sequences, tt.wantSequence) {
+				t.Errorf("[TestEventlogSubscription] error: expected sequences %v, retrieved %v", tt.wantSequence, sequences)
+			}
+
+			<-tt.server.requests
+			if tt.wantResume != nil {
+				resume := <-tt.server.requests
+				if resume.Snapshot != tt.wantResume.Snapshot || resume.Resume != tt.wantResume.Resume ||
+					resume.StartSequence != tt.wantResume.StartSequence {
+					t.Errorf("[TestEventlogSubscription] error: expected resume %v, retrieved %v", tt.wantResume, resume)
+				}
+			}
+
+			if tt.wantErr == nil {
+				cancel()
+			}
+			for range subscription.Events() {
+			}
+			if err := subscription.Err(); (err == nil) != (tt.wantErr == nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("[TestEventlogSubscription] error: expected %v, retrieved %v", tt.wantErr, err)
+			}
+			if len(tt.wantSequence) > 0 && subscription.NextSequence() != 5 {
+				t.Errorf("[TestEventlogSubscription] error: expected next sequence 5, retrieved %d", subscription.NextSequence())
+			}
+		})
+	}
+}
+
+func TestWatchEventlog(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	subscription := WatchEventlog(ctx, WithEventlogLevel(pb.LEVEL_SAAS), WithContainerId("ccnp-sdk-watch-test"))
+	sequence, err := RecordContainerEvent("ccnp-sdk-watch-test", "", pb.CONTAINER_EVENT_TYPE_CONTAINER_START, []byte("test"))
+	if err != nil {
+		t.Fatalf("[TestWatchEventlog] record event error: %v", err)
+	}
+
+	event, ok := <-subscription.Events()
+	if !ok || event.ContainerEventlog == nil || event.ContainerEventlog.Sequence != sequence {
+		t.Fatalf("[TestWatchEventlog] error: expected event %d, retrieved %+v, %v", sequence, event, subscription.Err())
+	}
+}
+
+func TestVerifyTdxEventlog(t *testing.T) {
+	digest := bytes.Repeat([]byte{0x1}, replay.RTMR_LEN)
+	eventlog := CCEventLogEntry{RegIdx: 2, EvtType: 0xd}
+	eventlog.addDigest(el.TPM_ALG_SHA384, digest)
+
+	rtmrs := make([]uint8, replay.RTMR_COUNT*replay.RTMR_LEN)
+	copy(rtmrs[2*replay.RTMR_LEN:], replay.Extend(make([]byte, replay.RTMR_LEN), digest))
+
+	results, err := VerifyTdxEventlog([]CCEventLogEntry{eventlog}, rtmrs)
+	if err != nil || len(results) != replay.RTMR_COUNT {
+		t.Fatalf("[TestVerifyTdxEventlog] verify eventlog error: %v", err)
+	}
+
+	for _, result := range results {
+		if !result.Match {
+			t.Fatalf("[TestVerifyTdxEventlog] error: RTMR %d mismatch, replayed %x measured %x", result.Index, result.Replayed, result.Measured)
+		}
+	}
+
+	rtmrs[0] = 0x1
+	results, _ = VerifyTdxEventlog([]CCEventLogEntry{eventlog}, rtmrs)
+	if results[0].Match {
+		t.Fatalf("[TestVerifyTdxEventlog] error: expected RTMR 0 mismatch")
+	}
+}
+
+func TestReplayTdxEventlog(t *testing.T) {
+	results, err := ReplayTdxEventlog()
+	if err != nil {
+		t.Fatalf("[TestReplayTdxEventlog] replay eventlog error: %v", err)
+	}
+
+	for _, result := range results {
+		if !result.Match {
+			t.Fatalf("[TestReplayTdxEventlog] error: RTMR %d mismatch, replayed %x measured %x", result.Index, result.Replayed, result.Measured)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package eventlog
+
+import (
+	"context"
+	"io"
+	"log"
+	"time"
+
+	pb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/eventlog/proto"
+	el "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+	"google.golang.org/grpc"
+)
+
+type ImaEventLogEntry struct {
+	PcrIdx        uint32
+	RtmrIdx       int32 // -1 if the PCR does not map to an RTMR
+	TemplateHash  []uint8
+	TemplateName  string
+	FileDigestAlg string
+	FileDigest    []uint8
+	FileName      string
+	Signature     []uint8 // only set for ima-sig entries with a file signature
+}
+
+// GetImaEventlog fetches the IMA runtime measurement list. The eventlog category option is ignored.
+func GetImaEventlog(opts ...func(*GetPlatformEventlogOptions)) ([]ImaEventLogEntry, error) {
+
+	input := GetPlatformEventlogOptions{startPosition: 0, count: 0}
+	for _, opt := range opts {
+		opt(&input)
+	}
+
+	if input.startPosition < 0 {
+		log.Fatalf("[GetImaEventlog] Invalid startPosition specified")
+	}
+
+	if input.count < 0 {
+		log.Fatalf("[GetImaEventlog] Invalid count specified")
+	}
+
+	channel, err := grpc.Dial(UDS_PATH, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("[GetImaEventlog] can not connect to UDS: %v", err)
+	}
+	defer channel.Close()
+
+	client := pb.NewEventlogClient(channel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	request := &pb.GetEventlogRequest{
+		EventlogLevel:    pb.LEVEL_PAAS,
+		EventlogCategory: pb.CATEGORY_IMA_EVENTLOG,
+		StartPosition:    input.startPosition,
+		Count:            input.count,
+	}
+
+	if !input.sharedFile {
+		return getStreamImaEventlogs(ctx, client, request)
+	}
+
+	response, err := client.GetEventlog(ctx, request)
+	if err != nil {
+		log.Fatalf("[GetImaEventlog] fail to get IMA Eventlog: %v", err)
+	}
+
+	rawEventlog, err := getRawEventlogs(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseImaEventlog(rawEventlog)
+}
+
+func getStreamImaEventlogs(ctx context.Context, client pb.EventlogClient, request *pb.GetEventlogRequest) ([]ImaEventLogEntry, error) {
+	stream, err := client.GetEventlogStream(ctx, request)
+	if err != nil {
+		log.Fatalf("[getStreamImaEventlogs] fail to get IMA Eventlog: %v", err)
+	}
+
+	var parsedEventLogList []ImaEventLogEntry
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("[getStreamImaEventlogs] fail to receive IMA Eventlog: %v", err)
+		}
+
+		imaEventLog, ok := getStreamImaEventlogEntry(entry)
+		if !ok {
+			continue
+		}
+		parsedEventLogList = append(parsedEventLogList, imaEventLog)
+	}
+
+	return parsedEventLogList, nil
+}
+
+func getStreamImaEventlogEntry(entry *pb.EventlogEntry) (ImaEventLogEntry, bool) {
+	imaEntry := entry.GetImaEntry()
+	if imaEntry == nil || len(entry.GetDigests()) < 1 {
+		return ImaEventLogEntry{}, false
+	}
+
+	return ImaEventLogEntry{
+		PcrIdx:        imaEntry.PcrIndex,
+		RtmrIdx:       imaEntry.RtmrIndex,
+		TemplateHash:  entry.Digests[0].Digest,
+		TemplateName:  imaEntry.TemplateName,
+		FileDigestAlg: imaEntry.FileDigestAlgorithm,
+		FileDigest:    imaEntry.FileDigest,
+		FileName:      imaEntry.FileName,
+		Signature:     imaEntry.Signature,
+	}, true
+}
+
+func parseImaEventlog(rawEventlog []byte) ([]ImaEventLogEntry, error) {
+	jsonEventlog, err := el.UnmarshalImaEventlogs(rawEventlog)
+	if err != nil {
+		log.Fatalf("[parseImaEventlog] Error unmarshal raw eventlog: %v", err)
+	}
+
+	var parsedEventLogList []ImaEventLogEntry
+	for _, rawEventlog := range jsonEventlog.EventLogs {
+		parsedEventLogList = append(parsedEventLogList, ImaEventLogEntry{
+			PcrIdx:        rawEventlog.PcrIndex,
+			RtmrIdx:       int32(rawEventlog.RtmrIndex),
+			TemplateHash:  rawEventlog.TemplateHash,
+			TemplateName:  rawEventlog.TemplateName,
+			FileDigestAlg: rawEventlog.FileDigestAlgorithm,
+			FileDigest:    rawEventlog.FileDigest,
+			FileName:      rawEventlog.FileName,
+			Signature:     rawEventlog.Signature,
+		})
+	}
+
+	return parsedEventLogList, nil
+}
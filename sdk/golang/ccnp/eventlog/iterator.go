@@ -0,0 +1,75 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package eventlog
+
+// EventlogIterator walks the TDX or TPM event log page by page, following the page token
+// returned by the server. The start position option applies to the first page.
+//
+//	it := NewEventlogIterator(100, WithEventlogCategory(pb.CATEGORY_TDX_EVENTLOG))
+//	for it.Next() {
+//		for _, eventlog := range it.Eventlogs() {
+//			...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type EventlogIterator struct {
+	opts      []func(*GetPlatformEventlogOptions)
+	pageSize  int32
+	pageToken string
+	done      bool
+	eventlogs []CCEventLogEntry
+	page      EventlogPage
+	err       error
+	// fetches a page, GetPlatformEventlogPage unless replaced in tests
+	getPage func(opts ...func(*GetPlatformEventlogOptions)) ([]CCEventLogEntry, EventlogPage, error)
+}
+
+// NewEventlogIterator returns an iterator over pages of pageSize events, a pageSize of 0 fetches
+// the event log in a single page.
+func NewEventlogIterator(pageSize int32, opts ...func(*GetPlatformEventlogOptions)) *EventlogIterator {
+	return &EventlogIterator{opts: opts, pageSize: pageSize, getPage: GetPlatformEventlogPage}
+}
+
+// Next fetches the next page, it returns false after the last page or on error.
+func (it *EventlogIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	opts := append(append([]func(*GetPlatformEventlogOptions){}, it.opts...), WithCount(it.pageSize))
+	if it.pageToken != "" {
+		opts = append(opts, WithPageToken(it.pageToken))
+	}
+
+	eventlogs, page, err := it.getPage(opts...)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.eventlogs = eventlogs
+	it.page = page
+	it.pageToken = page.NextPageToken
+	it.done = page.NextPageToken == ""
+
+	return page.Count > 0
+}
+
+// Eventlogs returns the event logs of the current page.
+func (it *EventlogIterator) Eventlogs() []CCEventLogEntry {
+	return it.eventlogs
+}
+
+// Page returns the range of the current page.
+func (it *EventlogIterator) Page() EventlogPage {
+	return it.page
+}
+
+func (it *EventlogIterator) Err() error {
+	return it.err
+}
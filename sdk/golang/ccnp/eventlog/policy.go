@@ -0,0 +1,81 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package eventlog
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	pb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/eventlog/proto"
+	"github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/policy"
+	"google.golang.org/grpc"
+)
+
+// Reference value policy types, see the policy package of the eventlog server
+type (
+	Policy             = policy.Policy
+	DigestReference    = policy.DigestReference
+	AuthorityReference = policy.AuthorityReference
+	PolicyResult       = policy.Result
+	PolicyCheckResult  = policy.CheckResult
+	PolicyViolation    = policy.Violation
+)
+
+// LoadPolicy parses a reference value policy in JSON or YAML.
+func LoadPolicy(data []byte) (Policy, error) {
+	return policy.Load(data)
+}
+
+// EvaluatePolicy fetches the platform event log, TDX by default or TPM with
+// WithEventlogCategory, and evaluates it against the policy.
+func EvaluatePolicy(referencePolicy Policy, opts ...func(*GetPlatformEventlogOptions)) (PolicyResult, error) {
+	eventlogs, err := GetPlatformEventlog(opts...)
+	if err != nil {
+		log.Fatalf("[EvaluatePolicy] fail to get Platform Eventlog: %v", err)
+	}
+
+	return EvaluatePolicyFromEventlog(referencePolicy, eventlogs)
+}
+
+// EvaluatePolicyFromEventlog evaluates the given event logs against the policy.
+func EvaluatePolicyFromEventlog(referencePolicy Policy, eventlogs []CCEventLogEntry) (PolicyResult, error) {
+	return policy.Evaluate(referencePolicy, toRawEventlogs(eventlogs))
+}
+
+// EvaluatePolicyOnServer has the eventlog server evaluate the TDX event log, or the TPM event log
+// with WithEventlogCategory, against the policy in JSON or YAML. The policy configured for the
+// server is evaluated if the policy is empty. The event log is not replayed against the measured
+// registers, a passed result says nothing about its authenticity.
+func EvaluatePolicyOnServer(referencePolicy string, opts ...func(*GetPlatformEventlogOptions)) (PolicyResult, error) {
+	input := getPlatformEventlogOptions("EvaluatePolicyOnServer", opts...)
+
+	channel, err := grpc.Dial(UDS_PATH, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("[EvaluatePolicyOnServer] can not connect to UDS: %v", err)
+	}
+	defer channel.Close()
+
+	client := pb.NewEventlogClient(channel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	response, err := client.EvaluatePolicy(ctx, &pb.EvaluatePolicyRequest{
+		EventlogCategory: input.eventlogCategory,
+		Policy:           referencePolicy,
+	})
+	if err != nil {
+		return PolicyResult{}, err
+	}
+
+	var result PolicyResult
+	if err := json.Unmarshal([]byte(response.Result), &result); err != nil {
+		return PolicyResult{}, err
+	}
+	return result, nil
+}
@@ -25,16 +25,19 @@ type CATEGORY int32
 const (
 	CATEGORY_TDX_EVENTLOG CATEGORY = 0
 	CATEGORY_TPM_EVENTLOG CATEGORY = 1
+	CATEGORY_IMA_EVENTLOG CATEGORY = 2
 )
 
 var CATEGORY_name = map[int32]string{
 	0: "TDX_EVENTLOG",
 	1: "TPM_EVENTLOG",
+	2: "IMA_EVENTLOG",
 }
 
 var CATEGORY_value = map[string]int32{
 	"TDX_EVENTLOG": 0,
 	"TPM_EVENTLOG": 1,
+	"IMA_EVENTLOG": 2,
 }
 
 func (x CATEGORY) String() string {
@@ -45,6 +48,71 @@ func (CATEGORY) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_3d123471d781508e, []int{0}
 }
 
+type CONTAINER_EVENT_TYPE int32
+
+const (
+	CONTAINER_EVENT_TYPE_CONTAINER_START  CONTAINER_EVENT_TYPE = 0
+	CONTAINER_EVENT_TYPE_CONTAINER_IMAGE  CONTAINER_EVENT_TYPE = 1
+	CONTAINER_EVENT_TYPE_CONTAINER_CONFIG CONTAINER_EVENT_TYPE = 2
+	CONTAINER_EVENT_TYPE_CONTAINER_MOUNT  CONTAINER_EVENT_TYPE = 3
+)
+
+var CONTAINER_EVENT_TYPE_name = map[int32]string{
+	0: "CONTAINER_START",
+	1: "CONTAINER_IMAGE",
+	2: "CONTAINER_CONFIG",
+	3: "CONTAINER_MOUNT",
+}
+
+var CONTAINER_EVENT_TYPE_value = map[string]int32{
+	"CONTAINER_START":  0,
+	"CONTAINER_IMAGE":  1,
+	"CONTAINER_CONFIG": 2,
+	"CONTAINER_MOUNT":  3,
+}
+
+func (x CONTAINER_EVENT_TYPE) String() string {
+	return proto.EnumName(CONTAINER_EVENT_TYPE_name, int32(x))
+}
+
+func (CONTAINER_EVENT_TYPE) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{1}
+}
+
+type FORMAT int32
+
+const (
+	FORMAT_DEFAULT  FORMAT = 0
+	FORMAT_CEL_JSON FORMAT = 1
+	FORMAT_CEL_CBOR FORMAT = 2
+	FORMAT_CEL_TLV  FORMAT = 3
+	FORMAT_RAW      FORMAT = 4
+)
+
+var FORMAT_name = map[int32]string{
+	0: "DEFAULT",
+	1: "CEL_JSON",
+	2: "CEL_CBOR",
+	3: "CEL_TLV",
+	4: "RAW",
+}
+
+var FORMAT_value = map[string]int32{
+	"DEFAULT":  0,
+	"CEL_JSON": 1,
+	"CEL_CBOR": 2,
+	"CEL_TLV":  3,
+	"RAW":      4,
+}
+
+func (x FORMAT) String() string {
+	return proto.EnumName(FORMAT_name, int32(x))
+}
+
+func (FORMAT) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{2}
+}
+
 type LEVEL int32
 
 const (
@@ -67,24 +135,78 @@ func (x LEVEL) String() string {
 }
 
 func (LEVEL) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_3d123471d781508e, []int{1}
+	return fileDescriptor_3d123471d781508e, []int{3}
 }
 
-type GetEventlogRequest struct {
-	EventlogLevel        LEVEL    `protobuf:"varint,1,opt,name=eventlog_level,json=eventlogLevel,proto3,enum=LEVEL" json:"eventlog_level,omitempty"`
-	EventlogCategory     CATEGORY `protobuf:"varint,2,opt,name=eventlog_category,json=eventlogCategory,proto3,enum=CATEGORY" json:"eventlog_category,omitempty"`
-	StartPosition        int32    `protobuf:"varint,3,opt,name=start_position,json=startPosition,proto3" json:"start_position,omitempty"`
-	Count                int32    `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+type EventTypeRange struct {
+	Min                  uint32   `protobuf:"varint,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max                  uint32   `protobuf:"varint,2,opt,name=max,proto3" json:"max,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
+func (m *EventTypeRange) Reset()         { *m = EventTypeRange{} }
+func (m *EventTypeRange) String() string { return proto.CompactTextString(m) }
+func (*EventTypeRange) ProtoMessage()    {}
+func (*EventTypeRange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{0}
+}
+
+func (m *EventTypeRange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventTypeRange.Unmarshal(m, b)
+}
+func (m *EventTypeRange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventTypeRange.Marshal(b, m, deterministic)
+}
+func (m *EventTypeRange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventTypeRange.Merge(m, src)
+}
+func (m *EventTypeRange) XXX_Size() int {
+	return xxx_messageInfo_EventTypeRange.Size(m)
+}
+func (m *EventTypeRange) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventTypeRange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventTypeRange proto.InternalMessageInfo
+
+func (m *EventTypeRange) GetMin() uint32 {
+	if m != nil {
+		return m.Min
+	}
+	return 0
+}
+
+func (m *EventTypeRange) GetMax() uint32 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+type GetEventlogRequest struct {
+	EventlogLevel        LEVEL           `protobuf:"varint,1,opt,name=eventlog_level,json=eventlogLevel,proto3,enum=LEVEL" json:"eventlog_level,omitempty"`
+	EventlogCategory     CATEGORY        `protobuf:"varint,2,opt,name=eventlog_category,json=eventlogCategory,proto3,enum=CATEGORY" json:"eventlog_category,omitempty"`
+	StartPosition        int32           `protobuf:"varint,3,opt,name=start_position,json=startPosition,proto3" json:"start_position,omitempty"`
+	Count                int32           `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	ContainerId          string          `protobuf:"bytes,5,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	RegisterIndexes      []uint32        `protobuf:"varint,6,rep,packed,name=register_indexes,json=registerIndexes,proto3" json:"register_indexes,omitempty"`
+	EventTypes           []uint32        `protobuf:"varint,7,rep,packed,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`
+	EventTypeRange       *EventTypeRange `protobuf:"bytes,8,opt,name=event_type_range,json=eventTypeRange,proto3" json:"event_type_range,omitempty"`
+	AlgorithmId          uint32          `protobuf:"varint,9,opt,name=algorithm_id,json=algorithmId,proto3" json:"algorithm_id,omitempty"`
+	PageToken            string          `protobuf:"bytes,10,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	EventlogFormat       FORMAT          `protobuf:"varint,11,opt,name=eventlog_format,json=eventlogFormat,proto3,enum=FORMAT" json:"eventlog_format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
 func (m *GetEventlogRequest) Reset()         { *m = GetEventlogRequest{} }
 func (m *GetEventlogRequest) String() string { return proto.CompactTextString(m) }
 func (*GetEventlogRequest) ProtoMessage()    {}
 func (*GetEventlogRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3d123471d781508e, []int{0}
+	return fileDescriptor_3d123471d781508e, []int{1}
 }
 
 func (m *GetEventlogRequest) XXX_Unmarshal(b []byte) error {
@@ -133,8 +255,63 @@ func (m *GetEventlogRequest) GetCount() int32 {
 	return 0
 }
 
+func (m *GetEventlogRequest) GetContainerId() string {
+	if m != nil {
+		return m.ContainerId
+	}
+	return ""
+}
+
+func (m *GetEventlogRequest) GetRegisterIndexes() []uint32 {
+	if m != nil {
+		return m.RegisterIndexes
+	}
+	return nil
+}
+
+func (m *GetEventlogRequest) GetEventTypes() []uint32 {
+	if m != nil {
+		return m.EventTypes
+	}
+	return nil
+}
+
+func (m *GetEventlogRequest) GetEventTypeRange() *EventTypeRange {
+	if m != nil {
+		return m.EventTypeRange
+	}
+	return nil
+}
+
+func (m *GetEventlogRequest) GetAlgorithmId() uint32 {
+	if m != nil {
+		return m.AlgorithmId
+	}
+	return 0
+}
+
+func (m *GetEventlogRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *GetEventlogRequest) GetEventlogFormat() FORMAT {
+	if m != nil {
+		return m.EventlogFormat
+	}
+	return FORMAT_DEFAULT
+}
+
 type GetEventlogReply struct {
 	EventlogDataLoc      string   `protobuf:"bytes,1,opt,name=eventlog_data_loc,json=eventlogDataLoc,proto3" json:"eventlog_data_loc,omitempty"`
+	EventlogDataDigest   string   `protobuf:"bytes,2,opt,name=eventlog_data_digest,json=eventlogDataDigest,proto3" json:"eventlog_data_digest,omitempty"`
+	TotalCount           int32    `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	StartPosition        int32    `protobuf:"varint,4,opt,name=start_position,json=startPosition,proto3" json:"start_position,omitempty"`
+	Count                int32    `protobuf:"varint,5,opt,name=count,proto3" json:"count,omitempty"`
+	NextPageToken        string   `protobuf:"bytes,6,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	EventlogData         []byte   `protobuf:"bytes,7,opt,name=eventlog_data,json=eventlogData,proto3" json:"eventlog_data,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -144,7 +321,7 @@ func (m *GetEventlogReply) Reset()         { *m = GetEventlogReply{} }
 func (m *GetEventlogReply) String() string { return proto.CompactTextString(m) }
 func (*GetEventlogReply) ProtoMessage()    {}
 func (*GetEventlogReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3d123471d781508e, []int{1}
+	return fileDescriptor_3d123471d781508e, []int{2}
 }
 
 func (m *GetEventlogReply) XXX_Unmarshal(b []byte) error {
@@ -172,37 +349,768 @@ func (m *GetEventlogReply) GetEventlogDataLoc() string {
 	return ""
 }
 
+func (m *GetEventlogReply) GetEventlogDataDigest() string {
+	if m != nil {
+		return m.EventlogDataDigest
+	}
+	return ""
+}
+
+func (m *GetEventlogReply) GetTotalCount() int32 {
+	if m != nil {
+		return m.TotalCount
+	}
+	return 0
+}
+
+func (m *GetEventlogReply) GetStartPosition() int32 {
+	if m != nil {
+		return m.StartPosition
+	}
+	return 0
+}
+
+func (m *GetEventlogReply) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *GetEventlogReply) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func (m *GetEventlogReply) GetEventlogData() []byte {
+	if m != nil {
+		return m.EventlogData
+	}
+	return nil
+}
+
+type EventlogDigest struct {
+	AlgorithmId          uint32   `protobuf:"varint,1,opt,name=algorithm_id,json=algorithmId,proto3" json:"algorithm_id,omitempty"`
+	Digest               []byte   `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EventlogDigest) Reset()         { *m = EventlogDigest{} }
+func (m *EventlogDigest) String() string { return proto.CompactTextString(m) }
+func (*EventlogDigest) ProtoMessage()    {}
+func (*EventlogDigest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{3}
+}
+
+func (m *EventlogDigest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventlogDigest.Unmarshal(m, b)
+}
+func (m *EventlogDigest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventlogDigest.Marshal(b, m, deterministic)
+}
+func (m *EventlogDigest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventlogDigest.Merge(m, src)
+}
+func (m *EventlogDigest) XXX_Size() int {
+	return xxx_messageInfo_EventlogDigest.Size(m)
+}
+func (m *EventlogDigest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventlogDigest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventlogDigest proto.InternalMessageInfo
+
+func (m *EventlogDigest) GetAlgorithmId() uint32 {
+	if m != nil {
+		return m.AlgorithmId
+	}
+	return 0
+}
+
+func (m *EventlogDigest) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
+type ImaEventlogEntry struct {
+	PcrIndex             uint32   `protobuf:"varint,1,opt,name=pcr_index,json=pcrIndex,proto3" json:"pcr_index,omitempty"`
+	RtmrIndex            int32    `protobuf:"varint,2,opt,name=rtmr_index,json=rtmrIndex,proto3" json:"rtmr_index,omitempty"`
+	TemplateName         string   `protobuf:"bytes,3,opt,name=template_name,json=templateName,proto3" json:"template_name,omitempty"`
+	FileDigestAlgorithm  string   `protobuf:"bytes,4,opt,name=file_digest_algorithm,json=fileDigestAlgorithm,proto3" json:"file_digest_algorithm,omitempty"`
+	FileDigest           []byte   `protobuf:"bytes,5,opt,name=file_digest,json=fileDigest,proto3" json:"file_digest,omitempty"`
+	FileName             string   `protobuf:"bytes,6,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Signature            []byte   `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImaEventlogEntry) Reset()         { *m = ImaEventlogEntry{} }
+func (m *ImaEventlogEntry) String() string { return proto.CompactTextString(m) }
+func (*ImaEventlogEntry) ProtoMessage()    {}
+func (*ImaEventlogEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{4}
+}
+
+func (m *ImaEventlogEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImaEventlogEntry.Unmarshal(m, b)
+}
+func (m *ImaEventlogEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImaEventlogEntry.Marshal(b, m, deterministic)
+}
+func (m *ImaEventlogEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImaEventlogEntry.Merge(m, src)
+}
+func (m *ImaEventlogEntry) XXX_Size() int {
+	return xxx_messageInfo_ImaEventlogEntry.Size(m)
+}
+func (m *ImaEventlogEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImaEventlogEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImaEventlogEntry proto.InternalMessageInfo
+
+func (m *ImaEventlogEntry) GetPcrIndex() uint32 {
+	if m != nil {
+		return m.PcrIndex
+	}
+	return 0
+}
+
+func (m *ImaEventlogEntry) GetRtmrIndex() int32 {
+	if m != nil {
+		return m.RtmrIndex
+	}
+	return 0
+}
+
+func (m *ImaEventlogEntry) GetTemplateName() string {
+	if m != nil {
+		return m.TemplateName
+	}
+	return ""
+}
+
+func (m *ImaEventlogEntry) GetFileDigestAlgorithm() string {
+	if m != nil {
+		return m.FileDigestAlgorithm
+	}
+	return ""
+}
+
+func (m *ImaEventlogEntry) GetFileDigest() []byte {
+	if m != nil {
+		return m.FileDigest
+	}
+	return nil
+}
+
+func (m *ImaEventlogEntry) GetFileName() string {
+	if m != nil {
+		return m.FileName
+	}
+	return ""
+}
+
+func (m *ImaEventlogEntry) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type ContainerEventlogEntry struct {
+	Sequence             uint32   `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	ContainerId          string   `protobuf:"bytes,2,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	PodId                string   `protobuf:"bytes,3,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	Timestamp            int64    `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ContainerEventlogEntry) Reset()         { *m = ContainerEventlogEntry{} }
+func (m *ContainerEventlogEntry) String() string { return proto.CompactTextString(m) }
+func (*ContainerEventlogEntry) ProtoMessage()    {}
+func (*ContainerEventlogEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{5}
+}
+
+func (m *ContainerEventlogEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContainerEventlogEntry.Unmarshal(m, b)
+}
+func (m *ContainerEventlogEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContainerEventlogEntry.Marshal(b, m, deterministic)
+}
+func (m *ContainerEventlogEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContainerEventlogEntry.Merge(m, src)
+}
+func (m *ContainerEventlogEntry) XXX_Size() int {
+	return xxx_messageInfo_ContainerEventlogEntry.Size(m)
+}
+func (m *ContainerEventlogEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContainerEventlogEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContainerEventlogEntry proto.InternalMessageInfo
+
+func (m *ContainerEventlogEntry) GetSequence() uint32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *ContainerEventlogEntry) GetContainerId() string {
+	if m != nil {
+		return m.ContainerId
+	}
+	return ""
+}
+
+func (m *ContainerEventlogEntry) GetPodId() string {
+	if m != nil {
+		return m.PodId
+	}
+	return ""
+}
+
+func (m *ContainerEventlogEntry) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type EventlogEntry struct {
+	RegisterIndex        uint32                  `protobuf:"varint,1,opt,name=register_index,json=registerIndex,proto3" json:"register_index,omitempty"`
+	EventType            uint32                  `protobuf:"varint,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Digests              []*EventlogDigest       `protobuf:"bytes,3,rep,name=digests,proto3" json:"digests,omitempty"`
+	EventSize            uint32                  `protobuf:"varint,4,opt,name=event_size,json=eventSize,proto3" json:"event_size,omitempty"`
+	Event                []byte                  `protobuf:"bytes,5,opt,name=event,proto3" json:"event,omitempty"`
+	ImaEntry             *ImaEventlogEntry       `protobuf:"bytes,6,opt,name=ima_entry,json=imaEntry,proto3" json:"ima_entry,omitempty"`
+	ContainerEntry       *ContainerEventlogEntry `protobuf:"bytes,7,opt,name=container_entry,json=containerEntry,proto3" json:"container_entry,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *EventlogEntry) Reset()         { *m = EventlogEntry{} }
+func (m *EventlogEntry) String() string { return proto.CompactTextString(m) }
+func (*EventlogEntry) ProtoMessage()    {}
+func (*EventlogEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{6}
+}
+
+func (m *EventlogEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventlogEntry.Unmarshal(m, b)
+}
+func (m *EventlogEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventlogEntry.Marshal(b, m, deterministic)
+}
+func (m *EventlogEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventlogEntry.Merge(m, src)
+}
+func (m *EventlogEntry) XXX_Size() int {
+	return xxx_messageInfo_EventlogEntry.Size(m)
+}
+func (m *EventlogEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventlogEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventlogEntry proto.InternalMessageInfo
+
+func (m *EventlogEntry) GetRegisterIndex() uint32 {
+	if m != nil {
+		return m.RegisterIndex
+	}
+	return 0
+}
+
+func (m *EventlogEntry) GetEventType() uint32 {
+	if m != nil {
+		return m.EventType
+	}
+	return 0
+}
+
+func (m *EventlogEntry) GetDigests() []*EventlogDigest {
+	if m != nil {
+		return m.Digests
+	}
+	return nil
+}
+
+func (m *EventlogEntry) GetEventSize() uint32 {
+	if m != nil {
+		return m.EventSize
+	}
+	return 0
+}
+
+func (m *EventlogEntry) GetEvent() []byte {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (m *EventlogEntry) GetImaEntry() *ImaEventlogEntry {
+	if m != nil {
+		return m.ImaEntry
+	}
+	return nil
+}
+
+func (m *EventlogEntry) GetContainerEntry() *ContainerEventlogEntry {
+	if m != nil {
+		return m.ContainerEntry
+	}
+	return nil
+}
+
+type WatchEventlogRequest struct {
+	EventlogLevel        LEVEL    `protobuf:"varint,1,opt,name=eventlog_level,json=eventlogLevel,proto3,enum=LEVEL" json:"eventlog_level,omitempty"`
+	EventlogCategory     CATEGORY `protobuf:"varint,2,opt,name=eventlog_category,json=eventlogCategory,proto3,enum=CATEGORY" json:"eventlog_category,omitempty"`
+	ContainerId          string   `protobuf:"bytes,3,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	Snapshot             bool     `protobuf:"varint,4,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	StartSequence        uint64   `protobuf:"varint,5,opt,name=start_sequence,json=startSequence,proto3" json:"start_sequence,omitempty"`
+	Resume               bool     `protobuf:"varint,6,opt,name=resume,proto3" json:"resume,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchEventlogRequest) Reset()         { *m = WatchEventlogRequest{} }
+func (m *WatchEventlogRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchEventlogRequest) ProtoMessage()    {}
+func (*WatchEventlogRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{7}
+}
+
+func (m *WatchEventlogRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchEventlogRequest.Unmarshal(m, b)
+}
+func (m *WatchEventlogRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchEventlogRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchEventlogRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchEventlogRequest.Merge(m, src)
+}
+func (m *WatchEventlogRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchEventlogRequest.Size(m)
+}
+func (m *WatchEventlogRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchEventlogRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchEventlogRequest proto.InternalMessageInfo
+
+func (m *WatchEventlogRequest) GetEventlogLevel() LEVEL {
+	if m != nil {
+		return m.EventlogLevel
+	}
+	return LEVEL_PAAS
+}
+
+func (m *WatchEventlogRequest) GetEventlogCategory() CATEGORY {
+	if m != nil {
+		return m.EventlogCategory
+	}
+	return CATEGORY_TDX_EVENTLOG
+}
+
+func (m *WatchEventlogRequest) GetContainerId() string {
+	if m != nil {
+		return m.ContainerId
+	}
+	return ""
+}
+
+func (m *WatchEventlogRequest) GetSnapshot() bool {
+	if m != nil {
+		return m.Snapshot
+	}
+	return false
+}
+
+func (m *WatchEventlogRequest) GetStartSequence() uint64 {
+	if m != nil {
+		return m.StartSequence
+	}
+	return 0
+}
+
+func (m *WatchEventlogRequest) GetResume() bool {
+	if m != nil {
+		return m.Resume
+	}
+	return false
+}
+
+type WatchEventlogReply struct {
+	Sequence             uint64         `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Entry                *EventlogEntry `protobuf:"bytes,2,opt,name=entry,proto3" json:"entry,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *WatchEventlogReply) Reset()         { *m = WatchEventlogReply{} }
+func (m *WatchEventlogReply) String() string { return proto.CompactTextString(m) }
+func (*WatchEventlogReply) ProtoMessage()    {}
+func (*WatchEventlogReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{8}
+}
+
+func (m *WatchEventlogReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchEventlogReply.Unmarshal(m, b)
+}
+func (m *WatchEventlogReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchEventlogReply.Marshal(b, m, deterministic)
+}
+func (m *WatchEventlogReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchEventlogReply.Merge(m, src)
+}
+func (m *WatchEventlogReply) XXX_Size() int {
+	return xxx_messageInfo_WatchEventlogReply.Size(m)
+}
+func (m *WatchEventlogReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchEventlogReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchEventlogReply proto.InternalMessageInfo
+
+func (m *WatchEventlogReply) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *WatchEventlogReply) GetEntry() *EventlogEntry {
+	if m != nil {
+		return m.Entry
+	}
+	return nil
+}
+
+type EvaluatePolicyRequest struct {
+	EventlogCategory     CATEGORY `protobuf:"varint,1,opt,name=eventlog_category,json=eventlogCategory,proto3,enum=CATEGORY" json:"eventlog_category,omitempty"`
+	Policy               string   `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EvaluatePolicyRequest) Reset()         { *m = EvaluatePolicyRequest{} }
+func (m *EvaluatePolicyRequest) String() string { return proto.CompactTextString(m) }
+func (*EvaluatePolicyRequest) ProtoMessage()    {}
+func (*EvaluatePolicyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{9}
+}
+
+func (m *EvaluatePolicyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EvaluatePolicyRequest.Unmarshal(m, b)
+}
+func (m *EvaluatePolicyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EvaluatePolicyRequest.Marshal(b, m, deterministic)
+}
+func (m *EvaluatePolicyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EvaluatePolicyRequest.Merge(m, src)
+}
+func (m *EvaluatePolicyRequest) XXX_Size() int {
+	return xxx_messageInfo_EvaluatePolicyRequest.Size(m)
+}
+func (m *EvaluatePolicyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EvaluatePolicyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EvaluatePolicyRequest proto.InternalMessageInfo
+
+func (m *EvaluatePolicyRequest) GetEventlogCategory() CATEGORY {
+	if m != nil {
+		return m.EventlogCategory
+	}
+	return CATEGORY_TDX_EVENTLOG
+}
+
+func (m *EvaluatePolicyRequest) GetPolicy() string {
+	if m != nil {
+		return m.Policy
+	}
+	return ""
+}
+
+type EvaluatePolicyReply struct {
+	Passed               bool     `protobuf:"varint,1,opt,name=passed,proto3" json:"passed,omitempty"`
+	Result               string   `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EvaluatePolicyReply) Reset()         { *m = EvaluatePolicyReply{} }
+func (m *EvaluatePolicyReply) String() string { return proto.CompactTextString(m) }
+func (*EvaluatePolicyReply) ProtoMessage()    {}
+func (*EvaluatePolicyReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{10}
+}
+
+func (m *EvaluatePolicyReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EvaluatePolicyReply.Unmarshal(m, b)
+}
+func (m *EvaluatePolicyReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EvaluatePolicyReply.Marshal(b, m, deterministic)
+}
+func (m *EvaluatePolicyReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EvaluatePolicyReply.Merge(m, src)
+}
+func (m *EvaluatePolicyReply) XXX_Size() int {
+	return xxx_messageInfo_EvaluatePolicyReply.Size(m)
+}
+func (m *EvaluatePolicyReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_EvaluatePolicyReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EvaluatePolicyReply proto.InternalMessageInfo
+
+func (m *EvaluatePolicyReply) GetPassed() bool {
+	if m != nil {
+		return m.Passed
+	}
+	return false
+}
+
+func (m *EvaluatePolicyReply) GetResult() string {
+	if m != nil {
+		return m.Result
+	}
+	return ""
+}
+
+type RecordContainerEventRequest struct {
+	ContainerId          string               `protobuf:"bytes,1,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	PodId                string               `protobuf:"bytes,2,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+	EventType            CONTAINER_EVENT_TYPE `protobuf:"varint,3,opt,name=event_type,json=eventType,proto3,enum=CONTAINER_EVENT_TYPE" json:"event_type,omitempty"`
+	Event                []byte               `protobuf:"bytes,4,opt,name=event,proto3" json:"event,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *RecordContainerEventRequest) Reset()         { *m = RecordContainerEventRequest{} }
+func (m *RecordContainerEventRequest) String() string { return proto.CompactTextString(m) }
+func (*RecordContainerEventRequest) ProtoMessage()    {}
+func (*RecordContainerEventRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{11}
+}
+
+func (m *RecordContainerEventRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecordContainerEventRequest.Unmarshal(m, b)
+}
+func (m *RecordContainerEventRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecordContainerEventRequest.Marshal(b, m, deterministic)
+}
+func (m *RecordContainerEventRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecordContainerEventRequest.Merge(m, src)
+}
+func (m *RecordContainerEventRequest) XXX_Size() int {
+	return xxx_messageInfo_RecordContainerEventRequest.Size(m)
+}
+func (m *RecordContainerEventRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecordContainerEventRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecordContainerEventRequest proto.InternalMessageInfo
+
+func (m *RecordContainerEventRequest) GetContainerId() string {
+	if m != nil {
+		return m.ContainerId
+	}
+	return ""
+}
+
+func (m *RecordContainerEventRequest) GetPodId() string {
+	if m != nil {
+		return m.PodId
+	}
+	return ""
+}
+
+func (m *RecordContainerEventRequest) GetEventType() CONTAINER_EVENT_TYPE {
+	if m != nil {
+		return m.EventType
+	}
+	return CONTAINER_EVENT_TYPE_CONTAINER_START
+}
+
+func (m *RecordContainerEventRequest) GetEvent() []byte {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+type RecordContainerEventReply struct {
+	Sequence             uint32   `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Digest               []byte   `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RecordContainerEventReply) Reset()         { *m = RecordContainerEventReply{} }
+func (m *RecordContainerEventReply) String() string { return proto.CompactTextString(m) }
+func (*RecordContainerEventReply) ProtoMessage()    {}
+func (*RecordContainerEventReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3d123471d781508e, []int{12}
+}
+
+func (m *RecordContainerEventReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecordContainerEventReply.Unmarshal(m, b)
+}
+func (m *RecordContainerEventReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecordContainerEventReply.Marshal(b, m, deterministic)
+}
+func (m *RecordContainerEventReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecordContainerEventReply.Merge(m, src)
+}
+func (m *RecordContainerEventReply) XXX_Size() int {
+	return xxx_messageInfo_RecordContainerEventReply.Size(m)
+}
+func (m *RecordContainerEventReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecordContainerEventReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecordContainerEventReply proto.InternalMessageInfo
+
+func (m *RecordContainerEventReply) GetSequence() uint32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *RecordContainerEventReply) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("CATEGORY", CATEGORY_name, CATEGORY_value)
+	proto.RegisterEnum("CONTAINER_EVENT_TYPE", CONTAINER_EVENT_TYPE_name, CONTAINER_EVENT_TYPE_value)
+	proto.RegisterEnum("FORMAT", FORMAT_name, FORMAT_value)
 	proto.RegisterEnum("LEVEL", LEVEL_name, LEVEL_value)
+	proto.RegisterType((*EventTypeRange)(nil), "EventTypeRange")
 	proto.RegisterType((*GetEventlogRequest)(nil), "GetEventlogRequest")
 	proto.RegisterType((*GetEventlogReply)(nil), "GetEventlogReply")
+	proto.RegisterType((*EventlogDigest)(nil), "EventlogDigest")
+	proto.RegisterType((*ImaEventlogEntry)(nil), "ImaEventlogEntry")
+	proto.RegisterType((*ContainerEventlogEntry)(nil), "ContainerEventlogEntry")
+	proto.RegisterType((*EventlogEntry)(nil), "EventlogEntry")
+	proto.RegisterType((*WatchEventlogRequest)(nil), "WatchEventlogRequest")
+	proto.RegisterType((*WatchEventlogReply)(nil), "WatchEventlogReply")
+	proto.RegisterType((*EvaluatePolicyRequest)(nil), "EvaluatePolicyRequest")
+	proto.RegisterType((*EvaluatePolicyReply)(nil), "EvaluatePolicyReply")
+	proto.RegisterType((*RecordContainerEventRequest)(nil), "RecordContainerEventRequest")
+	proto.RegisterType((*RecordContainerEventReply)(nil), "RecordContainerEventReply")
 }
 
-func init() { proto.RegisterFile("proto/eventlog-server.proto", fileDescriptor_3d123471d781508e) }
+func init() {
+	proto.RegisterFile("proto/eventlog-server.proto", fileDescriptor_3d123471d781508e)
+}
 
 var fileDescriptor_3d123471d781508e = []byte{
-	// 350 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x91, 0x51, 0x6b, 0xf2, 0x30,
-	0x14, 0x86, 0xed, 0xf7, 0xa9, 0x68, 0x36, 0x5d, 0xcd, 0x76, 0x51, 0xe6, 0x8d, 0x08, 0x03, 0x11,
-	0xda, 0x82, 0x83, 0xed, 0x6e, 0xe0, 0xb4, 0x78, 0xd3, 0x4d, 0xa9, 0x22, 0xdb, 0x6e, 0x4a, 0x8c,
-	0x59, 0x17, 0x88, 0x4d, 0xd7, 0x9e, 0x16, 0xfc, 0x67, 0xfb, 0x79, 0xa3, 0xd1, 0x4e, 0xe7, 0xee,
-	0x4e, 0x9f, 0xf7, 0x2d, 0x79, 0x92, 0x83, 0xda, 0x51, 0x2c, 0x41, 0xda, 0x2c, 0x63, 0x21, 0x08,
-	0x19, 0x98, 0x09, 0x8b, 0x33, 0x16, 0x5b, 0x8a, 0x76, 0xbf, 0x34, 0x84, 0x27, 0x0c, 0x9c, 0x7d,
-	0xe8, 0xb1, 0xcf, 0x94, 0x25, 0x80, 0x4d, 0xd4, 0x2c, 0xfa, 0xbe, 0x60, 0x19, 0x13, 0x86, 0xd6,
-	0xd1, 0x7a, 0xcd, 0x41, 0xd5, 0x72, 0x9d, 0xa5, 0xe3, 0x7a, 0x8d, 0x22, 0x75, 0xf3, 0x10, 0xdf,
-	0xa1, 0xd6, 0x4f, 0x9d, 0x12, 0x60, 0x81, 0x8c, 0xb7, 0xc6, 0x3f, 0xf5, 0x47, 0xdd, 0x1a, 0x0d,
-	0x17, 0xce, 0x64, 0xea, 0xbd, 0x7a, 0x7a, 0xd1, 0x19, 0xed, 0x2b, 0xf8, 0x06, 0x35, 0x13, 0x20,
-	0x31, 0xf8, 0x91, 0x4c, 0x38, 0x70, 0x19, 0x1a, 0xff, 0x3b, 0x5a, 0xaf, 0xe2, 0x35, 0x14, 0x9d,
-	0xed, 0x21, 0xbe, 0x42, 0x15, 0x2a, 0xd3, 0x10, 0x8c, 0xb2, 0x4a, 0x77, 0x1f, 0xdd, 0x07, 0xa4,
-	0xff, 0x32, 0x8f, 0xc4, 0x16, 0xf7, 0x8f, 0x44, 0xd6, 0x04, 0x88, 0x2f, 0x24, 0x55, 0xea, 0x75,
-	0xef, 0xa2, 0x08, 0xc6, 0x04, 0x88, 0x2b, 0x69, 0xdf, 0x42, 0xb5, 0x42, 0x0d, 0xeb, 0xe8, 0x7c,
-	0x31, 0x7e, 0xf1, 0x9d, 0xa5, 0xf3, 0xbc, 0x70, 0xa7, 0x13, 0xbd, 0xa4, 0xc8, 0xec, 0xe9, 0x40,
-	0xb4, 0x7e, 0x1b, 0x55, 0xd4, 0xe5, 0x71, 0x0d, 0x95, 0x67, 0xc3, 0xe1, 0x5c, 0x2f, 0xe5, 0xd3,
-	0x3c, 0x9f, 0xb4, 0xc1, 0x08, 0xd5, 0x0a, 0x13, 0x7c, 0x8f, 0xce, 0x8e, 0xc4, 0xf0, 0xa5, 0xf5,
-	0xf7, 0x81, 0xaf, 0x5b, 0xd6, 0xa9, 0x7b, 0xb7, 0xf4, 0x48, 0xde, 0xfc, 0x80, 0xc3, 0x47, 0xba,
-	0xb2, 0xa8, 0xdc, 0xd8, 0x3c, 0x04, 0x26, 0x6c, 0x2a, 0xc3, 0x77, 0xbe, 0x66, 0x21, 0x70, 0x22,
-	0x4c, 0x2a, 0x64, 0xba, 0x36, 0x43, 0x02, 0x3c, 0x63, 0x66, 0x14, 0xf3, 0x0d, 0xcf, 0xa7, 0xc4,
-	0xce, 0x57, 0xca, 0x29, 0x3b, 0xdd, 0xb1, 0xbd, 0xdb, 0x7c, 0x70, 0x38, 0x69, 0x55, 0x55, 0xe8,
-	0xf6, 0x3b, 0x00, 0x00, 0xff, 0xff, 0x51, 0xe1, 0x30, 0x36, 0x15, 0x02, 0x00, 0x00,
+	// 1299 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x57, 0x4b, 0x6f, 0xdb, 0xc6,
+	0x16, 0x36, 0x25, 0x59, 0x8f, 0xa3, 0x87, 0xe9, 0xb1, 0xec, 0xab, 0x6b, 0xe7, 0xe2, 0x2a, 0x4c,
+	0x53, 0x38, 0x06, 0x4c, 0x07, 0x6e, 0xd0, 0xa2, 0x40, 0x81, 0x46, 0x91, 0x69, 0x43, 0xad, 0x2c,
+	0x19, 0x23, 0xc5, 0x69, 0xba, 0x21, 0x26, 0xd4, 0x44, 0x26, 0xc2, 0x57, 0xc9, 0x91, 0x61, 0x67,
+	0xdb, 0x6d, 0xff, 0x41, 0x97, 0x5d, 0xf4, 0x1f, 0x15, 0xfd, 0x2f, 0xdd, 0x14, 0x33, 0x7c, 0x4b,
+	0x8a, 0xbb, 0xec, 0x4e, 0xe7, 0x3b, 0x87, 0xc3, 0x33, 0xdf, 0x77, 0xbe, 0x63, 0x1a, 0x0e, 0x3c,
+	0xdf, 0x65, 0xee, 0x09, 0xbd, 0xa5, 0x0e, 0xb3, 0xdc, 0xf9, 0x71, 0x40, 0xfd, 0x5b, 0xea, 0xab,
+	0x02, 0x55, 0x5e, 0x40, 0x4b, 0xe3, 0x89, 0xe9, 0xbd, 0x47, 0x31, 0x71, 0xe6, 0x14, 0xc9, 0x50,
+	0xb4, 0x4d, 0xa7, 0x23, 0x75, 0xa5, 0xc3, 0x26, 0xe6, 0x3f, 0x05, 0x42, 0xee, 0x3a, 0x85, 0x08,
+	0x21, 0x77, 0xca, 0x1f, 0x45, 0x40, 0x17, 0x94, 0x69, 0xd1, 0x91, 0x98, 0xfe, 0xb4, 0xa0, 0x01,
+	0x43, 0xc7, 0xd0, 0x8a, 0xdf, 0xa2, 0x5b, 0xf4, 0x96, 0x5a, 0xe2, 0x94, 0xd6, 0x69, 0x59, 0x1d,
+	0x6a, 0xd7, 0xda, 0x10, 0x37, 0xe3, 0xec, 0x90, 0x27, 0xd1, 0x97, 0xb0, 0x9d, 0x94, 0x1b, 0x84,
+	0xd1, 0xb9, 0xeb, 0xdf, 0x8b, 0xb7, 0xb4, 0x4e, 0x6b, 0x6a, 0xbf, 0x37, 0xd5, 0x2e, 0xc6, 0xf8,
+	0x2d, 0x96, 0xe3, 0x9a, 0x7e, 0x54, 0x82, 0x9e, 0x42, 0x2b, 0x60, 0xc4, 0x67, 0xba, 0xe7, 0x06,
+	0x26, 0x33, 0x5d, 0xa7, 0x53, 0xec, 0x4a, 0x87, 0x9b, 0xb8, 0x29, 0xd0, 0xab, 0x08, 0x44, 0x6d,
+	0xd8, 0x34, 0xdc, 0x85, 0xc3, 0x3a, 0x25, 0x91, 0x0d, 0x03, 0xf4, 0x18, 0x1a, 0x86, 0xeb, 0x30,
+	0x62, 0x3a, 0xd4, 0xd7, 0xcd, 0x59, 0x67, 0xb3, 0x2b, 0x1d, 0xd6, 0x70, 0x3d, 0xc1, 0x06, 0x33,
+	0xf4, 0x0c, 0x64, 0x9f, 0xce, 0xcd, 0x80, 0xf1, 0x0a, 0x67, 0x46, 0xef, 0x68, 0xd0, 0x29, 0x77,
+	0x8b, 0x87, 0x4d, 0xbc, 0x15, 0xe3, 0x83, 0x10, 0x46, 0xff, 0x87, 0xba, 0x68, 0x4f, 0x67, 0xf7,
+	0x1e, 0x0d, 0x3a, 0x15, 0x51, 0x05, 0x34, 0x66, 0x34, 0x40, 0x5f, 0x83, 0x9c, 0x16, 0xe8, 0x3e,
+	0x67, 0xb8, 0x53, 0xed, 0x4a, 0x87, 0xf5, 0xd3, 0x2d, 0x35, 0x4f, 0x3c, 0x6e, 0xd1, 0xbc, 0x10,
+	0x8f, 0xa1, 0x41, 0xac, 0xb9, 0xeb, 0x9b, 0xec, 0xc6, 0xe6, 0x9d, 0xd6, 0x04, 0xff, 0xf5, 0x04,
+	0x1b, 0xcc, 0xd0, 0xff, 0x00, 0x3c, 0x32, 0xa7, 0x3a, 0x73, 0x3f, 0x50, 0xa7, 0x03, 0xe2, 0x2a,
+	0x35, 0x8e, 0x4c, 0x39, 0x80, 0x9e, 0xc3, 0x56, 0x42, 0xf0, 0x7b, 0xd7, 0xb7, 0x09, 0xeb, 0xd4,
+	0x05, 0xbd, 0x15, 0xf5, 0x7c, 0x8c, 0x2f, 0x7b, 0x53, 0x9c, 0xe8, 0x75, 0x2e, 0xd2, 0xca, 0xaf,
+	0x05, 0x90, 0x73, 0xc2, 0x7a, 0xd6, 0x3d, 0x3a, 0xca, 0xe8, 0x34, 0x23, 0x8c, 0xe8, 0x96, 0x6b,
+	0x08, 0x65, 0x6b, 0x38, 0x39, 0xff, 0x8c, 0x30, 0x32, 0x74, 0x0d, 0xf4, 0x1c, 0xda, 0xf9, 0xda,
+	0x99, 0x39, 0xa7, 0x01, 0x13, 0xb2, 0xd6, 0x30, 0xca, 0x96, 0x9f, 0x89, 0x0c, 0xa7, 0x90, 0xb9,
+	0x8c, 0x58, 0x7a, 0x28, 0x56, 0x28, 0x25, 0x08, 0xa8, 0x2f, 0x14, 0x5b, 0x95, 0xbb, 0xf4, 0xa0,
+	0xdc, 0x9b, 0x59, 0xb9, 0x3f, 0x87, 0x2d, 0x87, 0xde, 0x31, 0x3d, 0x43, 0x53, 0x59, 0xb4, 0xd2,
+	0xe4, 0xf0, 0x55, 0x42, 0xd5, 0x13, 0x68, 0xe6, 0xfa, 0xee, 0x54, 0xba, 0xd2, 0x61, 0x03, 0x37,
+	0xb2, 0x0d, 0x2b, 0xdf, 0x47, 0x66, 0xe1, 0x71, 0xd8, 0xfc, 0xb2, 0x46, 0xd2, 0xaa, 0x46, 0x7b,
+	0x50, 0xce, 0x70, 0xd0, 0xc0, 0x51, 0xa4, 0xfc, 0x5c, 0x00, 0x79, 0x60, 0x93, 0xf8, 0x40, 0xcd,
+	0x61, 0xfe, 0x3d, 0x3a, 0x80, 0x9a, 0x67, 0x44, 0x53, 0x17, 0x1d, 0x56, 0xf5, 0x8c, 0x70, 0xdc,
+	0xb8, 0xda, 0x3e, 0xb3, 0xe3, 0x6c, 0x41, 0x5c, 0xb3, 0xc6, 0x91, 0x30, 0xfd, 0x04, 0x9a, 0x8c,
+	0xda, 0x9e, 0x45, 0x18, 0xd5, 0x1d, 0x62, 0x53, 0x41, 0x65, 0x0d, 0x37, 0x62, 0x70, 0x44, 0x6c,
+	0x8a, 0x4e, 0x61, 0xf7, 0xbd, 0x69, 0xd1, 0x48, 0x16, 0x3d, 0x69, 0x54, 0x70, 0x5a, 0xc3, 0x3b,
+	0x3c, 0x19, 0xde, 0xad, 0x17, 0xa7, 0xb8, 0x42, 0x99, 0x67, 0x04, 0xbf, 0x0d, 0x0c, 0x69, 0x25,
+	0xef, 0x5a, 0x14, 0x88, 0xb7, 0x86, 0xf4, 0x56, 0x39, 0x20, 0xde, 0xf8, 0x08, 0x6a, 0x81, 0x39,
+	0x77, 0x08, 0x5b, 0xf8, 0x34, 0x62, 0x35, 0x05, 0x94, 0x5f, 0x24, 0xd8, 0xeb, 0xc7, 0xde, 0xcb,
+	0x73, 0xb1, 0x0f, 0xd5, 0x80, 0x2f, 0x16, 0xc7, 0xa0, 0x31, 0x15, 0x71, 0xbc, 0xe2, 0xe2, 0xc2,
+	0xaa, 0x8b, 0x77, 0xa1, 0xec, 0xb9, 0x33, 0x9e, 0x0c, 0x79, 0xd8, 0xf4, 0xdc, 0xd9, 0x60, 0xc6,
+	0xdb, 0x61, 0xa6, 0x4d, 0x03, 0x46, 0x6c, 0x4f, 0x5c, 0xba, 0x88, 0x53, 0x40, 0xf9, 0xbd, 0x00,
+	0xcd, 0x7c, 0x17, 0x4f, 0xa1, 0x95, 0x5f, 0x06, 0x51, 0x2f, 0xcd, 0xdc, 0x2a, 0xe0, 0xda, 0xa4,
+	0x3e, 0x8f, 0x56, 0x65, 0x2d, 0x31, 0x34, 0x7a, 0x06, 0x95, 0x90, 0xbd, 0xa0, 0x53, 0xec, 0x16,
+	0x53, 0xf7, 0x27, 0x93, 0x84, 0xe3, 0x7c, 0x7a, 0x52, 0x60, 0x7e, 0xa4, 0x9d, 0x52, 0xe6, 0xa4,
+	0x89, 0xf9, 0x91, 0xf2, 0x31, 0x17, 0x41, 0x24, 0x43, 0x18, 0x20, 0x15, 0x6a, 0xa6, 0x4d, 0x74,
+	0xca, 0x5b, 0x16, 0x0a, 0xd4, 0x4f, 0xb7, 0xd5, 0xe5, 0xe9, 0xc2, 0x55, 0xd3, 0x26, 0xe1, 0xad,
+	0x5e, 0xc2, 0x56, 0xca, 0x5f, 0xf8, 0x54, 0x45, 0x3c, 0xf5, 0x1f, 0x75, 0xbd, 0x1a, 0xb8, 0x95,
+	0xd4, 0x8b, 0x58, 0xf9, 0x4b, 0x82, 0xf6, 0x1b, 0xc2, 0x8c, 0x9b, 0x7f, 0xe9, 0x8f, 0xc0, 0xf2,
+	0x04, 0x14, 0x57, 0x27, 0x80, 0x0f, 0x90, 0x43, 0xbc, 0xe0, 0xc6, 0x0d, 0xff, 0x06, 0x54, 0x71,
+	0x12, 0xa7, 0x4b, 0x25, 0x19, 0x31, 0xce, 0x67, 0x29, 0x5a, 0x2a, 0x93, 0x08, 0xe4, 0xe6, 0xf5,
+	0x69, 0xb0, 0x88, 0xc6, 0xba, 0x8a, 0xa3, 0x48, 0xb9, 0x06, 0xb4, 0x74, 0x79, 0xbe, 0x28, 0x97,
+	0x27, 0xb6, 0x94, 0x99, 0xd8, 0xcf, 0x60, 0x33, 0xe4, 0xb9, 0x20, 0x78, 0x6e, 0xa9, 0x79, 0x7a,
+	0xc3, 0xa4, 0x32, 0x87, 0x5d, 0xed, 0x96, 0x58, 0x0b, 0xc2, 0xe8, 0x95, 0x6b, 0x99, 0xc6, 0x7d,
+	0xcc, 0xea, 0x5a, 0x9a, 0xa4, 0x7f, 0xa6, 0x69, 0x8f, 0xbb, 0x80, 0x1f, 0x14, 0x59, 0x24, 0x8a,
+	0x14, 0x0d, 0x76, 0x96, 0x5f, 0xe4, 0x59, 0x61, 0x39, 0x09, 0x02, 0x1a, 0x6e, 0xb2, 0x2a, 0x8e,
+	0xa2, 0x98, 0x07, 0x2b, 0x5e, 0xe4, 0x51, 0xa4, 0xfc, 0x26, 0xc1, 0x01, 0xa6, 0x86, 0xeb, 0xcf,
+	0xf2, 0x63, 0x13, 0xb7, 0xbd, 0xac, 0x92, 0xf4, 0x90, 0x4f, 0x0b, 0x59, 0x9f, 0xbe, 0xc8, 0x19,
+	0xaa, 0x28, 0x6e, 0xba, 0xab, 0xf6, 0xc7, 0xa3, 0x69, 0x6f, 0x30, 0xd2, 0xb0, 0xae, 0x5d, 0x6b,
+	0xa3, 0xa9, 0x3e, 0x7d, 0x7b, 0xa5, 0x65, 0x7d, 0x96, 0xb8, 0xa3, 0x94, 0x71, 0x87, 0x32, 0x86,
+	0xff, 0xae, 0x6f, 0xd2, 0xb3, 0x1e, 0x5e, 0x33, 0x9f, 0xd8, 0xdd, 0x47, 0x2f, 0xa1, 0x1a, 0x73,
+	0x8e, 0x64, 0x68, 0x4c, 0xcf, 0x7e, 0x08, 0xfb, 0x19, 0x8e, 0x2f, 0xe4, 0x0d, 0x81, 0x5c, 0x5d,
+	0xa6, 0x88, 0xc4, 0x91, 0xc1, 0x65, 0x2f, 0x45, 0x0a, 0x47, 0x1f, 0xa0, 0xbd, 0xee, 0x2e, 0x68,
+	0x07, 0xb6, 0x52, 0x7c, 0x32, 0xed, 0xe1, 0xa9, 0xbc, 0x91, 0x07, 0x07, 0x97, 0xbd, 0x0b, 0x4d,
+	0x96, 0x50, 0x1b, 0xe4, 0x14, 0xec, 0x8f, 0x47, 0xe7, 0x83, 0x0b, 0xb9, 0x90, 0x2f, 0xbd, 0x1c,
+	0xbf, 0x1e, 0x4d, 0xe5, 0xe2, 0xd1, 0x05, 0x94, 0xc3, 0xbf, 0xf7, 0xa8, 0x0e, 0x95, 0x33, 0xed,
+	0xbc, 0xf7, 0x7a, 0xc8, 0x8f, 0x6d, 0x40, 0xb5, 0xaf, 0x0d, 0xf5, 0xef, 0x26, 0xe3, 0x91, 0x2c,
+	0xc5, 0x51, 0xff, 0xd5, 0x18, 0xcb, 0x05, 0x5e, 0xc8, 0xa3, 0xe9, 0xf0, 0x5a, 0x2e, 0xa2, 0x0a,
+	0x14, 0x71, 0xef, 0x8d, 0x5c, 0x3a, 0x3a, 0x80, 0x4d, 0x61, 0x62, 0x54, 0x85, 0xd2, 0x55, 0xaf,
+	0x37, 0x91, 0x37, 0xf8, 0xaf, 0x09, 0xff, 0x25, 0x9d, 0xfe, 0x59, 0x80, 0x6a, 0x3c, 0xd4, 0xe8,
+	0x2b, 0xa8, 0x67, 0xbe, 0x23, 0xd0, 0x8e, 0xba, 0xfa, 0xb9, 0xb8, 0xbf, 0xad, 0x2e, 0x7f, 0x6a,
+	0x28, 0x1b, 0xe8, 0x1b, 0xd8, 0xce, 0xa0, 0x13, 0xe6, 0x53, 0x62, 0xaf, 0x7f, 0x7c, 0xc9, 0x42,
+	0xca, 0xc6, 0x73, 0x09, 0x61, 0x68, 0xaf, 0x53, 0x1a, 0x3d, 0x52, 0x1f, 0x98, 0xd2, 0xfd, 0x7d,
+	0xf5, 0x93, 0xe3, 0xa1, 0x6c, 0xa0, 0x6f, 0xa1, 0x99, 0xf3, 0x3a, 0xda, 0x55, 0xd7, 0x2d, 0xbe,
+	0xfd, 0x1d, 0x75, 0x75, 0x25, 0x88, 0xa6, 0x5e, 0x42, 0x2b, 0xef, 0x35, 0xb4, 0xa7, 0xae, 0x75,
+	0xf9, 0x7e, 0x5b, 0x5d, 0x63, 0x4a, 0x65, 0xe3, 0x15, 0xf9, 0x51, 0x9f, 0x9b, 0xec, 0x66, 0xf1,
+	0x4e, 0x35, 0x5c, 0xfb, 0xc4, 0x74, 0x18, 0xb5, 0x4e, 0x0c, 0xd7, 0x79, 0x6f, 0xce, 0xa8, 0xc3,
+	0x4c, 0x62, 0x1d, 0x1b, 0x96, 0xbb, 0x98, 0x1d, 0x3b, 0x84, 0x99, 0xb7, 0xf4, 0xd8, 0xf3, 0x4d,
+	0xdb, 0xe4, 0xbf, 0x82, 0x13, 0xfe, 0xad, 0x6f, 0x1a, 0x74, 0xf9, 0xe3, 0xff, 0x24, 0xfc, 0x97,
+	0x60, 0x9e, 0x92, 0xfa, 0xae, 0x2c, 0xa0, 0x2f, 0xfe, 0x1e, 0x00, 0xea, 0x02, 0xaf, 0x01, 0x2e,
+	0x0c, 0x00, 0x00,
 }
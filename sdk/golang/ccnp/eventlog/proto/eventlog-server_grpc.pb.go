@@ -23,6 +23,10 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type EventlogClient interface {
 	GetEventlog(ctx context.Context, in *GetEventlogRequest, opts ...grpc.CallOption) (*GetEventlogReply, error)
+	GetEventlogStream(ctx context.Context, in *GetEventlogRequest, opts ...grpc.CallOption) (Eventlog_GetEventlogStreamClient, error)
+	RecordContainerEvent(ctx context.Context, in *RecordContainerEventRequest, opts ...grpc.CallOption) (*RecordContainerEventReply, error)
+	WatchEventlog(ctx context.Context, in *WatchEventlogRequest, opts ...grpc.CallOption) (Eventlog_WatchEventlogClient, error)
+	EvaluatePolicy(ctx context.Context, in *EvaluatePolicyRequest, opts ...grpc.CallOption) (*EvaluatePolicyReply, error)
 }
 
 type eventlogClient struct {
@@ -42,11 +46,97 @@ func (c *eventlogClient) GetEventlog(ctx context.Context, in *GetEventlogRequest
 	return out, nil
 }
 
+func (c *eventlogClient) GetEventlogStream(ctx context.Context, in *GetEventlogRequest, opts ...grpc.CallOption) (Eventlog_GetEventlogStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Eventlog_ServiceDesc.Streams[0], "/Eventlog/GetEventlogStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventlogGetEventlogStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Eventlog_GetEventlogStreamClient interface {
+	Recv() (*EventlogEntry, error)
+	grpc.ClientStream
+}
+
+type eventlogGetEventlogStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventlogGetEventlogStreamClient) Recv() (*EventlogEntry, error) {
+	m := new(EventlogEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventlogClient) RecordContainerEvent(ctx context.Context, in *RecordContainerEventRequest, opts ...grpc.CallOption) (*RecordContainerEventReply, error) {
+	out := new(RecordContainerEventReply)
+	err := c.cc.Invoke(ctx, "/Eventlog/RecordContainerEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventlogClient) WatchEventlog(ctx context.Context, in *WatchEventlogRequest, opts ...grpc.CallOption) (Eventlog_WatchEventlogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Eventlog_ServiceDesc.Streams[1], "/Eventlog/WatchEventlog", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventlogWatchEventlogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Eventlog_WatchEventlogClient interface {
+	Recv() (*WatchEventlogReply, error)
+	grpc.ClientStream
+}
+
+type eventlogWatchEventlogClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventlogWatchEventlogClient) Recv() (*WatchEventlogReply, error) {
+	m := new(WatchEventlogReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventlogClient) EvaluatePolicy(ctx context.Context, in *EvaluatePolicyRequest, opts ...grpc.CallOption) (*EvaluatePolicyReply, error) {
+	out := new(EvaluatePolicyReply)
+	err := c.cc.Invoke(ctx, "/Eventlog/EvaluatePolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EventlogServer is the server API for Eventlog service.
 // All implementations must embed UnimplementedEventlogServer
 // for forward compatibility
 type EventlogServer interface {
 	GetEventlog(context.Context, *GetEventlogRequest) (*GetEventlogReply, error)
+	GetEventlogStream(*GetEventlogRequest, Eventlog_GetEventlogStreamServer) error
+	RecordContainerEvent(context.Context, *RecordContainerEventRequest) (*RecordContainerEventReply, error)
+	WatchEventlog(*WatchEventlogRequest, Eventlog_WatchEventlogServer) error
+	EvaluatePolicy(context.Context, *EvaluatePolicyRequest) (*EvaluatePolicyReply, error)
 	mustEmbedUnimplementedEventlogServer()
 }
 
@@ -57,6 +147,18 @@ type UnimplementedEventlogServer struct {
 func (UnimplementedEventlogServer) GetEventlog(context.Context, *GetEventlogRequest) (*GetEventlogReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEventlog not implemented")
 }
+func (UnimplementedEventlogServer) GetEventlogStream(*GetEventlogRequest, Eventlog_GetEventlogStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetEventlogStream not implemented")
+}
+func (UnimplementedEventlogServer) RecordContainerEvent(context.Context, *RecordContainerEventRequest) (*RecordContainerEventReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordContainerEvent not implemented")
+}
+func (UnimplementedEventlogServer) WatchEventlog(*WatchEventlogRequest, Eventlog_WatchEventlogServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEventlog not implemented")
+}
+func (UnimplementedEventlogServer) EvaluatePolicy(context.Context, *EvaluatePolicyRequest) (*EvaluatePolicyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvaluatePolicy not implemented")
+}
 func (UnimplementedEventlogServer) mustEmbedUnimplementedEventlogServer() {}
 
 // UnsafeEventlogServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +190,84 @@ func _Eventlog_GetEventlog_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Eventlog_GetEventlogStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetEventlogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventlogServer).GetEventlogStream(m, &eventlogGetEventlogStreamServer{stream})
+}
+
+type Eventlog_GetEventlogStreamServer interface {
+	Send(*EventlogEntry) error
+	grpc.ServerStream
+}
+
+type eventlogGetEventlogStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventlogGetEventlogStreamServer) Send(m *EventlogEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Eventlog_RecordContainerEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordContainerEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventlogServer).RecordContainerEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Eventlog/RecordContainerEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventlogServer).RecordContainerEvent(ctx, req.(*RecordContainerEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eventlog_WatchEventlog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventlogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventlogServer).WatchEventlog(m, &eventlogWatchEventlogServer{stream})
+}
+
+type Eventlog_WatchEventlogServer interface {
+	Send(*WatchEventlogReply) error
+	grpc.ServerStream
+}
+
+type eventlogWatchEventlogServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventlogWatchEventlogServer) Send(m *WatchEventlogReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Eventlog_EvaluatePolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluatePolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventlogServer).EvaluatePolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Eventlog/EvaluatePolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventlogServer).EvaluatePolicy(ctx, req.(*EvaluatePolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Eventlog_ServiceDesc is the grpc.ServiceDesc for Eventlog service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,7 +279,26 @@ var Eventlog_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetEventlog",
 			Handler:    _Eventlog_GetEventlog_Handler,
 		},
+		{
+			MethodName: "RecordContainerEvent",
+			Handler:    _Eventlog_RecordContainerEvent_Handler,
+		},
+		{
+			MethodName: "EvaluatePolicy",
+			Handler:    _Eventlog_EvaluatePolicy_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetEventlogStream",
+			Handler:       _Eventlog_GetEventlogStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEventlog",
+			Handler:       _Eventlog_WatchEventlog_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/eventlog-server.proto",
 }
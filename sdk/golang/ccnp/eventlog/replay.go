@@ -0,0 +1,50 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package eventlog
+
+import (
+	pb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/eventlog/proto"
+	"github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/measurement"
+	mpb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/measurement/proto"
+	"github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/replay"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// ReplayTdxEventlog replays the TDX event log and checks the result against the RTMRs in the TD report.
+func ReplayTdxEventlog() ([]replay.RtmrReplayResult, error) {
+	eventlogs, err := GetPlatformEventlog(WithEventlogCategory(pb.CATEGORY_TDX_EVENTLOG))
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "[ReplayTdxEventlog] fail to get Platform Eventlog")
+	}
+
+	report, err := measurement.GetPlatformMeasurement(measurement.WithMeasurementType(mpb.CATEGORY_TEE_REPORT))
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "[ReplayTdxEventlog] fail to get TD report")
+	}
+
+	tdReportInfo, ok := report.(measurement.TDReportInfo)
+	if !ok {
+		return nil, pkgerrors.New("[ReplayTdxEventlog] TD report not available")
+	}
+
+	return VerifyTdxEventlog(eventlogs, tdReportInfo.TDReport.Rtmrs[:])
+}
+
+// VerifyTdxEventlog replays the event logs and compares the result with the RTMRs,
+// which are the 4 RTMR values concatenated as in the TD report.
+func VerifyTdxEventlog(eventlogs []CCEventLogEntry, rtmrs []uint8) ([]replay.RtmrReplayResult, error) {
+	var measured [replay.RTMR_COUNT][]byte
+
+	if len(rtmrs) != replay.RTMR_COUNT*replay.RTMR_LEN {
+		return nil, replay.InvalidRtmrLengthErr
+	}
+
+	for i := 0; i < replay.RTMR_COUNT; i++ {
+		measured[i] = rtmrs[i*replay.RTMR_LEN : (i+1)*replay.RTMR_LEN]
+	}
+
+	return replay.VerifyEventlogs(toRawEventlogs(eventlogs), measured)
+}
@@ -0,0 +1,38 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package eventlog
+
+import (
+	"log"
+
+	el "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+)
+
+// Secure Boot state types, see the resources package of the eventlog server
+type (
+	SecureBootState     = el.SecureBootState
+	SignatureDatabase   = el.SignatureDatabase
+	EfiSignatureList    = el.EfiSignatureList
+	EfiSignature        = el.EfiSignature
+	X509CertificateInfo = el.X509CertificateInfo
+	BootApplication     = el.BootApplication
+)
+
+// GetSecureBootState fetches the platform event log, TDX by default or TPM with
+// WithEventlogCategory, and reports the measured Secure Boot configuration.
+func GetSecureBootState(opts ...func(*GetPlatformEventlogOptions)) (SecureBootState, error) {
+	eventlogs, err := GetPlatformEventlog(opts...)
+	if err != nil {
+		log.Fatalf("[GetSecureBootState] fail to get Platform Eventlog: %v", err)
+	}
+
+	return GetSecureBootStateFromEventlog(eventlogs)
+}
+
+// GetSecureBootStateFromEventlog reports the Secure Boot configuration measured in the given event logs.
+func GetSecureBootStateFromEventlog(eventlogs []CCEventLogEntry) (SecureBootState, error) {
+	return el.GetSecureBootState(toRawEventlogs(eventlogs))
+}
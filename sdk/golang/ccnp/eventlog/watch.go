@@ -0,0 +1,265 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package eventlog
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/eventlog/proto"
+	el "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Interval before a watch interrupted by a restart of the server is resumed
+	WATCH_RETRY_INTERVAL = time.Second
+)
+
+// WatchedEventlog is an event of the watched event log, one of the entries is set depending
+// on the event log: Eventlog for TDX and TPM, ImaEventlog for IMA and ContainerEventlog for
+// the containers.
+type WatchedEventlog struct {
+	Sequence uint64 // position of the event in the whole event log
+	Snapshot bool   // the event was already in the log when the subscription started
+
+	Eventlog          *CCEventLogEntry
+	ImaEventlog       *ImaEventLogEntry
+	ContainerEventlog *ContainerEventLogEntry
+}
+
+// EventlogSubscription receives the events of a watched event log, see WatchEventlog.
+type EventlogSubscription struct {
+	request       *pb.WatchEventlogRequest
+	events        chan WatchedEventlog
+	retryInterval time.Duration
+
+	mutex        sync.Mutex
+	started      bool
+	snapshotEnd  uint64
+	nextSequence uint64
+	err          error
+}
+
+// WithEventlogLevel selects the container event log with LEVEL_SAAS for WatchEventlog.
+func WithEventlogLevel(eventlogLevel pb.LEVEL) func(*GetPlatformEventlogOptions) {
+	return func(opts *GetPlatformEventlogOptions) {
+		opts.eventlogLevel = eventlogLevel
+	}
+}
+
+// WithSnapshot sends the events already in the log before the appended ones for WatchEventlog.
+func WithSnapshot(snapshot bool) func(*GetPlatformEventlogOptions) {
+	return func(opts *GetPlatformEventlogOptions) {
+		opts.snapshot = snapshot
+	}
+}
+
+// WithStartSequence resumes WatchEventlog from the sequence, 0 included, to subscribe again
+// from the NextSequence of a previous subscription.
+func WithStartSequence(startSequence uint64) func(*GetPlatformEventlogOptions) {
+	return func(opts *GetPlatformEventlogOptions) {
+		opts.startSequence = startSequence
+		opts.resume = true
+	}
+}
+
+// WatchEventlog subscribes to the events appended to the TDX, TPM or IMA event log selected
+// by WithEventlogCategory, or to the container event log with WithEventlogLevel(pb.LEVEL_SAAS)
+// and WithContainerId. Only the events appended after the call are received, unless
+// WithSnapshot or WithStartSequence is given. The subscription resumes the watch from the next
+// sequence if the server restarts, and closes the channel of events once ctx is cancelled or
+// the watch fails.
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//	subscription := WatchEventlog(ctx, WithEventlogCategory(pb.CATEGORY_IMA_EVENTLOG), WithSnapshot(true))
+//	for event := range subscription.Events() {
+//		...
+//	}
+//	if err := subscription.Err(); err != nil {
+//		...
+//	}
+func WatchEventlog(ctx context.Context, opts ...func(*GetPlatformEventlogOptions)) *EventlogSubscription {
+
+	input := GetPlatformEventlogOptions{eventlogLevel: pb.LEVEL_PAAS, eventlogCategory: pb.CATEGORY_TDX_EVENTLOG}
+	for _, opt := range opts {
+		opt(&input)
+	}
+
+	if input.eventlogLevel != pb.LEVEL_PAAS && input.eventlogLevel != pb.LEVEL_SAAS {
+		log.Fatalf("[WatchEventlog] Invalid eventlogLevel specified")
+	}
+
+	if input.eventlogLevel == pb.LEVEL_PAAS && !isEventlogCategoryValid(input.eventlogCategory) &&
+		input.eventlogCategory != pb.CATEGORY_IMA_EVENTLOG {
+		log.Fatalf("[WatchEventlog] Invalid eventlogCategory specified")
+	}
+
+	channel, err := grpc.Dial(UDS_PATH, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("[WatchEventlog] can not connect to UDS: %v", err)
+	}
+
+	subscription := newEventlogSubscription(input)
+	go func() {
+		defer channel.Close()
+		subscription.run(ctx, pb.NewEventlogClient(channel))
+	}()
+
+	return subscription
+}
+
+func newEventlogSubscription(input GetPlatformEventlogOptions) *EventlogSubscription {
+	return &EventlogSubscription{
+		request: &pb.WatchEventlogRequest{
+			EventlogLevel:    input.eventlogLevel,
+			EventlogCategory: input.eventlogCategory,
+			ContainerId:      input.containerId,
+			Snapshot:         input.snapshot,
+			StartSequence:    input.startSequence,
+			Resume:           input.resume,
+		},
+		events:        make(chan WatchedEventlog),
+		retryInterval: WATCH_RETRY_INTERVAL,
+	}
+}
+
+// Events returns the channel of events, closed once the subscription ends.
+func (s *EventlogSubscription) Events() <-chan WatchedEventlog {
+	return s.events
+}
+
+// Err returns why the subscription ended once the channel of events is closed, it is nil if
+// the context was cancelled.
+func (s *EventlogSubscription) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+// NextSequence returns the sequence following the last event received, to subscribe again
+// with WithStartSequence.
+func (s *EventlogSubscription) NextSequence() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.nextSequence
+}
+
+func (s *EventlogSubscription) run(ctx context.Context, client pb.EventlogClient) {
+	defer close(s.events)
+
+	for {
+		err := s.watch(ctx, client)
+		if ctx.Err() != nil {
+			return
+		}
+
+		/* the server is restarting, the watch resumes from the next sequence */
+		if status.Code(err) != codes.Unavailable {
+			s.mutex.Lock()
+			s.err = err
+			s.mutex.Unlock()
+			return
+		}
+		log.Printf("[WatchEventlog] watch interrupted, resuming from sequence %d: %v", s.NextSequence(), err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.retryInterval):
+		}
+	}
+}
+
+func (s *EventlogSubscription) watch(ctx context.Context, client pb.EventlogClient) error {
+	request := &pb.WatchEventlogRequest{
+		EventlogLevel:    s.request.EventlogLevel,
+		EventlogCategory: s.request.EventlogCategory,
+		ContainerId:      s.request.ContainerId,
+		Snapshot:         s.request.Snapshot,
+		StartSequence:    s.request.StartSequence,
+		Resume:           s.request.Resume,
+	}
+
+	s.mutex.Lock()
+	if s.started {
+		request.Resume = true
+		request.StartSequence = s.nextSequence
+	}
+	s.mutex.Unlock()
+
+	stream, err := client.WatchEventlog(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	/* the header is missing if the watch failed, the error is returned by Recv */
+	header, err := stream.Header()
+	if err != nil {
+		return err
+	}
+	if values := header.Get(el.EVENTLOG_WATCH_SEQUENCE_KEY); len(values) > 0 {
+		s.start(request, values[0])
+	}
+
+	for {
+		reply, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		event := WatchedEventlog{Sequence: reply.Sequence}
+		s.mutex.Lock()
+		event.Snapshot = reply.Sequence < s.snapshotEnd
+		s.mutex.Unlock()
+
+		if request.EventlogLevel == pb.LEVEL_SAAS {
+			if entry, ok := getStreamContainerEventlogEntry(reply.Entry); ok {
+				event.ContainerEventlog = &entry
+			}
+		} else if request.EventlogCategory == pb.CATEGORY_IMA_EVENTLOG {
+			if entry, ok := getStreamImaEventlogEntry(reply.Entry); ok {
+				event.ImaEventlog = &entry
+			}
+		} else if entry, ok := getStreamEventlogEntry(reply.Entry); ok {
+			event.Eventlog = &entry
+		}
+
+		select {
+		case s.events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		s.mutex.Lock()
+		s.nextSequence = reply.Sequence + 1
+		s.mutex.Unlock()
+	}
+}
+
+/* The first watch tells the length of the log, the events before it are the snapshot */
+func (s *EventlogSubscription) start(request *pb.WatchEventlogRequest, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.started {
+		return
+	}
+
+	length, _ := strconv.ParseUint(value, 10, 64)
+	s.snapshotEnd = length
+	s.nextSequence = request.StartSequence
+	if !request.Snapshot && !request.Resume {
+		s.nextSequence = length
+	}
+	s.started = true
+}
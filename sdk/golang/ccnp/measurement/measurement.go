@@ -8,6 +8,7 @@ package measurement
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/binary"
 	"log"
@@ -21,12 +22,37 @@ import (
 const (
 	UDS_PATH       = "unix:/run/ccnp/uds/measurement.sock"
 	TDX_REPORT_LEN = 1024
+	// The length of the SEV-SNP ATTESTATION_REPORT
+	SNP_REPORT_LEN = 1184
+	// The highest VMPL a SEV-SNP report can be requested for
+	SNP_MAX_VMPL = 3
+	// The number of TPM PCRs of the PC client platform
+	TPM_PCR_COUNT = 24
 )
 
+var InvalidSNPReportErr = pkgerrors.New("SEV-SNP report with invalid length")
+var InvalidTPMReportErr = pkgerrors.New("TPM PCRs not matching the measurement")
+var InvalidSNPCertTableErr = pkgerrors.New("Invalid SEV-SNP certificate table")
+
+// A certificate table entry is a GUID, an offset and a length, the last entry is all zero
+const SNP_CERT_TABLE_ENTRY_LEN = 24
+
+// Names of the certificates in the SEV-SNP certificate table, by GUID in the byte order of RFC 4122
+var snpCertNames = map[[16]uint8]string{
+	{0x63, 0xda, 0x75, 0x8d, 0xe6, 0x64, 0x45, 0x64, 0xad, 0xc5, 0xf4, 0xb9, 0x3b, 0xe8, 0xac, 0xcd}: "VCEK",
+	{0xa8, 0x07, 0x4b, 0xc2, 0xa2, 0x5a, 0x48, 0x3e, 0xaa, 0xe6, 0x39, 0xc0, 0x45, 0xa0, 0xb8, 0xa1}: "VLEK",
+	{0x4a, 0xb7, 0xb3, 0x79, 0xbb, 0xac, 0x4f, 0xe4, 0xa0, 0x2f, 0x05, 0xae, 0xf3, 0x27, 0xc7, 0x82}: "ASK",
+	{0xc0, 0xb4, 0x06, 0xa4, 0xa8, 0x03, 0x49, 0x52, 0x97, 0x43, 0x3f, 0xb6, 0x01, 0x4c, 0xd0, 0xae}: "ARK",
+}
+
 type GetPlatformMeasurementOptions struct {
 	measurementType pb.CATEGORY
 	reportData      string
 	registerIndex   int32
+	vmpl            uint32
+	extendedReport  bool
+	pcrBank         pb.PCR_BANK
+	pcrIndexes      []int32
 }
 
 type TDReportInfo struct {
@@ -65,6 +91,63 @@ type TDReportStruct struct {
 	Reserved4     [112]uint8
 }
 
+type SNPReportInfo struct {
+	SNPReportRaw [SNP_REPORT_LEN]uint8 // full ATTESTATION_REPORT
+	SNPReport    SNPReportStruct
+	Certificates []SNPCertificate // certificate table of the extended report
+}
+
+/*
+SNPCertificate is an entry of the certificate table the host provides with the extended
+report. Name is VCEK, VLEK, ASK or ARK, Certificate is nil for entries of unknown GUIDs.
+*/
+type SNPCertificate struct {
+	Guid        [16]uint8
+	Name        string
+	Raw         []uint8
+	Certificate *x509.Certificate
+}
+
+/*
+ATTESTATION_REPORT defined in the SEV Secure Nested Paging Firmware ABI Specification,
+the TCB versions hold the SVNs of the boot loader, TEE, SNP firmware and microcode.
+*/
+type SNPReportStruct struct {
+	Version         uint32
+	GuestSvn        uint32
+	Policy          uint64
+	FamilyId        [16]uint8
+	ImageId         [16]uint8
+	Vmpl            uint32
+	SignatureAlgo   uint32
+	CurrentTcb      uint64
+	PlatformInfo    uint64
+	KeyInfo         uint32 // AUTHOR_KEY_EN, MASK_CHIP_KEY and SIGNING_KEY
+	Reserved1       uint32
+	ReportData      [64]uint8
+	Measurement     [48]uint8
+	HostData        [32]uint8
+	IdKeyDigest     [48]uint8
+	AuthorKeyDigest [48]uint8
+	ReportId        [32]uint8
+	ReportIdMa      [32]uint8
+	ReportedTcb     uint64
+	Reserved2       [24]uint8
+	ChipId          [64]uint8
+	CommittedTcb    uint64
+	CurrentBuild    uint8
+	CurrentMinor    uint8
+	CurrentMajor    uint8
+	Reserved3       uint8
+	CommittedBuild  uint8
+	CommittedMinor  uint8
+	CommittedMajor  uint8
+	Reserved4       uint8
+	LaunchTcb       uint64
+	Reserved5       [168]uint8
+	Signature       [512]uint8 // ECDSA P-384 R and S, little endian and zero extended
+}
+
 type TDXRtmrInfo struct {
 	TDXRtmrRaw []uint8
 }
@@ -74,7 +157,16 @@ type TPMReportInfo struct {
 	TPMReport    TPMReportStruct
 }
 
-type TPMReportStruct struct{}
+// TPMReportStruct holds the PCRs of a bank in the order they were requested.
+type TPMReportStruct struct {
+	Bank pb.PCR_BANK
+	Pcrs []TPMPcr
+}
+
+type TPMPcr struct {
+	Index  int32
+	Digest []uint8
+}
 
 func isMeasurementTypeValid(measurementType pb.CATEGORY) bool {
 	return measurementType == pb.CATEGORY_TEE_REPORT || measurementType == pb.CATEGORY_TDX_RTMR || measurementType == pb.CATEGORY_TPM
@@ -92,12 +184,40 @@ func WithReportData(reportData string) func(*GetPlatformMeasurementOptions) {
 	}
 }
 
+// WithVmpl requests the SEV-SNP report for the VMPL, 0 by default.
+func WithVmpl(vmpl uint32) func(*GetPlatformMeasurementOptions) {
+	return func(opts *GetPlatformMeasurementOptions) {
+		opts.vmpl = vmpl
+	}
+}
+
+// WithExtendedReport requests the SEV-SNP report with the certificate table of the host.
+func WithExtendedReport(extendedReport bool) func(*GetPlatformMeasurementOptions) {
+	return func(opts *GetPlatformMeasurementOptions) {
+		opts.extendedReport = extendedReport
+	}
+}
+
 func WithRegisterIndex(registerIndex int32) func(*GetPlatformMeasurementOptions) {
 	return func(opts *GetPlatformMeasurementOptions) {
 		opts.registerIndex = registerIndex
 	}
 }
 
+// WithPcrBank reads the TPM PCRs of the bank, SHA256 by default.
+func WithPcrBank(pcrBank pb.PCR_BANK) func(*GetPlatformMeasurementOptions) {
+	return func(opts *GetPlatformMeasurementOptions) {
+		opts.pcrBank = pcrBank
+	}
+}
+
+// WithPcrIndexes reads the TPM PCRs in one request, in place of the PCR of WithRegisterIndex.
+func WithPcrIndexes(pcrIndexes ...int32) func(*GetPlatformMeasurementOptions) {
+	return func(opts *GetPlatformMeasurementOptions) {
+		opts.pcrIndexes = pcrIndexes
+	}
+}
+
 func GetPlatformMeasurement(opts ...func(*GetPlatformMeasurementOptions)) (interface{}, error) {
 	input := GetPlatformMeasurementOptions{measurementType: pb.CATEGORY_TEE_REPORT, reportData: "", registerIndex: 0}
 	for _, opt := range opts {
@@ -108,18 +228,24 @@ func GetPlatformMeasurement(opts ...func(*GetPlatformMeasurementOptions)) (inter
 		log.Fatalf("[GetPlatformMeasurement] Invalid measurementType specified")
 	}
 
-	if input.measurementType == pb.CATEGORY_TPM {
-		log.Fatalf("[GetPlatformMeasurement] TPM to be supported later")
-	}
-
 	if len(input.reportData) > 64 {
 		log.Fatalf("[GetPlatformMeasurement] Invalid reportData specified")
 	}
 
-	if input.registerIndex < 0 || input.registerIndex > 16 {
+	if input.measurementType == pb.CATEGORY_TPM {
+		for _, index := range append([]int32{input.registerIndex}, input.pcrIndexes...) {
+			if index < 0 || index >= TPM_PCR_COUNT {
+				log.Fatalf("[GetPlatformMeasurement] Invalid PCR index specified")
+			}
+		}
+	} else if input.registerIndex < 0 || input.registerIndex > 16 {
 		log.Fatalf("[GetPlatformMeasurement] Invalid registerIndex specified")
 	}
 
+	if input.vmpl > SNP_MAX_VMPL {
+		log.Fatalf("[GetPlatformMeasurement] Invalid vmpl specified")
+	}
+
 	channel, err := grpc.Dial(UDS_PATH, grpc.WithInsecure())
 	if err != nil {
 		log.Fatalf("[GetPlatformMeasurement] can not connect to UDS: %v", err)
@@ -136,6 +262,10 @@ func GetPlatformMeasurement(opts ...func(*GetPlatformMeasurementOptions)) (inter
 		MeasurementCategory: input.measurementType,
 		ReportData:          input.reportData,
 		RegisterIndex:       input.registerIndex,
+		Vmpl:                input.vmpl,
+		ExtendedReport:      input.extendedReport,
+		PcrBank:             input.pcrBank,
+		PcrIndexes:          input.pcrIndexes,
 	})
 
 	if err != nil {
@@ -149,7 +279,14 @@ func GetPlatformMeasurement(opts ...func(*GetPlatformMeasurementOptions)) (inter
 
 	switch input.measurementType {
 	case pb.CATEGORY_TEE_REPORT:
-		//TODO: need to get the type of TEE: TDX, SEV, vTPM etc.
+		if response.TeeType == pb.TEE_SEV_SNP {
+			snpReportInfo, err := parseSNPReport(measurement)
+			if err != nil || len(response.CertTable) == 0 {
+				return snpReportInfo, err
+			}
+			snpReportInfo.Certificates, err = ParseSNPCertTable(response.CertTable)
+			return snpReportInfo, err
+		}
 		var tdReportInfo = TDReportInfo{}
 		err = binary.Read(bytes.NewReader(measurement[0:TDX_REPORT_LEN]), binary.LittleEndian, &tdReportInfo.TDReportRaw)
 		if err != nil {
@@ -162,7 +299,7 @@ func GetPlatformMeasurement(opts ...func(*GetPlatformMeasurementOptions)) (inter
 		tdxRtmrInfo.TDXRtmrRaw = measurement
 		return tdxRtmrInfo, nil
 	case pb.CATEGORY_TPM:
-		return "", pkgerrors.New("[GetPlatformMeasurement] TPM to be supported later")
+		return parseTPMReport(measurement, input.pcrBank, response.PcrValues)
 	default:
 		log.Fatalf("[GetPlatformMeasurement] unknown TEE enviroment!")
 	}
@@ -180,8 +317,107 @@ func parseTDXReport(report []byte) TDReportStruct {
 	return tdreport
 }
 
-func parseTPMReport(report []byte) (interface{}, error) {
-	return nil, pkgerrors.New("TPM to be supported later.")
+func parseSNPReport(report []byte) (SNPReportInfo, error) {
+	var snpReportInfo = SNPReportInfo{}
+	if len(report) != SNP_REPORT_LEN {
+		return snpReportInfo, InvalidSNPReportErr
+	}
+
+	copy(snpReportInfo.SNPReportRaw[:], report)
+	err := binary.Read(bytes.NewReader(report), binary.LittleEndian, &snpReportInfo.SNPReport)
+	if err != nil {
+		return snpReportInfo, err
+	}
+
+	return snpReportInfo, nil
+}
+
+// ParseSNPCertTable parses the SEV-SNP certificate table up to its all zero entry.
+func ParseSNPCertTable(certTable []byte) ([]SNPCertificate, error) {
+	var certificates []SNPCertificate
+
+	for index := 0; ; index += SNP_CERT_TABLE_ENTRY_LEN {
+		if index+SNP_CERT_TABLE_ENTRY_LEN > len(certTable) {
+			return nil, InvalidSNPCertTableErr
+		}
+
+		var certificate SNPCertificate
+		copy(certificate.Guid[:], certTable[index:index+16])
+		offset := uint64(binary.LittleEndian.Uint32(certTable[index+16 : index+20]))
+		length := uint64(binary.LittleEndian.Uint32(certTable[index+20 : index+24]))
+		if certificate.Guid == [16]uint8{} && offset == 0 && length == 0 {
+			return certificates, nil
+		}
+
+		if offset+length > uint64(len(certTable)) {
+			return nil, InvalidSNPCertTableErr
+		}
+		certificate.Raw = certTable[offset : offset+length]
+
+		name, known := snpCertNames[certificate.Guid]
+		if known {
+			var err error
+			certificate.Name = name
+			certificate.Certificate, err = x509.ParseCertificate(certificate.Raw)
+			if err != nil {
+				return nil, pkgerrors.Wrapf(InvalidSNPCertTableErr, "%s certificate: %v", name, err)
+			}
+		}
+		certificates = append(certificates, certificate)
+	}
+}
+
+// GetCertificate returns the certificate of the table by name, VCEK, VLEK, ASK or ARK.
+func (info SNPReportInfo) GetCertificate(name string) (*x509.Certificate, bool) {
+	for _, certificate := range info.Certificates {
+		if certificate.Name == name && certificate.Certificate != nil {
+			return certificate.Certificate, true
+		}
+	}
+	return nil, false
+}
+
+func parseTPMReport(report []byte, pcrBank pb.PCR_BANK, pcrValues []*pb.PcrValue) (TPMReportInfo, error) {
+	var tpmReportInfo = TPMReportInfo{TPMReportRaw: report}
+	tpmReportInfo.TPMReport.Bank = pcrBank
+
+	var digests []uint8
+	for _, value := range pcrValues {
+		tpmReportInfo.TPMReport.Pcrs = append(tpmReportInfo.TPMReport.Pcrs, TPMPcr{Index: value.Index, Digest: value.Digest})
+		digests = append(digests, value.Digest...)
+	}
+
+	/* the measurement is the concatenation of the PCR digests */
+	if len(pcrValues) == 0 || !bytes.Equal(digests, report) {
+		return tpmReportInfo, InvalidTPMReportErr
+	}
+	return tpmReportInfo, nil
+}
+
+/*
+ExtendMeasurement extends RTMR 2 with the SHA384 digest of the measured data, e.g. a config
+file or model weights, and records the event describing it in the runtime event log served by
+the eventlog server, so that the replay of the TDX event log matches the RTMR. RTMR 3 is
+reserved to the eventlog server and refused on the service socket.
+*/
+func ExtendMeasurement(registerIndex int32, digest []uint8, event []uint8) error {
+	channel, err := grpc.Dial(UDS_PATH, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("[ExtendMeasurement] can not connect to UDS: %v", err)
+	}
+	defer channel.Close()
+
+	client := pb.NewMeasurementClient(channel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err = client.ExtendMeasurement(ctx, &pb.ExtendMeasurementRequest{
+		RegisterIndex: registerIndex,
+		Digest:        digest,
+		Event:         event,
+	})
+	return err
 }
 
 func GetContainerMeasurement() (interface{}, error) {
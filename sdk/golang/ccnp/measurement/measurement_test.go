@@ -6,9 +6,20 @@
 package measurement
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"math/big"
 	"testing"
+	"time"
 
 	pb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/measurement/proto"
+	pkgerrors "github.com/pkg/errors"
 )
 
 const (
@@ -228,3 +239,183 @@ func TestGetPlatformMeasurementRTMRWithMeasurementTypeAndIndex(t *testing.T) {
 		t.Fatalf("[TestGetPlatformMeasurementRTMRWithMeasurementTypeAndIndex] unknown TEE enviroment!")
 	}
 }
+
+func TestExtendMeasurement(t *testing.T) {
+	getRtmr := func() []uint8 {
+		ret, err := GetPlatformMeasurement(WithMeasurementType(pb.CATEGORY_TDX_RTMR), WithRegisterIndex(2))
+		if err != nil {
+			t.Fatalf("[TestExtendMeasurement] get RTMR error: %v", err)
+		}
+		return ret.(TDXRtmrInfo).TDXRtmrRaw
+	}
+
+	digest := sha512.Sum384([]byte("ccnp-sdk-test"))
+	before := getRtmr()
+	if err := ExtendMeasurement(2, digest[:], []byte("ccnp-sdk-test")); err != nil {
+		t.Fatalf("[TestExtendMeasurement] extend RTMR error: %v", err)
+	}
+
+	expected := sha512.Sum384(append(before, digest[:]...))
+	if after := getRtmr(); !bytes.Equal(after, expected[:]) {
+		t.Fatalf("[TestExtendMeasurement] error: expected RTMR %x, retrieved %x", expected, after)
+	}
+
+	if err := ExtendMeasurement(0, digest[:], nil); err == nil {
+		t.Fatalf("[TestExtendMeasurement] error: expected error extending RTMR 0, retrieved nil")
+	}
+
+	/* RTMR 3 is reserved to the eventlog server */
+	if err := ExtendMeasurement(3, digest[:], nil); err == nil {
+		t.Fatalf("[TestExtendMeasurement] error: expected error extending RTMR 3, retrieved nil")
+	}
+}
+
+func TestGetPlatformMeasurementTPMWithPcrIndexes(t *testing.T) {
+	ret, err := GetPlatformMeasurement(WithMeasurementType(pb.CATEGORY_TPM), WithPcrBank(pb.PCR_BANK_SHA384), WithPcrIndexes(0, 7, 10))
+	if err != nil {
+		t.Fatalf("[TestGetPlatformMeasurementTPMWithPcrIndexes] get Platform Measurement error: %v", err)
+	}
+
+	switch ret.(type) {
+	case TPMReportInfo:
+		var r, _ = ret.(TPMReportInfo)
+		if len(r.TPMReport.Pcrs) != 3 || r.TPMReport.Pcrs[2].Index != 10 || len(r.TPMReport.Pcrs[2].Digest) != 48 {
+			t.Fatalf("[TestGetPlatformMeasurementTPMWithPcrIndexes] wrong PCRs, retrieved: %+v", r.TPMReport)
+		}
+
+	default:
+		t.Fatalf("[TestGetPlatformMeasurementTPMWithPcrIndexes] unknown TEE enviroment!")
+	}
+}
+
+func TestParseTPMReport(t *testing.T) {
+	pcr0 := bytes.Repeat([]byte{0xab}, 32)
+	pcr7 := bytes.Repeat([]byte{0xcd}, 32)
+	values := []*pb.PcrValue{{Index: 7, Digest: pcr7}, {Index: 0, Digest: pcr0}}
+
+	r, err := parseTPMReport(append(append([]byte{}, pcr7...), pcr0...), pb.PCR_BANK_SHA256, values)
+	if err != nil {
+		t.Fatalf("[TestParseTPMReport] parse TPM report error: %v", err)
+	}
+	if r.TPMReport.Bank != pb.PCR_BANK_SHA256 || len(r.TPMReport.Pcrs) != 2 || r.TPMReport.Pcrs[0].Index != 7 ||
+		!bytes.Equal(r.TPMReport.Pcrs[0].Digest, pcr7) || r.TPMReport.Pcrs[1].Index != 0 || !bytes.Equal(r.TPMReport.Pcrs[1].Digest, pcr0) {
+		t.Fatalf("[TestParseTPMReport] wrong TPM report, retrieved: %+v", r.TPMReport)
+	}
+
+	if _, err := parseTPMReport(pcr7, pb.PCR_BANK_SHA256, values); err != InvalidTPMReportErr {
+		t.Fatalf("[TestParseTPMReport] error: expected %v, retrieved %v", InvalidTPMReportErr, err)
+	}
+}
+
+func TestParseSNPReport(t *testing.T) {
+	if size := binary.Size(SNPReportStruct{}); size != SNP_REPORT_LEN {
+		t.Fatalf("[TestParseSNPReport] wrong SNPReportStruct size, retrieved: %v, expected: %v", size, SNP_REPORT_LEN)
+	}
+
+	report := make([]byte, SNP_REPORT_LEN)
+	binary.LittleEndian.PutUint32(report[0x0:], 2)
+	binary.LittleEndian.PutUint32(report[0x30:], 1)
+	copy(report[0x50:], EXPECTED_REPORT_DATA)
+	copy(report[0x90:], bytes.Repeat([]byte{0xab}, 48))
+	copy(report[0x1a0:], bytes.Repeat([]byte{0xcd}, 64))
+	binary.LittleEndian.PutUint64(report[0x1f0:], 0xdb18000000000307)
+	copy(report[0x2a0:], bytes.Repeat([]byte{0xef}, 512))
+
+	r, err := parseSNPReport(report)
+	if err != nil {
+		t.Fatalf("[TestParseSNPReport] parse SEV-SNP report error: %v", err)
+	}
+
+	snpReport := r.SNPReport
+	if snpReport.Version != 2 || snpReport.Vmpl != 1 || string(snpReport.ReportData[:]) != EXPECTED_REPORT_DATA ||
+		snpReport.Measurement[47] != 0xab || snpReport.ChipId[63] != 0xcd || snpReport.LaunchTcb != 0xdb18000000000307 ||
+		snpReport.Signature[511] != 0xef || !bytes.Equal(r.SNPReportRaw[:], report) {
+		t.Fatalf("[TestParseSNPReport] wrong SEV-SNP report, retrieved: %+v", snpReport)
+	}
+
+	if _, err := parseSNPReport(report[:SNP_REPORT_LEN-1]); err != InvalidSNPReportErr {
+		t.Fatalf("[TestParseSNPReport] error: expected %v, retrieved %v", InvalidSNPReportErr, err)
+	}
+}
+
+/* A self signed certificate standing for a certificate of the AMD chain */
+func createSNPCertificate(t *testing.T, name string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("[createSNPCertificate] generate key error: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("[createSNPCertificate] create certificate error: %v", err)
+	}
+	return der
+}
+
+func buildSNPCertTable(guids [][16]uint8, certs [][]byte) []byte {
+	offset := (len(guids) + 1) * SNP_CERT_TABLE_ENTRY_LEN
+	table := make([]byte, offset)
+	for i, guid := range guids {
+		entry := table[i*SNP_CERT_TABLE_ENTRY_LEN:]
+		copy(entry, guid[:])
+		binary.LittleEndian.PutUint32(entry[16:], uint32(offset))
+		binary.LittleEndian.PutUint32(entry[20:], uint32(len(certs[i])))
+		table = append(table, certs[i]...)
+		offset += len(certs[i])
+	}
+	return table
+}
+
+func TestParseSNPCertTable(t *testing.T) {
+	guidOf := map[string][16]uint8{}
+	for guid, name := range snpCertNames {
+		guidOf[name] = guid
+	}
+	unknownGuid := [16]uint8{0x01}
+
+	names := []string{"VCEK", "ASK", "ARK"}
+	var guids [][16]uint8
+	var certs [][]byte
+	for _, name := range names {
+		guids = append(guids, guidOf[name])
+		certs = append(certs, createSNPCertificate(t, name))
+	}
+	guids = append(guids, unknownGuid)
+	certs = append(certs, []byte{1, 2, 3})
+
+	certificates, err := ParseSNPCertTable(buildSNPCertTable(guids, certs))
+	if err != nil || len(certificates) != len(guids) {
+		t.Fatalf("[TestParseSNPCertTable] parse certificate table error: %v, %d entries", err, len(certificates))
+	}
+
+	info := SNPReportInfo{Certificates: certificates}
+	for _, name := range names {
+		certificate, ok := info.GetCertificate(name)
+		if !ok || certificate.Subject.CommonName != name {
+			t.Errorf("[TestParseSNPCertTable] wrong %s certificate, retrieved: %v", name, certificate)
+		}
+	}
+	if unknown := certificates[3]; unknown.Name != "" || unknown.Certificate != nil || !bytes.Equal(unknown.Raw, []byte{1, 2, 3}) {
+		t.Errorf("[TestParseSNPCertTable] wrong unknown entry, retrieved: %+v", unknown)
+	}
+	if _, ok := info.GetCertificate("VLEK"); ok {
+		t.Errorf("[TestParseSNPCertTable] VLEK certificate found in a table without VLEK")
+	}
+
+	invalid := [][]byte{
+		nil,
+		buildSNPCertTable(guids[:1], certs[:1])[:SNP_CERT_TABLE_ENTRY_LEN],
+		buildSNPCertTable([][16]uint8{guidOf["VCEK"]}, [][]byte{{1, 2, 3}}),
+	}
+	for _, certTable := range invalid {
+		if _, err := ParseSNPCertTable(certTable); pkgerrors.Cause(err) != InvalidSNPCertTableErr {
+			t.Errorf("[TestParseSNPCertTable] error: expected %v, retrieved %v", InvalidSNPCertTableErr, err)
+		}
+	}
+}
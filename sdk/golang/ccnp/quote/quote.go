@@ -25,8 +25,6 @@ const (
 	TYPE_TPM = "TPM"
 )
 
-type TPMQuote struct{}
-
 type TDXQuote struct {
 	Quote          []uint8    // full TD quote
 	Version        uint16     // TD quote version
@@ -186,6 +184,5 @@ func parseTDXQuote(quote []byte) (interface{}, error) {
 }
 
 func parseTPMQuote(quote []byte) (interface{}, error) {
-	// TODO: add vTPM support later
-	return nil, pkgerrors.New("TPM support to be implemented later.")
+	return ParseTPMQuote(quote)
 }
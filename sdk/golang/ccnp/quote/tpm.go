@@ -0,0 +1,475 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package quote
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/measurement"
+	mpb "github.com/intel/confidential-cloud-native-primitives/sdk/golang/ccnp/measurement/proto"
+	pkgerrors "github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+const (
+	TPM_GENERATED_VALUE   = 0xff544347
+	TPM_ST_ATTEST_QUOTE   = 0x8018
+	TPM_ALG_RSA           = 0x0001
+	TPM_ALG_SHA1          = 0x0004
+	TPM_ALG_SHA256        = 0x000b
+	TPM_ALG_SHA384        = 0x000c
+	TPM_ALG_SHA512        = 0x000d
+	TPM_ALG_NULL          = 0x0010
+	TPM_ALG_RSASSA        = 0x0014
+	TPM_ALG_RSAPSS        = 0x0016
+	TPM_ALG_ECDSA         = 0x0018
+	TPM_ALG_ECC           = 0x0023
+	TPM_ECC_NIST_P256     = 0x0003
+	TPM_ECC_NIST_P384     = 0x0004
+	TPM_ECC_NIST_P521     = 0x0005
+	TPM_OBJECT_RESTRICTED = 0x00010000
+	TPM_OBJECT_SIGN       = 0x00040000
+	// The attestation keys of the measurement server are primary keys of the endorsement hierarchy
+	TPM_RH_ENDORSEMENT = 0x4000000b
+)
+
+var InvalidTPMQuoteErr = pkgerrors.New("Invalid TPM quote")
+var TPMQuoteSignatureErr = pkgerrors.New("TPM quote signature not verified by the attestation key")
+var TPMQuoteSignerErr = pkgerrors.New("TPM quote not signed by the trusted attestation key")
+
+var tpmHashes = map[uint16]crypto.Hash{
+	TPM_ALG_SHA1:   crypto.SHA1,
+	TPM_ALG_SHA256: crypto.SHA256,
+	TPM_ALG_SHA384: crypto.SHA384,
+	TPM_ALG_SHA512: crypto.SHA512,
+}
+
+var tpmCurves = map[uint16]elliptic.Curve{
+	TPM_ECC_NIST_P256: elliptic.P256(),
+	TPM_ECC_NIST_P384: elliptic.P384(),
+	TPM_ECC_NIST_P521: elliptic.P521(),
+}
+
+/*
+TPMQuote is the TPM2_Quote made of the TPMS_ATTEST with TPMS_QUOTE_INFO, its signature and the
+public area of the attestation key, in TPM2B_ATTEST, TPMT_SIGNATURE and TPM2B_PUBLIC.
+*/
+type TPMQuote struct {
+	Quote           []uint8 // full TPM quote
+	Attest          []uint8 // TPMS_ATTEST signed by the attestation key
+	QualifiedSigner []uint8 // Qualified name of the attestation key
+	ExtraData       []uint8 // Qualifying data given by the caller
+	Clock           uint64
+	ResetCount      uint32
+	RestartCount    uint32
+	Safe            bool
+	FirmwareVersion uint64
+	PcrSelections   []TPMPcrSelection // PCRs quoted
+	PcrDigest       []uint8           // Digest of the quoted PCRs in increasing order of the banks and PCRs
+
+	SignatureAlgorithm uint16 // TPM_ALG_ECDSA, TPM_ALG_RSASSA or TPM_ALG_RSAPSS
+	SignatureHash      uint16
+	Signature          []uint8 // ECDSA r and s, or the RSA signature
+
+	AkPublic       []uint8 // TPMT_PUBLIC of the attestation key
+	AttestationKey crypto.PublicKey
+}
+
+type TPMPcrSelection struct {
+	Hash uint16
+	Pcrs []int
+}
+
+// GetTPMQuote returns the TPM quote of the PCRs from the measurement server, with the qualifying data.
+func GetTPMQuote(qualifyingData []byte, pcrBank mpb.PCR_BANK, pcrIndexes ...int32) (TPMQuote, error) {
+
+	channel, err := grpc.Dial(measurement.UDS_PATH, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalf("[GetTPMQuote] can not connect to UDS: %v", err)
+	}
+	defer channel.Close()
+
+	client := mpb.NewMeasurementClient(channel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	response, err := client.GetTpmQuote(ctx, &mpb.GetTpmQuoteRequest{
+		PcrBank:        pcrBank,
+		PcrIndexes:     pcrIndexes,
+		QualifyingData: qualifyingData,
+	})
+	if err != nil {
+		log.Fatalf("[GetTPMQuote] fail to get TPM quote: %v", err)
+	}
+
+	quote, err := base64.StdEncoding.DecodeString(response.Quote)
+	if err != nil {
+		log.Fatalf("[GetTPMQuote] decode quote error: %v", err)
+	}
+
+	return ParseTPMQuote(quote)
+}
+
+/*
+ParseTPMQuote parses the TPM quote and verifies its signature with the attestation key embedded
+in the quote, which must be a restricted signing key. Anyone can create such a key, so the
+embedded attestation key must be verified separately, e.g. with VerifyTPMQuoteWithAK against
+the attestation key pinned by the verifier. The qualifying data and the PCR digest are left to
+the caller to compare with the expected values.
+*/
+func ParseTPMQuote(quote []byte) (TPMQuote, error) {
+	var tpmQuote = TPMQuote{Quote: quote}
+	var err error
+
+	reader := bytes.NewReader(quote)
+	if tpmQuote.Attest, err = readTpm2b(reader); err != nil {
+		return TPMQuote{}, err
+	}
+	if err = parseTPMSignature(reader, &tpmQuote); err != nil {
+		return TPMQuote{}, err
+	}
+	if tpmQuote.AkPublic, err = readTpm2b(reader); err != nil {
+		return TPMQuote{}, err
+	}
+	if reader.Len() != 0 {
+		return TPMQuote{}, InvalidTPMQuoteErr
+	}
+
+	if err = parseTPMAttest(tpmQuote.Attest, &tpmQuote); err != nil {
+		return TPMQuote{}, err
+	}
+	if tpmQuote.AttestationKey, err = parseTPMPublic(tpmQuote.AkPublic); err != nil {
+		return TPMQuote{}, err
+	}
+
+	if err = VerifyTPMQuote(tpmQuote); err != nil {
+		return TPMQuote{}, err
+	}
+	return tpmQuote, nil
+}
+
+/*
+VerifyTPMQuoteWithAK verifies that the quote is signed by the trusted attestation key, given as
+its TPMT_PUBLIC pinned by the verifier, e.g. when the key was enrolled. The qualified signer of
+the quote must be the qualified name of the key as primary key of the endorsement hierarchy, and
+the signature is verified with the trusted key, not with the key embedded in the quote.
+*/
+func VerifyTPMQuoteWithAK(quote TPMQuote, akPublic []byte) error {
+	key, err := parseTPMPublic(akPublic)
+	if err != nil {
+		return err
+	}
+
+	name, err := getTPMName(akPublic)
+	if err != nil {
+		return err
+	}
+	qualifiedName, err := getTPMQualifiedName(TPM_RH_ENDORSEMENT, name)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(quote.QualifiedSigner, qualifiedName) {
+		log.Printf("[VerifyTPMQuoteWithAK] qualified signer %x is not the trusted attestation key %x",
+			quote.QualifiedSigner, qualifiedName)
+		return TPMQuoteSignerErr
+	}
+
+	quote.AttestationKey = key
+	return VerifyTPMQuote(quote)
+}
+
+/* The name of an object: its name algorithm followed by the digest of its TPMT_PUBLIC */
+func getTPMName(public []byte) ([]byte, error) {
+	if len(public) < 4 {
+		return nil, InvalidTPMQuoteErr
+	}
+	nameAlg := binary.BigEndian.Uint16(public[2:4])
+	hash, ok := tpmHashes[nameAlg]
+	if !ok {
+		return nil, InvalidTPMQuoteErr
+	}
+
+	digest := hash.New()
+	digest.Write(public)
+	return digest.Sum(append([]byte{}, public[2:4]...)), nil
+}
+
+/* The qualified name of a primary object: the digest of its hierarchy handle and its name */
+func getTPMQualifiedName(hierarchy uint32, name []byte) ([]byte, error) {
+	hash, ok := tpmHashes[binary.BigEndian.Uint16(name[:2])]
+	if !ok {
+		return nil, InvalidTPMQuoteErr
+	}
+
+	digest := hash.New()
+	binary.Write(digest, binary.BigEndian, hierarchy)
+	digest.Write(name)
+	return digest.Sum(append([]byte{}, name[:2]...)), nil
+}
+
+// VerifyTPMQuote verifies the signature of the TPMS_ATTEST with the attestation key of the quote.
+func VerifyTPMQuote(quote TPMQuote) error {
+	hash, ok := tpmHashes[quote.SignatureHash]
+	if !ok {
+		return InvalidTPMQuoteErr
+	}
+	digest := hash.New()
+	digest.Write(quote.Attest)
+
+	switch key := quote.AttestationKey.(type) {
+	case *ecdsa.PublicKey:
+		if quote.SignatureAlgorithm != TPM_ALG_ECDSA || len(quote.Signature)%2 != 0 {
+			return TPMQuoteSignatureErr
+		}
+		size := len(quote.Signature) / 2
+		r := new(big.Int).SetBytes(quote.Signature[:size])
+		s := new(big.Int).SetBytes(quote.Signature[size:])
+		if !ecdsa.Verify(key, digest.Sum(nil), r, s) {
+			return TPMQuoteSignatureErr
+		}
+	case *rsa.PublicKey:
+		switch quote.SignatureAlgorithm {
+		case TPM_ALG_RSASSA:
+			err := rsa.VerifyPKCS1v15(key, hash, digest.Sum(nil), quote.Signature)
+			if err != nil {
+				return TPMQuoteSignatureErr
+			}
+		case TPM_ALG_RSAPSS:
+			err := rsa.VerifyPSS(key, hash, digest.Sum(nil), quote.Signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+			if err != nil {
+				return TPMQuoteSignatureErr
+			}
+		default:
+			return TPMQuoteSignatureErr
+		}
+	default:
+		return InvalidTPMQuoteErr
+	}
+
+	return nil
+}
+
+/* Parse the TPMS_ATTEST of a quote */
+func parseTPMAttest(attest []byte, quote *TPMQuote) error {
+	var magic, count uint32
+	var attestType uint16
+	var safe uint8
+	var err error
+
+	reader := bytes.NewReader(attest)
+	if err = binary.Read(reader, binary.BigEndian, &magic); err != nil || magic != TPM_GENERATED_VALUE {
+		return InvalidTPMQuoteErr
+	}
+	if err = binary.Read(reader, binary.BigEndian, &attestType); err != nil || attestType != TPM_ST_ATTEST_QUOTE {
+		return InvalidTPMQuoteErr
+	}
+	if quote.QualifiedSigner, err = readTpm2b(reader); err != nil {
+		return err
+	}
+	if quote.ExtraData, err = readTpm2b(reader); err != nil {
+		return err
+	}
+
+	/* TPMS_CLOCK_INFO and the firmware version */
+	for _, field := range []interface{}{&quote.Clock, &quote.ResetCount, &quote.RestartCount, &safe, &quote.FirmwareVersion} {
+		if err = binary.Read(reader, binary.BigEndian, field); err != nil {
+			return InvalidTPMQuoteErr
+		}
+	}
+	quote.Safe = safe != 0
+
+	/* TPMS_QUOTE_INFO */
+	if err = binary.Read(reader, binary.BigEndian, &count); err != nil {
+		return InvalidTPMQuoteErr
+	}
+	quote.PcrSelections = nil
+	for i := uint32(0); i < count; i++ {
+		var selection TPMPcrSelection
+		var size uint8
+		if err = binary.Read(reader, binary.BigEndian, &selection.Hash); err != nil {
+			return InvalidTPMQuoteErr
+		}
+		if err = binary.Read(reader, binary.BigEndian, &size); err != nil {
+			return InvalidTPMQuoteErr
+		}
+		bitmap := make([]byte, size)
+		if _, err = io.ReadFull(reader, bitmap); err != nil {
+			return InvalidTPMQuoteErr
+		}
+		for index := 0; index < int(size)*8; index++ {
+			if bitmap[index/8]&(1<<(index%8)) != 0 {
+				selection.Pcrs = append(selection.Pcrs, index)
+			}
+		}
+		quote.PcrSelections = append(quote.PcrSelections, selection)
+	}
+	if quote.PcrDigest, err = readTpm2b(reader); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* Parse the TPMT_SIGNATURE, ECDSA r and s are kept as r followed by s of the same size */
+func parseTPMSignature(reader *bytes.Reader, quote *TPMQuote) error {
+	if err := binary.Read(reader, binary.BigEndian, &quote.SignatureAlgorithm); err != nil {
+		return InvalidTPMQuoteErr
+	}
+	if err := binary.Read(reader, binary.BigEndian, &quote.SignatureHash); err != nil {
+		return InvalidTPMQuoteErr
+	}
+
+	switch quote.SignatureAlgorithm {
+	case TPM_ALG_ECDSA:
+		r, err := readTpm2b(reader)
+		if err != nil {
+			return err
+		}
+		s, err := readTpm2b(reader)
+		if err != nil {
+			return err
+		}
+		size := len(r)
+		if len(s) > size {
+			size = len(s)
+		}
+		quote.Signature = make([]uint8, 2*size)
+		copy(quote.Signature[size-len(r):size], r)
+		copy(quote.Signature[2*size-len(s):], s)
+	case TPM_ALG_RSASSA, TPM_ALG_RSAPSS:
+		signature, err := readTpm2b(reader)
+		if err != nil {
+			return err
+		}
+		quote.Signature = signature
+	default:
+		return InvalidTPMQuoteErr
+	}
+
+	return nil
+}
+
+/* Parse the TPMT_PUBLIC of a restricted signing key and return its RSA or ECC public key */
+func parseTPMPublic(public []byte) (crypto.PublicKey, error) {
+	var keyType, nameAlg, symmetric, scheme uint16
+	var attributes uint32
+
+	reader := bytes.NewReader(public)
+	for _, field := range []interface{}{&keyType, &nameAlg, &attributes} {
+		if err := binary.Read(reader, binary.BigEndian, field); err != nil {
+			return nil, InvalidTPMQuoteErr
+		}
+	}
+
+	/* a key signing any data could sign a forged TPMS_ATTEST */
+	if attributes&(TPM_OBJECT_RESTRICTED|TPM_OBJECT_SIGN) != TPM_OBJECT_RESTRICTED|TPM_OBJECT_SIGN {
+		log.Printf("[parseTPMPublic] attestation key is not a restricted signing key")
+		return nil, InvalidTPMQuoteErr
+	}
+
+	if _, err := readTpm2b(reader); err != nil {
+		return nil, err
+	}
+
+	/* the symmetric algorithm and the scheme, followed by their details if not TPM_ALG_NULL */
+	if err := readTPMAlgorithm(reader, &symmetric, 2); err != nil {
+		return nil, err
+	}
+	if err := readTPMAlgorithm(reader, &scheme, 1); err != nil {
+		return nil, err
+	}
+
+	switch keyType {
+	case TPM_ALG_RSA:
+		var keyBits uint16
+		var exponent uint32
+		if err := binary.Read(reader, binary.BigEndian, &keyBits); err != nil {
+			return nil, InvalidTPMQuoteErr
+		}
+		if err := binary.Read(reader, binary.BigEndian, &exponent); err != nil {
+			return nil, InvalidTPMQuoteErr
+		}
+		modulus, err := readTpm2b(reader)
+		if err != nil {
+			return nil, err
+		}
+		/* an exponent of 0 stands for the default exponent */
+		if exponent == 0 {
+			exponent = 65537
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(exponent)}, nil
+	case TPM_ALG_ECC:
+		var curveId, kdf uint16
+		if err := binary.Read(reader, binary.BigEndian, &curveId); err != nil {
+			return nil, InvalidTPMQuoteErr
+		}
+		if err := readTPMAlgorithm(reader, &kdf, 1); err != nil {
+			return nil, err
+		}
+		x, err := readTpm2b(reader)
+		if err != nil {
+			return nil, err
+		}
+		y, err := readTpm2b(reader)
+		if err != nil {
+			return nil, err
+		}
+		curve, ok := tpmCurves[curveId]
+		if !ok {
+			return nil, InvalidTPMQuoteErr
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, InvalidTPMQuoteErr
+		}
+		return key, nil
+	default:
+		return nil, InvalidTPMQuoteErr
+	}
+}
+
+/* Read an algorithm, skipping its details of 16 bit values if it is not TPM_ALG_NULL */
+func readTPMAlgorithm(reader *bytes.Reader, algorithm *uint16, details int) error {
+	if err := binary.Read(reader, binary.BigEndian, algorithm); err != nil {
+		return InvalidTPMQuoteErr
+	}
+	if *algorithm != TPM_ALG_NULL {
+		if reader.Len() < 2*details {
+			return InvalidTPMQuoteErr
+		}
+		reader.Seek(int64(2*details), io.SeekCurrent)
+	}
+	return nil
+}
+
+/* Read a TPM2B, a buffer with its 16 bit size */
+func readTpm2b(reader *bytes.Reader) ([]byte, error) {
+	var size uint16
+	if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+		return nil, InvalidTPMQuoteErr
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, InvalidTPMQuoteErr
+	}
+	return data, nil
+}
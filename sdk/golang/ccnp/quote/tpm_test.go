@@ -0,0 +1,169 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package quote
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+)
+
+/*
+Quotes captured from the TPM simulator: the quote of PCR 0 and 7 of the SHA256 bank by the measurement
+server with its ECDSA attestation key, PCR 7 extended once with 32 bytes of 0x01, and the quote of PCR 0
+to 2 of the SHA1 bank by an RSA attestation key created by another tool
+*/
+const (
+	EXPECTED_QUALIFYING_DATA        = "abcdefghabcdefghabcdefghabcdefgh"
+	TPM_QUOTE_ATTEST_ENCODED        = "/1RDR4AYACIAC2Wk7TmgCksEXlYt31IHTBam3b6eGA45i54Q5RH/qyeRACBhYmNkZWZnaGFiY2RlZmdoYWJjZGVmZ2hhYmNkZWZnaAAAAAAAAAAFAAAAAQAAAAABIBcGGQAWNjYAAAABAAsDgQAAACASmouFW0ySp5nu1Ck3GiJi6I3EV272/IY546HDlTYyOQ=="
+	TPM_QUOTE_SIGNATURE_ENCODED     = "ABgACwAg6cebZ/mPnKyh7TnN/PAEAoeRsiLsmZxcY5kuMx11NNUAIBG0CuGrQqav6cQ7J2WDZV5PNYek+t2RpwuDeqwZipB6"
+	TPM_AK_PUBLIC_ENCODED           = "ACMACwAFAHIAAAAQABgACwADABAAIFpJSQeonGgolFrDxBL5bcX/BE+sYGVq2rzoKl+c8aBEACDBBvwSoi4sTNExF3SKMfNhUuTfYfIjDh+zjU3NjVJUbg=="
+	EXPECTED_PCR_DIGEST_ENCODED     = "EpqLhVtMkqeZ7tQpNxoiYuiNxFdu9vyGOeOhw5U2Mjk="
+	TPM_RSA_QUOTE_ATTEST_ENCODED    = "/1RDR4AYACIAC35a5n/x50d9i55n/B8Az9tU6jdrgax2ZRoinhxgEHQsACBhYmNkZWZnaGFiY2RlZmdoYWJjZGVmZ2hhYmNkZWZnaAAAAAAAAAAxAAAAAQAAAAABIBcGGQAWNjYAAAABAAQDBwAAACBdzBtYct2f8cI0UB8f79oB9mQWThWDw+G7Pb6kdYirMQ=="
+	TPM_RSA_QUOTE_SIGNATURE_ENCODED = "ABQACwEAeHTZvub7l/d/5gixz1IRqAjxzmHAqQLdfziGTQBNwpF8P7QNjHPSQ8fI9Pr0O7fAuNpTPdJsk8lAManQpRFE43OLqixTfBcNPgZTaQVyIJwTASKKIwsjWcwiMn2hiKsESgYP8rnyt17Yu9wlFp9Q+JrWBcg1eYxn2ZxyI+nS3EbvHNz4B6+rG0DWu3SKOeoEXR0O6EnrMCmJSTynWWxX8swd0U7bEeiqw/2kdBacWSlhLnIkzkJLThXpwrtXr7TvUvzjJ3etxkln8QB5dVHeDkYv4QlVfW0xTOtfbwxoEV42RtaRcIDdfc2gES9JV7QOE0aZFg9fXBk7nNdurzrkWQ=="
+	TPM_RSA_AK_PUBLIC_ENCODED       = "AAEACwAFAHIAAAAQABQACwgAAAAAAAEAjqOuQAVx/2G+WFhJITlHFOl23q6kX+qGREnNj/gkcSB7mGIQeuMhJ1Qc88FdObxJDcJSif/lZ/1LoTtB3odkFhyXI95nXh4ijFU82zuKmsPUiguOnyI6A9q6bKXTXlHl0qXP321a45WD+ZIJV3FePNU7hdBGK29nIqwCuxqWSySCiAwnePraRluGdnuSCwq381wCiGevg/5BlYlGdXeTJDsV4Q8jBMEyZmpjo0zQxQ5YqxDFmndCB+f3A8jb55HSA3sgK07Hstk6whmTS5mon33ihl+9kdgkA1w3BFBN2jDXL9VCzFDNOpSvf9sqaNkeELHB6tqh3Y7PEQ9+mopFxQ=="
+)
+
+func decodeBase64(t *testing.T, encoded string) []byte {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("[decodeBase64] decode error: %v", err)
+	}
+	return decoded
+}
+
+func marshalTPMQuote(attest []byte, signature []byte, public []byte) []byte {
+	quote := make([]byte, 2, 2+len(attest)+len(signature)+2+len(public))
+	binary.BigEndian.PutUint16(quote, uint16(len(attest)))
+	quote = append(quote, attest...)
+	quote = append(quote, signature...)
+	quote = binary.BigEndian.AppendUint16(quote, uint16(len(public)))
+	return append(quote, public...)
+}
+
+/* Return the TPMS_ATTEST, the TPMT_SIGNATURE and the TPMT_PUBLIC of the quote of the measurement server */
+func getTPMQuote(t *testing.T) ([]byte, []byte, []byte) {
+	return decodeBase64(t, TPM_QUOTE_ATTEST_ENCODED), decodeBase64(t, TPM_QUOTE_SIGNATURE_ENCODED),
+		decodeBase64(t, TPM_AK_PUBLIC_ENCODED)
+}
+
+/* Quote of the measurement server with its ECDSA attestation key */
+func TestParseTPMQuote(t *testing.T) {
+	attest, signature, akPublic := getTPMQuote(t)
+
+	ret, err := parseTPMQuote(marshalTPMQuote(attest, signature, akPublic))
+	if err != nil {
+		t.Fatalf("[TestParseTPMQuote] parse TPM quote error: %v", err)
+	}
+
+	tpmQuote, ok := ret.(TPMQuote)
+	if !ok {
+		t.Fatalf("[TestParseTPMQuote] wrong TPM quote type, retrieved: %T", ret)
+	}
+	if _, ok := tpmQuote.AttestationKey.(*ecdsa.PublicKey); !ok || tpmQuote.SignatureAlgorithm != TPM_ALG_ECDSA {
+		t.Fatalf("[TestParseTPMQuote] wrong attestation key, retrieved: %T", tpmQuote.AttestationKey)
+	}
+	if string(tpmQuote.ExtraData) != EXPECTED_QUALIFYING_DATA || len(tpmQuote.PcrSelections) != 1 ||
+		tpmQuote.PcrSelections[0].Hash != TPM_ALG_SHA256 || len(tpmQuote.PcrSelections[0].Pcrs) != 2 ||
+		tpmQuote.PcrSelections[0].Pcrs[0] != 0 || tpmQuote.PcrSelections[0].Pcrs[1] != 7 {
+		t.Fatalf("[TestParseTPMQuote] wrong quote info, retrieved: %+v", tpmQuote)
+	}
+
+	digest := decodeBase64(t, EXPECTED_PCR_DIGEST_ENCODED)
+	if !bytes.Equal(tpmQuote.PcrDigest, digest) {
+		t.Fatalf("[TestParseTPMQuote] wrong PCR digest, retrieved: %x, expected: %x", tpmQuote.PcrDigest, digest)
+	}
+}
+
+/* Quote of an RSA attestation key created by another tool */
+func TestParseTPMQuoteRSA(t *testing.T) {
+	attest := decodeBase64(t, TPM_RSA_QUOTE_ATTEST_ENCODED)
+	signature := decodeBase64(t, TPM_RSA_QUOTE_SIGNATURE_ENCODED)
+	publicArea := decodeBase64(t, TPM_RSA_AK_PUBLIC_ENCODED)
+
+	tpmQuote, err := ParseTPMQuote(marshalTPMQuote(attest, signature, publicArea))
+	if err != nil {
+		t.Fatalf("[TestParseTPMQuoteRSA] parse TPM quote error: %v", err)
+	}
+	if _, ok := tpmQuote.AttestationKey.(*rsa.PublicKey); !ok || tpmQuote.SignatureAlgorithm != TPM_ALG_RSASSA ||
+		tpmQuote.PcrSelections[0].Hash != TPM_ALG_SHA1 || len(tpmQuote.PcrSelections[0].Pcrs) != 3 {
+		t.Fatalf("[TestParseTPMQuoteRSA] wrong TPM quote, retrieved: %+v", tpmQuote)
+	}
+}
+
+func TestParseTPMQuoteInvalid(t *testing.T) {
+	attest, signature, akPublic := getTPMQuote(t)
+	quote := marshalTPMQuote(attest, signature, akPublic)
+
+	/* the qualifying data is the last byte of extraData, after the magic, the type and qualifiedSigner */
+	forged := append([]byte{}, attest...)
+	signerLen := int(binary.BigEndian.Uint16(forged[6:8]))
+	forged[8+signerLen+2] ^= 0xff
+
+	/* an unrestricted signing key could sign any TPMS_ATTEST */
+	unrestricted := append([]byte{}, akPublic...)
+	binary.BigEndian.PutUint32(unrestricted[4:], binary.BigEndian.Uint32(unrestricted[4:])&^TPM_OBJECT_RESTRICTED)
+
+	notQuote := append([]byte{}, attest...)
+	binary.BigEndian.PutUint16(notQuote[4:], 0x8017)
+
+	tests := []struct {
+		name  string
+		quote []byte
+		err   error
+	}{
+		{"Forged qualifying data", marshalTPMQuote(forged, signature, akPublic), TPMQuoteSignatureErr},
+		{"Unrestricted attestation key", marshalTPMQuote(attest, signature, unrestricted), InvalidTPMQuoteErr},
+		{"Not a quote", marshalTPMQuote(notQuote, signature, akPublic), InvalidTPMQuoteErr},
+		{"Truncated quote", quote[:len(attest)], InvalidTPMQuoteErr},
+		{"Trailing data", append(quote, 0), InvalidTPMQuoteErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTPMQuote(tt.quote); err != tt.err {
+				t.Errorf("[TestParseTPMQuoteInvalid] error: expected %v, retrieved %v", tt.err, err)
+			}
+		})
+	}
+}
+
+/* The quote is only trusted if signed by the attestation key pinned by the verifier */
+func TestVerifyTPMQuoteWithAK(t *testing.T) {
+	attest, signature, akPublic := getTPMQuote(t)
+	tpmQuote, err := ParseTPMQuote(marshalTPMQuote(attest, signature, akPublic))
+	if err != nil {
+		t.Fatalf("[TestVerifyTPMQuoteWithAK] parse TPM quote error: %v", err)
+	}
+	otherAk := decodeBase64(t, TPM_RSA_AK_PUBLIC_ENCODED)
+
+	forged := tpmQuote
+	forged.Attest = append([]byte{}, tpmQuote.Attest...)
+	forged.Attest[len(forged.Attest)-1] ^= 0xff
+
+	tests := []struct {
+		name     string
+		quote    TPMQuote
+		akPublic []byte
+		err      error
+	}{
+		{"Trusted attestation key", tpmQuote, akPublic, nil},
+		{"Other attestation key", tpmQuote, otherAk, TPMQuoteSignerErr},
+		{"Forged quote", forged, akPublic, TPMQuoteSignatureErr},
+		{"Invalid attestation key", tpmQuote, akPublic[:3], InvalidTPMQuoteErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyTPMQuoteWithAK(tt.quote, tt.akPublic); err != tt.err {
+				t.Errorf("[TestVerifyTPMQuoteWithAK] error: expected %v, retrieved %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	resources "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+	pkgerrors "github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SOCKET_LOCATION = "/run/ccnp/uds/eventlog.sock"
+	// Container events are only recorded on the socket of the container runtime, in a directory no workload
+	// mounts, by the user given with container-runtime-uid
+	CONTAINER_RUNTIME_SOCKET_LOCATION = "/run/ccnp/runtime/eventlog-container-runtime.sock"
+	// The measurement server extends RTMR 3 with the anchors of the container events on its extend socket
+	MEASUREMENT_SOCKET_LOCATION = "/run/ccnp/runtime/measurement-extend.sock"
+	RUNTIME_EVENT_LOG_DIR       = "/run/ccnp-eventlog/"
+
+	// The configuration file is given with the -config flag or the environment variable
+	CONFIG_FILE_ENV = "CCNP_EVENTLOG_CONFIG"
+	// Every setting can be given with the environment variable CCNP_EVENTLOG_<FLAG NAME>
+	ENV_PREFIX = "CCNP_EVENTLOG_"
+)
+
+var (
+	InvalidConfigErr = pkgerrors.New("Invalid configuration")
+)
+
+/*
+Config holds the settings of the eventlog server. The settings are read from the
+defaults, the optional YAML file, the environment variables and the flags, a later
+source overriding an earlier one.
+*/
+type Config struct {
+	Socket                string `yaml:"socket"`
+	EventlogDir           string `yaml:"eventlog_dir"`
+	LegacyEventlogFormat  bool   `yaml:"legacy_eventlog_format"`
+	ContainerEventlogFile string `yaml:"container_eventlog_file"`
+	PolicyFile            string `yaml:"policy_file"`
+
+	ContainerRuntimeSocket string `yaml:"container_runtime_socket"`
+	ContainerRuntimeUid    string `yaml:"container_runtime_uid"`
+	MeasurementSocket      string `yaml:"measurement_socket"`
+
+	CcelTable              string `yaml:"ccel_table"`
+	CcelData               string `yaml:"ccel_data"`
+	CcelTableMount         string `yaml:"ccel_table_mount"`
+	CcelDataMount          string `yaml:"ccel_data_mount"`
+	TpmEventlog            string `yaml:"tpm_eventlog"`
+	ImaBinaryEventlog      string `yaml:"ima_binary_eventlog"`
+	ImaAsciiEventlog       string `yaml:"ima_ascii_eventlog"`
+	ImaBinaryEventlogMount string `yaml:"ima_binary_eventlog_mount"`
+	ImaAsciiEventlogMount  string `yaml:"ima_ascii_eventlog_mount"`
+	RuntimeEventlog        string `yaml:"runtime_eventlog"`
+}
+
+type option struct {
+	// the flag name, the YAML key and the environment variable use the same name
+	name    string
+	usage   string
+	str     *string
+	boolean *bool
+}
+
+func Default() Config {
+	locations := resources.DefaultLocations()
+	return Config{
+		Socket:                 SOCKET_LOCATION,
+		EventlogDir:            RUNTIME_EVENT_LOG_DIR,
+		ContainerRuntimeSocket: CONTAINER_RUNTIME_SOCKET_LOCATION,
+		MeasurementSocket:      MEASUREMENT_SOCKET_LOCATION,
+		CcelTable:              locations.CcelTable,
+		CcelData:               locations.CcelData,
+		CcelTableMount:         locations.CcelTableMount,
+		CcelDataMount:          locations.CcelDataMount,
+		TpmEventlog:            locations.TpmEventlog,
+		ImaBinaryEventlog:      locations.ImaBinaryEventlog,
+		ImaAsciiEventlog:       locations.ImaAsciiEventlog,
+		ImaBinaryEventlogMount: locations.ImaBinaryEventlogMount,
+		ImaAsciiEventlogMount:  locations.ImaAsciiEventlogMount,
+		RuntimeEventlog:        locations.RuntimeEventlog,
+	}
+}
+
+func (c *Config) options() []option {
+	return []option{
+		{name: "socket", usage: "UDS path the server listens on", str: &c.Socket},
+		{name: "eventlog-dir", usage: "directory of the event log files written for the requests", str: &c.EventlogDir},
+		{name: "legacy-eventlog-format", usage: "write event logs in the format before schema version " +
+			resources.EVENTLOG_SCHEMA_VERSION + ", deprecated", boolean: &c.LegacyEventlogFormat},
+		{name: "container-eventlog-file", usage: "file to keep the container event log across restarts, " +
+			"the log is only kept in memory if empty", str: &c.ContainerEventlogFile},
+		{name: "container-runtime-socket", usage: "UDS path the container runtime records container events on, " +
+			"no events are recorded if empty", str: &c.ContainerRuntimeSocket},
+		{name: "container-runtime-uid", usage: "user id of the container runtime, the only peer allowed to record " +
+			"container events, no events are recorded if empty", str: &c.ContainerRuntimeUid},
+		{name: "measurement-socket", usage: "UDS extend socket of the measurement server extending RTMR 3 with the " +
+			"container events", str: &c.MeasurementSocket},
+		{name: "policy-file", usage: "reference value policy in JSON or YAML evaluated if a request " +
+			"carries no policy", str: &c.PolicyFile},
+		{name: "ccel-table", usage: "CCEL ACPI table", str: &c.CcelTable},
+		{name: "ccel-data", usage: "CCEL event log data", str: &c.CcelData},
+		{name: "ccel-table-mount", usage: "mounted CCEL ACPI table, read first", str: &c.CcelTableMount},
+		{name: "ccel-data-mount", usage: "mounted CCEL event log data, read first", str: &c.CcelDataMount},
+		{name: "tpm-eventlog", usage: "TPM event log", str: &c.TpmEventlog},
+		{name: "ima-binary-eventlog", usage: "IMA binary runtime measurement list", str: &c.ImaBinaryEventlog},
+		{name: "ima-ascii-eventlog", usage: "IMA ASCII runtime measurement list", str: &c.ImaAsciiEventlog},
+		{name: "ima-binary-eventlog-mount", usage: "mounted IMA binary runtime measurement list, read first",
+			str: &c.ImaBinaryEventlogMount},
+		{name: "ima-ascii-eventlog-mount", usage: "mounted IMA ASCII runtime measurement list, read first",
+			str: &c.ImaAsciiEventlogMount},
+		{name: "runtime-eventlog", usage: "runtime event log of the RTMR extends written by the measurement server, " +
+			"appended to the TDX event log", str: &c.RuntimeEventlog},
+	}
+}
+
+func getEnvName(name string) string {
+	return ENV_PREFIX + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+func (o option) set(value string) error {
+	if o.boolean == nil {
+		*o.str = value
+		return nil
+	}
+
+	boolean, err := strconv.ParseBool(value)
+	if err != nil {
+		return pkgerrors.Wrapf(InvalidConfigErr, "%s is not a boolean: %q", o.name, value)
+	}
+	*o.boolean = boolean
+	return nil
+}
+
+// Load reads the configuration, args are the command line arguments without the program name.
+func Load(name string, args []string) (Config, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv(CONFIG_FILE_ENV),
+		"optional YAML configuration file, also given with "+CONFIG_FILE_ENV)
+
+	/* the flags are parsed first to find the configuration file, and applied last */
+	defaults := Default()
+	for _, o := range defaults.options() {
+		if o.boolean != nil {
+			flags.BoolVar(o.boolean, o.name, *o.boolean, o.usage+", also given with "+getEnvName(o.name))
+		} else {
+			flags.StringVar(o.str, o.name, *o.str, o.usage+", also given with "+getEnvName(o.name))
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+	if flags.NArg() > 0 {
+		return Config{}, pkgerrors.Wrapf(InvalidConfigErr, "unexpected arguments %v", flags.Args())
+	}
+
+	config := Default()
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return Config{}, err
+		}
+
+		/* unknown keys are rejected, a misspelt path would otherwise be silently ignored */
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&config); err != nil && err != io.EOF {
+			return Config{}, pkgerrors.Wrapf(InvalidConfigErr, "%s: %v", *configFile, err)
+		}
+	}
+
+	for _, o := range config.options() {
+		if value, ok := os.LookupEnv(getEnvName(o.name)); ok {
+			if err := o.set(value); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+
+	var err error
+	options := config.options()
+	flags.Visit(func(f *flag.Flag) {
+		for _, o := range options {
+			if o.name == f.Name && err == nil {
+				err = o.set(f.Value.String())
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	if config.Socket == "" || config.EventlogDir == "" {
+		return Config{}, pkgerrors.Wrap(InvalidConfigErr, "socket and eventlog-dir must not be empty")
+	}
+	/* the user of the container runtime is never assumed, it has no default */
+	if _, err := strconv.ParseUint(config.ContainerRuntimeUid, 10, 32); config.ContainerRuntimeUid != "" && err != nil {
+		return Config{}, pkgerrors.Wrapf(InvalidConfigErr, "container-runtime-uid is not a user id: %q", config.ContainerRuntimeUid)
+	}
+	return config, nil
+}
+
+// RecordsContainerEvents returns whether the container runtime socket is served.
+func (c Config) RecordsContainerEvents() bool {
+	return c.ContainerRuntimeSocket != "" && c.ContainerRuntimeUid != ""
+}
+
+// ContainerRuntimePeerUid returns the user id of the container runtime, validated on load.
+func (c Config) ContainerRuntimePeerUid() uint32 {
+	uid, _ := strconv.ParseUint(c.ContainerRuntimeUid, 10, 32)
+	return uint32(uid)
+}
+
+func (c Config) Locations() resources.Locations {
+	return resources.Locations{
+		CcelTable:              c.CcelTable,
+		CcelData:               c.CcelData,
+		CcelTableMount:         c.CcelTableMount,
+		CcelDataMount:          c.CcelDataMount,
+		TpmEventlog:            c.TpmEventlog,
+		ImaBinaryEventlog:      c.ImaBinaryEventlog,
+		ImaAsciiEventlog:       c.ImaAsciiEventlog,
+		ImaBinaryEventlogMount: c.ImaBinaryEventlogMount,
+		ImaAsciiEventlogMount:  c.ImaAsciiEventlogMount,
+		RuntimeEventlog:        c.RuntimeEventlog,
+	}
+}
@@ -0,0 +1,139 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func writeConfigFile(t *testing.T, data string) string {
+	location := filepath.Join(t.TempDir(), "eventlog-server.yaml")
+	if err := os.WriteFile(location, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return location
+}
+
+func TestLoad(t *testing.T) {
+	configFile := writeConfigFile(t, "socket: /tmp/yaml.sock\ntpm_eventlog: /tmp/yaml_tpm\nlegacy_eventlog_format: true\n")
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want func(*Config)
+	}{
+		{"Defaults", nil, nil, func(c *Config) {}},
+		{"Flags", []string{"-socket", "/tmp/flag.sock", "-ccel-table", "/tmp/CCEL", "-legacy-eventlog-format"}, nil,
+			func(c *Config) { c.Socket, c.CcelTable, c.LegacyEventlogFormat = "/tmp/flag.sock", "/tmp/CCEL", true }},
+		{"Environment", nil, map[string]string{"CCNP_EVENTLOG_IMA_ASCII_EVENTLOG": "/tmp/ascii", "CCNP_EVENTLOG_CCEL_DATA_MOUNT": "",
+			"CCNP_EVENTLOG_POLICY_FILE": "/tmp/policy.yaml"},
+			func(c *Config) {
+				c.ImaAsciiEventlog, c.CcelDataMount, c.PolicyFile = "/tmp/ascii", "", "/tmp/policy.yaml"
+			}},
+		{"Runtime eventlog", []string{"-runtime-eventlog", "/tmp/runtime_eventlog"}, nil,
+			func(c *Config) { c.RuntimeEventlog = "/tmp/runtime_eventlog" }},
+		{"Container runtime uid", nil, map[string]string{"CCNP_EVENTLOG_CONTAINER_RUNTIME_UID": "0"},
+			func(c *Config) { c.ContainerRuntimeUid = "0" }},
+		{"Config file", []string{"-config", configFile}, nil,
+			func(c *Config) {
+				c.Socket, c.TpmEventlog, c.LegacyEventlogFormat = "/tmp/yaml.sock", "/tmp/yaml_tpm", true
+			}},
+		{"Config file from environment", nil, map[string]string{CONFIG_FILE_ENV: configFile},
+			func(c *Config) {
+				c.Socket, c.TpmEventlog, c.LegacyEventlogFormat = "/tmp/yaml.sock", "/tmp/yaml_tpm", true
+			}},
+		{"Environment overrides config file", []string{"-config", configFile},
+			map[string]string{"CCNP_EVENTLOG_SOCKET": "/tmp/env.sock", "CCNP_EVENTLOG_LEGACY_EVENTLOG_FORMAT": "false"},
+			func(c *Config) { c.Socket, c.TpmEventlog = "/tmp/env.sock", "/tmp/yaml_tpm" }},
+		{"Flags override environment", []string{"-config", configFile, "-socket=/tmp/flag.sock"},
+			map[string]string{"CCNP_EVENTLOG_SOCKET": "/tmp/env.sock"},
+			func(c *Config) {
+				c.Socket, c.TpmEventlog, c.LegacyEventlogFormat = "/tmp/flag.sock", "/tmp/yaml_tpm", true
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			got, err := Load("eventlog-server", tt.args)
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+			want := Default()
+			tt.want(&want)
+			if got != want {
+				t.Errorf("Config -> Want: %+v, Got: %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestLoadInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{"Unknown key", []string{"-config", writeConfigFile(t, "ccel_tabel: /tmp/CCEL\n")}, nil},
+		{"Invalid YAML", []string{"-config", writeConfigFile(t, "socket: [\n")}, nil},
+		{"Invalid boolean", nil, map[string]string{"CCNP_EVENTLOG_LEGACY_EVENTLOG_FORMAT": "maybe"}},
+		{"Empty socket", []string{"-socket="}, nil},
+		{"Invalid container runtime uid", []string{"-container-runtime-uid=root"}, nil},
+		{"Unexpected argument", []string{"/tmp/eventlog.sock"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			if _, err := Load("eventlog-server", tt.args); pkgerrors.Cause(err) != InvalidConfigErr {
+				t.Errorf("Err -> Want: %v, Got: %v", InvalidConfigErr, err)
+			}
+		})
+	}
+
+	if _, err := Load("eventlog-server", []string{"-config", "/nonexistent/eventlog-server.yaml"}); !os.IsNotExist(err) {
+		t.Errorf("Err -> Want: file not found, Got: %v", err)
+	}
+}
+
+/* Container events are only recorded once the user of the container runtime is given */
+func TestRecordsContainerEvents(t *testing.T) {
+	if Default().RecordsContainerEvents() {
+		t.Errorf("RecordsContainerEvents() -> Want: false without container runtime uid, Got: true")
+	}
+
+	cfg, err := Load("eventlog-server", []string{"-container-runtime-uid", "1001"})
+	if err != nil || !cfg.RecordsContainerEvents() || cfg.ContainerRuntimePeerUid() != 1001 {
+		t.Errorf("ContainerRuntimePeerUid() -> Want: 1001, Got: %d, %v", cfg.ContainerRuntimePeerUid(), err)
+	}
+
+	cfg, err = Load("eventlog-server", []string{"-container-runtime-uid", "1001", "-container-runtime-socket="})
+	if err != nil || cfg.RecordsContainerEvents() {
+		t.Errorf("RecordsContainerEvents() -> Want: false without container runtime socket, Got: %v, %v",
+			cfg.RecordsContainerEvents(), err)
+	}
+}
+
+func TestConfigLocations(t *testing.T) {
+	config := Default()
+	config.TpmEventlog = "/tmp/tpm"
+	locations := config.Locations()
+	if locations.TpmEventlog != "/tmp/tpm" || locations.CcelTable != config.CcelTable ||
+		locations.ImaAsciiEventlogMount != config.ImaAsciiEventlogMount {
+		t.Errorf("Locations -> Want: %+v, Got: %+v", config, locations)
+	}
+}
@@ -4,14 +4,19 @@ go 1.20
 
 require (
 	github.com/golang/protobuf v1.5.3
+	github.com/intel/confidential-cloud-native-primitives/service/measurement-server v0.0.0
 	github.com/pkg/errors v0.9.1
 	google.golang.org/grpc v1.56.3
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	golang.org/x/net v0.17.0 // indirect
-	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/text v0.13.0 // indirect
 	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
-	google.golang.org/protobuf v1.30.0 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
 )
+
+// The eventlog server is a client of the measurement server, both are built from this repository
+replace github.com/intel/confidential-cloud-native-primitives/service/measurement-server => ../measurement-server
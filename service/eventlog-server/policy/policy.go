@@ -0,0 +1,395 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package policy
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	resources "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+	pkgerrors "github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RULE_DIGEST         = "digest"
+	RULE_KERNEL_CMDLINE = "kernel_cmdline"
+	RULE_UEFI_AUTHORITY = "uefi_authority"
+
+	// grub measures the kernel command line as EV_IPL event with this prefix
+	KERNEL_CMDLINE_PREFIX = "kernel_cmdline: "
+	// The event index of a violation not caused by a specific event, e.g. a missing event
+	NO_EVENT_INDEX = -1
+)
+
+var (
+	InvalidPolicyErr = pkgerrors.New("Invalid reference value policy")
+)
+
+/*
+Policy holds the reference values the TDX or TPM event log is evaluated against. Every
+event matching a digest reference must carry one of its digests, every measured kernel
+command line must be allowed and every measured UEFI authority must match one of the
+allowed authorities. An empty list disables the corresponding check.
+*/
+type Policy struct {
+	Digests         []DigestReference    `json:"digests,omitempty" yaml:"digests"`
+	KernelCmdlines  []string             `json:"kernel_cmdlines,omitempty" yaml:"kernel_cmdlines"`
+	UefiAuthorities []AuthorityReference `json:"uefi_authorities,omitempty" yaml:"uefi_authorities"`
+}
+
+/* The expected digests of the events of a type measured into a register */
+type DigestReference struct {
+	RegisterIndex uint32 `json:"register_index" yaml:"register_index"`
+	// TCG name of the event type, e.g. EV_EFI_BOOT_SERVICES_APPLICATION, or its number
+	EventType string `json:"event_type" yaml:"event_type"`
+	// TCG name of the algorithm, e.g. SHA384, any digest of the event is compared if empty
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm"`
+	// hex encoded digests, an event matches one of them
+	Digests []string `json:"digests" yaml:"digests"`
+}
+
+/* An allowed UEFI authority, every given field has to match */
+type AuthorityReference struct {
+	// subject of the certificate, e.g. CN=Database Key,O=Example
+	Subject string `json:"subject,omitempty" yaml:"subject"`
+	// hex encoded SHA256 fingerprint of the certificate, or the hash of a hash authority
+	Sha256 string `json:"sha256,omitempty" yaml:"sha256"`
+}
+
+type Violation struct {
+	// position of the event in the event log, NO_EVENT_INDEX if no event was measured
+	EventIndex    int    `json:"event_index"`
+	RegisterIndex uint32 `json:"register_index"`
+	EventType     string `json:"event_type,omitempty"`
+	Measured      string `json:"measured,omitempty"`
+	Reason        string `json:"reason"`
+}
+
+/* The result of a digest reference, the kernel command lines or the UEFI authorities */
+type CheckResult struct {
+	Rule       string      `json:"rule"`
+	Reference  string      `json:"reference"`
+	Passed     bool        `json:"passed"`
+	EventCount int         `json:"event_count"`
+	Violations []Violation `json:"violations"`
+}
+
+type Result struct {
+	Passed bool          `json:"passed"`
+	Checks []CheckResult `json:"checks"`
+}
+
+type digestRule struct {
+	reference   DigestReference
+	eventType   uint32
+	algorithmId uint16
+	digests     map[string]bool
+}
+
+// Load parses a policy in JSON or YAML, unknown keys are rejected.
+func Load(data []byte) (Policy, error) {
+	var policy Policy
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&policy); err != nil {
+			return Policy{}, pkgerrors.Wrap(InvalidPolicyErr, err.Error())
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(trimmed))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&policy); err != nil && err != io.EOF {
+			return Policy{}, pkgerrors.Wrap(InvalidPolicyErr, err.Error())
+		}
+	}
+
+	if _, err := policy.getDigestRules(); err != nil {
+		return Policy{}, err
+	}
+	return policy, nil
+}
+
+// LoadFile reads a policy in JSON or YAML from the file.
+func LoadFile(location string) (Policy, error) {
+	data, err := os.ReadFile(location)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	policy, err := Load(data)
+	if err != nil {
+		return Policy{}, pkgerrors.Wrap(err, location)
+	}
+	return policy, nil
+}
+
+func (p Policy) getDigestRules() ([]digestRule, error) {
+	var rules []digestRule
+
+	for i, reference := range p.Digests {
+		rule := digestRule{reference: reference, digests: map[string]bool{}}
+
+		eventType, ok := resources.GetEventTypeByName(reference.EventType)
+		if !ok {
+			value, err := strconv.ParseUint(reference.EventType, 0, 32)
+			if err != nil {
+				return nil, pkgerrors.Wrapf(InvalidPolicyErr, "digest reference %d: unknown event type %q", i, reference.EventType)
+			}
+			eventType = uint32(value)
+		}
+		rule.eventType = eventType
+
+		if reference.Algorithm != "" {
+			if rule.algorithmId, ok = resources.GetAlgorithmIdByName(reference.Algorithm); !ok {
+				return nil, pkgerrors.Wrapf(InvalidPolicyErr, "digest reference %d: unknown algorithm %q", i, reference.Algorithm)
+			}
+		}
+
+		if len(reference.Digests) == 0 {
+			return nil, pkgerrors.Wrapf(InvalidPolicyErr, "digest reference %d: no digest", i)
+		}
+		for _, digest := range reference.Digests {
+			value, err := hex.DecodeString(digest)
+			if err != nil || len(value) == 0 {
+				return nil, pkgerrors.Wrapf(InvalidPolicyErr, "digest reference %d: invalid digest %q", i, digest)
+			}
+			rule.digests[hex.EncodeToString(value)] = true
+		}
+
+		rules = append(rules, rule)
+	}
+
+	for i, authority := range p.UefiAuthorities {
+		if authority.Subject == "" && authority.Sha256 == "" {
+			return nil, pkgerrors.Wrapf(InvalidPolicyErr, "UEFI authority %d: no subject or SHA256", i)
+		}
+	}
+
+	return rules, nil
+}
+
+// Evaluate checks the TDX or TPM event logs against the policy, the result explains every
+// violation. An error is only returned for an invalid policy.
+func Evaluate(policy Policy, eventlogs []resources.TDEventLog) (Result, error) {
+	rules, err := policy.getDigestRules()
+	if err != nil {
+		return Result{}, err
+	}
+
+	var checks []CheckResult
+	for _, rule := range rules {
+		checks = append(checks, evaluateDigestRule(rule, eventlogs))
+	}
+	if len(policy.KernelCmdlines) > 0 {
+		checks = append(checks, evaluateKernelCmdlines(policy.KernelCmdlines, eventlogs))
+	}
+	if len(policy.UefiAuthorities) > 0 {
+		checks = append(checks, evaluateUefiAuthorities(policy.UefiAuthorities, eventlogs))
+	}
+
+	result := Result{Passed: true, Checks: []CheckResult{}}
+	for _, check := range checks {
+		check.Passed = len(check.Violations) == 0
+		if check.Violations == nil {
+			check.Violations = []Violation{}
+		}
+		result.Passed = result.Passed && check.Passed
+		result.Checks = append(result.Checks, check)
+	}
+	return result, nil
+}
+
+func newViolation(index int, eventlog resources.TDEventLog, measured string, reason string) Violation {
+	return Violation{
+		EventIndex:    index,
+		RegisterIndex: eventlog.Rtmr,
+		EventType:     resources.GetEventTypeName(eventlog.Etype),
+		Measured:      measured,
+		Reason:        reason,
+	}
+}
+
+func evaluateDigestRule(rule digestRule, eventlogs []resources.TDEventLog) CheckResult {
+	check := CheckResult{
+		Rule:      RULE_DIGEST,
+		Reference: fmt.Sprintf("register %d %s", rule.reference.RegisterIndex, resources.GetEventTypeName(rule.eventType)),
+	}
+
+	for i, eventlog := range eventlogs {
+		if eventlog.Rtmr != rule.reference.RegisterIndex || eventlog.Etype != rule.eventType {
+			continue
+		}
+		check.EventCount++
+
+		var measured []string
+		matched := false
+		for _, digest := range eventlog.Digests {
+			if rule.algorithmId != 0 && digest.AlgorithmId != rule.algorithmId {
+				continue
+			}
+			value := hex.EncodeToString(digest.Digest)
+			measured = append(measured, resources.GetAlgorithmName(digest.AlgorithmId)+":"+value)
+			matched = matched || rule.digests[value]
+		}
+
+		if len(measured) == 0 {
+			check.Violations = append(check.Violations, newViolation(i, eventlog, "",
+				fmt.Sprintf("No %s digest measured", rule.reference.Algorithm)))
+		} else if !matched {
+			check.Violations = append(check.Violations, newViolation(i, eventlog, strings.Join(measured, " "),
+				"Digest not in the reference values"))
+		}
+	}
+
+	if check.EventCount == 0 {
+		check.Violations = append(check.Violations, Violation{
+			EventIndex:    NO_EVENT_INDEX,
+			RegisterIndex: rule.reference.RegisterIndex,
+			EventType:     resources.GetEventTypeName(rule.eventType),
+			Reason:        "No event measured",
+		})
+	}
+	return check
+}
+
+func evaluateKernelCmdlines(allowed []string, eventlogs []resources.TDEventLog) CheckResult {
+	check := CheckResult{Rule: RULE_KERNEL_CMDLINE, Reference: "kernel command line"}
+
+	for i, eventlog := range eventlogs {
+		if eventlog.Etype != resources.EVENT_TYPE_EV_IPL {
+			continue
+		}
+		decoded, err := resources.DecodeEvent(eventlog.Etype, eventlog.Event)
+		if err != nil {
+			continue
+		}
+		cmdline := decoded.(resources.StringEvent).String
+		if !strings.HasPrefix(cmdline, KERNEL_CMDLINE_PREFIX) {
+			continue
+		}
+		cmdline = strings.TrimPrefix(cmdline, KERNEL_CMDLINE_PREFIX)
+		check.EventCount++
+
+		/* grub measures the command line without the prefix */
+		if reason := verifyEventDigests(eventlog, []byte(cmdline)); reason != "" {
+			check.Violations = append(check.Violations, newViolation(i, eventlog, cmdline, reason))
+		} else if !containsString(allowed, cmdline) {
+			check.Violations = append(check.Violations, newViolation(i, eventlog, cmdline,
+				"Kernel command line not allowed"))
+		}
+	}
+
+	if check.EventCount == 0 {
+		check.Violations = append(check.Violations, Violation{
+			EventIndex: NO_EVENT_INDEX,
+			EventType:  resources.GetEventTypeName(resources.EVENT_TYPE_EV_IPL),
+			Reason:     "No kernel command line measured",
+		})
+	}
+	return check
+}
+
+func evaluateUefiAuthorities(allowed []AuthorityReference, eventlogs []resources.TDEventLog) CheckResult {
+	check := CheckResult{Rule: RULE_UEFI_AUTHORITY, Reference: "UEFI authority"}
+
+	for i, eventlog := range eventlogs {
+		if eventlog.Etype != resources.EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY {
+			continue
+		}
+		check.EventCount++
+
+		/* the digest of the authority is the digest of the whole UEFI variable data */
+		if reason := verifyEventDigests(eventlog, eventlog.Event); reason != "" {
+			check.Violations = append(check.Violations, newViolation(i, eventlog, "", reason))
+			continue
+		}
+
+		decoded, err := resources.DecodeEvent(eventlog.Etype, eventlog.Event)
+		if err != nil {
+			check.Violations = append(check.Violations, newViolation(i, eventlog, "", "Invalid UEFI variable data"))
+			continue
+		}
+		authority, err := resources.ParseEfiAuthority(decoded.(resources.UefiVariableData).VariableData)
+		if err != nil {
+			check.Violations = append(check.Violations, newViolation(i, eventlog, "", "Invalid UEFI authority"))
+			continue
+		}
+
+		subject, sha256 := "", hex.EncodeToString(authority.Data)
+		measured := sha256
+		if authority.Certificate != nil {
+			subject, sha256 = authority.Certificate.Subject, authority.Certificate.Sha256
+			measured = fmt.Sprintf("%s (%s)", subject, sha256)
+		}
+
+		if !isAuthorityAllowed(allowed, subject, sha256) {
+			check.Violations = append(check.Violations, newViolation(i, eventlog, measured, "UEFI authority not allowed"))
+		}
+	}
+
+	if check.EventCount == 0 {
+		check.Violations = append(check.Violations, Violation{
+			EventIndex: NO_EVENT_INDEX,
+			EventType:  resources.GetEventTypeName(resources.EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY),
+			Reason:     "No UEFI authority measured",
+		})
+	}
+	return check
+}
+
+/*
+The event data is only evidence of what was measured if the digests of the event are the
+digests of the data. Every digest of a supported algorithm is compared, at least one has to be.
+The reason of the violation is returned, or an empty string.
+*/
+func verifyEventDigests(eventlog resources.TDEventLog, data []byte) string {
+	verified := 0
+
+	for _, digest := range eventlog.Digests {
+		h, ok := resources.GetAlgorithmHash(digest.AlgorithmId)
+		if !ok {
+			continue
+		}
+		h.Write(data)
+		if !bytes.Equal(h.Sum(nil), digest.Digest) {
+			return fmt.Sprintf("%s digest does not match the event data", resources.GetAlgorithmName(digest.AlgorithmId))
+		}
+		verified++
+	}
+
+	if verified == 0 {
+		return "No digest of the event data to verify"
+	}
+	return ""
+}
+
+func isAuthorityAllowed(allowed []AuthorityReference, subject string, sha256 string) bool {
+	for _, authority := range allowed {
+		if (authority.Subject == "" || authority.Subject == subject) &&
+			(authority.Sha256 == "" || strings.EqualFold(authority.Sha256, sha256)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
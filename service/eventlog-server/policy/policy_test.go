@@ -0,0 +1,266 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package policy
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	resources "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const TEST_POLICY_YAML = `
+digests:
+  - register_index: 1
+    event_type: EV_EFI_BOOT_SERVICES_APPLICATION
+    algorithm: SHA384
+    digests:
+      - "010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101"
+kernel_cmdlines:
+  - /vmlinuz root=/dev/vda1 console=hvc0
+uefi_authorities:
+  - subject: CN=Test DB Key
+`
+
+const TEST_POLICY_JSON = `{
+	"digests": [{
+		"register_index": 1,
+		"event_type": "EV_EFI_BOOT_SERVICES_APPLICATION",
+		"algorithm": "SHA384",
+		"digests": ["010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101"]
+	}],
+	"kernel_cmdlines": ["/vmlinuz root=/dev/vda1 console=hvc0"],
+	"uefi_authorities": [{"subject": "CN=Test DB Key"}]
+}`
+
+func newEventlog(rtmr uint32, etype uint32, value byte, event []byte) resources.TDEventLog {
+	return resources.TDEventLog{
+		Rtmr:        rtmr,
+		Etype:       etype,
+		DigestCount: 1,
+		Digests: []resources.TDEventLogDigest{
+			{AlgorithmId: resources.TPM_ALG_SHA384, Digest: bytes.Repeat([]byte{value}, 48)},
+		},
+		Event:     event,
+		EventSize: uint32(len(event)),
+	}
+}
+
+/* An event whose SHA384 digest is the digest of the measured data */
+func newMeasuredEventlog(rtmr uint32, etype uint32, measured []byte, event []byte) resources.TDEventLog {
+	eventlog := newEventlog(rtmr, etype, 0, event)
+	digest := sha512.Sum384(measured)
+	eventlog.Digests[0].Digest = digest[:]
+	return eventlog
+}
+
+func createTestCertificate(t *testing.T, commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key error: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate error: %v", err)
+	}
+	return certificate
+}
+
+/* UEFI_VARIABLE_DATA of an EV_EFI_VARIABLE_AUTHORITY event for the db variable */
+func buildAuthorityEvent(certificate []byte) []byte {
+	var buf bytes.Buffer
+
+	buf.Write(resources.EFI_IMAGE_SECURITY_DATABASE_GUID[:])
+	_ = binary.Write(&buf, binary.LittleEndian, uint64(2))
+	_ = binary.Write(&buf, binary.LittleEndian, uint64(16+len(certificate)))
+	buf.Write([]byte{'d', 0, 'b', 0})
+	buf.Write(make([]byte, 16))
+	buf.Write(certificate)
+	return buf.Bytes()
+}
+
+func newTestEventlogs(t *testing.T, authority string, cmdline string, digest byte) []resources.TDEventLog {
+	authorityEvent := buildAuthorityEvent(createTestCertificate(t, authority))
+	return []resources.TDEventLog{
+		newMeasuredEventlog(1, resources.EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY, authorityEvent, authorityEvent),
+		newEventlog(1, resources.EVENT_TYPE_EV_EFI_BOOT_SERVICES_APPLICATION, digest, nil),
+		newEventlog(2, resources.EVENT_TYPE_EV_IPL, 0x3, []byte("grub_cmd: linux /vmlinuz\x00")),
+		newMeasuredEventlog(2, resources.EVENT_TYPE_EV_IPL, []byte(cmdline), []byte(KERNEL_CMDLINE_PREFIX+cmdline+"\x00")),
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fromYaml, err := Load([]byte(TEST_POLICY_YAML))
+	if err != nil {
+		t.Fatalf("Load(YAML) returned error: %v", err)
+	}
+	fromJson, err := Load([]byte(TEST_POLICY_JSON))
+	if err != nil {
+		t.Fatalf("Load(JSON) returned error: %v", err)
+	}
+	if !reflect.DeepEqual(fromYaml, fromJson) || len(fromYaml.Digests) != 1 || len(fromYaml.KernelCmdlines) != 1 ||
+		len(fromYaml.UefiAuthorities) != 1 {
+		t.Errorf("Load() -> YAML: %+v, JSON: %+v", fromYaml, fromJson)
+	}
+
+	location := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(location, []byte(TEST_POLICY_YAML), 0600); err != nil {
+		t.Fatalf("Failed to write policy file: %v", err)
+	}
+	if fromFile, err := LoadFile(location); err != nil || !reflect.DeepEqual(fromFile, fromYaml) {
+		t.Errorf("LoadFile() = %+v, %v want %+v", fromFile, err, fromYaml)
+	}
+}
+
+func TestLoadInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"Unknown YAML key", "kernel_cmdline: [console=hvc0]\n"},
+		{"Unknown JSON key", `{"kernel_cmdline": ["console=hvc0"]}`},
+		{"Invalid JSON", `{"digests": [`},
+		{"Unknown event type", "digests: [{register_index: 1, event_type: EV_UNKNOWN, digests: ['01']}]\n"},
+		{"Unknown algorithm", "digests: [{register_index: 1, event_type: EV_IPL, algorithm: MD5, digests: ['01']}]\n"},
+		{"Invalid digest", "digests: [{register_index: 1, event_type: '0xd', digests: ['0x1']}]\n"},
+		{"Without digest", "digests: [{register_index: 1, event_type: EV_IPL}]\n"},
+		{"Empty authority", "uefi_authorities: [{}]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load([]byte(tt.data)); pkgerrors.Cause(err) != InvalidPolicyErr {
+				t.Errorf("Err -> Want: %v, Got: %v", InvalidPolicyErr, err)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	policy, err := Load([]byte(TEST_POLICY_YAML))
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		eventlogs []resources.TDEventLog
+		// the violations of the digest, kernel command line and UEFI authority checks
+		want [3][]Violation
+	}{
+		{"Passed", newTestEventlogs(t, "Test DB Key", "/vmlinuz root=/dev/vda1 console=hvc0", 0x1), [3][]Violation{}},
+		{"Unexpected digest", newTestEventlogs(t, "Test DB Key", "/vmlinuz root=/dev/vda1 console=hvc0", 0x5),
+			[3][]Violation{{{EventIndex: 1, RegisterIndex: 1, EventType: "EV_EFI_BOOT_SERVICES_APPLICATION",
+				Measured: "SHA384:" + strings.Repeat("05", 48), Reason: "Digest not in the reference values"}}}},
+		{"Unexpected kernel command line", newTestEventlogs(t, "Test DB Key", "/vmlinuz init=/bin/sh", 0x1),
+			[3][]Violation{nil, {{EventIndex: 3, RegisterIndex: 2, EventType: "EV_IPL", Measured: "/vmlinuz init=/bin/sh",
+				Reason: "Kernel command line not allowed"}}}},
+		{"Kernel command line not measured", func() []resources.TDEventLog {
+			eventlogs := newTestEventlogs(t, "Test DB Key", "/vmlinuz init=/bin/sh", 0x1)
+			eventlogs[3].Event = []byte(KERNEL_CMDLINE_PREFIX + "/vmlinuz root=/dev/vda1 console=hvc0\x00")
+			return eventlogs
+		}(), [3][]Violation{nil, {{EventIndex: 3, RegisterIndex: 2, EventType: "EV_IPL", Measured: "/vmlinuz root=/dev/vda1 console=hvc0",
+			Reason: "SHA384 digest does not match the event data"}}}},
+		{"UEFI authority not measured", func() []resources.TDEventLog {
+			eventlogs := newTestEventlogs(t, "Test DB Key", "/vmlinuz root=/dev/vda1 console=hvc0", 0x1)
+			eventlogs[0].Digests[0].Digest = bytes.Repeat([]byte{0x2}, 48)
+			return eventlogs
+		}(), [3][]Violation{nil, nil, {{EventIndex: 0, RegisterIndex: 1, EventType: "EV_EFI_VARIABLE_AUTHORITY",
+			Reason: "SHA384 digest does not match the event data"}}}},
+		{"Missing events", []resources.TDEventLog{newEventlog(0, resources.EVENT_TYPE_EV_SEPARATOR, 0x0, nil)},
+			[3][]Violation{
+				{{EventIndex: NO_EVENT_INDEX, RegisterIndex: 1, EventType: "EV_EFI_BOOT_SERVICES_APPLICATION", Reason: "No event measured"}},
+				{{EventIndex: NO_EVENT_INDEX, EventType: "EV_IPL", Reason: "No kernel command line measured"}},
+				{{EventIndex: NO_EVENT_INDEX, EventType: "EV_EFI_VARIABLE_AUTHORITY", Reason: "No UEFI authority measured"}},
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(policy, tt.eventlogs)
+			if err != nil {
+				t.Fatalf("Evaluate() returned error: %v", err)
+			}
+			if len(result.Checks) != 3 {
+				t.Fatalf("Evaluate() = %+v, want 3 checks", result)
+			}
+
+			passed := true
+			for i, check := range result.Checks {
+				want := tt.want[i]
+				if want == nil {
+					want = []Violation{}
+				}
+				if !reflect.DeepEqual(check.Violations, want) || check.Passed != (len(want) == 0) {
+					t.Errorf("Check %s -> Want: %+v, Got: %+v", check.Rule, want, check)
+				}
+				passed = passed && len(want) == 0
+			}
+			if result.Passed != passed {
+				t.Errorf("Passed -> Want: %v, Got: %v", passed, result.Passed)
+			}
+		})
+	}
+}
+
+func TestEvaluateUefiAuthority(t *testing.T) {
+	certificate := createTestCertificate(t, "Other Key")
+	fingerprint := sha256.Sum256(certificate)
+	eventlogs := []resources.TDEventLog{
+		newMeasuredEventlog(1, resources.EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY, buildAuthorityEvent(certificate), buildAuthorityEvent(certificate)),
+	}
+
+	tests := []struct {
+		name      string
+		authority AuthorityReference
+		passed    bool
+	}{
+		{"Subject", AuthorityReference{Subject: "CN=Other Key"}, true},
+		{"Fingerprint", AuthorityReference{Sha256: strings.ToUpper(hex.EncodeToString(fingerprint[:]))}, true},
+		{"Subject and fingerprint", AuthorityReference{Subject: "CN=Other Key", Sha256: hex.EncodeToString(fingerprint[:])}, true},
+		{"Other subject", AuthorityReference{Subject: "CN=Test DB Key"}, false},
+		{"Other fingerprint", AuthorityReference{Subject: "CN=Other Key", Sha256: strings.Repeat("00", 32)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(Policy{UefiAuthorities: []AuthorityReference{tt.authority}}, eventlogs)
+			if err != nil || len(result.Checks) != 1 || result.Checks[0].EventCount != 1 {
+				t.Fatalf("Evaluate() = %+v, %v want 1 check of 1 event", result, err)
+			}
+			if result.Passed != tt.passed {
+				t.Errorf("Passed -> Want: %v, Got: %+v", tt.passed, result)
+			}
+		})
+	}
+
+	if _, err := Evaluate(Policy{UefiAuthorities: []AuthorityReference{{}}}, eventlogs); pkgerrors.Cause(err) != InvalidPolicyErr {
+		t.Errorf("Err -> Want: %v, Got: %v", InvalidPolicyErr, err)
+	}
+}
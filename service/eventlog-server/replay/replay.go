@@ -0,0 +1,141 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package replay
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"fmt"
+	"log"
+
+	resources "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	// The number of runtime measurement registers of TDX
+	RTMR_COUNT = 4
+	// The length of RTMR, which is a SHA384 digest
+	RTMR_LEN = 48
+)
+
+var (
+	MissingSha384DigestErr = pkgerrors.New("Eventlog without SHA384 digest")
+	InvalidRtmrLengthErr   = pkgerrors.New("RTMR with invalid length")
+)
+
+type RtmrReplayResult struct {
+	Index    int
+	Replayed []byte
+	Measured []byte
+	Match    bool
+}
+
+// Extend simulates the RTMR extend operation: RTMR_new = SHA384(RTMR_old || digest).
+func Extend(rtmr []byte, digest []byte) []byte {
+	h := sha512.New384()
+	h.Write(rtmr)
+	h.Write(digest)
+	return h.Sum(nil)
+}
+
+// ReplayEventlogs folds the SHA384 digest of every event into simulated RTMRs starting from zero.
+// Events targeting other registers, like MRTD, and EV_NO_ACTION events are not extended.
+func ReplayEventlogs(eventlogs []resources.TDEventLog) ([RTMR_COUNT][]byte, error) {
+	var rtmrs [RTMR_COUNT][]byte
+
+	for i := range rtmrs {
+		rtmrs[i] = make([]byte, RTMR_LEN)
+	}
+
+	for i, eventlog := range eventlogs {
+		if eventlog.Rtmr >= RTMR_COUNT || eventlog.Etype == resources.EVENT_TYPE_EV_NO_ACTION {
+			continue
+		}
+
+		digest, err := getSha384Digest(eventlog)
+		if err != nil {
+			log.Println("Error in getting SHA384 digest of event", i)
+			return rtmrs, err
+		}
+
+		rtmrs[eventlog.Rtmr] = Extend(rtmrs[eventlog.Rtmr], digest)
+	}
+
+	return rtmrs, nil
+}
+
+// CompareRtmrs reports per register whether the replayed RTMR matches the measured one.
+func CompareRtmrs(replayed [RTMR_COUNT][]byte, measured [RTMR_COUNT][]byte) ([]RtmrReplayResult, error) {
+	var results []RtmrReplayResult
+
+	for i := 0; i < RTMR_COUNT; i++ {
+		if len(measured[i]) != RTMR_LEN {
+			return nil, pkgerrors.Wrap(InvalidRtmrLengthErr, fmt.Sprintf("RTMR %d", i))
+		}
+
+		results = append(results, RtmrReplayResult{
+			Index:    i,
+			Replayed: replayed[i],
+			Measured: measured[i],
+			Match:    bytes.Equal(replayed[i], measured[i]),
+		})
+	}
+
+	return results, nil
+}
+
+// VerifyEventlogs replays the event logs and compares the result with the measured RTMRs.
+func VerifyEventlogs(eventlogs []resources.TDEventLog, measured [RTMR_COUNT][]byte) ([]RtmrReplayResult, error) {
+	replayed, err := ReplayEventlogs(eventlogs)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompareRtmrs(replayed, measured)
+}
+
+/*
+VerifyContainerEventlogs checks that every container event is anchored in RTMR 3: the TDX
+event log holds, in the order of the container events, an event of RTMR 3 whose data is the
+anchor of the container event and whose SHA384 digest is the digest of the anchor. The TDX
+event log must be verified against the measured RTMRs with VerifyEventlogs.
+*/
+func VerifyContainerEventlogs(eventlogs []resources.TDEventLog, containerEventlogs []resources.ContainerEventLog) error {
+	next := 0
+
+	for _, eventlog := range eventlogs {
+		if next == len(containerEventlogs) {
+			break
+		}
+		if eventlog.Rtmr != resources.CONTAINER_EVENT_RTMR_INDEX {
+			continue
+		}
+
+		anchor := resources.GetContainerEventAnchor(containerEventlogs[next])
+		expected := sha512.Sum384(anchor)
+		digest, err := getSha384Digest(eventlog)
+		if err == nil && bytes.Equal(eventlog.Event, anchor) && bytes.Equal(digest, expected[:]) {
+			next += 1
+		}
+	}
+
+	if next < len(containerEventlogs) {
+		return pkgerrors.Wrap(resources.ContainerEventNotAnchoredErr,
+			fmt.Sprintf("container event %d", containerEventlogs[next].Sequence))
+	}
+	return nil
+}
+
+func getSha384Digest(eventlog resources.TDEventLog) ([]byte, error) {
+	for _, digest := range eventlog.Digests {
+		if digest.AlgorithmId == resources.TPM_ALG_SHA384 && len(digest.Digest) == RTMR_LEN {
+			return digest.Digest, nil
+		}
+	}
+
+	return nil, MissingSha384DigestErr
+}
@@ -0,0 +1,149 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package replay
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"testing"
+
+	resources "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+)
+
+func newEventlog(rtmr uint32, etype uint32, value byte) resources.TDEventLog {
+	return resources.TDEventLog{
+		Rtmr:        rtmr,
+		Etype:       etype,
+		DigestCount: 1,
+		Digests: []resources.TDEventLogDigest{
+			{AlgorithmId: resources.TPM_ALG_SHA384, Digest: bytes.Repeat([]byte{value}, RTMR_LEN)},
+		},
+	}
+}
+
+func TestExtend(t *testing.T) {
+	rtmr := make([]byte, RTMR_LEN)
+	digest := bytes.Repeat([]byte{0x1}, RTMR_LEN)
+
+	expected := sha512.Sum384(append(make([]byte, RTMR_LEN), digest...))
+	if value := Extend(rtmr, digest); !bytes.Equal(value, expected[:]) {
+		t.Fatalf(`Extend(zero, digest) = %x want %x`, value, expected)
+	}
+}
+
+func TestReplayEventlogs(t *testing.T) {
+	eventlogs := []resources.TDEventLog{
+		newEventlog(0, 0x80000001, 0x1),
+		newEventlog(0, 0x80000002, 0x2),
+		newEventlog(2, 0xd, 0x3),
+		newEventlog(1, resources.EVENT_TYPE_EV_NO_ACTION, 0x4),
+		newEventlog(0xFFFFFFFF, 0x80000003, 0x5),
+	}
+
+	rtmrs, err := ReplayEventlogs(eventlogs)
+	if err != nil {
+		t.Fatalf(`ReplayEventlogs(eventlogs) = %v want %v`, err, nil)
+	}
+
+	zero := make([]byte, RTMR_LEN)
+	rtmr0 := Extend(Extend(zero, bytes.Repeat([]byte{0x1}, RTMR_LEN)), bytes.Repeat([]byte{0x2}, RTMR_LEN))
+	rtmr2 := Extend(zero, bytes.Repeat([]byte{0x3}, RTMR_LEN))
+
+	if !bytes.Equal(rtmrs[0], rtmr0) || !bytes.Equal(rtmrs[1], zero) ||
+		!bytes.Equal(rtmrs[2], rtmr2) || !bytes.Equal(rtmrs[3], zero) {
+		t.Fatalf(`ReplayEventlogs(eventlogs) = %x want %x, %x, %x, %x`, rtmrs, rtmr0, zero, rtmr2, zero)
+	}
+}
+
+func TestReplayEventlogsWithoutSha384(t *testing.T) {
+	eventlog := newEventlog(0, 0x80000001, 0x1)
+	eventlog.Digests[0].AlgorithmId = resources.TPM_ALG_SHA256
+
+	_, err := ReplayEventlogs([]resources.TDEventLog{eventlog})
+	if err != MissingSha384DigestErr {
+		t.Fatalf(`ReplayEventlogs(eventlogs) = %v want %v`, err, MissingSha384DigestErr)
+	}
+}
+
+func TestVerifyEventlogs(t *testing.T) {
+	eventlogs := []resources.TDEventLog{newEventlog(1, 0x80000001, 0x1)}
+
+	var measured [RTMR_COUNT][]byte
+	for i := range measured {
+		measured[i] = make([]byte, RTMR_LEN)
+	}
+	measured[1] = Extend(measured[1], bytes.Repeat([]byte{0x1}, RTMR_LEN))
+	measured[3] = bytes.Repeat([]byte{0xff}, RTMR_LEN)
+
+	results, err := VerifyEventlogs(eventlogs, measured)
+	if err != nil || len(results) != RTMR_COUNT {
+		t.Fatalf(`VerifyEventlogs(eventlogs, measured) = %v, %v want %d results`, results, err, RTMR_COUNT)
+	}
+
+	for _, result := range results {
+		if result.Match != (result.Index != 3) {
+			t.Fatalf(`VerifyEventlogs(eventlogs, measured) RTMR %d match = %v want %v`, result.Index, result.Match, result.Index != 3)
+		}
+	}
+
+	measured[2] = measured[2][:10]
+	_, err = VerifyEventlogs(eventlogs, measured)
+	if err == nil {
+		t.Fatalf(`VerifyEventlogs(eventlogs, invalid measured) = %v want error`, err)
+	}
+}
+
+func newAnchorEventlog(containerEventlog resources.ContainerEventLog) resources.TDEventLog {
+	anchor := resources.GetContainerEventAnchor(containerEventlog)
+	digest := sha512.Sum384(anchor)
+	eventlog := newEventlog(resources.CONTAINER_EVENT_RTMR_INDEX, resources.EVENT_TYPE_EV_IPL, 0)
+	eventlog.Digests[0].Digest = digest[:]
+	eventlog.Event = anchor
+	return eventlog
+}
+
+func TestVerifyContainerEventlogs(t *testing.T) {
+	var containerEventlogs []resources.ContainerEventLog
+	for i, event := range []string{"nginx@sha256:00", "nginx", "redis"} {
+		digest := sha512.Sum384([]byte(event))
+		containerEventlogs = append(containerEventlogs, resources.ContainerEventLog{Sequence: uint32(i), ContainerId: "c1",
+			EventType: resources.CONTAINER_EVENT_START, Digest: digest[:], Event: []byte(event)})
+	}
+
+	eventlogs := []resources.TDEventLog{newEventlog(0, 0x80000001, 0x1)}
+	for _, containerEventlog := range containerEventlogs {
+		eventlogs = append(eventlogs, newEventlog(2, resources.EVENT_TYPE_EV_IPL, 0x2), newAnchorEventlog(containerEventlog))
+	}
+
+	tampered := containerEventlogs[1]
+	tampered.ContainerId = "c2"
+	unmeasured := append([]resources.TDEventLog{}, eventlogs...)
+	unmeasured[4] = newAnchorEventlog(containerEventlogs[1])
+	unmeasured[4].Digests[0].Digest = bytes.Repeat([]byte{0x3}, RTMR_LEN)
+
+	tests := []struct {
+		name               string
+		eventlogs          []resources.TDEventLog
+		containerEventlogs []resources.ContainerEventLog
+		wantErr            bool
+	}{
+		{"All anchored", eventlogs, containerEventlogs, false},
+		{"Selected events", eventlogs, []resources.ContainerEventLog{containerEventlogs[0], containerEventlogs[2]}, false},
+		{"Tampered event", eventlogs, []resources.ContainerEventLog{tampered}, true},
+		{"Out of order", eventlogs, []resources.ContainerEventLog{containerEventlogs[2], containerEventlogs[0]}, true},
+		{"Missing anchor", eventlogs[:5], containerEventlogs, true},
+		{"Digest not of the anchor", unmeasured, containerEventlogs, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyContainerEventlogs(tt.eventlogs, tt.containerEventlogs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyContainerEventlogs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
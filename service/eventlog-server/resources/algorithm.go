@@ -0,0 +1,69 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+/*
+The algorithm IDs are defined in the TCG Algorithm Registry
+at https://trustedcomputinggroup.org/resource/tcg-algorithm-registry/
+*/
+const (
+	TPM_ALG_SHA1    = 0x4
+	TPM_ALG_SHA256  = 0xB
+	TPM_ALG_SHA384  = 0xC
+	TPM_ALG_SHA512  = 0xD
+	TPM_ALG_SM3_256 = 0x12
+)
+
+var algorithmNames = map[uint16]string{
+	TPM_ALG_SHA1:    "SHA1",
+	TPM_ALG_SHA256:  "SHA256",
+	TPM_ALG_SHA384:  "SHA384",
+	TPM_ALG_SHA512:  "SHA512",
+	TPM_ALG_SM3_256: "SM3_256",
+}
+
+var algorithmHashes = map[uint16]func() hash.Hash{
+	TPM_ALG_SHA1:   sha1.New,
+	TPM_ALG_SHA256: sha256.New,
+	TPM_ALG_SHA384: sha512.New384,
+	TPM_ALG_SHA512: sha512.New,
+}
+
+// GetAlgorithmHash returns the hash of the algorithm, SM3_256 and unknown algorithms have none.
+func GetAlgorithmHash(algId uint16) (hash.Hash, bool) {
+	newHash, ok := algorithmHashes[algId]
+	if !ok {
+		return nil, false
+	}
+	return newHash(), true
+}
+
+// GetAlgorithmName returns the TCG registry name of the algorithm, or its hex ID if unknown.
+func GetAlgorithmName(algId uint16) string {
+	if name, ok := algorithmNames[algId]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04X", algId)
+}
+
+// GetAlgorithmIdByName returns the algorithm ID of the TCG registry name, e.g. SHA384, ignoring case.
+func GetAlgorithmIdByName(name string) (uint16, bool) {
+	for algId, algName := range algorithmNames {
+		if strings.EqualFold(algName, name) {
+			return algId, true
+		}
+	}
+	return 0, false
+}
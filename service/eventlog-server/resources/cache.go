@@ -0,0 +1,143 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+Parsing the event logs on every request is costly on nodes serving many pods, so the parsed
+event logs are kept in memory. The TDX and TPM event logs are parsed again once the size or
+the modification time of one of their files changes. The IMA runtime measurement list grows
+at runtime and securityfs reports neither its size nor its modification time, so the list
+is read on every request and only the measurements appended since the previous request are
+parsed.
+*/
+var (
+	tdxEventlogCache = &eventlogCache{}
+	tpmEventlogCache = &eventlogCache{}
+	imaEventlogCache = &imaEventlogListCache{}
+)
+
+type fileState struct {
+	location string
+	size     int64
+	modTime  time.Time
+}
+
+func getFileStates(locations []string) ([]fileState, error) {
+	var states []fileState
+	for _, location := range locations {
+		info, err := os.Stat(location)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, fileState{location: location, size: info.Size(), modTime: info.ModTime()})
+	}
+	return states, nil
+}
+
+func isFileStatesEqual(a []fileState, b []fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].location != b[i].location || a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+eventlogCache holds the event logs parsed from a set of files. The mutex is held while the
+event logs are loaded, so that concurrent requests parse the files only once. The cached
+event logs are shared between requests and must not be modified.
+*/
+type eventlogCache struct {
+	mutex     sync.Mutex
+	states    []fileState
+	eventlogs TDEventLogs
+	loaded    bool
+	// number of times the event logs were loaded, for tests
+	loads int
+}
+
+// get returns the cached event logs if the files are unchanged, or the event logs loaded again.
+func (c *eventlogCache) get(locations []string, load func() (TDEventLogs, error)) (TDEventLogs, error) {
+	states, err := getFileStates(locations)
+	if err != nil {
+		return TDEventLogs{}, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.loaded && isFileStatesEqual(c.states, states) {
+		return c.eventlogs, nil
+	}
+
+	c.loads += 1
+	eventlogs, err := load()
+	if err != nil {
+		c.loaded = false
+		return TDEventLogs{}, err
+	}
+
+	c.states = states
+	c.eventlogs = eventlogs
+	c.loaded = true
+	return eventlogs, nil
+}
+
+/* imaEventlogListCache holds the IMA runtime measurement list and the data it was parsed from */
+type imaEventlogListCache struct {
+	mutex     sync.Mutex
+	location  string
+	data      []byte
+	eventlogs []ImaEventLog
+	// number of bytes parsed, for tests
+	parsed int
+}
+
+func (c *imaEventlogListCache) get(location string, data []byte, parse func([]byte) ([]ImaEventLog, error)) ([]ImaEventLog, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	/* the list is append only, a list not starting with the cached data is parsed again */
+	if c.eventlogs != nil && location == c.location && len(data) >= len(c.data) && bytes.Equal(data[:len(c.data)], c.data) {
+		if len(data) == len(c.data) {
+			return c.eventlogs, nil
+		}
+
+		c.parsed += len(data) - len(c.data)
+		appended, err := parse(data[len(c.data):])
+		if err != nil {
+			return nil, err
+		}
+
+		/* appending leaves the measurements seen by the previous requests untouched */
+		c.eventlogs = append(c.eventlogs, appended...)
+		c.data = data
+		return c.eventlogs, nil
+	}
+
+	c.parsed += len(data)
+	eventlogs, err := parse(data)
+	if err != nil {
+		c.eventlogs = nil
+		return nil, err
+	}
+
+	c.location = location
+	c.data = data
+	c.eventlogs = eventlogs
+	return eventlogs, nil
+}
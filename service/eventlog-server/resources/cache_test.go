@@ -0,0 +1,231 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeTpmEventlogFile(t testing.TB, eventNum int) string {
+	location := filepath.Join(t.TempDir(), "binary_bios_measurements")
+	if err := os.WriteFile(location, buildTpmEventlog(eventNum), 0600); err != nil {
+		t.Fatalf("Failed to write TPM eventlog: %v", err)
+	}
+	return location
+}
+
+func TestEventlogCache(t *testing.T) {
+	location := writeTpmEventlogFile(t, 3)
+	cache := &eventlogCache{}
+	load := func() (TDEventLogs, error) { return loadTpmEventlogs(location) }
+
+	tests := []struct {
+		name       string
+		change     func()
+		wantEvents int
+		wantLoads  int
+	}{
+		{"First request", func() {}, 3, 1},
+		{"Unchanged file", func() {}, 3, 1},
+		{"Size changed", func() { os.WriteFile(location, buildTpmEventlog(5), 0600) }, 5, 2},
+		{"Modification time changed", func() {
+			os.Chtimes(location, time.Now(), time.Now().Add(time.Hour))
+		}, 5, 3},
+		{"Unchanged again", func() {}, 5, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+			eventlogs, err := cache.get([]string{location}, load)
+			if err != nil || len(eventlogs.EventLogs) != tt.wantEvents {
+				t.Fatalf("get() = %d, %v want %d events", len(eventlogs.EventLogs), err, tt.wantEvents)
+			}
+			if cache.loads != tt.wantLoads {
+				t.Errorf("Loads -> Want: %d, Got: %d", tt.wantLoads, cache.loads)
+			}
+		})
+	}
+
+	/* a failed load is not cached */
+	os.WriteFile(location, []byte{0x1}, 0600)
+	if _, err := cache.get([]string{location}, load); err == nil {
+		t.Fatalf("get() of invalid eventlog returned no error")
+	}
+	os.WriteFile(location, buildTpmEventlog(2), 0600)
+	if eventlogs, err := cache.get([]string{location}, load); err != nil || len(eventlogs.EventLogs) != 2 {
+		t.Fatalf("get() after invalid eventlog = %d, %v want 2 events", len(eventlogs.EventLogs), err)
+	}
+
+	os.Remove(location)
+	if _, err := cache.get([]string{location}, load); !os.IsNotExist(err) {
+		t.Fatalf("Err -> Want: file not found, Got: %v", err)
+	}
+}
+
+func TestEventlogCacheConcurrentRequests(t *testing.T) {
+	location := writeTpmEventlogFile(t, 16)
+	cache := &eventlogCache{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			eventlogs, err := cache.get([]string{location}, func() (TDEventLogs, error) { return loadTpmEventlogs(location) })
+			if err == nil {
+				_, err = selectEventlogs(eventlogs, EventlogFilter{AlgorithmId: TPM_ALG_SHA256}, 2, 4)
+			}
+			if err != nil {
+				t.Errorf("get() returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if cache.loads != 1 {
+		t.Errorf("Loads -> Want: 1, Got: %d", cache.loads)
+	}
+
+	/* the requests leave the cached event logs untouched */
+	eventlogs, _ := cache.get([]string{location}, nil)
+	if len(eventlogs.EventLogs) != 16 || len(eventlogs.EventLogs[0].Digests) != 2 {
+		t.Errorf("Cached event logs modified: %d events, %d digests", len(eventlogs.EventLogs), len(eventlogs.EventLogs[0].Digests))
+	}
+}
+
+func TestImaEventlogListCache(t *testing.T) {
+	data := buildImaBinaryEventlog()
+	entry := buildImaBinaryEntry(11, IMA_TEMPLATE_IMA_NG,
+		buildImaTemplateFields([]byte("sha256:\x00"), []byte("/etc/passwd\x00")))
+	appended := append(append([]byte{}, data...), entry...)
+
+	tests := []struct {
+		name       string
+		location   string
+		data       []byte
+		wantEvents int
+		wantParsed int
+	}{
+		{"First request", "binary", data, 4, len(data)},
+		{"Unchanged list", "binary", data, 4, len(data)},
+		{"Appended measurement", "binary", appended, 5, len(appended)},
+		{"Other location", "other", appended, 5, 2 * len(appended)},
+		{"List not starting with the cached one", "other", data[len(data)/2:], 0, 2*len(appended) + len(data) - len(data)/2},
+	}
+
+	cache := &imaEventlogListCache{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventlogs, err := cache.get(tt.location, tt.data, parseImaBinaryEventlogs)
+			if tt.wantEvents == 0 {
+				if err == nil {
+					t.Fatalf("get() of invalid list returned no error")
+				}
+				return
+			}
+
+			if err != nil || len(eventlogs) != tt.wantEvents {
+				t.Fatalf("get() = %d, %v want %d measurements", len(eventlogs), err, tt.wantEvents)
+			}
+			if cache.parsed != tt.wantParsed {
+				t.Errorf("Parsed bytes -> Want: %d, Got: %d", tt.wantParsed, cache.parsed)
+			}
+		})
+	}
+
+	/* the list parsed in parts matches the list parsed at once */
+	cache = &imaEventlogListCache{}
+	cache.get("binary", data, parseImaBinaryEventlogs)
+	eventlogs, _ := cache.get("binary", appended, parseImaBinaryEventlogs)
+	want, _ := parseImaBinaryEventlogs(appended)
+	if eventlogs[4].FileName != want[4].FileName || eventlogs[4].PcrIndex != 11 {
+		t.Errorf("Appended measurement -> Want: %+v, Got: %+v", want[4], eventlogs[4])
+	}
+}
+
+/*
+Request latency of the TPM event log under concurrent load, parsing the event log on every
+request against the cached event log, run with go test ./resources -bench=GetEventlogs
+*/
+func BenchmarkGetEventlogs(b *testing.B) {
+	location := writeTpmEventlogFile(b, 2048)
+	filter := EventlogFilter{RegisterIndexes: []uint32{1, 2}}
+
+	b.Run("Uncached", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				eventlogs, err := loadTpmEventlogs(location)
+				if err == nil {
+					_, err = selectEventlogs(eventlogs, filter, 0, 100)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+
+	b.Run("Cached", func(b *testing.B) {
+		cache := &eventlogCache{}
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				eventlogs, err := cache.get([]string{location}, func() (TDEventLogs, error) { return loadTpmEventlogs(location) })
+				if err == nil {
+					_, err = selectEventlogs(eventlogs, filter, 0, 100)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+}
+
+/* The IMA list is read on every request, as securityfs reports no change */
+func BenchmarkGetImaEventlogs(b *testing.B) {
+	var data []byte
+	for i := 0; i < 256; i++ {
+		data = append(data, buildImaBinaryEventlog()...)
+	}
+	location := filepath.Join(b.TempDir(), "binary_runtime_measurements")
+	if err := os.WriteFile(location, data, 0600); err != nil {
+		b.Fatalf("Failed to write IMA eventlog: %v", err)
+	}
+
+	b.Run("Uncached", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				data, err := os.ReadFile(location)
+				if err == nil {
+					_, err = parseImaBinaryEventlogs(data)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+
+	b.Run("Cached", func(b *testing.B) {
+		cache := &imaEventlogListCache{}
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				data, err := os.ReadFile(location)
+				if err == nil {
+					_, err = cache.get(location, data, parseImaBinaryEventlogs)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,157 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/*
+Minimal CBOR (RFC 8949) support for the canonical event log: unsigned integers, byte and
+text strings, arrays and maps with definite lengths. Lengths are always encoded in their
+shortest form, so that the encoding of a record is deterministic.
+*/
+const (
+	CBOR_MAJOR_UINT   = 0
+	CBOR_MAJOR_BYTES  = 2
+	CBOR_MAJOR_STRING = 3
+	CBOR_MAJOR_ARRAY  = 4
+	CBOR_MAJOR_MAP    = 5
+)
+
+var InvalidCborErr = pkgerrors.New("Invalid or unsupported CBOR data")
+
+type cborWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *cborWriter) writeHead(major byte, value uint64) {
+	major <<= 5
+	switch {
+	case value < 24:
+		w.buf.WriteByte(major | byte(value))
+	case value <= 0xFF:
+		w.buf.Write([]byte{major | 24, byte(value)})
+	case value <= 0xFFFF:
+		w.buf.WriteByte(major | 25)
+		binary.Write(&w.buf, binary.BigEndian, uint16(value))
+	case value <= 0xFFFFFFFF:
+		w.buf.WriteByte(major | 26)
+		binary.Write(&w.buf, binary.BigEndian, uint32(value))
+	default:
+		w.buf.WriteByte(major | 27)
+		binary.Write(&w.buf, binary.BigEndian, value)
+	}
+}
+
+func (w *cborWriter) writeUint(value uint64) {
+	w.writeHead(CBOR_MAJOR_UINT, value)
+}
+
+func (w *cborWriter) writeBytes(data []byte) {
+	w.writeHead(CBOR_MAJOR_BYTES, uint64(len(data)))
+	w.buf.Write(data)
+}
+
+func (w *cborWriter) writeString(str string) {
+	w.writeHead(CBOR_MAJOR_STRING, uint64(len(str)))
+	w.buf.WriteString(str)
+}
+
+func (w *cborWriter) writeArrayHeader(size int) {
+	w.writeHead(CBOR_MAJOR_ARRAY, uint64(size))
+}
+
+func (w *cborWriter) writeMapHeader(size int) {
+	w.writeHead(CBOR_MAJOR_MAP, uint64(size))
+}
+
+type cborReader struct {
+	data  []byte
+	index int
+}
+
+func (r *cborReader) readHead(major byte) (uint64, error) {
+	if r.index >= len(r.data) || r.data[r.index]>>5 != major {
+		return 0, InvalidCborErr
+	}
+
+	info := r.data[r.index] & 0x1F
+	r.index++
+	if info < 24 {
+		return uint64(info), nil
+	}
+
+	/* indefinite lengths and the reserved values are not supported */
+	if info > 27 {
+		return 0, InvalidCborErr
+	}
+	size := 1 << (info - 24)
+	if size > len(r.data)-r.index {
+		return 0, InvalidCborErr
+	}
+
+	var value uint64
+	for _, b := range r.data[r.index : r.index+size] {
+		value = value<<8 | uint64(b)
+	}
+	r.index += size
+	return value, nil
+}
+
+func (r *cborReader) readUint() (uint64, error) {
+	return r.readHead(CBOR_MAJOR_UINT)
+}
+
+func (r *cborReader) readBytes() ([]byte, error) {
+	return r.readData(CBOR_MAJOR_BYTES)
+}
+
+func (r *cborReader) readString() (string, error) {
+	data, err := r.readData(CBOR_MAJOR_STRING)
+	return string(data), err
+}
+
+func (r *cborReader) readData(major byte) ([]byte, error) {
+	size, err := r.readHead(major)
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(len(r.data)-r.index) {
+		return nil, InvalidCborErr
+	}
+	data := r.data[r.index : r.index+int(size)]
+	r.index += int(size)
+	return data, nil
+}
+
+// readArrayHeader and readMapHeader bound the number of items by the remaining data, every
+// item taking at least one byte, so that a forged size cannot trigger a large allocation.
+func (r *cborReader) readArrayHeader() (int, error) {
+	return r.readContainerHeader(CBOR_MAJOR_ARRAY, 1)
+}
+
+func (r *cborReader) readMapHeader() (int, error) {
+	return r.readContainerHeader(CBOR_MAJOR_MAP, 2)
+}
+
+func (r *cborReader) readContainerHeader(major byte, itemSize uint64) (int, error) {
+	size, err := r.readHead(major)
+	if err != nil {
+		return 0, err
+	}
+	if size > uint64(len(r.data)-r.index)/itemSize {
+		return 0, InvalidCborErr
+	}
+	return int(size), nil
+}
+
+func (r *cborReader) done() bool {
+	return r.index == len(r.data)
+}
@@ -0,0 +1,118 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"log"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/*
+The CC Event Log ACPI table is defined in ACPI specification 6.5 section 5.2.34
+at https://uefi.org/specs/ACPI/6.5/05_ACPI_Software_Programming_Model.html#cc-event-log-acpi-table
+*/
+const (
+	CCEL_TABLE_SIGNATURE = "CCEL"
+	CCEL_TABLE_LENGTH    = 56
+
+	CC_TYPE_SEV = 1
+	CC_TYPE_TDX = 2
+)
+
+var (
+	CcelTableLengthErr   = pkgerrors.New("CCEL table length does not match the table data")
+	CcelTableChecksumErr = pkgerrors.New("CCEL table checksum verification failed")
+	UnsupportedCcTypeErr = pkgerrors.New("CC type in CCEL table is not TDX")
+	CcelLogAreaErr       = pkgerrors.New("CCEL log area does not match the eventlog data")
+)
+
+type CcelTable struct {
+	Signature       string `json:"signature"`
+	Length          uint32 `json:"length"`
+	Revision        uint8  `json:"revision"`
+	Checksum        uint8  `json:"checksum"`
+	OemId           string `json:"oem_id"`
+	OemTableId      string `json:"oem_table_id"`
+	OemRevision     uint32 `json:"oem_revision"`
+	CreatorId       string `json:"creator_id"`
+	CreatorRevision uint32 `json:"creator_revision"`
+	CcType          uint8  `json:"cc_type"`
+	CcSubtype       uint8  `json:"cc_subtype"`
+	Laml            uint64 `json:"laml"`
+	Lasa            uint64 `json:"lasa"`
+}
+
+// ParseCcelTable decodes and validates the CCEL ACPI table.
+func ParseCcelTable(data []byte) (CcelTable, error) {
+	var err error
+
+	if len(data) < len(CCEL_TABLE_SIGNATURE) || !bytes.Equal(data[0:4], []byte(CCEL_TABLE_SIGNATURE)) {
+		return CcelTable{}, InvalidCcelTableErr
+	}
+
+	if len(data) < CCEL_TABLE_LENGTH {
+		log.Printf("CCEL table with %d bytes shorter than %d bytes", len(data), CCEL_TABLE_LENGTH)
+		return CcelTable{}, CcelTableLengthErr
+	}
+
+	table := CcelTable{Signature: CCEL_TABLE_SIGNATURE}
+	table.Length, _, err = getUint32Object(data, 4)
+	if err != nil {
+		return CcelTable{}, err
+	}
+
+	if table.Length < CCEL_TABLE_LENGTH || uint64(table.Length) != uint64(len(data)) {
+		log.Printf("CCEL table length %d does not match table data with %d bytes", table.Length, len(data))
+		return CcelTable{}, CcelTableLengthErr
+	}
+
+	var sum uint8
+	for _, b := range data {
+		sum += b
+	}
+	if sum != 0 {
+		return CcelTable{}, CcelTableChecksumErr
+	}
+
+	table.Revision = data[8]
+	table.Checksum = data[9]
+	table.OemId = getAcpiString(data[10:16])
+	table.OemTableId = getAcpiString(data[16:24])
+	table.OemRevision, _, _ = getUint32Object(data, 24)
+	table.CreatorId = getAcpiString(data[28:32])
+	table.CreatorRevision, _, _ = getUint32Object(data, 32)
+	table.CcType = data[36]
+	table.CcSubtype = data[37]
+	table.Laml, _, _ = getUint64Object(data, 40)
+	table.Lasa, _, _ = getUint64Object(data, 48)
+
+	if table.CcType != CC_TYPE_TDX {
+		log.Printf("Unsupported CC type %d in CCEL table", table.CcType)
+		return CcelTable{}, UnsupportedCcTypeErr
+	}
+
+	if table.Laml == 0 || table.Lasa == 0 {
+		return CcelTable{}, CcelLogAreaErr
+	}
+
+	return table, nil
+}
+
+// ValidateEventlogData checks the eventlog data read from the data file against the log area of the table.
+func (t CcelTable) ValidateEventlogData(data []byte) error {
+	if uint64(len(data)) != t.Laml {
+		log.Printf("CCEL log area with %d bytes does not match eventlog data with %d bytes", t.Laml, len(data))
+		return CcelLogAreaErr
+	}
+	return nil
+}
+
+func getAcpiString(data []byte) string {
+	return strings.TrimRight(string(data), "\x00 ")
+}
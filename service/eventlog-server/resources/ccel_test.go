@@ -0,0 +1,136 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func buildCcelTable(ccType uint8, laml uint64, lasa uint64) []byte {
+	data := make([]byte, CCEL_TABLE_LENGTH)
+	copy(data[0:4], CCEL_TABLE_SIGNATURE)
+	binary.LittleEndian.PutUint32(data[4:8], CCEL_TABLE_LENGTH)
+	data[8] = 1
+	copy(data[10:16], "INTEL ")
+	copy(data[16:24], "EDK2    ")
+	binary.LittleEndian.PutUint32(data[24:28], 2)
+	copy(data[28:32], "INTL")
+	binary.LittleEndian.PutUint32(data[32:36], 0x1000013)
+	data[36] = ccType
+	binary.LittleEndian.PutUint64(data[40:48], laml)
+	binary.LittleEndian.PutUint64(data[48:56], lasa)
+	updateCcelTableChecksum(data)
+	return data
+}
+
+func updateCcelTableChecksum(data []byte) {
+	var sum uint8
+	data[9] = 0
+	for _, b := range data {
+		sum += b
+	}
+	data[9] = -sum
+}
+
+func TestParseCcelTable(t *testing.T) {
+	data := buildCcelTable(CC_TYPE_TDX, 0x10000, 0x7e000000)
+
+	table, err := ParseCcelTable(data)
+	if err != nil {
+		t.Fatalf("ParseCcelTable() returned error: %v", err)
+	}
+
+	expected := CcelTable{
+		Signature:       "CCEL",
+		Length:          CCEL_TABLE_LENGTH,
+		Revision:        1,
+		Checksum:        data[9],
+		OemId:           "INTEL",
+		OemTableId:      "EDK2",
+		OemRevision:     2,
+		CreatorId:       "INTL",
+		CreatorRevision: 0x1000013,
+		CcType:          CC_TYPE_TDX,
+		CcSubtype:       0,
+		Laml:            0x10000,
+		Lasa:            0x7e000000,
+	}
+	if table != expected {
+		t.Fatalf("ParseCcelTable() = %+v, want %+v", table, expected)
+	}
+}
+
+func TestParseCcelTableInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     func() []byte
+		expected error
+	}{
+		{"empty", func() []byte { return []byte{} }, InvalidCcelTableErr},
+		{"invalidSignature", func() []byte {
+			data := buildCcelTable(CC_TYPE_TDX, 0x10000, 0x7e000000)
+			copy(data[0:4], "FACP")
+			return data
+		}, InvalidCcelTableErr},
+		{"truncated", func() []byte { return buildCcelTable(CC_TYPE_TDX, 0x10000, 0x7e000000)[:40] }, CcelTableLengthErr},
+		{"lengthMismatch", func() []byte {
+			data := buildCcelTable(CC_TYPE_TDX, 0x10000, 0x7e000000)
+			data = append(data, 0)
+			updateCcelTableChecksum(data)
+			return data
+		}, CcelTableLengthErr},
+		{"tampered", func() []byte {
+			data := buildCcelTable(CC_TYPE_TDX, 0x10000, 0x7e000000)
+			data[40] ^= 0x1
+			return data
+		}, CcelTableChecksumErr},
+		{"sevTable", func() []byte { return buildCcelTable(CC_TYPE_SEV, 0x10000, 0x7e000000) }, UnsupportedCcTypeErr},
+		{"emptyLogArea", func() []byte { return buildCcelTable(CC_TYPE_TDX, 0, 0x7e000000) }, CcelLogAreaErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCcelTable(tt.data())
+			if err != tt.expected {
+				t.Fatalf("ParseCcelTable() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCcelTableValidateEventlogData(t *testing.T) {
+	table := CcelTable{Laml: 16, Lasa: 0x7e000000}
+
+	if err := table.ValidateEventlogData(make([]byte, 16)); err != nil {
+		t.Fatalf("ValidateEventlogData() returned error: %v", err)
+	}
+
+	if err := table.ValidateEventlogData(make([]byte, 8)); err != CcelLogAreaErr {
+		t.Fatalf("ValidateEventlogData() error = %v, want %v", err, CcelLogAreaErr)
+	}
+}
+
+func TestMarshalEventlogsWithCcelTable(t *testing.T) {
+	table, err := ParseCcelTable(buildCcelTable(CC_TYPE_TDX, 0x10000, 0x7e000000))
+	if err != nil {
+		t.Fatalf("ParseCcelTable() returned error: %v", err)
+	}
+
+	eventlogs := getSampleEventlogs()
+	eventlogs.CcelTable = &table
+
+	data, err := MarshalEventlogs(eventlogs, false)
+	if err != nil || !strings.Contains(data, `"ccel_table":{"signature":"CCEL"`) {
+		t.Fatalf("MarshalEventlogs() = %s, %v want CCEL table", data, err)
+	}
+
+	parsed, err := UnmarshalEventlogs([]byte(data))
+	if err != nil || parsed.CcelTable == nil || *parsed.CcelTable != table {
+		t.Fatalf("UnmarshalEventlogs() = %+v, %v want CCEL table %+v", parsed.CcelTable, err, table)
+	}
+}
@@ -0,0 +1,640 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/*
+The Canonical Event Log (CEL) is defined in the TCG Canonical Event Log Format specification
+at https://trustedcomputinggroup.org/resource/canonical-event-log-format/
+Every event is a record with its number, the register it was extended to, its digests and
+its content. The events of TDX and TPM logs have PCCLIENT_STD content, the events of the IMA
+log have IMA_TEMPLATE content. The register of TDX events is the register index of the CCEL
+event, it is encoded as the PCR of the record. The Spec ID event of the log header is not
+a record of the CEL.
+
+The same types identify the fields in the TLV encoding and the keys of the CBOR encoding.
+*/
+const (
+	CEL_TYPE_RECNUM       = 0
+	CEL_TYPE_PCR          = 1
+	CEL_TYPE_NV_INDEX     = 2
+	CEL_TYPE_DIGESTS      = 3
+	CEL_TYPE_MGT          = 4
+	CEL_TYPE_PCCLIENT_STD = 5
+	CEL_TYPE_IMA_TEMPLATE = 7
+
+	// Fields of the PCCLIENT_STD content
+	CEL_PCCLIENT_STD_EVENT_TYPE = 0
+	CEL_PCCLIENT_STD_EVENT_DATA = 1
+	// Fields of the IMA_TEMPLATE content
+	CEL_IMA_TEMPLATE_NAME = 0
+	CEL_IMA_TEMPLATE_DATA = 1
+
+	CEL_CONTENT_PCCLIENT_STD = "pcclient_std"
+	CEL_CONTENT_IMA_TEMPLATE = "ima_template"
+
+	// Type and length of a TLV field
+	CEL_TLV_HEADER_SIZE = 5
+)
+
+// The formats match the FORMAT values of the GetEventlogRequest
+const (
+	CEL_FORMAT_JSON = 1
+	CEL_FORMAT_CBOR = 2
+	CEL_FORMAT_TLV  = 3
+)
+
+var (
+	InvalidCelErr           = pkgerrors.New("Invalid canonical event log")
+	UnsupportedCelFormatErr = pkgerrors.New("Unsupported canonical event log format")
+)
+
+type CelDigest struct {
+	AlgorithmId uint16
+	Digest      []byte
+}
+
+type CelRecord struct {
+	RecNum      uint64
+	Pcr         uint32
+	Digests     []CelDigest
+	ContentType uint8
+	// PCCLIENT_STD content
+	EventType uint32
+	EventData []byte
+	// IMA_TEMPLATE content
+	TemplateName string
+	TemplateData []byte
+}
+
+// GetCelRecords converts the TDX or TPM events into CEL records numbered from start.
+func GetCelRecords(eventlogs []TDEventLog, start int) []CelRecord {
+	records := []CelRecord{}
+	for i, eventlog := range eventlogs {
+		record := CelRecord{
+			RecNum:      uint64(start + i),
+			Pcr:         eventlog.Rtmr,
+			ContentType: CEL_TYPE_PCCLIENT_STD,
+			EventType:   eventlog.Etype,
+			EventData:   eventlog.Event,
+		}
+		for _, digest := range eventlog.Digests {
+			record.Digests = append(record.Digests, CelDigest{AlgorithmId: digest.AlgorithmId, Digest: digest.Digest})
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// GetImaCelRecords converts the IMA events into CEL records numbered from start, the
+// digest of a record is the SHA1 template hash of the event.
+func GetImaCelRecords(eventlogs []ImaEventLog, start int) []CelRecord {
+	records := []CelRecord{}
+	for i, eventlog := range eventlogs {
+		records = append(records, CelRecord{
+			RecNum:       uint64(start + i),
+			Pcr:          eventlog.PcrIndex,
+			Digests:      []CelDigest{{AlgorithmId: TPM_ALG_SHA1, Digest: eventlog.TemplateHash}},
+			ContentType:  CEL_TYPE_IMA_TEMPLATE,
+			TemplateName: eventlog.TemplateName,
+			TemplateData: eventlog.TemplateData,
+		})
+	}
+	return records
+}
+
+// GetEventlogsFromCel converts CEL records with PCCLIENT_STD content back into events.
+func GetEventlogsFromCel(records []CelRecord) ([]TDEventLog, error) {
+	eventlogs := []TDEventLog{}
+	for _, record := range records {
+		if record.ContentType != CEL_TYPE_PCCLIENT_STD {
+			log.Printf("Unsupported content type %d of CEL record %d", record.ContentType, record.RecNum)
+			return nil, InvalidCelErr
+		}
+
+		eventlog := TDEventLog{
+			Rtmr:        record.Pcr,
+			Etype:       record.EventType,
+			DigestCount: uint32(len(record.Digests)),
+			EventSize:   uint32(len(record.EventData)),
+			Event:       record.EventData,
+		}
+		for _, digest := range record.Digests {
+			eventlog.Digests = append(eventlog.Digests, TDEventLogDigest{AlgorithmId: digest.AlgorithmId, Digest: digest.Digest})
+		}
+		eventlogs = append(eventlogs, eventlog)
+	}
+	return eventlogs, nil
+}
+
+func MarshalCel(records []CelRecord, format int) ([]byte, error) {
+	switch format {
+	case CEL_FORMAT_JSON:
+		return marshalCelJson(records)
+	case CEL_FORMAT_CBOR:
+		return marshalCelCbor(records)
+	case CEL_FORMAT_TLV:
+		return marshalCelTlv(records)
+	}
+	log.Println("Unsupported CEL format", format)
+	return nil, UnsupportedCelFormatErr
+}
+
+func UnmarshalCel(data []byte, format int) ([]CelRecord, error) {
+	switch format {
+	case CEL_FORMAT_JSON:
+		return unmarshalCelJson(data)
+	case CEL_FORMAT_CBOR:
+		return unmarshalCelCbor(data)
+	case CEL_FORMAT_TLV:
+		return unmarshalCelTlv(data)
+	}
+	log.Println("Unsupported CEL format", format)
+	return nil, UnsupportedCelFormatErr
+}
+
+/* CEL-JSON, the digest algorithms are named in lower case and the content data in base64 */
+type celJsonRecord struct {
+	RecNum      uint64          `json:"recnum"`
+	Pcr         uint32          `json:"pcr"`
+	Digests     []celJsonDigest `json:"digests"`
+	ContentType string          `json:"content_type"`
+	Content     json.RawMessage `json:"content"`
+}
+
+type celJsonDigest struct {
+	HashAlg string `json:"hashAlg"`
+	Digest  string `json:"digest"`
+}
+
+type celJsonPcClientStd struct {
+	EventType uint32 `json:"event_type"`
+	EventData string `json:"event_data"`
+}
+
+type celJsonImaTemplate struct {
+	TemplateName string `json:"template_name"`
+	TemplateData string `json:"template_data"`
+}
+
+func getCelAlgorithmName(algId uint16) (string, error) {
+	name, ok := algorithmNames[algId]
+	if !ok {
+		log.Printf("No CEL name for algorithm 0x%04X", algId)
+		return "", UnknownAlgorithmErr
+	}
+	return strings.ToLower(name), nil
+}
+
+func getCelAlgorithmId(name string) (uint16, error) {
+	for algId, algName := range algorithmNames {
+		if strings.ToLower(algName) == name {
+			return algId, nil
+		}
+	}
+	log.Println("Unknown CEL algorithm", name)
+	return 0, UnknownAlgorithmErr
+}
+
+func marshalCelJson(records []CelRecord) ([]byte, error) {
+	jsonRecords := []celJsonRecord{}
+	for _, record := range records {
+		jsonRecord := celJsonRecord{RecNum: record.RecNum, Pcr: record.Pcr, Digests: []celJsonDigest{}}
+		for _, digest := range record.Digests {
+			name, err := getCelAlgorithmName(digest.AlgorithmId)
+			if err != nil {
+				return nil, err
+			}
+			jsonRecord.Digests = append(jsonRecord.Digests, celJsonDigest{HashAlg: name, Digest: hex.EncodeToString(digest.Digest)})
+		}
+
+		var content interface{}
+		switch record.ContentType {
+		case CEL_TYPE_PCCLIENT_STD:
+			jsonRecord.ContentType = CEL_CONTENT_PCCLIENT_STD
+			content = celJsonPcClientStd{EventType: record.EventType, EventData: base64.StdEncoding.EncodeToString(record.EventData)}
+		case CEL_TYPE_IMA_TEMPLATE:
+			jsonRecord.ContentType = CEL_CONTENT_IMA_TEMPLATE
+			content = celJsonImaTemplate{TemplateName: record.TemplateName, TemplateData: base64.StdEncoding.EncodeToString(record.TemplateData)}
+		default:
+			return nil, InvalidCelErr
+		}
+
+		var err error
+		if jsonRecord.Content, err = json.Marshal(content); err != nil {
+			return nil, err
+		}
+		jsonRecords = append(jsonRecords, jsonRecord)
+	}
+
+	return json.Marshal(jsonRecords)
+}
+
+func unmarshalCelJson(data []byte) ([]CelRecord, error) {
+	var jsonRecords []celJsonRecord
+	if err := json.Unmarshal(data, &jsonRecords); err != nil {
+		return nil, pkgerrors.WithMessage(InvalidCelErr, err.Error())
+	}
+
+	records := []CelRecord{}
+	for _, jsonRecord := range jsonRecords {
+		record := CelRecord{RecNum: jsonRecord.RecNum, Pcr: jsonRecord.Pcr}
+		for _, jsonDigest := range jsonRecord.Digests {
+			algId, err := getCelAlgorithmId(jsonDigest.HashAlg)
+			if err != nil {
+				return nil, err
+			}
+			digest, err := hex.DecodeString(jsonDigest.Digest)
+			if err != nil {
+				return nil, InvalidCelErr
+			}
+			record.Digests = append(record.Digests, CelDigest{AlgorithmId: algId, Digest: digest})
+		}
+
+		var err error
+		switch jsonRecord.ContentType {
+		case CEL_CONTENT_PCCLIENT_STD:
+			content := celJsonPcClientStd{}
+			if err = json.Unmarshal(jsonRecord.Content, &content); err == nil {
+				record.ContentType = CEL_TYPE_PCCLIENT_STD
+				record.EventType = content.EventType
+				record.EventData, err = base64.StdEncoding.DecodeString(content.EventData)
+			}
+		case CEL_CONTENT_IMA_TEMPLATE:
+			content := celJsonImaTemplate{}
+			if err = json.Unmarshal(jsonRecord.Content, &content); err == nil {
+				record.ContentType = CEL_TYPE_IMA_TEMPLATE
+				record.TemplateName = content.TemplateName
+				record.TemplateData, err = base64.StdEncoding.DecodeString(content.TemplateData)
+			}
+		default:
+			log.Println("Unsupported CEL content type", jsonRecord.ContentType)
+			err = InvalidCelErr
+		}
+		if err != nil {
+			return nil, pkgerrors.WithMessagef(InvalidCelErr, "Record %d: %v", jsonRecord.RecNum, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+/*
+CEL-CBOR, the log is an array of records. Every record is a map keyed by the CEL types:
+the record number, the PCR, the digests as a map of algorithm ID to digest, and the content
+as a map keyed by the fields of its content type.
+*/
+func marshalCelCbor(records []CelRecord) ([]byte, error) {
+	w := &cborWriter{}
+	w.writeArrayHeader(len(records))
+	for _, record := range records {
+		w.writeMapHeader(4)
+		w.writeUint(CEL_TYPE_RECNUM)
+		w.writeUint(record.RecNum)
+		w.writeUint(CEL_TYPE_PCR)
+		w.writeUint(uint64(record.Pcr))
+
+		w.writeUint(CEL_TYPE_DIGESTS)
+		w.writeMapHeader(len(record.Digests))
+		for _, digest := range record.Digests {
+			w.writeUint(uint64(digest.AlgorithmId))
+			w.writeBytes(digest.Digest)
+		}
+
+		w.writeUint(uint64(record.ContentType))
+		w.writeMapHeader(2)
+		switch record.ContentType {
+		case CEL_TYPE_PCCLIENT_STD:
+			w.writeUint(CEL_PCCLIENT_STD_EVENT_TYPE)
+			w.writeUint(uint64(record.EventType))
+			w.writeUint(CEL_PCCLIENT_STD_EVENT_DATA)
+			w.writeBytes(record.EventData)
+		case CEL_TYPE_IMA_TEMPLATE:
+			w.writeUint(CEL_IMA_TEMPLATE_NAME)
+			w.writeString(record.TemplateName)
+			w.writeUint(CEL_IMA_TEMPLATE_DATA)
+			w.writeBytes(record.TemplateData)
+		default:
+			return nil, InvalidCelErr
+		}
+	}
+
+	return w.buf.Bytes(), nil
+}
+
+func unmarshalCelCbor(data []byte) ([]CelRecord, error) {
+	r := &cborReader{data: data}
+
+	count, err := r.readArrayHeader()
+	if err != nil {
+		return nil, pkgerrors.WithMessage(InvalidCelErr, err.Error())
+	}
+
+	records := []CelRecord{}
+	for i := 0; i < count; i++ {
+		record, err := readCelCborRecord(r)
+		if err != nil {
+			return nil, pkgerrors.WithMessagef(InvalidCelErr, "Record %d: %v", i, err)
+		}
+		records = append(records, record)
+	}
+
+	if !r.done() {
+		return nil, pkgerrors.WithMessage(InvalidCelErr, "Trailing data after the records")
+	}
+	return records, nil
+}
+
+func readCelCborRecord(r *cborReader) (CelRecord, error) {
+	record := CelRecord{}
+
+	size, err := r.readMapHeader()
+	if err != nil {
+		return CelRecord{}, err
+	}
+
+	var value uint64
+	for i := 0; i < size; i++ {
+		key, err := r.readUint()
+		if err != nil {
+			return CelRecord{}, err
+		}
+
+		switch key {
+		case CEL_TYPE_RECNUM:
+			record.RecNum, err = r.readUint()
+		case CEL_TYPE_PCR:
+			if value, err = r.readUint(); err == nil && value > 0xFFFFFFFF {
+				err = InvalidCborErr
+			}
+			record.Pcr = uint32(value)
+		case CEL_TYPE_DIGESTS:
+			record.Digests, err = readCelCborDigests(r)
+		case CEL_TYPE_PCCLIENT_STD, CEL_TYPE_IMA_TEMPLATE:
+			record.ContentType = uint8(key)
+			err = readCelCborContent(r, &record)
+		default:
+			err = pkgerrors.Errorf("Unsupported field %d", key)
+		}
+		if err != nil {
+			return CelRecord{}, err
+		}
+	}
+
+	if record.ContentType == 0 {
+		return CelRecord{}, pkgerrors.New("Missing content")
+	}
+	return record, nil
+}
+
+func readCelCborDigests(r *cborReader) ([]CelDigest, error) {
+	size, err := r.readMapHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	digests := []CelDigest{}
+	for i := 0; i < size; i++ {
+		algId, err := r.readUint()
+		if err != nil || algId > 0xFFFF {
+			return nil, InvalidCborErr
+		}
+		digest, err := r.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		digests = append(digests, CelDigest{AlgorithmId: uint16(algId), Digest: digest})
+	}
+	return digests, nil
+}
+
+func readCelCborContent(r *cborReader, record *CelRecord) error {
+	size, err := r.readMapHeader()
+	if err != nil {
+		return err
+	}
+
+	var value uint64
+	for i := 0; i < size; i++ {
+		field, err := r.readUint()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case record.ContentType == CEL_TYPE_PCCLIENT_STD && field == CEL_PCCLIENT_STD_EVENT_TYPE:
+			if value, err = r.readUint(); err == nil && value > 0xFFFFFFFF {
+				err = InvalidCborErr
+			}
+			record.EventType = uint32(value)
+		case record.ContentType == CEL_TYPE_PCCLIENT_STD && field == CEL_PCCLIENT_STD_EVENT_DATA:
+			record.EventData, err = r.readBytes()
+		case record.ContentType == CEL_TYPE_IMA_TEMPLATE && field == CEL_IMA_TEMPLATE_NAME:
+			record.TemplateName, err = r.readString()
+		case record.ContentType == CEL_TYPE_IMA_TEMPLATE && field == CEL_IMA_TEMPLATE_DATA:
+			record.TemplateData, err = r.readBytes()
+		default:
+			err = pkgerrors.Errorf("Unsupported content field %d", field)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+CEL-TLV, every field is a 1 byte type, a 4 bytes big endian length and the value. A record
+is its RECNUM, PCR, DIGESTS and content fields. The DIGESTS value holds a field per digest
+with the algorithm ID as type, the content value holds a field per content field. Integers
+are big endian.
+*/
+func writeCelTlv(buf *bytes.Buffer, ftype uint8, value []byte) {
+	buf.WriteByte(ftype)
+	binary.Write(buf, binary.BigEndian, uint32(len(value)))
+	buf.Write(value)
+}
+
+func writeCelTlvUint(buf *bytes.Buffer, ftype uint8, value uint64, size int) {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, value)
+	writeCelTlv(buf, ftype, data[8-size:])
+}
+
+func marshalCelTlv(records []CelRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, record := range records {
+		writeCelTlvUint(&buf, CEL_TYPE_RECNUM, record.RecNum, 8)
+		writeCelTlvUint(&buf, CEL_TYPE_PCR, uint64(record.Pcr), 4)
+
+		var digests bytes.Buffer
+		for _, digest := range record.Digests {
+			/* the type of a field is a single byte */
+			if digest.AlgorithmId > 0xFF {
+				log.Printf("Algorithm 0x%04X cannot be encoded in CEL-TLV", digest.AlgorithmId)
+				return nil, UnknownAlgorithmErr
+			}
+			writeCelTlv(&digests, uint8(digest.AlgorithmId), digest.Digest)
+		}
+		writeCelTlv(&buf, CEL_TYPE_DIGESTS, digests.Bytes())
+
+		var content bytes.Buffer
+		switch record.ContentType {
+		case CEL_TYPE_PCCLIENT_STD:
+			writeCelTlvUint(&content, CEL_PCCLIENT_STD_EVENT_TYPE, uint64(record.EventType), 4)
+			writeCelTlv(&content, CEL_PCCLIENT_STD_EVENT_DATA, record.EventData)
+		case CEL_TYPE_IMA_TEMPLATE:
+			writeCelTlv(&content, CEL_IMA_TEMPLATE_NAME, []byte(record.TemplateName))
+			writeCelTlv(&content, CEL_IMA_TEMPLATE_DATA, record.TemplateData)
+		default:
+			return nil, InvalidCelErr
+		}
+		writeCelTlv(&buf, record.ContentType, content.Bytes())
+	}
+
+	return buf.Bytes(), nil
+}
+
+func readCelTlv(data []byte, index int) (uint8, []byte, int, error) {
+	if CEL_TLV_HEADER_SIZE > len(data)-index {
+		return 0, nil, 0, InvalidCelErr
+	}
+	ftype := data[index]
+	size := binary.BigEndian.Uint32(data[index+1 : index+CEL_TLV_HEADER_SIZE])
+	index += CEL_TLV_HEADER_SIZE
+	if uint64(size) > uint64(len(data)-index) {
+		return 0, nil, 0, InvalidCelErr
+	}
+	return ftype, data[index : index+int(size)], index + int(size), nil
+}
+
+func getCelTlvUint(value []byte, maxSize int) (uint64, error) {
+	if len(value) == 0 || len(value) > maxSize {
+		return 0, InvalidCelErr
+	}
+	var result uint64
+	for _, b := range value {
+		result = result<<8 | uint64(b)
+	}
+	return result, nil
+}
+
+func unmarshalCelTlv(data []byte) ([]CelRecord, error) {
+	records := []CelRecord{}
+
+	var record *CelRecord
+	index := 0
+	for index < len(data) {
+		start := index
+		ftype, value, next, err := readCelTlv(data, index)
+		if err != nil {
+			return nil, getEventOffsetErr(err, len(records), start)
+		}
+		index = next
+
+		/* a record starts with its number */
+		if ftype == CEL_TYPE_RECNUM {
+			if record != nil {
+				if err = checkCelTlvRecord(record); err != nil {
+					return nil, getEventOffsetErr(err, len(records), start)
+				}
+				records = append(records, *record)
+			}
+			record = &CelRecord{}
+			record.RecNum, err = getCelTlvUint(value, 8)
+		} else if record == nil {
+			err = InvalidCelErr
+		} else {
+			err = parseCelTlvField(record, ftype, value)
+		}
+		if err != nil {
+			return nil, getEventOffsetErr(err, len(records), start)
+		}
+	}
+
+	if record != nil {
+		if err := checkCelTlvRecord(record); err != nil {
+			return nil, getEventOffsetErr(err, len(records), index)
+		}
+		records = append(records, *record)
+	}
+	return records, nil
+}
+
+func checkCelTlvRecord(record *CelRecord) error {
+	if record.ContentType == 0 {
+		log.Println("Missing content of CEL record", record.RecNum)
+		return InvalidCelErr
+	}
+	return nil
+}
+
+func parseCelTlvField(record *CelRecord, ftype uint8, value []byte) error {
+	var err error
+	var number uint64
+
+	switch ftype {
+	case CEL_TYPE_PCR:
+		number, err = getCelTlvUint(value, 4)
+		record.Pcr = uint32(number)
+	case CEL_TYPE_DIGESTS:
+		record.Digests = []CelDigest{}
+		for index := 0; index < len(value) && err == nil; {
+			var algId uint8
+			var digest []byte
+			algId, digest, index, err = readCelTlv(value, index)
+			record.Digests = append(record.Digests, CelDigest{AlgorithmId: uint16(algId), Digest: digest})
+		}
+	case CEL_TYPE_PCCLIENT_STD, CEL_TYPE_IMA_TEMPLATE:
+		record.ContentType = ftype
+		for index := 0; index < len(value) && err == nil; {
+			var field uint8
+			var fieldValue []byte
+			if field, fieldValue, index, err = readCelTlv(value, index); err != nil {
+				break
+			}
+			err = parseCelTlvContentField(record, field, fieldValue)
+		}
+	default:
+		log.Println("Unsupported CEL-TLV field type", ftype)
+		err = InvalidCelErr
+	}
+	return err
+}
+
+func parseCelTlvContentField(record *CelRecord, field uint8, value []byte) error {
+	var err error
+	var number uint64
+
+	switch {
+	case record.ContentType == CEL_TYPE_PCCLIENT_STD && field == CEL_PCCLIENT_STD_EVENT_TYPE:
+		number, err = getCelTlvUint(value, 4)
+		record.EventType = uint32(number)
+	case record.ContentType == CEL_TYPE_PCCLIENT_STD && field == CEL_PCCLIENT_STD_EVENT_DATA:
+		record.EventData = value
+	case record.ContentType == CEL_TYPE_IMA_TEMPLATE && field == CEL_IMA_TEMPLATE_NAME:
+		record.TemplateName = string(value)
+	case record.ContentType == CEL_TYPE_IMA_TEMPLATE && field == CEL_IMA_TEMPLATE_DATA:
+		record.TemplateData = value
+	default:
+		log.Println("Unsupported CEL-TLV content field", field)
+		err = InvalidCelErr
+	}
+	return err
+}
@@ -0,0 +1,222 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func buildCelRecords(t *testing.T) []CelRecord {
+	eventlogs := buildFilterEventlogs()
+	eventlogs.EventLogs[0].Event = []byte("SecureBoot")
+	records := GetCelRecords(eventlogs.EventLogs, 0)
+
+	imaEventlogs, err := parseImaBinaryEventlogs(buildImaBinaryEventlog())
+	if err != nil {
+		t.Fatalf("parseImaBinaryEventlogs returned error: %v", err)
+	}
+	return append(records, GetImaCelRecords(imaEventlogs, len(records))...)
+}
+
+func TestGetCelRecords(t *testing.T) {
+	records := buildCelRecords(t)
+	if len(records) != 10 {
+		t.Fatalf("Record count -> Want: 10, Got: %d", len(records))
+	}
+
+	for i, record := range records {
+		if record.RecNum != uint64(i) {
+			t.Errorf("RecNum of record %d -> Want: %d, Got: %d", i, i, record.RecNum)
+		}
+	}
+
+	tdx := records[0]
+	if tdx.ContentType != CEL_TYPE_PCCLIENT_STD || tdx.EventType != EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG ||
+		string(tdx.EventData) != "SecureBoot" || len(tdx.Digests) != 2 {
+		t.Errorf("Unexpected TDX record %+v", tdx)
+	}
+
+	ima := records[7]
+	if ima.ContentType != CEL_TYPE_IMA_TEMPLATE || ima.Pcr != 10 || ima.TemplateName != IMA_TEMPLATE_IMA_NG ||
+		len(ima.Digests) != 1 || ima.Digests[0].AlgorithmId != TPM_ALG_SHA1 || len(ima.TemplateData) == 0 {
+		t.Errorf("Unexpected IMA record %+v", ima)
+	}
+}
+
+func TestMarshalCelRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		format int
+	}{
+		{"CEL-JSON", CEL_FORMAT_JSON},
+		{"CEL-CBOR", CEL_FORMAT_CBOR},
+		{"CEL-TLV", CEL_FORMAT_TLV},
+	}
+
+	records := buildCelRecords(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalCel(records, tt.format)
+			if err != nil {
+				t.Fatalf("MarshalCel returned error: %v", err)
+			}
+
+			decoded, err := UnmarshalCel(data, tt.format)
+			if err != nil {
+				t.Fatalf("UnmarshalCel returned error: %v", err)
+			}
+			if len(decoded) != len(records) {
+				t.Fatalf("Record count -> Want: %d, Got: %d", len(records), len(decoded))
+			}
+			for i := range records {
+				want, got := records[i], decoded[i]
+				if got.RecNum != want.RecNum || got.Pcr != want.Pcr || got.ContentType != want.ContentType ||
+					got.EventType != want.EventType || !bytes.Equal(got.EventData, want.EventData) ||
+					got.TemplateName != want.TemplateName || !bytes.Equal(got.TemplateData, want.TemplateData) ||
+					!reflect.DeepEqual(got.Digests, want.Digests) {
+					t.Errorf("Record %d -> Want: %+v, Got: %+v", i, want, got)
+				}
+			}
+		})
+	}
+
+	if _, err := MarshalCel(records, 0); err != UnsupportedCelFormatErr {
+		t.Errorf("Err -> Want: %v, Got: %v", UnsupportedCelFormatErr, err)
+	}
+}
+
+func TestMarshalCelJson(t *testing.T) {
+	records := []CelRecord{{
+		RecNum:      3,
+		Pcr:         1,
+		Digests:     []CelDigest{{AlgorithmId: TPM_ALG_SHA384, Digest: []byte{0xab, 0xcd}}},
+		ContentType: CEL_TYPE_PCCLIENT_STD,
+		EventType:   EVENT_TYPE_EV_SEPARATOR,
+		EventData:   []byte{0, 0, 0, 0},
+	}}
+
+	data, err := MarshalCel(records, CEL_FORMAT_JSON)
+	if err != nil {
+		t.Fatalf("MarshalCel returned error: %v", err)
+	}
+
+	var got, want interface{}
+	json.Unmarshal(data, &got)
+	json.Unmarshal([]byte(`[{"recnum":3,"pcr":1,"digests":[{"hashAlg":"sha384","digest":"abcd"}],
+		"content_type":"pcclient_std","content":{"event_type":4,"event_data":"AAAAAA=="}}]`), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CEL-JSON -> Want: %v, Got: %s", want, data)
+	}
+}
+
+func TestMarshalCelCbor(t *testing.T) {
+	records := []CelRecord{{
+		RecNum:       1,
+		Pcr:          10,
+		Digests:      []CelDigest{{AlgorithmId: TPM_ALG_SHA1, Digest: []byte{0xaa}}},
+		ContentType:  CEL_TYPE_IMA_TEMPLATE,
+		TemplateName: "ima",
+		TemplateData: []byte{0x1},
+	}}
+
+	data, err := MarshalCel(records, CEL_FORMAT_CBOR)
+	if err != nil {
+		t.Fatalf("MarshalCel returned error: %v", err)
+	}
+
+	/* [{0: 1, 1: 10, 3: {4: h'aa'}, 7: {0: "ima", 1: h'01'}}] */
+	want := []byte{0x81, 0xa4, 0x00, 0x01, 0x01, 0x0a, 0x03, 0xa1, 0x04, 0x41, 0xaa,
+		0x07, 0xa2, 0x00, 0x63, 'i', 'm', 'a', 0x01, 0x41, 0x01}
+	if !bytes.Equal(data, want) {
+		t.Errorf("CEL-CBOR -> Want: %x, Got: %x", want, data)
+	}
+}
+
+func TestUnmarshalCelInvalidData(t *testing.T) {
+	tests := []struct {
+		name   string
+		format int
+		data   []byte
+	}{
+		{"CEL-JSON not an array", CEL_FORMAT_JSON, []byte(`{"recnum": 0}`)},
+		{"CEL-JSON unknown algorithm", CEL_FORMAT_JSON, []byte(`[{"recnum":0,"digests":[{"hashAlg":"md5","digest":"00"}],"content_type":"pcclient_std","content":{}}]`)},
+		{"CEL-JSON unknown content", CEL_FORMAT_JSON, []byte(`[{"recnum":0,"digests":[],"content_type":"systemd","content":{}}]`)},
+		{"CEL-JSON invalid event data", CEL_FORMAT_JSON, []byte(`[{"recnum":0,"digests":[],"content_type":"pcclient_std","content":{"event_data":"!"}}]`)},
+		{"CEL-CBOR not an array", CEL_FORMAT_CBOR, []byte{0xa0}},
+		{"CEL-CBOR truncated", CEL_FORMAT_CBOR, []byte{0x81, 0xa4, 0x00}},
+		{"CEL-CBOR forged array size", CEL_FORMAT_CBOR, []byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"CEL-CBOR indefinite length", CEL_FORMAT_CBOR, []byte{0x9f, 0xff}},
+		{"CEL-CBOR missing content", CEL_FORMAT_CBOR, []byte{0x81, 0xa1, 0x00, 0x01}},
+		{"CEL-CBOR trailing data", CEL_FORMAT_CBOR, []byte{0x80, 0x00}},
+		{"CEL-TLV truncated header", CEL_FORMAT_TLV, []byte{0x00, 0x00, 0x00}},
+		{"CEL-TLV forged length", CEL_FORMAT_TLV, []byte{0x00, 0xff, 0xff, 0xff, 0xff, 0x00}},
+		{"CEL-TLV no record number", CEL_FORMAT_TLV, []byte{0x01, 0x00, 0x00, 0x00, 0x01, 0x00}},
+		{"CEL-TLV missing content", CEL_FORMAT_TLV, []byte{0x00, 0x00, 0x00, 0x00, 0x01, 0x00}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnmarshalCel(tt.data, tt.format)
+			if pkgerrors.Cause(err) != InvalidCelErr && pkgerrors.Cause(err) != UnknownAlgorithmErr {
+				t.Errorf("Err -> Want: %v, Got: %v", InvalidCelErr, err)
+			}
+		})
+	}
+}
+
+func TestGetEventlogsFromCel(t *testing.T) {
+	eventlogs := buildFilterEventlogs().EventLogs
+	records := GetCelRecords(eventlogs, 0)
+
+	decoded, err := GetEventlogsFromCel(records)
+	if err != nil || len(decoded) != len(eventlogs) {
+		t.Fatalf("GetEventlogsFromCel() = %d, %v want %d events", len(decoded), err, len(eventlogs))
+	}
+	for i := range eventlogs {
+		if decoded[i].Rtmr != eventlogs[i].Rtmr || decoded[i].Etype != eventlogs[i].Etype ||
+			decoded[i].DigestCount != eventlogs[i].DigestCount || !reflect.DeepEqual(decoded[i].Digests, eventlogs[i].Digests) {
+			t.Errorf("Event %d -> Want: %+v, Got: %+v", i, eventlogs[i], decoded[i])
+		}
+	}
+
+	records = append(records, CelRecord{ContentType: CEL_TYPE_IMA_TEMPLATE})
+	if _, err := GetEventlogsFromCel(records); err != InvalidCelErr {
+		t.Errorf("Err -> Want: %v, Got: %v", InvalidCelErr, err)
+	}
+}
+
+/* The CEL decoders must never panic on untrusted input, run with go test ./resources -fuzz=FuzzUnmarshalCel */
+func FuzzUnmarshalCel(f *testing.F) {
+	records := []CelRecord{{RecNum: 1, Pcr: 2, Digests: []CelDigest{{AlgorithmId: TPM_ALG_SHA384, Digest: []byte{0x1}}},
+		ContentType: CEL_TYPE_PCCLIENT_STD, EventType: EVENT_TYPE_EV_IPL, EventData: []byte("grub")}}
+	for _, format := range []int{CEL_FORMAT_JSON, CEL_FORMAT_CBOR, CEL_FORMAT_TLV} {
+		data, _ := MarshalCel(records, format)
+		f.Add(data, format)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, format int) {
+		records, err := UnmarshalCel(data, format)
+		if err != nil {
+			return
+		}
+
+		/* the decoded records encode again, unless an algorithm has no name or TLV type */
+		encoded, err := MarshalCel(records, format)
+		if err != nil && err != UnknownAlgorithmErr {
+			t.Fatalf("MarshalCel() of decoded records returned error: %v", err)
+		}
+		if err == nil {
+			if decoded, err := UnmarshalCel(encoded, format); err != nil || len(decoded) != len(records) {
+				t.Fatalf("UnmarshalCel() of encoded records = %d, %v want %d records", len(decoded), err, len(records))
+			}
+		}
+	})
+}
@@ -0,0 +1,360 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bufio"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/*
+The container event log is a runtime event log kept by the eventlog server. The container
+runtime records the start of every container along with the digests of its image, its
+config and its mounts, so that verifiers can check what ran inside each pod. Every event
+is measured with SHA384, the same algorithm as the RTMRs, and anchored in RTMR 3 through the
+measurement server, so that the replay of the TDX event log verifies it.
+*/
+const (
+	CONTAINER_EVENT_START  = 0
+	CONTAINER_EVENT_IMAGE  = 1
+	CONTAINER_EVENT_CONFIG = 2
+	CONTAINER_EVENT_MOUNT  = 3
+
+	CONTAINER_EVENTLOG_VERSION = "1.0"
+	// Events are digests and short descriptions, not the image or the config themselves
+	MAX_CONTAINER_EVENT_SIZE = 64 * 1024
+	MAX_CONTAINER_ID_LEN     = 256
+
+	// The register extended with the anchors of the container events
+	CONTAINER_EVENT_RTMR_INDEX    = 3
+	CONTAINER_EVENT_ANCHOR_PREFIX = "ccnp-container-event"
+)
+
+var (
+	InvalidContainerEventErr     = pkgerrors.New("Invalid container event")
+	ContainerEventlogNotFoundErr = pkgerrors.New("Container eventlog not found.")
+	ContainerEventNotAnchoredErr = pkgerrors.New("Container event not anchored in RTMR 3")
+	ContainerEventExtenderErr    = pkgerrors.New("No extender to anchor container events in RTMR 3")
+)
+
+var containerEventTypeNames = map[uint32]string{
+	CONTAINER_EVENT_START:  "CONTAINER_START",
+	CONTAINER_EVENT_IMAGE:  "CONTAINER_IMAGE",
+	CONTAINER_EVENT_CONFIG: "CONTAINER_CONFIG",
+	CONTAINER_EVENT_MOUNT:  "CONTAINER_MOUNT",
+}
+
+type ContainerEventLog struct {
+	// Sequence is the position of the event in the log of all containers
+	Sequence      uint32    `json:"sequence"`
+	ContainerId   string    `json:"container_id"`
+	PodId         string    `json:"pod_id,omitempty"`
+	EventType     uint32    `json:"event_type"`
+	EventTypeName string    `json:"event_type_name"`
+	Timestamp     time.Time `json:"timestamp"`
+	AlgorithmId   uint16    `json:"algorithm_id"`
+	Digest        HexBytes  `json:"digest"`
+	Event         []byte    `json:"event"`
+}
+
+type ContainerEventLogs struct {
+	Version   string              `json:"version"`
+	EventLogs []ContainerEventLog `json:"eventlogs"`
+	// TotalCount is the number of events of the container before the range is applied
+	TotalCount int `json:"-"`
+}
+
+// GetContainerEventTypeName returns the name of the container event type, or its value in hex if unknown.
+func GetContainerEventTypeName(etype uint32) string {
+	if name, ok := containerEventTypeNames[etype]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%08X", etype)
+}
+
+/*
+ContainerEventExtender extends RTMR 3 with the SHA384 digest of the anchor of a container
+event, and records the anchor in the runtime event log.
+*/
+type ContainerEventExtender func(digest []byte, anchor []byte) error
+
+/*
+ContainerEventStore keeps the container event log in memory. With a location, every
+event is also appended to that file as a JSON line and the log is loaded from it on
+start, so that the log survives a restart of the server.
+*/
+type ContainerEventStore struct {
+	// serializes the records, which wait for the extend of RTMR 3, without blocking the readers
+	recordMutex sync.Mutex
+	mutex       sync.RWMutex
+	eventlogs   []ContainerEventLog
+	file        *os.File
+	extend      ContainerEventExtender
+	// closed and replaced once an event is recorded, to wake up the watchers of the log
+	recorded chan struct{}
+}
+
+// NewContainerEventStore returns the store of the container event log, every event being anchored with extend.
+func NewContainerEventStore(location string, extend ContainerEventExtender) (*ContainerEventStore, error) {
+	if extend == nil {
+		return nil, ContainerEventExtenderErr
+	}
+
+	store := &ContainerEventStore{recorded: make(chan struct{}), extend: extend}
+	if location == "" {
+		return store, nil
+	}
+
+	eventlogs, err := loadContainerEventlogs(location)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(location, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		log.Println("Error opening container eventlog file", location)
+		return nil, err
+	}
+
+	store.eventlogs = eventlogs
+	store.file = file
+	return store, nil
+}
+
+/* Every event of the file is anchored, the events are written once their anchor is extended */
+func loadContainerEventlogs(location string) ([]ContainerEventLog, error) {
+	var eventlogs []ContainerEventLog
+
+	file, err := os.Open(location)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 4096), 4*MAX_CONTAINER_EVENT_SIZE)
+	for scanner.Scan() {
+		eventlog := ContainerEventLog{}
+		if err := json.Unmarshal(scanner.Bytes(), &eventlog); err != nil {
+			return nil, pkgerrors.WithMessagef(err, "Failed to load container event %d", len(eventlogs))
+		}
+		if eventlog.Sequence != uint32(len(eventlogs)) {
+			return nil, pkgerrors.WithMessagef(InvalidContainerEventErr, "Unexpected sequence %d of container event %d",
+				eventlog.Sequence, len(eventlogs))
+		}
+		eventlogs = append(eventlogs, eventlog)
+	}
+
+	return eventlogs, scanner.Err()
+}
+
+func (s *ContainerEventStore) Close() error {
+	s.recordMutex.Lock()
+	defer s.recordMutex.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+/*
+GetContainerEventAnchor returns the event extended into RTMR 3 for the container event. It
+binds the sequence, the type, the ids and the digest of the event, the timestamp is not
+measured.
+*/
+func GetContainerEventAnchor(eventlog ContainerEventLog) []byte {
+	return []byte(fmt.Sprintf("%s:%d:%s:%q:%q:%x", CONTAINER_EVENT_ANCHOR_PREFIX, eventlog.Sequence,
+		GetContainerEventTypeName(eventlog.EventType), eventlog.ContainerId, eventlog.PodId, []byte(eventlog.Digest)))
+}
+
+/*
+Record measures the event and appends it to the log of the container. The anchor of the
+event is extended into RTMR 3 before the event is written, so that every event in the log
+is anchored. A crash or a failed write after the extend leaves an anchor without event in
+the runtime event log, whose sequence is recorded again by the next event: the replay of
+RTMR 3 still matches, and the digest in the anchor tells the two events apart.
+
+The records are serialized, the readers of the log only wait for the event to be appended
+in memory, not for the extend.
+*/
+func (s *ContainerEventStore) Record(containerId string, podId string, etype uint32, event []byte) (ContainerEventLog, error) {
+	if containerId == "" || len(containerId) > MAX_CONTAINER_ID_LEN || len(podId) > MAX_CONTAINER_ID_LEN {
+		log.Println("Invalid container or pod id")
+		return ContainerEventLog{}, InvalidContainerEventErr
+	}
+
+	if _, ok := containerEventTypeNames[etype]; !ok {
+		log.Println("Invalid container event type", etype)
+		return ContainerEventLog{}, InvalidContainerEventErr
+	}
+
+	if len(event) > MAX_CONTAINER_EVENT_SIZE {
+		log.Println("Container event exceeds valid length:", len(event))
+		return ContainerEventLog{}, InvalidContainerEventErr
+	}
+
+	digest := sha512.Sum384(event)
+
+	s.recordMutex.Lock()
+	defer s.recordMutex.Unlock()
+
+	/* the log in memory only grows under the record mutex */
+	eventlog := ContainerEventLog{
+		Sequence:      uint32(len(s.eventlogs)),
+		ContainerId:   containerId,
+		PodId:         podId,
+		EventType:     etype,
+		EventTypeName: GetContainerEventTypeName(etype),
+		Timestamp:     time.Now().UTC(),
+		AlgorithmId:   TPM_ALG_SHA384,
+		Digest:        digest[:],
+		Event:         append([]byte(nil), event...),
+	}
+
+	line, err := json.Marshal(eventlog)
+	if err != nil {
+		return ContainerEventLog{}, err
+	}
+
+	anchor := GetContainerEventAnchor(eventlog)
+	anchorDigest := sha512.Sum384(anchor)
+	if err := s.extend(anchorDigest[:], anchor); err != nil {
+		log.Println("Error extending RTMR 3 with container event", eventlog.Sequence)
+		return ContainerEventLog{}, pkgerrors.Wrap(ContainerEventNotAnchoredErr, err.Error())
+	}
+
+	if s.file != nil {
+		if err := s.writeEvent(line); err != nil {
+			log.Println("Error writing container eventlog file, RTMR 3 holds the anchor of event", eventlog.Sequence)
+			return ContainerEventLog{}, err
+		}
+	}
+
+	s.mutex.Lock()
+	s.eventlogs = append(s.eventlogs, eventlog)
+	close(s.recorded)
+	s.recorded = make(chan struct{})
+	s.mutex.Unlock()
+	return eventlog, nil
+}
+
+/* Append the event to the file as a JSON line, a partially written line is removed */
+func (s *ContainerEventStore) writeEvent(line []byte) error {
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err = s.file.Write(append(line, '\n')); err == nil {
+		err = s.file.Sync()
+	}
+	if err != nil {
+		s.removeEvent(info.Size())
+	}
+	return err
+}
+
+/* Remove the partially written event from the file, it is not added to the log in memory */
+func (s *ContainerEventStore) removeEvent(size int64) {
+	if s.file == nil {
+		return
+	}
+	err := s.file.Truncate(size)
+	if err == nil {
+		err = s.file.Sync()
+	}
+	if err != nil {
+		log.Printf("Container eventlog %s holds a partially written event: %v", s.file.Name(), err)
+	}
+}
+
+// GetEventlogs returns the events of the container or the pod with the id, or of all
+// containers if the id is empty. The position and count apply to the matching events.
+func (s *ContainerEventStore) GetEventlogs(id string, start_position int, count int) (ContainerEventLogs, error) {
+	var eventlogs []ContainerEventLog
+
+	s.mutex.RLock()
+	for _, eventlog := range s.eventlogs {
+		if id == "" || eventlog.ContainerId == id || eventlog.PodId == id {
+			eventlogs = append(eventlogs, eventlog)
+		}
+	}
+	s.mutex.RUnlock()
+
+	if id != "" && len(eventlogs) == 0 {
+		log.Println("No container event found for", id)
+		return ContainerEventLogs{}, ContainerEventlogNotFoundErr
+	}
+
+	return getContainerEventlogsInRange(eventlogs, start_position, count)
+}
+
+// WatchEventlogs returns the events of the id, or of all containers if the id is empty, from
+// the sequence, along with the number of events recorded and a channel closed once the next
+// event is recorded. A sequence beyond the recorded events returns no events.
+func (s *ContainerEventStore) WatchEventlogs(id string, sequence int) ([]ContainerEventLog, int, <-chan struct{}) {
+	var eventlogs []ContainerEventLog
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for i := sequence; i < len(s.eventlogs); i++ {
+		eventlog := s.eventlogs[i]
+		if id == "" || eventlog.ContainerId == id || eventlog.PodId == id {
+			eventlogs = append(eventlogs, eventlog)
+		}
+	}
+
+	return eventlogs, len(s.eventlogs), s.recorded
+}
+
+func getContainerEventlogsInRange(eventlogs []ContainerEventLog, position int, count int) (ContainerEventLogs, error) {
+
+	start, end, err := GetPageRange(len(eventlogs), position, count)
+	if err != nil {
+		return ContainerEventLogs{}, err
+	}
+
+	page := []ContainerEventLog{}
+	page = append(page, eventlogs[start:end]...)
+
+	return ContainerEventLogs{Version: CONTAINER_EVENTLOG_VERSION, EventLogs: page, TotalCount: len(eventlogs)}, nil
+}
+
+func MarshalContainerEventlogs(eventlogs ContainerEventLogs) (string, error) {
+	eventlogs_str, err := json.Marshal(eventlogs)
+	if err != nil {
+		log.Println("Error in marshaling container event logs")
+		return "", err
+	}
+	return string(eventlogs_str), nil
+}
+
+func UnmarshalContainerEventlogs(data []byte) (ContainerEventLogs, error) {
+	eventlogs := ContainerEventLogs{}
+	if err := json.Unmarshal(data, &eventlogs); err != nil {
+		return ContainerEventLogs{}, err
+	}
+
+	if eventlogs.Version != CONTAINER_EVENTLOG_VERSION {
+		log.Println("Unsupported container eventlog schema version", eventlogs.Version)
+		return ContainerEventLogs{}, InvalidEventlogSchemaErr
+	}
+	return eventlogs, nil
+}
@@ -0,0 +1,333 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/* Records the anchors extended into RTMR 3 */
+type fakeContainerEventExtender struct {
+	anchors [][]byte
+	err     error
+}
+
+func (e *fakeContainerEventExtender) extend(digest []byte, anchor []byte) error {
+	if e.err != nil {
+		return e.err
+	}
+	if expected := sha512.Sum384(anchor); !bytes.Equal(digest, expected[:]) {
+		return InvalidContainerEventErr
+	}
+	e.anchors = append(e.anchors, anchor)
+	return nil
+}
+
+func recordContainerEvents(t *testing.T, store *ContainerEventStore) {
+	events := []struct {
+		containerId string
+		podId       string
+		etype       uint32
+		event       string
+	}{
+		{"c1", "default/nginx", CONTAINER_EVENT_IMAGE, "docker.io/library/nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"},
+		{"c1", "default/nginx", CONTAINER_EVENT_CONFIG, "sha256:5b0d5d4b7b3c1b1d6c0d5e0a9b3f2e1d"},
+		{"c1", "default/nginx", CONTAINER_EVENT_MOUNT, "/var/lib/kubelet/pods/1/volumes:/data:ro"},
+		{"c1", "default/nginx", CONTAINER_EVENT_START, "nginx"},
+		{"c2", "default/redis", CONTAINER_EVENT_IMAGE, "docker.io/library/redis@sha256:7d3a1c1b"},
+		{"c2", "default/redis", CONTAINER_EVENT_START, "redis"},
+	}
+
+	for _, e := range events {
+		if _, err := store.Record(e.containerId, e.podId, e.etype, []byte(e.event)); err != nil {
+			t.Fatalf("Record(%s, %s) returned error: %v", e.containerId, e.event, err)
+		}
+	}
+}
+
+func TestContainerEventStoreRecord(t *testing.T) {
+	store, _ := NewContainerEventStore("", (&fakeContainerEventExtender{}).extend)
+
+	eventlog, err := store.Record("c1", "default/nginx", CONTAINER_EVENT_IMAGE, []byte("nginx@sha256:00"))
+	if err != nil {
+		t.Fatalf("Record returned error: %v", err)
+	}
+
+	digest := sha512.Sum384([]byte("nginx@sha256:00"))
+	if !bytes.Equal(eventlog.Digest, digest[:]) || eventlog.AlgorithmId != TPM_ALG_SHA384 {
+		t.Errorf("Unexpected digest %x of algorithm %d", eventlog.Digest, eventlog.AlgorithmId)
+	}
+	if eventlog.Sequence != 0 || eventlog.EventTypeName != "CONTAINER_IMAGE" || eventlog.Timestamp.IsZero() {
+		t.Errorf("Unexpected event %+v", eventlog)
+	}
+
+	eventlog, _ = store.Record("c2", "", CONTAINER_EVENT_START, nil)
+	if eventlog.Sequence != 1 {
+		t.Errorf("Sequence -> Want: 1, Got: %d", eventlog.Sequence)
+	}
+}
+
+func TestContainerEventStoreRecordInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		containerId string
+		podId       string
+		etype       uint32
+		event       []byte
+	}{
+		{"Empty container id", "", "default/nginx", CONTAINER_EVENT_START, nil},
+		{"Container id too long", strings.Repeat("c", MAX_CONTAINER_ID_LEN+1), "", CONTAINER_EVENT_START, nil},
+		{"Pod id too long", "c1", strings.Repeat("p", MAX_CONTAINER_ID_LEN+1), CONTAINER_EVENT_START, nil},
+		{"Unknown event type", "c1", "", 0x10, nil},
+		{"Event too long", "c1", "", CONTAINER_EVENT_CONFIG, make([]byte, MAX_CONTAINER_EVENT_SIZE+1)},
+	}
+
+	store, _ := NewContainerEventStore("", (&fakeContainerEventExtender{}).extend)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.Record(tt.containerId, tt.podId, tt.etype, tt.event)
+			if pkgerrors.Cause(err) != InvalidContainerEventErr {
+				t.Errorf("Err -> Want: %v, Got: %v", InvalidContainerEventErr, err)
+			}
+		})
+	}
+}
+
+func TestContainerEventStoreGetEventlogs(t *testing.T) {
+	tests := []struct {
+		name          string
+		id            string
+		position      int
+		count         int
+		wantSequences []uint32
+		expectedErr   error
+	}{
+		{"All containers", "", 0, 0, []uint32{0, 1, 2, 3, 4, 5}, nil},
+		{"By container id", "c2", 0, 0, []uint32{4, 5}, nil},
+		{"By pod id", "default/nginx", 0, 0, []uint32{0, 1, 2, 3}, nil},
+		{"By pod id with range", "default/nginx", 1, 2, []uint32{1, 2}, nil},
+		{"Last page", "c1", 2, 2, []uint32{2, 3}, nil},
+		{"Last page with fewer events", "c1", 3, 2, []uint32{3}, nil},
+		{"From position", "c2", 1, 0, []uint32{5}, nil},
+		{"Beyond the end", "c2", 3, 0, nil, InvalidEventlogRangeErr},
+		{"Unknown container", "c3", 0, 0, nil, ContainerEventlogNotFoundErr},
+	}
+
+	store, _ := NewContainerEventStore("", (&fakeContainerEventExtender{}).extend)
+	recordContainerEvents(t, store)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventlogs, err := store.GetEventlogs(tt.id, tt.position, tt.count)
+			if err != tt.expectedErr {
+				t.Fatalf("Err -> Want: %v, Got: %v", tt.expectedErr, err)
+			}
+			if len(eventlogs.EventLogs) != len(tt.wantSequences) {
+				t.Fatalf("Event count -> Want: %d, Got: %d", len(tt.wantSequences), len(eventlogs.EventLogs))
+			}
+			for i, eventlog := range eventlogs.EventLogs {
+				if eventlog.Sequence != tt.wantSequences[i] {
+					t.Errorf("Sequence of event %d -> Want: %d, Got: %d", i, tt.wantSequences[i], eventlog.Sequence)
+				}
+			}
+		})
+	}
+}
+
+func TestContainerEventStoreWatchEventlogs(t *testing.T) {
+	store, _ := NewContainerEventStore("", (&fakeContainerEventExtender{}).extend)
+	recordContainerEvents(t, store)
+
+	tests := []struct {
+		name         string
+		id           string
+		sequence     int
+		wantSequence []uint32
+	}{
+		{"All containers", "", 0, []uint32{0, 1, 2, 3, 4, 5}},
+		{"From sequence", "", 4, []uint32{4, 5}},
+		{"Pod from sequence", "default/nginx", 2, []uint32{2, 3}},
+		{"Container without events", "c3", 0, nil},
+		{"End of the log", "", 6, nil},
+		{"Beyond the log", "", 9, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventlogs, length, _ := store.WatchEventlogs(tt.id, tt.sequence)
+			if length != 6 || len(eventlogs) != len(tt.wantSequence) {
+				t.Fatalf("WatchEventlogs() = %d events of %d want %d events of 6", len(eventlogs), length, len(tt.wantSequence))
+			}
+			for i, eventlog := range eventlogs {
+				if eventlog.Sequence != tt.wantSequence[i] {
+					t.Errorf("Sequence -> Want: %d, Got: %d", tt.wantSequence[i], eventlog.Sequence)
+				}
+			}
+		})
+	}
+
+	/* the channel is closed by the next recorded event only */
+	_, _, recorded := store.WatchEventlogs("c3", 6)
+	select {
+	case <-recorded:
+		t.Fatalf("Channel closed before an event is recorded")
+	default:
+	}
+	store.Record("c3", "default/busybox", CONTAINER_EVENT_START, nil)
+	select {
+	case <-recorded:
+	default:
+		t.Fatalf("Channel not closed once an event is recorded")
+	}
+	if eventlogs, length, _ := store.WatchEventlogs("c3", 6); length != 7 || len(eventlogs) != 1 {
+		t.Errorf("WatchEventlogs() after record = %d events of %d want 1 event of 7", len(eventlogs), length)
+	}
+}
+
+func TestContainerEventStoreAnchor(t *testing.T) {
+	extender := &fakeContainerEventExtender{}
+	location := filepath.Join(t.TempDir(), "container-eventlog.jsonl")
+	store, err := NewContainerEventStore(location, extender.extend)
+	if err != nil {
+		t.Fatalf("NewContainerEventStore returned error: %v", err)
+	}
+	defer store.Close()
+	recordContainerEvents(t, store)
+
+	eventlogs, _ := store.GetEventlogs("", 0, 0)
+	if len(extender.anchors) != len(eventlogs.EventLogs) {
+		t.Fatalf("%d anchors extended for %d events", len(extender.anchors), len(eventlogs.EventLogs))
+	}
+	for i, eventlog := range eventlogs.EventLogs {
+		if !bytes.Equal(extender.anchors[i], GetContainerEventAnchor(eventlog)) {
+			t.Errorf("Anchor %d = %s want %s", i, extender.anchors[i], GetContainerEventAnchor(eventlog))
+		}
+	}
+
+	/* an event not anchored is never written to the log */
+	data, _ := os.ReadFile(location)
+	extender.err = pkgerrors.New("RTMR extend failed")
+	if _, err := store.Record("c3", "", CONTAINER_EVENT_START, []byte("busybox")); pkgerrors.Cause(err) != ContainerEventNotAnchoredErr {
+		t.Errorf("Record() with failed extend = %v want %v", err, ContainerEventNotAnchoredErr)
+	}
+	if after, _ := os.ReadFile(location); !bytes.Equal(after, data) {
+		t.Errorf("Container eventlog of %d bytes after a failed extend want %d", len(after), len(data))
+	}
+	if _, length, _ := store.WatchEventlogs("", 0); length != len(eventlogs.EventLogs) {
+		t.Errorf("%d events after a failed extend want %d", length, len(eventlogs.EventLogs))
+	}
+}
+
+func TestContainerEventStoreRecordDuringExtend(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "container-eventlog.jsonl")
+	extending := make(chan struct{})
+	extended := make(chan struct{})
+	store, err := NewContainerEventStore(location, func(digest []byte, anchor []byte) error {
+		close(extending)
+		<-extended
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewContainerEventStore returned error: %v", err)
+	}
+	defer store.Close()
+
+	recorded := make(chan error)
+	go func() {
+		_, err := store.Record("c1", "default/nginx", CONTAINER_EVENT_START, []byte("nginx"))
+		recorded <- err
+	}()
+	<-extending
+
+	/* the log is readable and the event not written while its anchor is extended */
+	if eventlogs, err := store.GetEventlogs("", 0, 0); err != nil || len(eventlogs.EventLogs) != 0 {
+		t.Errorf("GetEventlogs() during the extend = %v, %v want no event", eventlogs, err)
+	}
+	if data, _ := os.ReadFile(location); len(data) != 0 {
+		t.Errorf("Container eventlog of %d bytes before the extend want 0", len(data))
+	}
+
+	close(extended)
+	if err := <-recorded; err != nil {
+		t.Fatalf("Record() returned error: %v", err)
+	}
+	if eventlogs, _ := loadContainerEventlogs(location); len(eventlogs) != 1 {
+		t.Errorf("%d events written after the extend want 1", len(eventlogs))
+	}
+}
+
+func TestContainerEventStorePersistence(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "container-eventlog.jsonl")
+
+	store, err := NewContainerEventStore(location, (&fakeContainerEventExtender{}).extend)
+	if err != nil {
+		t.Fatalf("NewContainerEventStore returned error: %v", err)
+	}
+	recordContainerEvents(t, store)
+	store.Close()
+
+	store, err = NewContainerEventStore(location, (&fakeContainerEventExtender{}).extend)
+	if err != nil {
+		t.Fatalf("NewContainerEventStore returned error on reload: %v", err)
+	}
+	defer store.Close()
+
+	eventlog, err := store.Record("c3", "", CONTAINER_EVENT_START, []byte("busybox"))
+	if err != nil || eventlog.Sequence != 6 {
+		t.Fatalf("Record after reload -> sequence %d, error %v", eventlog.Sequence, err)
+	}
+
+	eventlogs, _ := store.GetEventlogs("default/redis", 0, 0)
+	if len(eventlogs.EventLogs) != 2 || string(eventlogs.EventLogs[1].Event) != "redis" {
+		t.Errorf("Unexpected events after reload: %+v", eventlogs.EventLogs)
+	}
+
+	/* a log with a missing event is rejected */
+	os.WriteFile(location, []byte(`{"sequence":1,"container_id":"c1"}`+"\n"), 0600)
+	if _, err := NewContainerEventStore(location, (&fakeContainerEventExtender{}).extend); pkgerrors.Cause(err) != InvalidContainerEventErr {
+		t.Errorf("Err -> Want: %v, Got: %v", InvalidContainerEventErr, err)
+	}
+
+	/* events are never recorded without their anchor */
+	if _, err := NewContainerEventStore("", nil); err != ContainerEventExtenderErr {
+		t.Errorf("Err -> Want: %v, Got: %v", ContainerEventExtenderErr, err)
+	}
+}
+
+func TestContainerEventlogsSchemaRoundTrip(t *testing.T) {
+	store, _ := NewContainerEventStore("", (&fakeContainerEventExtender{}).extend)
+	recordContainerEvents(t, store)
+
+	selected, _ := store.GetEventlogs("c1", 0, 0)
+	eventlog, err := MarshalContainerEventlogs(selected)
+	if err != nil {
+		t.Fatalf("MarshalContainerEventlogs returned error: %v", err)
+	}
+
+	eventlogs, err := UnmarshalContainerEventlogs([]byte(eventlog))
+	if err != nil {
+		t.Fatalf("UnmarshalContainerEventlogs returned error: %v", err)
+	}
+
+	expected, _ := store.GetEventlogs("c1", 0, 0)
+	for i, entry := range eventlogs.EventLogs {
+		if !bytes.Equal(entry.Digest, expected.EventLogs[i].Digest) || !bytes.Equal(entry.Event, expected.EventLogs[i].Event) ||
+			!entry.Timestamp.Equal(expected.EventLogs[i].Timestamp) {
+			t.Errorf("Event %d -> Want: %+v, Got: %+v", i, expected.EventLogs[i], entry)
+		}
+	}
+
+	if _, err := UnmarshalContainerEventlogs([]byte(`{"version": "2.0"}`)); err != InvalidEventlogSchemaErr {
+		t.Errorf("Err -> Want: %v, Got: %v", InvalidEventlogSchemaErr, err)
+	}
+}
@@ -0,0 +1,453 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/*
+The event types are defined in TCG PC Client Platform Firmware Profile Specification
+at https://trustedcomputinggroup.org/resource/pc-client-specific-platform-firmware-profile-specification/
+*/
+const (
+	EVENT_TYPE_EV_PREBOOT_CERT                  = 0x0
+	EVENT_TYPE_EV_POST_CODE                     = 0x1
+	EVENT_TYPE_EV_UNUSED                        = 0x2
+	EVENT_TYPE_EV_SEPARATOR                     = 0x4
+	EVENT_TYPE_EV_ACTION                        = 0x5
+	EVENT_TYPE_EV_EVENT_TAG                     = 0x6
+	EVENT_TYPE_EV_S_CRTM_CONTENTS               = 0x7
+	EVENT_TYPE_EV_S_CRTM_VERSION                = 0x8
+	EVENT_TYPE_EV_CPU_MICROCODE                 = 0x9
+	EVENT_TYPE_EV_PLATFORM_CONFIG_FLAGS         = 0xa
+	EVENT_TYPE_EV_TABLE_OF_DEVICES              = 0xb
+	EVENT_TYPE_EV_COMPACT_HASH                  = 0xc
+	EVENT_TYPE_EV_IPL                           = 0xd
+	EVENT_TYPE_EV_IPL_PARTITION_DATA            = 0xe
+	EVENT_TYPE_EV_NONHOST_CODE                  = 0xf
+	EVENT_TYPE_EV_NONHOST_CONFIG                = 0x10
+	EVENT_TYPE_EV_NONHOST_INFO                  = 0x11
+	EVENT_TYPE_EV_OMIT_BOOT_DEVICE_EVENTS       = 0x12
+	EVENT_TYPE_EV_EFI_EVENT_BASE                = 0x80000000
+	EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG    = 0x80000001
+	EVENT_TYPE_EV_EFI_VARIABLE_BOOT             = 0x80000002
+	EVENT_TYPE_EV_EFI_BOOT_SERVICES_APPLICATION = 0x80000003
+	EVENT_TYPE_EV_EFI_BOOT_SERVICES_DRIVER      = 0x80000004
+	EVENT_TYPE_EV_EFI_RUNTIME_SERVICES_DRIVER   = 0x80000005
+	EVENT_TYPE_EV_EFI_GPT_EVENT                 = 0x80000006
+	EVENT_TYPE_EV_EFI_ACTION                    = 0x80000007
+	EVENT_TYPE_EV_EFI_PLATFORM_FIRMWARE_BLOB    = 0x80000008
+	EVENT_TYPE_EV_EFI_HANDOFF_TABLES            = 0x80000009
+	EVENT_TYPE_EV_EFI_PLATFORM_FIRMWARE_BLOB2   = 0x8000000a
+	EVENT_TYPE_EV_EFI_HANDOFF_TABLES2           = 0x8000000b
+	EVENT_TYPE_EV_EFI_VARIABLE_BOOT2            = 0x8000000c
+	EVENT_TYPE_EV_EFI_HCRTM_EVENT               = 0x80000010
+	EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY        = 0x800000e0
+	EVENT_TYPE_EV_EFI_SPDM_FIRMWARE_BLOB        = 0x800000e1
+	EVENT_TYPE_EV_EFI_SPDM_FIRMWARE_CONFIG      = 0x800000e2
+)
+
+var eventTypeNames = map[uint32]string{
+	EVENT_TYPE_EV_PREBOOT_CERT:                  "EV_PREBOOT_CERT",
+	EVENT_TYPE_EV_POST_CODE:                     "EV_POST_CODE",
+	EVENT_TYPE_EV_UNUSED:                        "EV_UNUSED",
+	EVENT_TYPE_EV_NO_ACTION:                     "EV_NO_ACTION",
+	EVENT_TYPE_EV_SEPARATOR:                     "EV_SEPARATOR",
+	EVENT_TYPE_EV_ACTION:                        "EV_ACTION",
+	EVENT_TYPE_EV_EVENT_TAG:                     "EV_EVENT_TAG",
+	EVENT_TYPE_EV_S_CRTM_CONTENTS:               "EV_S_CRTM_CONTENTS",
+	EVENT_TYPE_EV_S_CRTM_VERSION:                "EV_S_CRTM_VERSION",
+	EVENT_TYPE_EV_CPU_MICROCODE:                 "EV_CPU_MICROCODE",
+	EVENT_TYPE_EV_PLATFORM_CONFIG_FLAGS:         "EV_PLATFORM_CONFIG_FLAGS",
+	EVENT_TYPE_EV_TABLE_OF_DEVICES:              "EV_TABLE_OF_DEVICES",
+	EVENT_TYPE_EV_COMPACT_HASH:                  "EV_COMPACT_HASH",
+	EVENT_TYPE_EV_IPL:                           "EV_IPL",
+	EVENT_TYPE_EV_IPL_PARTITION_DATA:            "EV_IPL_PARTITION_DATA",
+	EVENT_TYPE_EV_NONHOST_CODE:                  "EV_NONHOST_CODE",
+	EVENT_TYPE_EV_NONHOST_CONFIG:                "EV_NONHOST_CONFIG",
+	EVENT_TYPE_EV_NONHOST_INFO:                  "EV_NONHOST_INFO",
+	EVENT_TYPE_EV_OMIT_BOOT_DEVICE_EVENTS:       "EV_OMIT_BOOT_DEVICE_EVENTS",
+	EVENT_TYPE_EV_EFI_EVENT_BASE:                "EV_EFI_EVENT_BASE",
+	EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG:    "EV_EFI_VARIABLE_DRIVER_CONFIG",
+	EVENT_TYPE_EV_EFI_VARIABLE_BOOT:             "EV_EFI_VARIABLE_BOOT",
+	EVENT_TYPE_EV_EFI_BOOT_SERVICES_APPLICATION: "EV_EFI_BOOT_SERVICES_APPLICATION",
+	EVENT_TYPE_EV_EFI_BOOT_SERVICES_DRIVER:      "EV_EFI_BOOT_SERVICES_DRIVER",
+	EVENT_TYPE_EV_EFI_RUNTIME_SERVICES_DRIVER:   "EV_EFI_RUNTIME_SERVICES_DRIVER",
+	EVENT_TYPE_EV_EFI_GPT_EVENT:                 "EV_EFI_GPT_EVENT",
+	EVENT_TYPE_EV_EFI_ACTION:                    "EV_EFI_ACTION",
+	EVENT_TYPE_EV_EFI_PLATFORM_FIRMWARE_BLOB:    "EV_EFI_PLATFORM_FIRMWARE_BLOB",
+	EVENT_TYPE_EV_EFI_HANDOFF_TABLES:            "EV_EFI_HANDOFF_TABLES",
+	EVENT_TYPE_EV_EFI_PLATFORM_FIRMWARE_BLOB2:   "EV_EFI_PLATFORM_FIRMWARE_BLOB2",
+	EVENT_TYPE_EV_EFI_HANDOFF_TABLES2:           "EV_EFI_HANDOFF_TABLES2",
+	EVENT_TYPE_EV_EFI_VARIABLE_BOOT2:            "EV_EFI_VARIABLE_BOOT2",
+	EVENT_TYPE_EV_EFI_HCRTM_EVENT:               "EV_EFI_HCRTM_EVENT",
+	EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY:        "EV_EFI_VARIABLE_AUTHORITY",
+	EVENT_TYPE_EV_EFI_SPDM_FIRMWARE_BLOB:        "EV_EFI_SPDM_FIRMWARE_BLOB",
+	EVENT_TYPE_EV_EFI_SPDM_FIRMWARE_CONFIG:      "EV_EFI_SPDM_FIRMWARE_CONFIG",
+}
+
+var (
+	InvalidEventDataErr = pkgerrors.New("Event data with invalid length")
+)
+
+type EventDecoder func(event []byte) (interface{}, error)
+
+var eventDecoders = map[uint32]EventDecoder{
+	EVENT_TYPE_EV_SEPARATOR:                     decodeSeparatorEvent,
+	EVENT_TYPE_EV_ACTION:                        decodeStringEvent,
+	EVENT_TYPE_EV_EVENT_TAG:                     decodeTaggedEvent,
+	EVENT_TYPE_EV_IPL:                           decodeStringEvent,
+	EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG:    decodeUefiVariableData,
+	EVENT_TYPE_EV_EFI_VARIABLE_BOOT:             decodeUefiVariableData,
+	EVENT_TYPE_EV_EFI_VARIABLE_BOOT2:            decodeUefiVariableData,
+	EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY:        decodeUefiVariableData,
+	EVENT_TYPE_EV_EFI_BOOT_SERVICES_APPLICATION: decodeUefiImageLoadEvent,
+	EVENT_TYPE_EV_EFI_BOOT_SERVICES_DRIVER:      decodeUefiImageLoadEvent,
+	EVENT_TYPE_EV_EFI_RUNTIME_SERVICES_DRIVER:   decodeUefiImageLoadEvent,
+	EVENT_TYPE_EV_EFI_ACTION:                    decodeStringEvent,
+	EVENT_TYPE_EV_EFI_PLATFORM_FIRMWARE_BLOB:    decodeUefiPlatformFirmwareBlob,
+	EVENT_TYPE_EV_EFI_PLATFORM_FIRMWARE_BLOB2:   decodeUefiPlatformFirmwareBlob2,
+}
+
+/* EFI_GUID, the first three fields are little endian */
+type EfiGuid [16]byte
+
+func (g EfiGuid) String() string {
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(g[0:4]), binary.LittleEndian.Uint16(g[4:6]),
+		binary.LittleEndian.Uint16(g[6:8]), g[8:10], g[10:16])
+}
+
+func (g EfiGuid) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+/* UEFI_VARIABLE_DATA for EV_EFI_VARIABLE_* events */
+type UefiVariableData struct {
+	VariableName EfiGuid `json:"variable_name"`
+	UnicodeName  string  `json:"unicode_name"`
+	VariableData []byte  `json:"variable_data"`
+}
+
+/* UEFI_IMAGE_LOAD_EVENT for EV_EFI_BOOT_SERVICES_* and EV_EFI_RUNTIME_SERVICES_DRIVER events */
+type UefiImageLoadEvent struct {
+	ImageLocationInMemory uint64 `json:"image_location_in_memory"`
+	ImageLengthInMemory   uint64 `json:"image_length_in_memory"`
+	ImageLinkTimeAddress  uint64 `json:"image_link_time_address"`
+	DevicePath            string `json:"device_path"`
+}
+
+/* UEFI_PLATFORM_FIRMWARE_BLOB(2) for EV_EFI_PLATFORM_FIRMWARE_BLOB(2) events */
+type UefiPlatformFirmwareBlob struct {
+	BlobDescription string `json:"blob_description,omitempty"`
+	BlobBase        uint64 `json:"blob_base"`
+	BlobLength      uint64 `json:"blob_length"`
+}
+
+/* Strings measured by EV_IPL, EV_ACTION and EV_EFI_ACTION events, e.g. grub commands and kernel command line */
+type StringEvent struct {
+	String string `json:"string"`
+}
+
+/* EV_SEPARATOR carries 0 normally and 0xFFFFFFFF if an error occurred */
+type SeparatorEvent struct {
+	Value uint32 `json:"value"`
+	Error bool   `json:"error"`
+}
+
+/* TCG_PCClientTaggedEvent for EV_EVENT_TAG events */
+type TaggedEvent struct {
+	TaggedEventId   uint32 `json:"tagged_event_id"`
+	TaggedEventData []byte `json:"tagged_event_data"`
+}
+
+// GetEventTypeName returns the TCG name of the event type, or its hex value if unknown.
+func GetEventTypeName(etype uint32) string {
+	if name, ok := eventTypeNames[etype]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%08X", etype)
+}
+
+// GetEventTypeByName returns the event type of the TCG name, e.g. EV_IPL.
+func GetEventTypeByName(name string) (uint32, bool) {
+	for etype, etypeName := range eventTypeNames {
+		if etypeName == name {
+			return etype, true
+		}
+	}
+	return 0, false
+}
+
+// DecodeEvent decodes the event data according to the event type.
+// It returns nil without error for event types without decoder.
+func DecodeEvent(etype uint32, event []byte) (interface{}, error) {
+	decoder, ok := eventDecoders[etype]
+	if !ok {
+		return nil, nil
+	}
+
+	return decoder(event)
+}
+
+func decodeUefiVariableData(event []byte) (interface{}, error) {
+	var nameLength, dataLength uint64
+	var err error
+
+	variable := UefiVariableData{}
+	if len(event) < len(variable.VariableName) {
+		return nil, InvalidEventDataErr
+	}
+	copy(variable.VariableName[:], event[0:16])
+	index := 16
+
+	nameLength, index, err = getUint64Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	dataLength, index, err = getUint64Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	if nameLength > uint64(len(event)) || dataLength > uint64(len(event)) ||
+		uint64(index)+nameLength*2+dataLength > uint64(len(event)) {
+		return nil, InvalidEventDataErr
+	}
+
+	variable.UnicodeName = decodeUtf16String(event[index : index+int(nameLength)*2])
+	index += int(nameLength) * 2
+	variable.VariableData = event[index : index+int(dataLength)]
+
+	return variable, nil
+}
+
+func decodeUefiImageLoadEvent(event []byte) (interface{}, error) {
+	var pathLength uint64
+	var err error
+
+	image := UefiImageLoadEvent{}
+	index := 0
+
+	image.ImageLocationInMemory, index, err = getUint64Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	image.ImageLengthInMemory, index, err = getUint64Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	image.ImageLinkTimeAddress, index, err = getUint64Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	pathLength, index, err = getUint64Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	if pathLength > uint64(len(event)-index) {
+		return nil, InvalidEventDataErr
+	}
+
+	image.DevicePath = decodeDevicePath(event[index : index+int(pathLength)])
+
+	return image, nil
+}
+
+func decodeUefiPlatformFirmwareBlob(event []byte) (interface{}, error) {
+	var err error
+
+	blob := UefiPlatformFirmwareBlob{}
+	index := 0
+
+	blob.BlobBase, index, err = getUint64Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	blob.BlobLength, _, err = getUint64Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return blob, nil
+}
+
+func decodeUefiPlatformFirmwareBlob2(event []byte) (interface{}, error) {
+	var descriptionSize uint8
+	var err error
+
+	blob := UefiPlatformFirmwareBlob{}
+	index := 0
+
+	descriptionSize, index, err = getUint8Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	if int(descriptionSize) > len(event)-index {
+		return nil, InvalidEventDataErr
+	}
+	blob.BlobDescription = decodeString(event[index : index+int(descriptionSize)])
+	index += int(descriptionSize)
+
+	blob.BlobBase, index, err = getUint64Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	blob.BlobLength, _, err = getUint64Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return blob, nil
+}
+
+func decodeStringEvent(event []byte) (interface{}, error) {
+	return StringEvent{String: decodeString(event)}, nil
+}
+
+func decodeSeparatorEvent(event []byte) (interface{}, error) {
+	value, _, err := getUint32Object(event, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return SeparatorEvent{Value: value, Error: value == 0xFFFFFFFF}, nil
+}
+
+func decodeTaggedEvent(event []byte) (interface{}, error) {
+	var dataSize uint32
+	var err error
+
+	tagged := TaggedEvent{}
+	index := 0
+
+	tagged.TaggedEventId, index, err = getUint32Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSize, index, err = getUint32Object(event, index)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(dataSize) > uint64(len(event)-index) {
+		return nil, InvalidEventDataErr
+	}
+	tagged.TaggedEventData = event[index : index+int(dataSize)]
+
+	return tagged, nil
+}
+
+/* Decode a string which may be either UTF-16LE (e.g. systemd-boot) or ASCII (e.g. grub) */
+func decodeString(data []byte) string {
+	if len(data) >= 2 && len(data)%2 == 0 && data[1] == 0 && data[0] != 0 {
+		isUtf16 := true
+		for i := 1; i < len(data); i += 2 {
+			if data[i] != 0 {
+				isUtf16 = false
+				break
+			}
+		}
+		if isUtf16 {
+			return decodeUtf16String(data)
+		}
+	}
+
+	return strings.TrimRight(string(data), "\x00")
+}
+
+func decodeUtf16String(data []byte) string {
+	chars := make([]uint16, len(data)/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(data[i*2 : i*2+2])
+	}
+
+	return strings.TrimRight(string(utf16.Decode(chars)), "\x00")
+}
+
+/*
+Convert the EFI device path into text following the UEFI specification
+"Device Path Protocol", nodes without text form are printed as Path(type,subtype,data).
+*/
+func decodeDevicePath(path []byte) string {
+	var nodes []string
+
+	index := 0
+	for index+4 <= len(path) {
+		nodeType := path[index]
+		subType := path[index+1]
+		length := int(binary.LittleEndian.Uint16(path[index+2 : index+4]))
+		if length < 4 || index+length > len(path) {
+			nodes = append(nodes, fmt.Sprintf("Invalid(%x)", path[index:]))
+			break
+		}
+
+		/* End of entire device path */
+		if nodeType == 0x7f && subType == 0xff {
+			break
+		}
+
+		nodes = append(nodes, decodeDevicePathNode(nodeType, subType, path[index+4:index+length]))
+		index += length
+	}
+
+	return strings.Join(nodes, "/")
+}
+
+func decodeDevicePathNode(nodeType uint8, subType uint8, data []byte) string {
+	switch {
+	case nodeType == 0x1 && subType == 0x1 && len(data) == 2:
+		return fmt.Sprintf("Pci(0x%x,0x%x)", data[1], data[0])
+	case nodeType == 0x2 && subType == 0x1 && len(data) == 8:
+		hid := binary.LittleEndian.Uint32(data[0:4])
+		uid := binary.LittleEndian.Uint32(data[4:8])
+		if hid&0xffff == 0x41d0 {
+			return fmt.Sprintf("PciRoot(0x%x)", uid)
+		}
+		return fmt.Sprintf("Acpi(0x%08x,0x%x)", hid, uid)
+	case nodeType == 0x3 && subType == 0x12 && len(data) == 6:
+		return fmt.Sprintf("Sata(0x%x,0x%x,0x%x)", binary.LittleEndian.Uint16(data[0:2]),
+			binary.LittleEndian.Uint16(data[2:4]), binary.LittleEndian.Uint16(data[4:6]))
+	case nodeType == 0x3 && subType == 0x17 && len(data) >= 4:
+		return fmt.Sprintf("NVMe(0x%x)", binary.LittleEndian.Uint32(data[0:4]))
+	case nodeType == 0x3 && subType == 0x2 && len(data) == 4:
+		return fmt.Sprintf("Scsi(0x%x,0x%x)", binary.LittleEndian.Uint16(data[0:2]), binary.LittleEndian.Uint16(data[2:4]))
+	case nodeType == 0x4 && subType == 0x1 && len(data) == 38:
+		var signature EfiGuid
+		copy(signature[:], data[20:36])
+		return fmt.Sprintf("HD(%d,GPT,%s,0x%x,0x%x)", binary.LittleEndian.Uint32(data[0:4]), signature,
+			binary.LittleEndian.Uint64(data[4:12]), binary.LittleEndian.Uint64(data[12:20]))
+	case nodeType == 0x4 && subType == 0x4:
+		return decodeUtf16String(data)
+	case nodeType == 0x4 && subType == 0x6 && len(data) == 16:
+		var guid EfiGuid
+		copy(guid[:], data)
+		return fmt.Sprintf("FvFile(%s)", guid)
+	case nodeType == 0x4 && subType == 0x7 && len(data) == 16:
+		var guid EfiGuid
+		copy(guid[:], data)
+		return fmt.Sprintf("Fv(%s)", guid)
+	case nodeType == 0x4 && subType == 0x8 && len(data) == 16:
+		return fmt.Sprintf("Offset(0x%x,0x%x)", binary.LittleEndian.Uint64(data[0:8]), binary.LittleEndian.Uint64(data[8:16]))
+	}
+
+	return fmt.Sprintf("Path(%d,%d,%x)", nodeType, subType, data)
+}
@@ -0,0 +1,252 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUtf16(s string) []byte {
+	var buf bytes.Buffer
+	for _, c := range utf16.Encode([]rune(s)) {
+		_ = binary.Write(&buf, binary.LittleEndian, c)
+	}
+	return buf.Bytes()
+}
+
+func buildUefiVariableData(guid EfiGuid, name string, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(guid[:])
+	_ = binary.Write(&buf, binary.LittleEndian, uint64(len([]rune(name))))
+	_ = binary.Write(&buf, binary.LittleEndian, uint64(len(data)))
+	buf.Write(encodeUtf16(name))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func buildDevicePathNode(nodeType uint8, subType uint8, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(nodeType)
+	buf.WriteByte(subType)
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(len(data)+4))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func buildUefiImageLoadEvent(location uint64, length uint64, path []byte) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, location)
+	_ = binary.Write(&buf, binary.LittleEndian, length)
+	_ = binary.Write(&buf, binary.LittleEndian, uint64(0))
+	_ = binary.Write(&buf, binary.LittleEndian, uint64(len(path)))
+	buf.Write(path)
+	return buf.Bytes()
+}
+
+func TestEfiGuidString(t *testing.T) {
+	expected := "8be4df61-93ca-11d2-aa0d-00e098032b8c"
+	guid := EfiGuid{0x61, 0xdf, 0xe4, 0x8b, 0xca, 0x93, 0xd2, 0x11, 0xaa, 0x0d, 0x00, 0xe0, 0x98, 0x03, 0x2b, 0x8c}
+	if guid != EFI_GLOBAL_VARIABLE_GUID || guid.String() != expected {
+		t.Fatalf("EfiGuid.String() = %s, want %s", guid.String(), expected)
+	}
+}
+
+func TestGetEventTypeName(t *testing.T) {
+	tests := []struct {
+		etype    uint32
+		expected string
+	}{
+		{EVENT_TYPE_EV_NO_ACTION, "EV_NO_ACTION"},
+		{EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY, "EV_EFI_VARIABLE_AUTHORITY"},
+		{0x12345678, "0x12345678"},
+	}
+
+	for _, tt := range tests {
+		name := GetEventTypeName(tt.etype)
+		if name != tt.expected {
+			t.Errorf("GetEventTypeName(0x%x) = %s, want %s", tt.etype, name, tt.expected)
+		}
+	}
+}
+
+func TestDecodeUefiVariableData(t *testing.T) {
+	event := buildUefiVariableData(EFI_GLOBAL_VARIABLE_GUID, "SecureBoot", []byte{0x1})
+
+	decoded, err := DecodeEvent(EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG, event)
+	if err != nil {
+		t.Fatalf("DecodeEvent() returned error: %v", err)
+	}
+
+	variable, ok := decoded.(UefiVariableData)
+	if !ok {
+		t.Fatalf("DecodeEvent() returned %T, want UefiVariableData", decoded)
+	}
+	if variable.VariableName != EFI_GLOBAL_VARIABLE_GUID || variable.UnicodeName != "SecureBoot" ||
+		!bytes.Equal(variable.VariableData, []byte{0x1}) {
+		t.Fatalf("DecodeEvent() = %+v, unexpected variable", variable)
+	}
+
+	_, err = DecodeEvent(EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY, event[:len(event)-2])
+	if err == nil {
+		t.Fatalf("DecodeEvent() with truncated variable data should return error")
+	}
+}
+
+func TestDecodeUefiImageLoadEvent(t *testing.T) {
+	var path []byte
+	path = append(path, buildDevicePathNode(0x2, 0x1, []byte{0xd0, 0x41, 0x03, 0x0a, 0, 0, 0, 0})...)
+	path = append(path, buildDevicePathNode(0x1, 0x1, []byte{0x0, 0x3})...)
+	path = append(path, buildDevicePathNode(0x4, 0x4, encodeUtf16("\\EFI\\BOOT\\BOOTX64.EFI\x00"))...)
+	path = append(path, buildDevicePathNode(0x7f, 0xff, nil)...)
+	event := buildUefiImageLoadEvent(0x7e000000, 0x1000, path)
+
+	decoded, err := DecodeEvent(EVENT_TYPE_EV_EFI_BOOT_SERVICES_APPLICATION, event)
+	if err != nil {
+		t.Fatalf("DecodeEvent() returned error: %v", err)
+	}
+
+	image, ok := decoded.(UefiImageLoadEvent)
+	if !ok {
+		t.Fatalf("DecodeEvent() returned %T, want UefiImageLoadEvent", decoded)
+	}
+	expectedPath := "PciRoot(0x0)/Pci(0x3,0x0)/\\EFI\\BOOT\\BOOTX64.EFI"
+	if image.ImageLocationInMemory != 0x7e000000 || image.ImageLengthInMemory != 0x1000 ||
+		image.DevicePath != expectedPath {
+		t.Fatalf("DecodeEvent() = %+v, want device path %s", image, expectedPath)
+	}
+
+	_, err = DecodeEvent(EVENT_TYPE_EV_EFI_BOOT_SERVICES_APPLICATION, event[:20])
+	if err == nil {
+		t.Fatalf("DecodeEvent() with truncated image load event should return error")
+	}
+}
+
+func TestDecodeUefiPlatformFirmwareBlob2(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteByte(byte(len("TDXTABLE")))
+	buf.WriteString("TDXTABLE")
+	_ = binary.Write(&buf, binary.LittleEndian, uint64(0x809000))
+	_ = binary.Write(&buf, binary.LittleEndian, uint64(0x2000))
+
+	decoded, err := DecodeEvent(EVENT_TYPE_EV_EFI_PLATFORM_FIRMWARE_BLOB2, buf.Bytes())
+	if err != nil {
+		t.Fatalf("DecodeEvent() returned error: %v", err)
+	}
+
+	blob := decoded.(UefiPlatformFirmwareBlob)
+	if blob.BlobDescription != "TDXTABLE" || blob.BlobBase != 0x809000 || blob.BlobLength != 0x2000 {
+		t.Fatalf("DecodeEvent() = %+v, unexpected firmware blob", blob)
+	}
+
+	_, err = DecodeEvent(EVENT_TYPE_EV_EFI_PLATFORM_FIRMWARE_BLOB2, []byte{0xff, 0x1})
+	if err == nil {
+		t.Fatalf("DecodeEvent() with invalid description size should return error")
+	}
+}
+
+func TestDecodeIplEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    []byte
+		expected string
+	}{
+		{"grubCommand", []byte("grub_cmd: linux /vmlinuz root=/dev/vda1\x00"), "grub_cmd: linux /vmlinuz root=/dev/vda1"},
+		{"kernelCmdline", []byte("kernel_cmdline: /vmlinuz console=ttyS0\x00"), "kernel_cmdline: /vmlinuz console=ttyS0"},
+		{"utf16String", encodeUtf16("initrd=initrd.img\x00"), "initrd=initrd.img"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeEvent(EVENT_TYPE_EV_IPL, tt.event)
+			if err != nil {
+				t.Fatalf("DecodeEvent() returned error: %v", err)
+			}
+			if decoded.(StringEvent).String != tt.expected {
+				t.Fatalf("DecodeEvent() = %q, want %q", decoded.(StringEvent).String, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeSeparatorEvent(t *testing.T) {
+	tests := []struct {
+		event    []byte
+		expected SeparatorEvent
+	}{
+		{[]byte{0, 0, 0, 0}, SeparatorEvent{Value: 0, Error: false}},
+		{[]byte{0xff, 0xff, 0xff, 0xff}, SeparatorEvent{Value: 0xFFFFFFFF, Error: true}},
+	}
+
+	for _, tt := range tests {
+		decoded, err := DecodeEvent(EVENT_TYPE_EV_SEPARATOR, tt.event)
+		if err != nil || decoded.(SeparatorEvent) != tt.expected {
+			t.Errorf("DecodeEvent(%v) = %+v, %v want %+v", tt.event, decoded, err, tt.expected)
+		}
+	}
+
+	_, err := DecodeEvent(EVENT_TYPE_EV_SEPARATOR, []byte{0})
+	if err == nil {
+		t.Fatalf("DecodeEvent() with truncated separator should return error")
+	}
+}
+
+func TestDecodeTaggedEvent(t *testing.T) {
+	event := []byte{0x1, 0, 0, 0, 0x2, 0, 0, 0, 0xaa, 0xbb}
+
+	decoded, err := DecodeEvent(EVENT_TYPE_EV_EVENT_TAG, event)
+	if err != nil {
+		t.Fatalf("DecodeEvent() returned error: %v", err)
+	}
+
+	tagged := decoded.(TaggedEvent)
+	if tagged.TaggedEventId != 1 || !bytes.Equal(tagged.TaggedEventData, []byte{0xaa, 0xbb}) {
+		t.Fatalf("DecodeEvent() = %+v, unexpected tagged event", tagged)
+	}
+
+	_, err = DecodeEvent(EVENT_TYPE_EV_EVENT_TAG, event[:9])
+	if err == nil {
+		t.Fatalf("DecodeEvent() with truncated tagged event should return error")
+	}
+}
+
+func TestDecodeEventWithoutDecoder(t *testing.T) {
+	decoded, err := DecodeEvent(EVENT_TYPE_EV_POST_CODE, []byte{0x1, 0x2})
+	if decoded != nil || err != nil {
+		t.Fatalf("DecodeEvent() = %v, %v want nil, nil", decoded, err)
+	}
+}
+
+func TestMarshalEventlogsWithDecodedEvent(t *testing.T) {
+	eventlogs := getSampleEventlogs()
+	eventlogs.EventLogs[0].Etype = EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG
+	eventlogs.EventLogs[0].Event = buildUefiVariableData(EFI_GLOBAL_VARIABLE_GUID, "SecureBoot", []byte{0x1})
+	eventlogs.EventLogs[0].EventSize = uint32(len(eventlogs.EventLogs[0].Event))
+
+	data, err := MarshalEventlogs(eventlogs, false)
+	if err != nil {
+		t.Fatalf("MarshalEventlogs() returned error: %v", err)
+	}
+	if !strings.Contains(data, `"event_type_name":"EV_EFI_VARIABLE_DRIVER_CONFIG"`) ||
+		!strings.Contains(data, `"variable_name":"8be4df61-93ca-11d2-aa0d-00e098032b8c"`) ||
+		!strings.Contains(data, `"unicode_name":"SecureBoot"`) {
+		t.Fatalf("MarshalEventlogs() = %s, decoded event missing", data)
+	}
+
+	var eventlogsV1 EventLogsV1
+	if err = json.Unmarshal([]byte(data), &eventlogsV1); err != nil {
+		t.Fatalf("json.Unmarshal() returned error: %v", err)
+	}
+
+	parsed, err := UnmarshalEventlogs([]byte(data))
+	if err != nil || !bytes.Equal(parsed.EventLogs[0].Event, eventlogs.EventLogs[0].Event) {
+		t.Fatalf("UnmarshalEventlogs() = %v, raw event not preserved", err)
+	}
+}
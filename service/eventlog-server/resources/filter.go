@@ -0,0 +1,109 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"log"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+var (
+	InvalidEventlogFilterErr = pkgerrors.New("Invalid eventlog filter")
+)
+
+/* Inclusive range of event types, e.g. EV_EFI_EVENT_BASE to EV_EFI_HCRTM_EVENT for the UEFI events */
+type EventTypeRange struct {
+	Min uint32
+	Max uint32
+}
+
+/*
+EventlogFilter selects the events of the TDX and TPM event logs on the server side. An
+event matches if it is in one of the registers, is one of the event types and is within
+the event type range, the criteria which are not set match every event. With an algorithm,
+only the digests of that algorithm are kept and the events without it are left out.
+*/
+type EventlogFilter struct {
+	RegisterIndexes []uint32
+	EventTypes      []uint32
+	EventTypeRange  *EventTypeRange
+	AlgorithmId     uint16
+}
+
+func (f EventlogFilter) IsEmpty() bool {
+	return len(f.RegisterIndexes) == 0 && len(f.EventTypes) == 0 && f.EventTypeRange == nil && f.AlgorithmId == 0
+}
+
+func (f EventlogFilter) Match(eventlog TDEventLog) bool {
+	if len(f.RegisterIndexes) != 0 && !containsUint32(f.RegisterIndexes, eventlog.Rtmr) {
+		return false
+	}
+
+	if len(f.EventTypes) != 0 && !containsUint32(f.EventTypes, eventlog.Etype) {
+		return false
+	}
+
+	if f.EventTypeRange != nil && (eventlog.Etype < f.EventTypeRange.Min || eventlog.Etype > f.EventTypeRange.Max) {
+		return false
+	}
+
+	return true
+}
+
+// FilterEventlogs returns the events matching the filter, the header is kept as is.
+func FilterEventlogs(eventlogs TDEventLogs, filter EventlogFilter) (TDEventLogs, error) {
+	if filter.IsEmpty() {
+		return eventlogs, nil
+	}
+
+	if filter.EventTypeRange != nil && filter.EventTypeRange.Min > filter.EventTypeRange.Max {
+		log.Printf("Invalid event type range 0x%x-0x%x", filter.EventTypeRange.Min, filter.EventTypeRange.Max)
+		return TDEventLogs{}, InvalidEventlogFilterErr
+	}
+
+	if filter.AlgorithmId != 0 {
+		if _, ok := eventlogs.Header.DigestSizes[filter.AlgorithmId]; !ok {
+			log.Println("Filter algorithm not declared in eventlog header:", GetAlgorithmName(filter.AlgorithmId))
+			return TDEventLogs{}, UnknownAlgorithmErr
+		}
+	}
+
+	filtered := eventlogs
+	filtered.EventLogs = nil
+	for _, eventlog := range eventlogs.EventLogs {
+		if !filter.Match(eventlog) {
+			continue
+		}
+
+		if filter.AlgorithmId != 0 {
+			var digests []TDEventLogDigest
+			for _, digest := range eventlog.Digests {
+				if digest.AlgorithmId == filter.AlgorithmId {
+					digests = append(digests, digest)
+				}
+			}
+			if len(digests) == 0 {
+				continue
+			}
+			eventlog.Digests = digests
+			eventlog.DigestCount = uint32(len(digests))
+		}
+
+		filtered.EventLogs = append(filtered.EventLogs, eventlog)
+	}
+
+	return filtered, nil
+}
+
+func containsUint32(values []uint32, value uint32) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"testing"
+)
+
+func buildFilterEventlogs() TDEventLogs {
+	eventlogs := TDEventLogs{
+		Header: TDEventLogSpecIdHeader{DigestSizes: map[uint16]uint16{TPM_ALG_SHA256: 32, TPM_ALG_SHA384: 48}},
+	}
+
+	events := []struct {
+		rtmr  uint32
+		etype uint32
+		algs  []uint16
+	}{
+		{0, EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG, []uint16{TPM_ALG_SHA256, TPM_ALG_SHA384}},
+		{0, EVENT_TYPE_EV_SEPARATOR, []uint16{TPM_ALG_SHA256, TPM_ALG_SHA384}},
+		{1, EVENT_TYPE_EV_EFI_BOOT_SERVICES_APPLICATION, []uint16{TPM_ALG_SHA384}},
+		{0, EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY, []uint16{TPM_ALG_SHA256, TPM_ALG_SHA384}},
+		{2, EVENT_TYPE_EV_IPL, []uint16{TPM_ALG_SHA256}},
+		{2, EVENT_TYPE_EV_IPL, []uint16{TPM_ALG_SHA256, TPM_ALG_SHA384}},
+	}
+
+	for _, e := range events {
+		eventlog := TDEventLog{Rtmr: e.rtmr, Etype: e.etype, DigestCount: uint32(len(e.algs))}
+		for _, alg := range e.algs {
+			eventlog.Digests = append(eventlog.Digests, TDEventLogDigest{AlgorithmId: alg, Digest: make([]byte, eventlogs.Header.DigestSizes[alg])})
+		}
+		eventlogs.EventLogs = append(eventlogs.EventLogs, eventlog)
+	}
+
+	return eventlogs
+}
+
+func TestFilterEventlogs(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      EventlogFilter
+		wantIndexes []int
+		expectedErr error
+	}{
+		{"Empty filter", EventlogFilter{}, []int{0, 1, 2, 3, 4, 5}, nil},
+		{"Register index", EventlogFilter{RegisterIndexes: []uint32{2}}, []int{4, 5}, nil},
+		{"Register index set", EventlogFilter{RegisterIndexes: []uint32{1, 2}}, []int{2, 4, 5}, nil},
+		{"Event type", EventlogFilter{EventTypes: []uint32{EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY}}, []int{3}, nil},
+		{"Event type range", EventlogFilter{EventTypeRange: &EventTypeRange{Min: EVENT_TYPE_EV_EFI_EVENT_BASE, Max: EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY}},
+			[]int{0, 2, 3}, nil},
+		{"Register and event type", EventlogFilter{RegisterIndexes: []uint32{0}, EventTypes: []uint32{EVENT_TYPE_EV_SEPARATOR, EVENT_TYPE_EV_IPL}},
+			[]int{1}, nil},
+		{"Algorithm", EventlogFilter{AlgorithmId: TPM_ALG_SHA256}, []int{0, 1, 3, 4, 5}, nil},
+		{"No match", EventlogFilter{RegisterIndexes: []uint32{3}}, []int{}, nil},
+		{"Invalid event type range", EventlogFilter{EventTypeRange: &EventTypeRange{Min: 2, Max: 1}}, nil, InvalidEventlogFilterErr},
+		{"Undeclared algorithm", EventlogFilter{AlgorithmId: TPM_ALG_SHA1}, nil, UnknownAlgorithmErr},
+	}
+
+	eventlogs := buildFilterEventlogs()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := FilterEventlogs(eventlogs, tt.filter)
+			if err != tt.expectedErr {
+				t.Fatalf("Err -> Want: %v, Got: %v", tt.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(filtered.EventLogs) != len(tt.wantIndexes) {
+				t.Fatalf("Event count -> Want: %d, Got: %d", len(tt.wantIndexes), len(filtered.EventLogs))
+			}
+			for i, index := range tt.wantIndexes {
+				want := eventlogs.EventLogs[index]
+				got := filtered.EventLogs[i]
+				if got.Rtmr != want.Rtmr || got.Etype != want.Etype {
+					t.Errorf("Event %d -> Want: event %d, Got: %+v", i, index, got)
+				}
+				if tt.filter.AlgorithmId != 0 && (len(got.Digests) != 1 || got.DigestCount != 1 || got.Digests[0].AlgorithmId != tt.filter.AlgorithmId) {
+					t.Errorf("Digests of event %d -> Want: only %s, Got: %+v", i, GetAlgorithmName(tt.filter.AlgorithmId), got.Digests)
+				}
+			}
+		})
+	}
+
+	/* the digests of the original event logs are left untouched */
+	if len(eventlogs.EventLogs[0].Digests) != 2 {
+		t.Errorf("FilterEventlogs modified the original event logs")
+	}
+}
+
+func TestSelectEventlogs(t *testing.T) {
+	eventlogs := buildFilterEventlogs()
+
+	selected, err := selectEventlogs(eventlogs, EventlogFilter{RegisterIndexes: []uint32{0}}, 1, 1)
+	if err != nil || selected.TotalCount != 3 || len(selected.EventLogs) != 1 || selected.EventLogs[0].Etype != EVENT_TYPE_EV_SEPARATOR {
+		t.Fatalf("selectEventlogs(RTMR 0, 1, 1) = %+v, %v want the second event of RTMR 0 of 3", selected, err)
+	}
+
+	selected, err = selectEventlogs(eventlogs, EventlogFilter{}, 0, 0)
+	if err != nil || selected.TotalCount != 6 || len(selected.EventLogs) != 6 {
+		t.Fatalf("selectEventlogs(all) = %+v, %v want 6 events", selected, err)
+	}
+
+	selected, err = selectEventlogs(eventlogs, EventlogFilter{RegisterIndexes: []uint32{2}}, 1, 2)
+	if err != nil || selected.TotalCount != 2 || len(selected.EventLogs) != 1 {
+		t.Fatalf("selectEventlogs(RTMR 2, 1, 2) = %+v, %v want the last event of RTMR 2", selected, err)
+	}
+
+	if _, err = selectEventlogs(eventlogs, EventlogFilter{RegisterIndexes: []uint32{2}}, 3, 0); err != InvalidEventlogRangeErr {
+		t.Fatalf("selectEventlogs(RTMR 2, 3, 0) = %v want %v", err, InvalidEventlogRangeErr)
+	}
+}
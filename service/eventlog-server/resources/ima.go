@@ -0,0 +1,387 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/*
+The IMA runtime measurement lists are described in the kernel documentation
+at https://www.kernel.org/doc/html/latest/security/IMA-templates.html
+*/
+const (
+	IMA_BINARY_EVENT_LOG_LOCATION = "/sys/kernel/security/integrity/ima/binary_runtime_measurements"
+	IMA_ASCII_EVENT_LOG_LOCATION  = "/sys/kernel/security/integrity/ima/ascii_runtime_measurements"
+	//The location of mounted IMA runtime measurement lists
+	IMA_BINARY_EVENT_LOG_MOUNT_LOCATION = "/run/security/integrity/ima/binary_runtime_measurements"
+	IMA_ASCII_EVENT_LOG_MOUNT_LOCATION  = "/run/security/integrity/ima/ascii_runtime_measurements"
+
+	IMA_TEMPLATE_IMA        = "ima"
+	IMA_TEMPLATE_IMA_NG     = "ima-ng"
+	IMA_TEMPLATE_IMA_SIG    = "ima-sig"
+	IMA_TEMPLATE_IMA_MODSIG = "ima-modsig"
+
+	// The template hash of the runtime measurement lists is SHA1
+	IMA_TEMPLATE_HASH_SIZE = 20
+	IMA_DIGEST_SIZE        = 20
+	IMA_EVENTLOG_VERSION   = "1.0"
+
+	// Register index of the entries targeting a PCR without RTMR
+	RTMR_INDEX_NONE = -1
+)
+
+var (
+	ImaEventlogNotFoundErr = pkgerrors.New("IMA eventlog not found.")
+	InvalidImaEventlogErr  = pkgerrors.New("IMA eventlog with invalid data")
+)
+
+/* Bytes encoded as hex string in JSON */
+type HexBytes []byte
+
+func (h HexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(h)), nil
+}
+
+func (h *HexBytes) UnmarshalText(text []byte) error {
+	data, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*h = data
+	return nil
+}
+
+type ImaEventLog struct {
+	PcrIndex            uint32   `json:"pcr_index"`
+	RtmrIndex           int      `json:"rtmr_index"`
+	TemplateHash        HexBytes `json:"template_hash"`
+	TemplateName        string   `json:"template_name"`
+	FileDigestAlgorithm string   `json:"file_digest_algorithm"`
+	FileDigest          HexBytes `json:"file_digest"`
+	FileName            string   `json:"file_name"`
+	Signature           HexBytes `json:"signature,omitempty"`
+	TemplateData        HexBytes `json:"template_data,omitempty"`
+}
+
+type ImaEventLogs struct {
+	Version   string        `json:"version"`
+	EventLogs []ImaEventLog `json:"eventlogs"`
+	// TotalCount is the number of events before the range is applied
+	TotalCount int `json:"-"`
+}
+
+// GetRtmrIndexByPcr maps the PCR to the RTMR as defined by the TDX virtual firmware
+// design guide: PCR[1,7] to RTMR[0], PCR[2-6] to RTMR[1] and PCR[8-15] to RTMR[2].
+// PCR[0], which maps to MRTD, and the other PCRs return RTMR_INDEX_NONE.
+func GetRtmrIndexByPcr(pcr uint32) int {
+	switch {
+	case pcr == 1 || pcr == 7:
+		return 0
+	case pcr >= 2 && pcr <= 6:
+		return 1
+	case pcr >= 8 && pcr <= 15:
+		return 2
+	}
+	return RTMR_INDEX_NONE
+}
+
+func GetImaEventlog(start_position int, count int) (string, error) {
+
+	eventlogs, err := GetImaEventlogs(start_position, count)
+	if err != nil {
+		return "", err
+	}
+
+	return MarshalImaEventlogs(eventlogs)
+}
+
+// GetImaEventlogs reads the binary runtime measurement list, or the ASCII one if the
+// binary list is not available, from the mounted location first and then the host.
+// Only the measurements appended since the previous call are parsed.
+func GetImaEventlogs(start_position int, count int) (ImaEventLogs, error) {
+
+	var eventlogs []ImaEventLog
+	var data []byte
+
+	binaryLocations := []string{locations.ImaBinaryEventlogMount, locations.ImaBinaryEventlog}
+	asciiLocations := []string{locations.ImaAsciiEventlogMount, locations.ImaAsciiEventlog}
+	/* a node without IMA has none of the lists, other read errors are kept for the caller */
+	err := ImaEventlogNotFoundErr
+
+	for i, location := range append(binaryLocations, asciiLocations...) {
+		if location == "" {
+			continue
+		}
+		var readErr error
+		data, readErr = os.ReadFile(location)
+		if readErr != nil {
+			if !os.IsNotExist(readErr) && err == ImaEventlogNotFoundErr {
+				err = readErr
+			}
+			continue
+		}
+		err = nil
+
+		parse := parseImaAsciiEventlogs
+		if i < len(binaryLocations) {
+			parse = parseImaBinaryEventlogs
+		}
+		eventlogs, err = imaEventlogCache.get(location, data, parse)
+		if err != nil {
+			return ImaEventLogs{}, err
+		}
+		break
+	}
+	if err != nil {
+		log.Println("IMA runtime measurements not found")
+		return ImaEventLogs{}, err
+	}
+
+	if len(eventlogs) == 0 {
+		return ImaEventLogs{}, ImaEventlogNotFoundErr
+	}
+
+	return getImaEventlogsInRange(eventlogs, start_position, count)
+}
+
+func getImaEventlogsInRange(eventlogs []ImaEventLog, position int, count int) (ImaEventLogs, error) {
+
+	start, end, err := GetPageRange(len(eventlogs), position, count)
+	if err != nil {
+		return ImaEventLogs{}, err
+	}
+
+	return ImaEventLogs{Version: IMA_EVENTLOG_VERSION, EventLogs: eventlogs[start:end], TotalCount: len(eventlogs)}, nil
+}
+
+func MarshalImaEventlogs(eventlogs ImaEventLogs) (string, error) {
+	eventlogs_str, err := json.Marshal(eventlogs)
+	if err != nil {
+		log.Println("Error in marshaling IMA event logs")
+		return "", err
+	}
+	return string(eventlogs_str), nil
+}
+
+func UnmarshalImaEventlogs(data []byte) (ImaEventLogs, error) {
+	eventlogs := ImaEventLogs{}
+	if err := json.Unmarshal(data, &eventlogs); err != nil {
+		return ImaEventLogs{}, err
+	}
+
+	if eventlogs.Version != IMA_EVENTLOG_VERSION {
+		log.Println("Unsupported IMA eventlog schema version", eventlogs.Version)
+		return ImaEventLogs{}, InvalidEventlogSchemaErr
+	}
+	return eventlogs, nil
+}
+
+/*
+Each entry of the binary list is: PCR index (u32), template hash, template name size (u32),
+template name, template data size (u32, absent for the ima template) and template data.
+*/
+func parseImaBinaryEventlogs(data []byte) ([]ImaEventLog, error) {
+
+	var nameSize, size uint32
+	var dataSize uint64
+	var err error
+
+	eventlogs := []ImaEventLog{}
+	index := 0
+	for index < len(data) {
+		start := index
+		eventlog := ImaEventLog{}
+
+		eventlog.PcrIndex, index, err = getUint32Object(data, index)
+		if err != nil {
+			return nil, getEventOffsetErr(err, len(eventlogs), start)
+		}
+		eventlog.RtmrIndex = GetRtmrIndexByPcr(eventlog.PcrIndex)
+
+		if IMA_TEMPLATE_HASH_SIZE > len(data)-index {
+			return nil, getEventOffsetErr(InvalidImaEventlogErr, len(eventlogs), start)
+		}
+		eventlog.TemplateHash = data[index : index+IMA_TEMPLATE_HASH_SIZE]
+		index += IMA_TEMPLATE_HASH_SIZE
+
+		nameSize, index, err = getUint32Object(data, index)
+		if err != nil {
+			return nil, getEventOffsetErr(err, len(eventlogs), start)
+		}
+		if uint64(nameSize) > uint64(len(data)-index) {
+			return nil, getEventOffsetErr(InvalidImaEventlogErr, len(eventlogs), start)
+		}
+		eventlog.TemplateName = string(data[index : index+int(nameSize)])
+		index += int(nameSize)
+
+		if eventlog.TemplateName == IMA_TEMPLATE_IMA {
+			/* digest without size followed by the file name with size */
+			dataSize = IMA_DIGEST_SIZE
+			if index+IMA_DIGEST_SIZE+4 <= len(data) {
+				size, _, _ = getUint32Object(data, index+IMA_DIGEST_SIZE)
+				dataSize = uint64(IMA_DIGEST_SIZE+4) + uint64(size)
+			}
+		} else {
+			size, index, err = getUint32Object(data, index)
+			if err != nil {
+				return nil, getEventOffsetErr(err, len(eventlogs), start)
+			}
+			dataSize = uint64(size)
+		}
+
+		if dataSize > uint64(len(data)-index) {
+			return nil, getEventOffsetErr(InvalidImaEventlogErr, len(eventlogs), start)
+		}
+		eventlog.TemplateData = data[index : index+int(dataSize)]
+		index += int(dataSize)
+
+		err = parseImaTemplateData(&eventlog)
+		if err != nil {
+			return nil, getEventOffsetErr(err, len(eventlogs), start)
+		}
+
+		eventlogs = append(eventlogs, eventlog)
+	}
+
+	return eventlogs, nil
+}
+
+func parseImaTemplateData(eventlog *ImaEventLog) error {
+
+	data := eventlog.TemplateData
+	if eventlog.TemplateName == IMA_TEMPLATE_IMA {
+		if len(data) < IMA_DIGEST_SIZE+4 {
+			return InvalidImaEventlogErr
+		}
+		eventlog.FileDigestAlgorithm = "sha1"
+		eventlog.FileDigest = data[:IMA_DIGEST_SIZE]
+		eventlog.FileName = string(bytes.TrimRight(data[IMA_DIGEST_SIZE+4:], "\x00"))
+		return nil
+	}
+
+	/* The other templates are a list of fields with size */
+	var fields [][]byte
+	index := 0
+	for index < len(data) {
+		size, next, err := getUint32Object(data, index)
+		if err != nil {
+			return InvalidImaEventlogErr
+		}
+		if uint64(size) > uint64(len(data)-next) {
+			return InvalidImaEventlogErr
+		}
+		fields = append(fields, data[next:next+int(size)])
+		index = next + int(size)
+	}
+
+	/* Templates ima-ng, ima-sig and ima-modsig start with the d-ng and n-ng fields */
+	if len(fields) >= 2 {
+		eventlog.FileDigestAlgorithm, eventlog.FileDigest = parseImaDigestField(fields[0])
+		eventlog.FileName = string(bytes.TrimRight(fields[1], "\x00"))
+	}
+	if (eventlog.TemplateName == IMA_TEMPLATE_IMA_SIG || eventlog.TemplateName == IMA_TEMPLATE_IMA_MODSIG) &&
+		len(fields) >= 3 && len(fields[2]) > 0 {
+		eventlog.Signature = fields[2]
+	}
+
+	return nil
+}
+
+/* The d-ng field is "<algorithm>:\0" followed by the digest */
+func parseImaDigestField(field []byte) (string, []byte) {
+	separator := bytes.Index(field, []byte(":\x00"))
+	if separator < 0 {
+		return "sha1", field
+	}
+	return string(field[:separator]), field[separator+2:]
+}
+
+/*
+Each line of the ASCII list is: PCR index, template hash, template name, file digest
+("<algorithm>:<hex>" except for the ima template), file name and, for ima-sig, the
+optional signature.
+*/
+func parseImaAsciiEventlogs(data []byte) ([]ImaEventLog, error) {
+
+	eventlogs := []ImaEventLog{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, CHUNK_SIZE), len(data)+1)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum += 1
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		eventlog, err := parseImaAsciiLine(line)
+		if err != nil {
+			return nil, pkgerrors.WithMessagef(err, "Failed to parse IMA eventlog line %d", lineNum)
+		}
+		eventlogs = append(eventlogs, eventlog)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return eventlogs, nil
+}
+
+func parseImaAsciiLine(line string) (ImaEventLog, error) {
+
+	eventlog := ImaEventLog{}
+	fields := strings.SplitN(line, " ", 5)
+	if len(fields) < 5 {
+		return ImaEventLog{}, InvalidImaEventlogErr
+	}
+
+	pcr, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return ImaEventLog{}, InvalidImaEventlogErr
+	}
+	eventlog.PcrIndex = uint32(pcr)
+	eventlog.RtmrIndex = GetRtmrIndexByPcr(eventlog.PcrIndex)
+
+	eventlog.TemplateHash, err = hex.DecodeString(fields[1])
+	if err != nil {
+		return ImaEventLog{}, InvalidImaEventlogErr
+	}
+	eventlog.TemplateName = fields[2]
+
+	algorithm, digest, found := strings.Cut(fields[3], ":")
+	if !found {
+		algorithm, digest = "sha1", fields[3]
+	}
+	eventlog.FileDigestAlgorithm = algorithm
+	eventlog.FileDigest, err = hex.DecodeString(digest)
+	if err != nil {
+		return ImaEventLog{}, InvalidImaEventlogErr
+	}
+
+	eventlog.FileName = fields[4]
+	if eventlog.TemplateName == IMA_TEMPLATE_IMA_SIG || eventlog.TemplateName == IMA_TEMPLATE_IMA_MODSIG {
+		/* The file name may contain spaces, the signature is the last hex encoded field */
+		if separator := strings.LastIndex(fields[4], " "); separator > 0 {
+			if signature, err := hex.DecodeString(fields[4][separator+1:]); err == nil && len(signature) > 0 {
+				eventlog.FileName = fields[4][:separator]
+				eventlog.Signature = signature
+			}
+		}
+	}
+
+	return eventlog, nil
+}
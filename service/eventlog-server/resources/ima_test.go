@@ -0,0 +1,238 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	IMA_ASCII_EVENTLOG = "10 91f34b5c671d73504b274a919661cf80dab1e127 ima-ng sha1:1801e1be3e65ef1eaa5c16617bec8f1274eaf6b3 boot_aggregate\n" +
+		"10 8b1683287f61f96e5448f40bdef6df32be86486a ima 6a4a3b3bb0a8a2c5d8a0a3f37e7a1f0b6c1a3d2e /usr/lib/systemd/systemd\n" +
+		"10 ec8a4a8b1f1df1c2b4f1a07b6c2e2dd86d2e6c2c ima-sig sha256:a2c3b7f8e0a1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e5d6c7 /usr/bin/my app 030204aabbccdd\n" +
+		"11 d3c1f3a2b4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9 ima-sig sha256:b2c3b7f8e0a1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e5d6c7 /etc/hosts\n"
+)
+
+func buildImaBinaryEntry(pcr uint32, templateName string, templateData []byte) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, pcr)
+	buf.Write(bytes.Repeat([]byte{0xaa}, IMA_TEMPLATE_HASH_SIZE))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(templateName)))
+	buf.WriteString(templateName)
+	if templateName != IMA_TEMPLATE_IMA {
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(templateData)))
+	}
+	buf.Write(templateData)
+	return buf.Bytes()
+}
+
+func buildImaTemplateFields(fields ...[]byte) []byte {
+	var buf bytes.Buffer
+	for _, field := range fields {
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(field)))
+		buf.Write(field)
+	}
+	return buf.Bytes()
+}
+
+func buildImaBinaryEventlog() []byte {
+	var data []byte
+
+	/* ima template: digest without size and file name with size */
+	imaData := bytes.Repeat([]byte{0x1}, IMA_DIGEST_SIZE)
+	imaData = binary.LittleEndian.AppendUint32(imaData, uint32(len("boot_aggregate")))
+	imaData = append(imaData, "boot_aggregate"...)
+	data = append(data, buildImaBinaryEntry(10, IMA_TEMPLATE_IMA, imaData)...)
+
+	digest := append([]byte("sha256:\x00"), bytes.Repeat([]byte{0x2}, 32)...)
+	data = append(data, buildImaBinaryEntry(10, IMA_TEMPLATE_IMA_NG,
+		buildImaTemplateFields(digest, []byte("/usr/bin/bash\x00")))...)
+	data = append(data, buildImaBinaryEntry(10, IMA_TEMPLATE_IMA_SIG,
+		buildImaTemplateFields(digest, []byte("/usr/bin/ls\x00"), []byte{0x3, 0x2, 0x4}))...)
+	data = append(data, buildImaBinaryEntry(10, IMA_TEMPLATE_IMA_SIG,
+		buildImaTemplateFields(digest, []byte("/etc/hosts\x00"), []byte{}))...)
+
+	return data
+}
+
+func TestGetRtmrIndexByPcr(t *testing.T) {
+	tests := []struct {
+		pcr      uint32
+		expected int
+	}{
+		{0, RTMR_INDEX_NONE},
+		{1, 0},
+		{7, 0},
+		{4, 1},
+		{10, 2},
+		{15, 2},
+		{16, RTMR_INDEX_NONE},
+	}
+
+	for _, tt := range tests {
+		if rtmr := GetRtmrIndexByPcr(tt.pcr); rtmr != tt.expected {
+			t.Errorf("GetRtmrIndexByPcr(%d) = %d, want %d", tt.pcr, rtmr, tt.expected)
+		}
+	}
+}
+
+func TestParseImaBinaryEventlogs(t *testing.T) {
+	eventlogs, err := parseImaBinaryEventlogs(buildImaBinaryEventlog())
+	if err != nil || len(eventlogs) != 4 {
+		t.Fatalf("parseImaBinaryEventlogs() = %d, %v want 4 entries", len(eventlogs), err)
+	}
+
+	ima := eventlogs[0]
+	if ima.TemplateName != IMA_TEMPLATE_IMA || ima.FileDigestAlgorithm != "sha1" ||
+		!bytes.Equal(ima.FileDigest, bytes.Repeat([]byte{0x1}, IMA_DIGEST_SIZE)) || ima.FileName != "boot_aggregate" ||
+		ima.PcrIndex != 10 || ima.RtmrIndex != 2 {
+		t.Fatalf("parseImaBinaryEventlogs() = %+v, unexpected ima entry", ima)
+	}
+
+	imaNg := eventlogs[1]
+	if imaNg.TemplateName != IMA_TEMPLATE_IMA_NG || imaNg.FileDigestAlgorithm != "sha256" ||
+		!bytes.Equal(imaNg.FileDigest, bytes.Repeat([]byte{0x2}, 32)) || imaNg.FileName != "/usr/bin/bash" ||
+		imaNg.Signature != nil {
+		t.Fatalf("parseImaBinaryEventlogs() = %+v, unexpected ima-ng entry", imaNg)
+	}
+
+	imaSig := eventlogs[2]
+	if imaSig.FileName != "/usr/bin/ls" || !bytes.Equal(imaSig.Signature, []byte{0x3, 0x2, 0x4}) ||
+		!bytes.Equal(imaSig.TemplateHash, bytes.Repeat([]byte{0xaa}, IMA_TEMPLATE_HASH_SIZE)) {
+		t.Fatalf("parseImaBinaryEventlogs() = %+v, unexpected ima-sig entry", imaSig)
+	}
+
+	if eventlogs[3].FileName != "/etc/hosts" || eventlogs[3].Signature != nil {
+		t.Fatalf("parseImaBinaryEventlogs() = %+v, unexpected unsigned ima-sig entry", eventlogs[3])
+	}
+}
+
+func TestParseImaBinaryEventlogsInvalidData(t *testing.T) {
+	data := buildImaBinaryEventlog()
+
+	for _, size := range []int{3, 20, 30, 40, len(data) - 1} {
+		_, err := parseImaBinaryEventlogs(data[:size])
+		if err == nil {
+			t.Fatalf("parseImaBinaryEventlogs() with %d bytes should return error", size)
+		}
+	}
+
+	invalidField := buildImaBinaryEntry(10, IMA_TEMPLATE_IMA_NG, []byte{0xff, 0xff, 0xff, 0xff})
+	_, err := parseImaBinaryEventlogs(invalidField)
+	if pkgerrors.Cause(err) != InvalidImaEventlogErr || !strings.Contains(err.Error(), "offset 0x0") {
+		t.Fatalf("parseImaBinaryEventlogs() error = %v, want %v", err, InvalidImaEventlogErr)
+	}
+}
+
+func TestParseImaAsciiEventlogs(t *testing.T) {
+	eventlogs, err := parseImaAsciiEventlogs([]byte(IMA_ASCII_EVENTLOG))
+	if err != nil || len(eventlogs) != 4 {
+		t.Fatalf("parseImaAsciiEventlogs() = %d, %v want 4 entries", len(eventlogs), err)
+	}
+
+	if eventlogs[0].TemplateName != IMA_TEMPLATE_IMA_NG || eventlogs[0].FileDigestAlgorithm != "sha1" ||
+		eventlogs[0].FileName != "boot_aggregate" || len(eventlogs[0].TemplateHash) != IMA_TEMPLATE_HASH_SIZE {
+		t.Fatalf("parseImaAsciiEventlogs() = %+v, unexpected ima-ng entry", eventlogs[0])
+	}
+
+	if eventlogs[1].TemplateName != IMA_TEMPLATE_IMA || eventlogs[1].FileDigestAlgorithm != "sha1" ||
+		eventlogs[1].FileName != "/usr/lib/systemd/systemd" {
+		t.Fatalf("parseImaAsciiEventlogs() = %+v, unexpected ima entry", eventlogs[1])
+	}
+
+	if eventlogs[2].FileDigestAlgorithm != "sha256" || len(eventlogs[2].FileDigest) != 32 ||
+		eventlogs[2].FileName != "/usr/bin/my app" || !bytes.Equal(eventlogs[2].Signature, []byte{0x3, 0x2, 0x4, 0xaa, 0xbb, 0xcc, 0xdd}) {
+		t.Fatalf("parseImaAsciiEventlogs() = %+v, unexpected ima-sig entry", eventlogs[2])
+	}
+
+	if eventlogs[3].PcrIndex != 11 || eventlogs[3].RtmrIndex != 2 || eventlogs[3].FileName != "/etc/hosts" || eventlogs[3].Signature != nil {
+		t.Fatalf("parseImaAsciiEventlogs() = %+v, unexpected unsigned ima-sig entry", eventlogs[3])
+	}
+
+	_, err = parseImaAsciiEventlogs([]byte("10 zz ima-ng sha1:00 boot_aggregate\n"))
+	if pkgerrors.Cause(err) != InvalidImaEventlogErr || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("parseImaAsciiEventlogs() error = %v, want %v", err, InvalidImaEventlogErr)
+	}
+}
+
+func TestGetImaEventlogsInRange(t *testing.T) {
+	eventlogs, _ := parseImaAsciiEventlogs([]byte(IMA_ASCII_EVENTLOG))
+
+	tests := []struct {
+		name     string
+		position int
+		count    int
+		expected int
+		err      bool
+	}{
+		{"all", 0, 0, 4, false},
+		{"first", 0, 1, 1, false},
+		{"last", 3, 1, 1, false},
+		{"last page", 3, 2, 1, false},
+		{"from position", 2, 0, 2, false},
+		{"at end", 4, 0, 0, false},
+		{"beyond end", 5, 0, 0, true},
+		{"negative", -1, 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := getImaEventlogsInRange(eventlogs, tt.position, tt.count)
+			if (err != nil) != tt.err || len(result.EventLogs) != tt.expected {
+				t.Fatalf("getImaEventlogsInRange(%d, %d) = %d, %v want %d", tt.position, tt.count, len(result.EventLogs), err, tt.expected)
+			}
+		})
+	}
+}
+
+/* A node without IMA has none of the runtime measurement lists */
+func TestGetImaEventlogsNotFound(t *testing.T) {
+	defer SetLocations(DefaultLocations())
+
+	dir := t.TempDir()
+	SetLocations(Locations{
+		ImaBinaryEventlogMount: filepath.Join(dir, "mount", "binary_runtime_measurements"),
+		ImaBinaryEventlog:      filepath.Join(dir, "binary_runtime_measurements"),
+		ImaAsciiEventlog:       filepath.Join(dir, "ascii_runtime_measurements"),
+	})
+	if _, err := GetImaEventlogs(0, 0); err != ImaEventlogNotFoundErr {
+		t.Fatalf("GetImaEventlogs() error = %v, want %v", err, ImaEventlogNotFoundErr)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "ascii_runtime_measurements"), []byte(IMA_ASCII_EVENTLOG), 0600); err != nil {
+		t.Fatalf("Failed to write IMA eventlog: %v", err)
+	}
+	if eventlogs, err := GetImaEventlogs(0, 0); err != nil || len(eventlogs.EventLogs) != 4 {
+		t.Fatalf("GetImaEventlogs() = %d, %v want 4 eventlogs", len(eventlogs.EventLogs), err)
+	}
+}
+
+func TestMarshalImaEventlogs(t *testing.T) {
+	eventlogs, _ := parseImaBinaryEventlogs(buildImaBinaryEventlog())
+	imaEventlogs := ImaEventLogs{Version: IMA_EVENTLOG_VERSION, EventLogs: eventlogs}
+
+	data, err := MarshalImaEventlogs(imaEventlogs)
+	if err != nil || !strings.Contains(data, `"file_name":"/usr/bin/ls","signature":"030204"`) {
+		t.Fatalf("MarshalImaEventlogs() = %s, %v", data, err)
+	}
+
+	parsed, err := UnmarshalImaEventlogs([]byte(data))
+	if err != nil || len(parsed.EventLogs) != 4 || !bytes.Equal(parsed.EventLogs[1].FileDigest, eventlogs[1].FileDigest) {
+		t.Fatalf("UnmarshalImaEventlogs() = %+v, %v", parsed, err)
+	}
+
+	_, err = UnmarshalImaEventlogs([]byte(`{"version":"0.1"}`))
+	if err != InvalidEventlogSchemaErr {
+		t.Fatalf("UnmarshalImaEventlogs() error = %v, want %v", err, InvalidEventlogSchemaErr)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+/*
+Locations are the files the event logs are read from. The mounted location of a file is
+read first and the host location if nothing is mounted, an empty mounted location is
+never read. The defaults are the firmware and securityfs files of the node, the server
+configuration can point them to custom mounts or recorded fixture files.
+*/
+type Locations struct {
+	CcelTable              string
+	CcelData               string
+	CcelTableMount         string
+	CcelDataMount          string
+	TpmEventlog            string
+	ImaBinaryEventlog      string
+	ImaAsciiEventlog       string
+	ImaBinaryEventlogMount string
+	ImaAsciiEventlogMount  string
+	// The runtime event log is not mounted, an empty location is never read
+	RuntimeEventlog string
+}
+
+var locations = DefaultLocations()
+
+func DefaultLocations() Locations {
+	return Locations{
+		CcelTable:              CCEL_FILE_LOCATION,
+		CcelData:               CCEL_DATA_LOCATION,
+		CcelTableMount:         CCEL_FILE_MOUNT_LOCATION,
+		CcelDataMount:          CCEL_DATA_MOUNT_LOCATION,
+		TpmEventlog:            TPM_EVENT_LOG_LOCATION,
+		ImaBinaryEventlog:      IMA_BINARY_EVENT_LOG_LOCATION,
+		ImaAsciiEventlog:       IMA_ASCII_EVENT_LOG_LOCATION,
+		ImaBinaryEventlogMount: IMA_BINARY_EVENT_LOG_MOUNT_LOCATION,
+		ImaAsciiEventlogMount:  IMA_ASCII_EVENT_LOG_MOUNT_LOCATION,
+		RuntimeEventlog:        RUNTIME_EVENT_LOG_LOCATION,
+	}
+}
+
+// SetLocations changes the event log locations, it is called once before serving requests.
+func SetLocations(l Locations) {
+	locations = l
+}
+
+func GetLocations() Locations {
+	return locations
+}
@@ -0,0 +1,48 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetLocations(t *testing.T) {
+	defer SetLocations(DefaultLocations())
+
+	fixtures := t.TempDir()
+	imaLocation := filepath.Join(fixtures, "ima_fixture")
+	if err := os.WriteFile(imaLocation, buildImaBinaryEventlog(), 0600); err != nil {
+		t.Fatalf("Failed to write IMA eventlog: %v", err)
+	}
+
+	/* the fixtures are read instead of the securityfs files, whatever their names */
+	SetLocations(Locations{
+		TpmEventlog:            writeTpmEventlogFile(t, 4),
+		ImaBinaryEventlogMount: filepath.Join(fixtures, "not_mounted"),
+		ImaBinaryEventlog:      imaLocation,
+	})
+
+	eventlogs, err := GetTpmEventlogs(0, 0, EventlogFilter{})
+	if err != nil || len(eventlogs.EventLogs) != 4 {
+		t.Errorf("GetTpmEventlogs() = %d, %v want 4 events", len(eventlogs.EventLogs), err)
+	}
+
+	imaEventlogs, err := GetImaEventlogs(0, 0)
+	if err != nil || len(imaEventlogs.EventLogs) != 4 {
+		t.Errorf("GetImaEventlogs() = %d, %v want 4 measurements", len(imaEventlogs.EventLogs), err)
+	}
+
+	/* empty locations are never read */
+	if _, err := GetTdxEventlogs(0, 0, EventlogFilter{}); !os.IsNotExist(err) {
+		t.Errorf("Err -> Want: file not found, Got: %v", err)
+	}
+	SetLocations(Locations{})
+	if _, err := GetImaEventlogs(0, 0); err != ImaEventlogNotFoundErr {
+		t.Errorf("Err -> Want: %v, Got: %v", ImaEventlogNotFoundErr, err)
+	}
+}
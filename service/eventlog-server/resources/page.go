@@ -0,0 +1,91 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"strconv"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/*
+The event logs of every category are paged the same way. The start position is the index
+of the first event among the events matching the request, from 0 to the total count. The
+count is the maximum number of events to return, 0 returns all the events from the start
+position, and the last page holds the remaining events. The continuation token carries the
+start position of the next page, bound to the request it was returned for.
+*/
+const (
+	PAGE_TOKEN_VERSION    = "v1"
+	PAGE_TOKEN_SCOPE_SIZE = 8
+
+	// The page of the GetEventlogStream response is returned in the header metadata
+	EVENTLOG_TOTAL_COUNT_KEY     = "eventlog-total-count"
+	EVENTLOG_START_POSITION_KEY  = "eventlog-start-position"
+	EVENTLOG_COUNT_KEY           = "eventlog-count"
+	EVENTLOG_NEXT_PAGE_TOKEN_KEY = "eventlog-next-page-token"
+)
+
+var (
+	InvalidEventlogRangeErr = pkgerrors.New("Invalid start position or count of event log")
+	InvalidPageTokenErr     = pkgerrors.New("Invalid page token")
+)
+
+// GetPageRange returns the start and end index of the page among total events.
+func GetPageRange(total int, position int, count int) (int, int, error) {
+	if position < 0 || count < 0 || position > total {
+		log.Printf("Invalid range from %d of %d events out of %d", position, count, total)
+		return 0, 0, InvalidEventlogRangeErr
+	}
+
+	end := total
+	if count != 0 && count < total-position {
+		end = position + count
+	}
+
+	return position, end, nil
+}
+
+// EncodePageToken returns the token of the next page, or an empty string if there is none.
+// The scope identifies the request, so that the token cannot be used with another one.
+func EncodePageToken(position int, total int, scope string) string {
+	if position >= total {
+		return ""
+	}
+
+	token := PAGE_TOKEN_VERSION + ":" + strconv.Itoa(position) + ":" + getPageTokenScope(scope)
+	return base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+func DecodePageToken(token string, scope string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, InvalidPageTokenErr
+	}
+
+	fields := strings.Split(string(data), ":")
+	if len(fields) != 3 || fields[0] != PAGE_TOKEN_VERSION || fields[2] != getPageTokenScope(scope) {
+		log.Println("Page token not issued for the request")
+		return 0, InvalidPageTokenErr
+	}
+
+	position, err := strconv.Atoi(fields[1])
+	if err != nil || position < 0 {
+		return 0, InvalidPageTokenErr
+	}
+
+	return position, nil
+}
+
+func getPageTokenScope(scope string) string {
+	digest := sha256.Sum256([]byte(scope))
+	return hex.EncodeToString(digest[:PAGE_TOKEN_SCOPE_SIZE])
+}
@@ -0,0 +1,70 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"testing"
+)
+
+func TestGetPageRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int
+		position    int
+		count       int
+		start       int
+		end         int
+		expectedErr error
+	}{
+		{"All", 10, 0, 0, 0, 10, nil},
+		{"First page", 10, 0, 4, 0, 4, nil},
+		{"Middle page", 10, 4, 4, 4, 8, nil},
+		{"Last page", 10, 8, 4, 8, 10, nil},
+		{"Exact last page", 10, 6, 4, 6, 10, nil},
+		{"From position", 10, 3, 0, 3, 10, nil},
+		{"At end", 10, 10, 0, 10, 10, nil},
+		{"Empty log", 0, 0, 5, 0, 0, nil},
+		{"Beyond end", 10, 11, 1, 0, 0, InvalidEventlogRangeErr},
+		{"Negative position", 10, -1, 1, 0, 0, InvalidEventlogRangeErr},
+		{"Negative count", 10, 0, -1, 0, 0, InvalidEventlogRangeErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := GetPageRange(tt.total, tt.position, tt.count)
+			if err != tt.expectedErr || start != tt.start || end != tt.end {
+				t.Errorf("GetPageRange(%d, %d, %d) = %d, %d, %v want %d, %d, %v", tt.total, tt.position, tt.count,
+					start, end, err, tt.start, tt.end, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestPageToken(t *testing.T) {
+	token := EncodePageToken(8, 10, "PAAS/TDX_EVENTLOG")
+	if token == "" {
+		t.Fatalf("EncodePageToken(8, 10) want token of the next page")
+	}
+
+	position, err := DecodePageToken(token, "PAAS/TDX_EVENTLOG")
+	if err != nil || position != 8 {
+		t.Fatalf("DecodePageToken(%s) = %d, %v want 8", token, position, err)
+	}
+
+	if _, err = DecodePageToken(token, "PAAS/TPM_EVENTLOG"); err != InvalidPageTokenErr {
+		t.Errorf("DecodePageToken(token of another request) = %v want %v", err, InvalidPageTokenErr)
+	}
+
+	for _, invalid := range []string{"!", "djE6OA", EncodePageToken(-1, 10, "PAAS/TDX_EVENTLOG")} {
+		if _, err = DecodePageToken(invalid, "PAAS/TDX_EVENTLOG"); err != InvalidPageTokenErr {
+			t.Errorf("DecodePageToken(%q) = %v want %v", invalid, err, InvalidPageTokenErr)
+		}
+	}
+
+	if token = EncodePageToken(10, 10, "PAAS/TDX_EVENTLOG"); token != "" {
+		t.Errorf("EncodePageToken(10, 10) = %q want no token after the last page", token)
+	}
+}
@@ -0,0 +1,359 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/*
+The JSON schema of the event logs returned by the eventlog server. Version 1.0 uses
+hex encoded digests, TCG algorithm registry names and base64 encoded event data. The
+CCEL table is only present for TDX event logs, the decoded event only for event types
+with a decoder. The data of the header and of the events is the binary record as read from
+the event log:
+
+	{
+	  "version": "1.0",
+	  "ccel_table": {"signature": "CCEL", "length": 56, "cc_type": 2, "laml": 65536, "lasa": 2113929216, ...},
+	  "header": {
+	    "register_index": 0,
+	    "event_type": 3,
+	    "algorithms": [{"algorithm_id": 12, "algorithm": "SHA384", "digest_size": 48}],
+	    "data": "<base64>"
+	  },
+	  "eventlogs": [{
+	    "register_index": 0,
+	    "event_type": 2147483659,
+	    "event_type_name": "EV_EFI_HANDOFF_TABLES2",
+	    "digests": [{"algorithm_id": 12, "algorithm": "SHA384", "digest": "<hex>"}],
+	    "event_size": 42,
+	    "event": "<base64>",
+	    "data": "<base64>",
+	    "decoded_event": {...}
+	  }]
+	}
+
+The legacy format, which is the JSON encoding of TDEventLogs with digests printed by
+fmt "%v", is kept for one release behind the legacy format flag of the server.
+*/
+const (
+	EVENTLOG_SCHEMA_VERSION = "1.0"
+)
+
+var (
+	InvalidEventlogSchemaErr = pkgerrors.New("Eventlog with unsupported schema")
+)
+
+type EventLogsV1 struct {
+	Version   string            `json:"version"`
+	CcelTable *CcelTable        `json:"ccel_table,omitempty"`
+	Header    EventLogHeaderV1  `json:"header"`
+	EventLogs []EventLogEntryV1 `json:"eventlogs"`
+}
+
+type EventLogHeaderV1 struct {
+	RegisterIndex uint32                `json:"register_index"`
+	EventType     uint32                `json:"event_type"`
+	Algorithms    []EventLogAlgorithmV1 `json:"algorithms"`
+	Data          string                `json:"data"`
+}
+
+type EventLogAlgorithmV1 struct {
+	AlgorithmId uint16 `json:"algorithm_id"`
+	Algorithm   string `json:"algorithm"`
+	DigestSize  uint16 `json:"digest_size"`
+}
+
+type EventLogEntryV1 struct {
+	RegisterIndex uint32             `json:"register_index"`
+	EventType     uint32             `json:"event_type"`
+	EventTypeName string             `json:"event_type_name"`
+	Digests       []EventLogDigestV1 `json:"digests"`
+	EventSize     uint32             `json:"event_size"`
+	Event         string             `json:"event"`
+	Data          string             `json:"data"`
+	DecodedEvent  interface{}        `json:"decoded_event,omitempty"`
+}
+
+type EventLogDigestV1 struct {
+	AlgorithmId uint16 `json:"algorithm_id"`
+	Algorithm   string `json:"algorithm"`
+	Digest      string `json:"digest"`
+}
+
+/* The event log shape before schema versioning */
+type legacyEventLog struct {
+	Rtmr        uint32
+	Etype       uint32
+	DigestCount uint32
+	Digests     []string
+	Data        []byte
+	Event       []byte
+	Length      int
+	EventSize   uint32
+	AlgorithmId uint16
+}
+
+type legacyEventLogs struct {
+	Header    TDEventLogSpecIdHeader
+	EventLogs []legacyEventLog
+}
+
+// MarshalEventlogs encodes the event logs with the current schema, or the legacy format if required.
+func MarshalEventlogs(eventlogs TDEventLogs, legacy bool) (string, error) {
+	var eventlogs_str []byte
+	var err error
+
+	if legacy {
+		eventlogs_str, err = json.Marshal(toLegacyEventlogs(eventlogs))
+	} else {
+		eventlogs_str, err = json.Marshal(toEventlogsV1(eventlogs))
+	}
+	if err != nil {
+		log.Println("Error in marshaling event logs")
+		return "", err
+	}
+	return string(eventlogs_str), nil
+}
+
+// UnmarshalEventlogs decodes the event logs of either the current schema or the legacy format.
+func UnmarshalEventlogs(data []byte) (TDEventLogs, error) {
+	var version struct {
+		Version *string `json:"version"`
+	}
+
+	if err := json.Unmarshal(data, &version); err != nil {
+		return TDEventLogs{}, err
+	}
+
+	if version.Version == nil {
+		legacy := legacyEventLogs{}
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return TDEventLogs{}, err
+		}
+		return fromLegacyEventlogs(legacy)
+	}
+
+	if *version.Version != EVENTLOG_SCHEMA_VERSION {
+		log.Println("Unsupported eventlog schema version", *version.Version)
+		return TDEventLogs{}, InvalidEventlogSchemaErr
+	}
+
+	eventlogsV1 := EventLogsV1{}
+	if err := json.Unmarshal(data, &eventlogsV1); err != nil {
+		return TDEventLogs{}, err
+	}
+	return fromEventlogsV1(eventlogsV1)
+}
+
+func toEventlogsV1(eventlogs TDEventLogs) EventLogsV1 {
+	var algIds []int
+
+	header := EventLogHeaderV1{
+		RegisterIndex: eventlogs.Header.Rtmr,
+		EventType:     eventlogs.Header.Etype,
+		Algorithms:    []EventLogAlgorithmV1{},
+		Data:          base64.StdEncoding.EncodeToString(eventlogs.Header.HeaderData),
+	}
+
+	for algId := range eventlogs.Header.DigestSizes {
+		algIds = append(algIds, int(algId))
+	}
+	sort.Ints(algIds)
+	for _, algId := range algIds {
+		header.Algorithms = append(header.Algorithms, EventLogAlgorithmV1{
+			AlgorithmId: uint16(algId),
+			Algorithm:   GetAlgorithmName(uint16(algId)),
+			DigestSize:  eventlogs.Header.DigestSizes[uint16(algId)],
+		})
+	}
+
+	entries := []EventLogEntryV1{}
+	for _, eventlog := range eventlogs.EventLogs {
+		entry := EventLogEntryV1{
+			RegisterIndex: eventlog.Rtmr,
+			EventType:     eventlog.Etype,
+			EventTypeName: GetEventTypeName(eventlog.Etype),
+			Digests:       []EventLogDigestV1{},
+			EventSize:     eventlog.EventSize,
+			Event:         base64.StdEncoding.EncodeToString(eventlog.Event),
+			Data:          base64.StdEncoding.EncodeToString(eventlog.Data),
+		}
+		for _, digest := range eventlog.Digests {
+			entry.Digests = append(entry.Digests, EventLogDigestV1{
+				AlgorithmId: digest.AlgorithmId,
+				Algorithm:   GetAlgorithmName(digest.AlgorithmId),
+				Digest:      hex.EncodeToString(digest.Digest),
+			})
+		}
+		decoded, err := DecodeEvent(eventlog.Etype, eventlog.Event)
+		if err != nil {
+			log.Printf("Failed to decode event of type %s: %v", entry.EventTypeName, err)
+		} else if decoded != nil {
+			entry.DecodedEvent = decoded
+		}
+		entries = append(entries, entry)
+	}
+
+	return EventLogsV1{
+		Version:   EVENTLOG_SCHEMA_VERSION,
+		CcelTable: eventlogs.CcelTable,
+		Header:    header,
+		EventLogs: entries,
+	}
+}
+
+func fromEventlogsV1(eventlogsV1 EventLogsV1) (TDEventLogs, error) {
+	var err error
+
+	eventlogs := TDEventLogs{CcelTable: eventlogsV1.CcelTable}
+	eventlogs.Header.Rtmr = eventlogsV1.Header.RegisterIndex
+	eventlogs.Header.Etype = eventlogsV1.Header.EventType
+	eventlogs.Header.DigestSizes = make(map[uint16]uint16)
+	for _, algorithm := range eventlogsV1.Header.Algorithms {
+		eventlogs.Header.DigestSizes[algorithm.AlgorithmId] = algorithm.DigestSize
+	}
+	eventlogs.Header.HeaderData, err = base64.StdEncoding.DecodeString(eventlogsV1.Header.Data)
+	if err != nil {
+		return TDEventLogs{}, err
+	}
+	eventlogs.Header.Length = len(eventlogs.Header.HeaderData)
+
+	for _, entry := range eventlogsV1.EventLogs {
+		eventlog := TDEventLog{
+			Rtmr:        entry.RegisterIndex,
+			Etype:       entry.EventType,
+			DigestCount: uint32(len(entry.Digests)),
+			EventSize:   entry.EventSize,
+		}
+
+		eventlog.Event, err = base64.StdEncoding.DecodeString(entry.Event)
+		if err != nil {
+			return TDEventLogs{}, err
+		}
+
+		eventlog.Data, err = base64.StdEncoding.DecodeString(entry.Data)
+		if err != nil {
+			return TDEventLogs{}, err
+		}
+		eventlog.Length = len(eventlog.Data)
+
+		for _, digest := range entry.Digests {
+			digestData, err := hex.DecodeString(digest.Digest)
+			if err != nil {
+				return TDEventLogs{}, err
+			}
+			eventlog.Digests = append(eventlog.Digests, TDEventLogDigest{AlgorithmId: digest.AlgorithmId, Digest: digestData})
+		}
+
+		eventlogs.EventLogs = append(eventlogs.EventLogs, eventlog)
+	}
+
+	return eventlogs, nil
+}
+
+func toLegacyEventlogs(eventlogs TDEventLogs) legacyEventLogs {
+	legacy := legacyEventLogs{Header: eventlogs.Header}
+
+	for _, eventlog := range eventlogs.EventLogs {
+		entry := legacyEventLog{
+			Rtmr:        eventlog.Rtmr,
+			Etype:       eventlog.Etype,
+			DigestCount: eventlog.DigestCount,
+			Data:        eventlog.Data,
+			Event:       eventlog.Event,
+			Length:      eventlog.Length,
+			EventSize:   eventlog.EventSize,
+		}
+		for _, digest := range eventlog.Digests {
+			entry.Digests = append(entry.Digests, fmt.Sprintf("%v", digest.Digest))
+			entry.AlgorithmId = digest.AlgorithmId
+		}
+		legacy.EventLogs = append(legacy.EventLogs, entry)
+	}
+
+	return legacy
+}
+
+func fromLegacyEventlogs(legacy legacyEventLogs) (TDEventLogs, error) {
+	eventlogs := TDEventLogs{Header: legacy.Header}
+
+	for _, entry := range legacy.EventLogs {
+		eventlog := TDEventLog{
+			Rtmr:        entry.Rtmr,
+			Etype:       entry.Etype,
+			DigestCount: entry.DigestCount,
+			Data:        entry.Data,
+			Event:       entry.Event,
+			Length:      entry.Length,
+			EventSize:   entry.EventSize,
+		}
+
+		for i, digestStr := range entry.Digests {
+			digestData, err := parseLegacyDigest(digestStr)
+			if err != nil {
+				return TDEventLogs{}, err
+			}
+
+			/* the legacy format only records the algorithm of the last digest */
+			algId := entry.AlgorithmId
+			if i != len(entry.Digests)-1 {
+				algId = getAlgorithmBySize(legacy.Header.DigestSizes, len(digestData))
+			}
+			eventlog.Digests = append(eventlog.Digests, TDEventLogDigest{AlgorithmId: algId, Digest: digestData})
+		}
+
+		eventlogs.EventLogs = append(eventlogs.EventLogs, eventlog)
+	}
+
+	return eventlogs, nil
+}
+
+/* Parse the digest printed by fmt "%v", e.g. "[12 34 56]" */
+func parseLegacyDigest(digestStr string) ([]byte, error) {
+	if !strings.HasPrefix(digestStr, "[") || !strings.HasSuffix(digestStr, "]") {
+		return nil, InvalidEventlogSchemaErr
+	}
+
+	fields := strings.Fields(digestStr[1 : len(digestStr)-1])
+	digest := make([]byte, 0, len(fields))
+	for _, field := range fields {
+		value, err := strconv.ParseUint(field, 10, 8)
+		if err != nil {
+			return nil, InvalidEventlogSchemaErr
+		}
+		digest = append(digest, uint8(value))
+	}
+
+	return digest, nil
+}
+
+func getAlgorithmBySize(digestSizes map[uint16]uint16, size int) uint16 {
+	var found uint16
+	matches := 0
+
+	for algId, digestSize := range digestSizes {
+		if int(digestSize) == size {
+			found = algId
+			matches++
+		}
+	}
+
+	if matches != 1 {
+		return 0
+	}
+	return found
+}
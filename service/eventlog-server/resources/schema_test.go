@@ -0,0 +1,114 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func getSampleEventlogs() TDEventLogs {
+	return TDEventLogs{
+		Header: TDEventLogSpecIdHeader{
+			Rtmr:        0,
+			Etype:       EVENT_TYPE_EV_NO_ACTION,
+			HeaderData:  []byte{0x1, 0x2},
+			Length:      2,
+			DigestSizes: map[uint16]uint16{TPM_ALG_SHA256: 2, TPM_ALG_SHA384: 3},
+		},
+		EventLogs: []TDEventLog{
+			{
+				Rtmr:        1,
+				Etype:       0x80000001,
+				DigestCount: 2,
+				Digests: []TDEventLogDigest{
+					{AlgorithmId: TPM_ALG_SHA256, Digest: []byte{0xab, 0xcd}},
+					{AlgorithmId: TPM_ALG_SHA384, Digest: []byte{0x1, 0x2, 0x3}},
+				},
+				Data:      []byte{0x2, 0x0, 0x0, 0x0, 0x1, 0x0, 0x0, 0x80, 0x2, 0x0, 0x0, 0x0, 0xb, 0x0, 0xab, 0xcd, 0xc, 0x0, 0x1, 0x2, 0x3, 0x3, 0x0, 0x0, 0x0, 'a', 'b', 'c'},
+				Length:    28,
+				EventSize: 3,
+				Event:     []byte("abc"),
+			},
+		},
+	}
+}
+
+func TestMarshalEventlogs(t *testing.T) {
+	eventlog, err := MarshalEventlogs(getSampleEventlogs(), false)
+	if err != nil {
+		t.Fatalf(`MarshalEventlogs(eventlogs, false) = %v want %v`, err, nil)
+	}
+
+	eventlogsV1 := EventLogsV1{}
+	err = json.Unmarshal([]byte(eventlog), &eventlogsV1)
+	if err != nil || eventlogsV1.Version != EVENTLOG_SCHEMA_VERSION || len(eventlogsV1.EventLogs) != 1 {
+		t.Fatalf(`MarshalEventlogs(eventlogs, false) = %s want eventlogs of schema %s`, eventlog, EVENTLOG_SCHEMA_VERSION)
+	}
+
+	entry := eventlogsV1.EventLogs[0]
+	if entry.Event != "YWJj" || len(entry.Digests) != 2 ||
+		entry.Digests[0].Algorithm != "SHA256" || entry.Digests[0].Digest != "abcd" ||
+		entry.Digests[1].Algorithm != "SHA384" || entry.Digests[1].Digest != "010203" {
+		t.Fatalf(`MarshalEventlogs(eventlogs, false) = %s want hex digests and base64 event`, eventlog)
+	}
+
+	if len(eventlogsV1.Header.Algorithms) != 2 || eventlogsV1.Header.Algorithms[0].Algorithm != "SHA256" {
+		t.Fatalf(`MarshalEventlogs(eventlogs, false) = %s want sorted header algorithms`, eventlog)
+	}
+}
+
+func TestUnmarshalEventlogs(t *testing.T) {
+	for _, legacy := range []bool{false, true} {
+		eventlog, err := MarshalEventlogs(getSampleEventlogs(), legacy)
+		if err != nil {
+			t.Fatalf(`MarshalEventlogs(eventlogs, %v) = %v want %v`, legacy, err, nil)
+		}
+
+		eventlogs, err := UnmarshalEventlogs([]byte(eventlog))
+		if err != nil || len(eventlogs.EventLogs) != 1 {
+			t.Fatalf(`UnmarshalEventlogs(%s) = %v want 1 eventlog`, eventlog, err)
+		}
+
+		entry := eventlogs.EventLogs[0]
+		if entry.Rtmr != 1 || entry.Etype != 0x80000001 || !bytes.Equal(entry.Event, []byte("abc")) || len(entry.Digests) != 2 ||
+			entry.Digests[0].AlgorithmId != TPM_ALG_SHA256 || !bytes.Equal(entry.Digests[0].Digest, []byte{0xab, 0xcd}) ||
+			entry.Digests[1].AlgorithmId != TPM_ALG_SHA384 || !bytes.Equal(entry.Digests[1].Digest, []byte{0x1, 0x2, 0x3}) {
+			t.Fatalf(`UnmarshalEventlogs(%s) = %v want the original eventlog`, eventlog, entry)
+		}
+
+		if eventlogs.Header.DigestSizes[TPM_ALG_SHA384] != 3 || !bytes.Equal(eventlogs.Header.HeaderData, []byte{0x1, 0x2}) {
+			t.Fatalf(`UnmarshalEventlogs(%s) = %v want the original header`, eventlog, eventlogs.Header)
+		}
+
+		/* the event logs survive the round trip whole, the raw event log is rebuilt from the data */
+		if want := getSampleEventlogs(); !reflect.DeepEqual(eventlogs, want) {
+			t.Fatalf(`UnmarshalEventlogs(%s) = %+v want %+v`, eventlog, eventlogs, want)
+		}
+	}
+}
+
+func TestMarshalLegacyEventlogs(t *testing.T) {
+	eventlog, err := MarshalEventlogs(getSampleEventlogs(), true)
+	if err != nil || !strings.Contains(eventlog, `"Digests":["[171 205]","[1 2 3]"]`) || !strings.Contains(eventlog, `"AlgorithmId":12`) {
+		t.Fatalf(`MarshalEventlogs(eventlogs, true) = %s, %v want legacy digests`, eventlog, err)
+	}
+}
+
+func TestUnmarshalEventlogsInvalidSchema(t *testing.T) {
+	_, err := UnmarshalEventlogs([]byte(`{"version": "0.1"}`))
+	if err != InvalidEventlogSchemaErr {
+		t.Fatalf(`UnmarshalEventlogs(version 0.1) = %v want %v`, err, InvalidEventlogSchemaErr)
+	}
+
+	_, err = UnmarshalEventlogs([]byte(`{"EventLogs": [{"Digests": ["12 34"]}]}`))
+	if err != InvalidEventlogSchemaErr {
+		t.Fatalf(`UnmarshalEventlogs(invalid legacy digest) = %v want %v`, err, InvalidEventlogSchemaErr)
+	}
+}
@@ -0,0 +1,326 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"log"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/* GUIDs defined in the UEFI specification */
+var (
+	EFI_GLOBAL_VARIABLE_GUID         = newEfiGuid("8be4df61-93ca-11d2-aa0d-00e098032b8c")
+	EFI_IMAGE_SECURITY_DATABASE_GUID = newEfiGuid("d719b2cb-3d3a-4596-a3bc-dad00e67656f")
+
+	EFI_CERT_SHA1_GUID        = newEfiGuid("826ca512-cf10-4ac9-b187-be01496631bd")
+	EFI_CERT_SHA256_GUID      = newEfiGuid("c1c41626-504c-4092-aca9-41f936934328")
+	EFI_CERT_SHA384_GUID      = newEfiGuid("ff3e5307-9fd0-48c9-85f1-8ad56c701e01")
+	EFI_CERT_SHA512_GUID      = newEfiGuid("093e0fae-a6c4-4f50-9f1b-d41e2b89c19a")
+	EFI_CERT_RSA2048_GUID     = newEfiGuid("3c5766e8-269c-4e34-aa14-ed776e85b3b6")
+	EFI_CERT_X509_GUID        = newEfiGuid("a5c059a1-94e4-4aa7-87b5-ab155c2bf072")
+	EFI_CERT_X509_SHA256_GUID = newEfiGuid("3bd2a492-96c0-4079-b420-fcf98ef103ed")
+	EFI_CERT_X509_SHA384_GUID = newEfiGuid("7076876e-80c2-4ee6-aad2-28b349a6865b")
+	EFI_CERT_X509_SHA512_GUID = newEfiGuid("446dbf63-2502-4cda-bcfa-2465d2b0fe9d")
+)
+
+const (
+	EFI_SIGNATURE_LIST_HEADER_SIZE = 28
+	EFI_SIGNATURE_OWNER_SIZE       = 16
+)
+
+var signatureTypeNames = map[EfiGuid]string{
+	EFI_CERT_SHA1_GUID:        "EFI_CERT_SHA1",
+	EFI_CERT_SHA256_GUID:      "EFI_CERT_SHA256",
+	EFI_CERT_SHA384_GUID:      "EFI_CERT_SHA384",
+	EFI_CERT_SHA512_GUID:      "EFI_CERT_SHA512",
+	EFI_CERT_RSA2048_GUID:     "EFI_CERT_RSA2048",
+	EFI_CERT_X509_GUID:        "EFI_CERT_X509",
+	EFI_CERT_X509_SHA256_GUID: "EFI_CERT_X509_SHA256",
+	EFI_CERT_X509_SHA384_GUID: "EFI_CERT_X509_SHA384",
+	EFI_CERT_X509_SHA512_GUID: "EFI_CERT_X509_SHA512",
+}
+
+var (
+	InvalidSignatureListErr = pkgerrors.New("EFI_SIGNATURE_LIST with invalid length")
+)
+
+type X509CertificateInfo struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	Sha256       string    `json:"sha256"`
+}
+
+/* EFI_SIGNATURE_DATA, either a certificate or a hash depending on the signature type */
+type EfiSignature struct {
+	Owner       EfiGuid              `json:"owner"`
+	Certificate *X509CertificateInfo `json:"certificate,omitempty"`
+	Hash        string               `json:"hash,omitempty"`
+	Data        []byte               `json:"data,omitempty"`
+}
+
+type EfiSignatureList struct {
+	SignatureType     EfiGuid        `json:"signature_type"`
+	SignatureTypeName string         `json:"signature_type_name"`
+	Signatures        []EfiSignature `json:"signatures"`
+}
+
+/* Signature database variable (PK, KEK, db or dbx) as measured in the event log */
+type SignatureDatabase struct {
+	Measured       bool               `json:"measured"`
+	Digest         string             `json:"digest"`
+	SignatureLists []EfiSignatureList `json:"signature_lists"`
+}
+
+type BootApplication struct {
+	RegisterIndex     uint32        `json:"register_index"`
+	DevicePath        string        `json:"device_path"`
+	AuthorityVariable string        `json:"authority_variable,omitempty"`
+	Authority         *EfiSignature `json:"authority,omitempty"`
+}
+
+type SecureBootState struct {
+	SecureBootMeasured bool              `json:"secure_boot_measured"`
+	SecureBootEnabled  bool              `json:"secure_boot_enabled"`
+	PK                 SignatureDatabase `json:"pk"`
+	KEK                SignatureDatabase `json:"kek"`
+	Db                 SignatureDatabase `json:"db"`
+	Dbx                SignatureDatabase `json:"dbx"`
+	BootApplications   []BootApplication `json:"boot_applications"`
+}
+
+// GetSecureBootState derives the measured Secure Boot configuration from the decoded
+// EV_EFI_VARIABLE_DRIVER_CONFIG, EV_EFI_VARIABLE_AUTHORITY and EV_EFI_BOOT_SERVICES_APPLICATION events.
+func GetSecureBootState(eventlogs []TDEventLog) (SecureBootState, error) {
+	var authority *EfiSignature
+	var authorityVariable string
+
+	state := SecureBootState{BootApplications: []BootApplication{}}
+	for _, eventlog := range eventlogs {
+		switch eventlog.Etype {
+		case EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG:
+			decoded, err := decodeUefiVariableData(eventlog.Event)
+			if err != nil {
+				log.Println("Error in decoding UEFI variable data")
+				return SecureBootState{}, err
+			}
+			err = state.setVariable(decoded.(UefiVariableData))
+			if err != nil {
+				return SecureBootState{}, err
+			}
+		case EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY:
+			decoded, err := decodeUefiVariableData(eventlog.Event)
+			if err != nil {
+				log.Println("Error in decoding UEFI variable data")
+				return SecureBootState{}, err
+			}
+			variable := decoded.(UefiVariableData)
+			signature, err := parseEfiSignatureData(EfiGuid{}, variable.VariableData)
+			if err != nil {
+				return SecureBootState{}, err
+			}
+			authority = &signature
+			authorityVariable = variable.UnicodeName
+		case EVENT_TYPE_EV_EFI_BOOT_SERVICES_APPLICATION:
+			decoded, err := decodeUefiImageLoadEvent(eventlog.Event)
+			if err != nil {
+				log.Println("Error in decoding UEFI image load event")
+				return SecureBootState{}, err
+			}
+			/*
+				Firmware only logs an authority the first time it is used to verify an image,
+				so an application is attributed to the last authority logged before it.
+			*/
+			state.BootApplications = append(state.BootApplications, BootApplication{
+				RegisterIndex:     eventlog.Rtmr,
+				DevicePath:        decoded.(UefiImageLoadEvent).DevicePath,
+				AuthorityVariable: authorityVariable,
+				Authority:         authority,
+			})
+		}
+	}
+
+	return state, nil
+}
+
+func (s *SecureBootState) setVariable(variable UefiVariableData) error {
+	var database *SignatureDatabase
+
+	switch {
+	case variable.VariableName == EFI_GLOBAL_VARIABLE_GUID && variable.UnicodeName == "SecureBoot":
+		s.SecureBootMeasured = true
+		s.SecureBootEnabled = len(variable.VariableData) == 1 && variable.VariableData[0] == 1
+		return nil
+	case variable.VariableName == EFI_GLOBAL_VARIABLE_GUID && variable.UnicodeName == "PK":
+		database = &s.PK
+	case variable.VariableName == EFI_GLOBAL_VARIABLE_GUID && variable.UnicodeName == "KEK":
+		database = &s.KEK
+	case variable.VariableName == EFI_IMAGE_SECURITY_DATABASE_GUID && variable.UnicodeName == "db":
+		database = &s.Db
+	case variable.VariableName == EFI_IMAGE_SECURITY_DATABASE_GUID && variable.UnicodeName == "dbx":
+		database = &s.Dbx
+	default:
+		return nil
+	}
+
+	signatureLists, err := ParseEfiSignatureLists(variable.VariableData)
+	if err != nil {
+		log.Printf("Error in parsing signature lists of %s", variable.UnicodeName)
+		return err
+	}
+
+	digest := sha256.Sum256(variable.VariableData)
+	database.Measured = true
+	database.Digest = hex.EncodeToString(digest[:])
+	database.SignatureLists = signatureLists
+	return nil
+}
+
+// Count returns the number of signatures of the given type, e.g. the number of
+// EFI_CERT_SHA256 hashes in dbx which usually identifies the dbx revision.
+func (d SignatureDatabase) Count(signatureType EfiGuid) int {
+	count := 0
+	for _, signatureList := range d.SignatureLists {
+		if signatureList.SignatureType == signatureType {
+			count += len(signatureList.Signatures)
+		}
+	}
+	return count
+}
+
+// ParseEfiSignatureLists parses the content of a signature database variable, which is a
+// sequence of EFI_SIGNATURE_LIST structures.
+func ParseEfiSignatureLists(data []byte) ([]EfiSignatureList, error) {
+	var listSize, headerSize, signatureSize uint32
+	var err error
+
+	signatureLists := []EfiSignatureList{}
+	index := 0
+	for index < len(data) {
+		if len(data)-index < EFI_SIGNATURE_LIST_HEADER_SIZE {
+			return nil, InvalidSignatureListErr
+		}
+
+		signatureList := EfiSignatureList{Signatures: []EfiSignature{}}
+		copy(signatureList.SignatureType[:], data[index:index+16])
+		signatureList.SignatureTypeName = getSignatureTypeName(signatureList.SignatureType)
+
+		listSize, _, err = getUint32Object(data, index+16)
+		if err != nil {
+			return nil, err
+		}
+		headerSize, _, err = getUint32Object(data, index+20)
+		if err != nil {
+			return nil, err
+		}
+		signatureSize, _, err = getUint32Object(data, index+24)
+		if err != nil {
+			return nil, err
+		}
+
+		if uint64(listSize) > uint64(len(data)-index) ||
+			uint64(listSize) < uint64(EFI_SIGNATURE_LIST_HEADER_SIZE)+uint64(headerSize) ||
+			signatureSize <= EFI_SIGNATURE_OWNER_SIZE ||
+			(listSize-EFI_SIGNATURE_LIST_HEADER_SIZE-headerSize)%signatureSize != 0 {
+			return nil, InvalidSignatureListErr
+		}
+
+		end := index + int(listSize)
+		for pos := index + EFI_SIGNATURE_LIST_HEADER_SIZE + int(headerSize); pos < end; pos += int(signatureSize) {
+			signature, err := parseEfiSignatureData(signatureList.SignatureType, data[pos:pos+int(signatureSize)])
+			if err != nil {
+				return nil, err
+			}
+			signatureList.Signatures = append(signatureList.Signatures, signature)
+		}
+
+		signatureLists = append(signatureLists, signatureList)
+		index = end
+	}
+
+	return signatureLists, nil
+}
+
+// ParseEfiAuthority parses the EFI_SIGNATURE_DATA measured as variable data of an
+// EV_EFI_VARIABLE_AUTHORITY event, the authority used to verify a loaded image.
+func ParseEfiAuthority(data []byte) (EfiSignature, error) {
+	return parseEfiSignatureData(EfiGuid{}, data)
+}
+
+/*
+Parse EFI_SIGNATURE_DATA. An empty signature type is used for EV_EFI_VARIABLE_AUTHORITY
+events, which do not carry the type, the data is then parsed as certificate if possible.
+*/
+func parseEfiSignatureData(signatureType EfiGuid, data []byte) (EfiSignature, error) {
+	signature := EfiSignature{}
+	if len(data) < EFI_SIGNATURE_OWNER_SIZE {
+		return signature, InvalidSignatureListErr
+	}
+	copy(signature.Owner[:], data[:EFI_SIGNATURE_OWNER_SIZE])
+	content := data[EFI_SIGNATURE_OWNER_SIZE:]
+
+	switch signatureType {
+	case EFI_CERT_SHA1_GUID, EFI_CERT_SHA256_GUID, EFI_CERT_SHA384_GUID, EFI_CERT_SHA512_GUID,
+		EFI_CERT_X509_SHA256_GUID, EFI_CERT_X509_SHA384_GUID, EFI_CERT_X509_SHA512_GUID:
+		signature.Hash = hex.EncodeToString(content)
+	case EFI_CERT_X509_GUID, EfiGuid{}:
+		certificate, err := x509.ParseCertificate(content)
+		if err != nil {
+			log.Printf("Failed to parse X.509 certificate in signature data: %v", err)
+			signature.Data = content
+			break
+		}
+		signature.Certificate = getX509CertificateInfo(certificate)
+	default:
+		signature.Data = content
+	}
+
+	return signature, nil
+}
+
+func getX509CertificateInfo(certificate *x509.Certificate) *X509CertificateInfo {
+	fingerprint := sha256.Sum256(certificate.Raw)
+	return &X509CertificateInfo{
+		Subject:      certificate.Subject.String(),
+		Issuer:       certificate.Issuer.String(),
+		SerialNumber: certificate.SerialNumber.String(),
+		NotBefore:    certificate.NotBefore,
+		NotAfter:     certificate.NotAfter,
+		Sha256:       hex.EncodeToString(fingerprint[:]),
+	}
+}
+
+func getSignatureTypeName(signatureType EfiGuid) string {
+	if name, ok := signatureTypeNames[signatureType]; ok {
+		return name
+	}
+	return signatureType.String()
+}
+
+/* Convert the registry format GUID string into EFI_GUID, only used for the constants above */
+func newEfiGuid(guid string) EfiGuid {
+	var efiGuid EfiGuid
+
+	data, err := hex.DecodeString(strings.ReplaceAll(guid, "-", ""))
+	if err != nil || len(data) != len(efiGuid) {
+		panic("invalid GUID " + guid)
+	}
+
+	binary.LittleEndian.PutUint32(efiGuid[0:4], binary.BigEndian.Uint32(data[0:4]))
+	binary.LittleEndian.PutUint16(efiGuid[4:6], binary.BigEndian.Uint16(data[4:6]))
+	binary.LittleEndian.PutUint16(efiGuid[6:8], binary.BigEndian.Uint16(data[6:8]))
+	copy(efiGuid[8:], data[8:])
+	return efiGuid
+}
@@ -0,0 +1,176 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+)
+
+var TEST_OWNER_GUID = newEfiGuid("77fa9abd-0359-4d32-bd60-28f4e78f784b")
+
+func createTestCertificate(t *testing.T, commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key error: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate error: %v", err)
+	}
+	return certificate
+}
+
+func buildEfiSignatureList(signatureType EfiGuid, signatures ...[]byte) []byte {
+	var buf bytes.Buffer
+
+	signatureSize := EFI_SIGNATURE_OWNER_SIZE + len(signatures[0])
+	buf.Write(signatureType[:])
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(EFI_SIGNATURE_LIST_HEADER_SIZE+signatureSize*len(signatures)))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(signatureSize))
+	for _, signature := range signatures {
+		buf.Write(TEST_OWNER_GUID[:])
+		buf.Write(signature)
+	}
+	return buf.Bytes()
+}
+
+func TestParseEfiSignatureLists(t *testing.T) {
+	certificate := createTestCertificate(t, "Test DB Key")
+	data := buildEfiSignatureList(EFI_CERT_X509_GUID, certificate)
+	data = append(data, buildEfiSignatureList(EFI_CERT_SHA256_GUID, bytes.Repeat([]byte{0x1}, 32), bytes.Repeat([]byte{0x2}, 32))...)
+
+	signatureLists, err := ParseEfiSignatureLists(data)
+	if err != nil || len(signatureLists) != 2 {
+		t.Fatalf("ParseEfiSignatureLists() = %v, %v want 2 signature lists", signatureLists, err)
+	}
+
+	x509List := signatureLists[0]
+	if x509List.SignatureTypeName != "EFI_CERT_X509" || len(x509List.Signatures) != 1 ||
+		x509List.Signatures[0].Owner != TEST_OWNER_GUID || x509List.Signatures[0].Certificate == nil ||
+		x509List.Signatures[0].Certificate.Subject != "CN=Test DB Key" ||
+		x509List.Signatures[0].Certificate.Issuer != "CN=Test DB Key" {
+		t.Fatalf("ParseEfiSignatureLists() = %+v, unexpected X.509 signature list", x509List)
+	}
+
+	hashList := signatureLists[1]
+	if hashList.SignatureTypeName != "EFI_CERT_SHA256" || len(hashList.Signatures) != 2 ||
+		hashList.Signatures[1].Hash != "0202020202020202020202020202020202020202020202020202020202020202" {
+		t.Fatalf("ParseEfiSignatureLists() = %+v, unexpected SHA256 signature list", hashList)
+	}
+}
+
+func TestParseEfiSignatureListsInvalidData(t *testing.T) {
+	valid := buildEfiSignatureList(EFI_CERT_SHA256_GUID, bytes.Repeat([]byte{0x1}, 32))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncatedHeader", valid[:20]},
+		{"truncatedList", valid[:len(valid)-1]},
+		{"invalidSignatureSize", func() []byte {
+			data := append([]byte{}, valid...)
+			binary.LittleEndian.PutUint32(data[24:28], 0)
+			return data
+		}()},
+		{"invalidHeaderSize", func() []byte {
+			data := append([]byte{}, valid...)
+			binary.LittleEndian.PutUint32(data[20:24], 0xffffffff)
+			return data
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEfiSignatureLists(tt.data)
+			if err != InvalidSignatureListErr {
+				t.Fatalf("ParseEfiSignatureLists() error = %v, want %v", err, InvalidSignatureListErr)
+			}
+		})
+	}
+}
+
+func TestGetSecureBootState(t *testing.T) {
+	certificate := createTestCertificate(t, "Test DB Key")
+	dbx := buildEfiSignatureList(EFI_CERT_SHA256_GUID, bytes.Repeat([]byte{0x1}, 32), bytes.Repeat([]byte{0x2}, 32))
+	authority := append(append([]byte{}, TEST_OWNER_GUID[:]...), certificate...)
+	shimPath := buildDevicePathNode(0x4, 0x4, encodeUtf16("\\EFI\\BOOT\\BOOTX64.EFI\x00"))
+
+	eventlogs := []TDEventLog{
+		{Etype: EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG, Event: buildUefiVariableData(EFI_GLOBAL_VARIABLE_GUID, "SecureBoot", []byte{0x1})},
+		{Etype: EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG, Event: buildUefiVariableData(EFI_GLOBAL_VARIABLE_GUID, "PK", nil)},
+		{Etype: EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG, Event: buildUefiVariableData(EFI_IMAGE_SECURITY_DATABASE_GUID, "db",
+			buildEfiSignatureList(EFI_CERT_X509_GUID, certificate))},
+		{Etype: EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG, Event: buildUefiVariableData(EFI_IMAGE_SECURITY_DATABASE_GUID, "dbx", dbx)},
+		{Etype: EVENT_TYPE_EV_SEPARATOR, Event: []byte{0, 0, 0, 0}},
+		{Rtmr: 1, Etype: EVENT_TYPE_EV_EFI_BOOT_SERVICES_APPLICATION, Event: buildUefiImageLoadEvent(0x1000, 0x1000, nil)},
+		{Etype: EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY, Event: buildUefiVariableData(EFI_IMAGE_SECURITY_DATABASE_GUID, "db", authority)},
+		{Rtmr: 1, Etype: EVENT_TYPE_EV_EFI_BOOT_SERVICES_APPLICATION, Event: buildUefiImageLoadEvent(0x2000, 0x1000, shimPath)},
+	}
+
+	state, err := GetSecureBootState(eventlogs)
+	if err != nil {
+		t.Fatalf("GetSecureBootState() returned error: %v", err)
+	}
+
+	if !state.SecureBootMeasured || !state.SecureBootEnabled {
+		t.Fatalf("GetSecureBootState() = %+v, want Secure Boot enabled", state)
+	}
+	if !state.PK.Measured || len(state.PK.SignatureLists) != 0 || state.KEK.Measured {
+		t.Fatalf("GetSecureBootState() = %+v, unexpected PK and KEK", state)
+	}
+	if len(state.Db.SignatureLists) != 1 || state.Db.SignatureLists[0].Signatures[0].Certificate.Subject != "CN=Test DB Key" {
+		t.Fatalf("GetSecureBootState() = %+v, unexpected db", state.Db)
+	}
+	if state.Dbx.Count(EFI_CERT_SHA256_GUID) != 2 || len(state.Dbx.Digest) != 64 {
+		t.Fatalf("GetSecureBootState() = %+v, unexpected dbx", state.Dbx)
+	}
+
+	if len(state.BootApplications) != 2 {
+		t.Fatalf("GetSecureBootState() = %+v, want 2 boot applications", state.BootApplications)
+	}
+	if state.BootApplications[0].Authority != nil {
+		t.Fatalf("GetSecureBootState() = %+v, want no authority for first application", state.BootApplications[0])
+	}
+	application := state.BootApplications[1]
+	if application.DevicePath != "\\EFI\\BOOT\\BOOTX64.EFI" || application.AuthorityVariable != "db" ||
+		application.Authority == nil || application.Authority.Certificate.Subject != "CN=Test DB Key" {
+		t.Fatalf("GetSecureBootState() = %+v, unexpected boot application", application)
+	}
+}
+
+func TestGetSecureBootStateDisabled(t *testing.T) {
+	eventlogs := []TDEventLog{
+		{Etype: EVENT_TYPE_EV_EFI_VARIABLE_DRIVER_CONFIG, Event: buildUefiVariableData(EFI_GLOBAL_VARIABLE_GUID, "SecureBoot", []byte{0x0})},
+	}
+
+	state, err := GetSecureBootState(eventlogs)
+	if err != nil || !state.SecureBootMeasured || state.SecureBootEnabled {
+		t.Fatalf("GetSecureBootState() = %+v, %v want Secure Boot disabled", state, err)
+	}
+
+	state, err = GetSecureBootState(nil)
+	if err != nil || state.SecureBootMeasured {
+		t.Fatalf("GetSecureBootState() = %+v, %v want Secure Boot not measured", state, err)
+	}
+}
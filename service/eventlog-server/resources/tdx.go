@@ -8,9 +8,6 @@ package resources
 import (
 	"bytes"
 	"encoding/binary"
-	"encoding/json"
-	"fmt"
-	"io"
 	"log"
 	"os"
 
@@ -24,224 +21,428 @@ const (
 	//The location of mounted CCEL table
 	CCEL_FILE_MOUNT_LOCATION = "/run/firmware/acpi/tables/CCEL"
 	CCEL_DATA_MOUNT_LOCATION = "/run/firmware/acpi/tables/data/CCEL"
+	//The location of the runtime event log of the RTMR extends, written by the measurement server
+	RUNTIME_EVENT_LOG_LOCATION = "/run/ccnp/runtime/runtime_eventlog"
 
 	EVENT_TYPE_EV_NO_ACTION = 0x3
 )
 
 var (
-	TdxGetEventlogErr     = pkgerrors.New("Failed to get eventlog in CCEL table.")
-	CcelTableNotFoundErr  = pkgerrors.New("CCEL table not found.")
-	InvalidCcelTableErr   = pkgerrors.New("CCEL table with invalid data")
-	FetchCcelTableAttrErr = pkgerrors.New("Failed to get the base address of CCEL table")
+	TdxGetEventlogErr      = pkgerrors.New("Failed to get eventlog in CCEL table.")
+	CcelTableNotFoundErr   = pkgerrors.New("CCEL table not found.")
+	InvalidCcelTableErr    = pkgerrors.New("CCEL table with invalid data")
+	UnknownAlgorithmErr    = pkgerrors.New("Digest algorithm not declared in eventlog header")
+	InvalidCcelEventlogErr = pkgerrors.New("CCEL eventlog with invalid data")
+	RawEventlogNotFoundErr = pkgerrors.New("Binary eventlog data not available")
 )
 
-func GetTdxEventlog(start_position int, count int) (string, error) {
+func GetTdxEventlog(start_position int, count int, filter EventlogFilter) (string, error) {
 
-	var eventlog string
-	var object *os.File
-	var err error
-
-	/* Check if the ccel file exists*/
-	if _, err = os.Stat(CCEL_FILE_MOUNT_LOCATION); err != nil {
-		log.Println("Checking CCEL file in host path")
-		if _, err = os.Stat(CCEL_FILE_LOCATION); err != nil {
-			return "", err
-		}
+	eventlogs, err := GetTdxEventlogs(start_position, count, filter)
+	if err != nil {
+		return "", err
 	}
 
-	/* Open ccel file to get prepared for event log fetching*/
-	object, err = os.OpenFile(CCEL_FILE_MOUNT_LOCATION, os.O_RDONLY, 0644)
+	return MarshalEventlogs(eventlogs, false)
+}
+
+func GetTdxEventlogs(start_position int, count int, filter EventlogFilter) (TDEventLogs, error) {
+
+	tableLocation, err := getCcelFileLocation(locations.CcelTableMount, locations.CcelTable)
 	if err != nil {
-		object, err = os.OpenFile(CCEL_FILE_LOCATION, os.O_RDONLY, 0644)
-		if err != nil {
-			return "", CcelTableNotFoundErr
-		}
+		return TDEventLogs{}, err
 	}
 
-	data, err := io.ReadAll(object)
+	dataLocation, err := getCcelFileLocation(locations.CcelDataMount, locations.CcelData)
 	if err != nil {
-		return "", err
+		return TDEventLogs{}, err
 	}
 
-	if len(data) == 0 || !bytes.Equal(data[0:4], []byte("CCEL")) {
-		return "", InvalidCcelTableErr
+	/* the runtime event log is created by the first RTMR extend */
+	files := []string{tableLocation, dataLocation}
+	runtimeLocation := locations.RuntimeEventlog
+	if _, err := os.Stat(runtimeLocation); runtimeLocation != "" && err == nil {
+		files = append(files, runtimeLocation)
+	} else {
+		runtimeLocation = ""
 	}
 
-	eventlog, err = parseTdxEventlog(data, start_position, count)
+	eventlogs, err := tdxEventlogCache.get(files, func() (TDEventLogs, error) {
+		return loadTdxEventlogs(tableLocation, dataLocation, runtimeLocation)
+	})
 	if err != nil {
-		return "", err
+		return TDEventLogs{}, err
 	}
 
-	return eventlog, nil
+	return selectEventlogs(eventlogs, filter, start_position, count)
 }
 
-func parseTdxEventlog(data []byte, position int, count int) (string, error) {
-
-	var baseAddr, currLen uint64
-	var num int
-	var eventlogs TDEventLogs
+func loadTdxEventlogs(tableLocation string, dataLocation string, runtimeLocation string) (TDEventLogs, error) {
 
-	if len(data) > 56 {
-		return "", InvalidCcelTableErr
+	/* Read ccel table to get prepared for event log fetching*/
+	data, err := readCcelLocation(tableLocation)
+	if err != nil {
+		return TDEventLogs{}, err
 	}
 
-	err := binary.Read(bytes.NewReader(data[48:56]), binary.LittleEndian, &baseAddr)
+	eventlogData, err := readCcelLocation(dataLocation)
 	if err != nil {
-		return "", FetchCcelTableAttrErr
+		return TDEventLogs{}, err
 	}
 
-	err = binary.Read(bytes.NewReader(data[40:48]), binary.LittleEndian, &currLen)
+	eventlogs, err := parseTdxEventlogs(data, eventlogData)
+	if err != nil || runtimeLocation == "" {
+		return eventlogs, err
+	}
+
+	runtimeData, err := os.ReadFile(runtimeLocation)
 	if err != nil {
-		return "", FetchCcelTableAttrErr
+		log.Println("Error reading runtime eventlog", runtimeLocation)
+		return TDEventLogs{}, err
 	}
 
-	eventlogs, num, err = fetchEventlogs()
+	return appendRuntimeEventlogs(eventlogs, runtimeData)
+}
+
+/*
+The runtime event log holds the RTMR extends requested by the workloads through the measurement
+server, in the format of the CCEL event log. Its events are extended after the events of the
+CCEL, so they follow them in the event log.
+*/
+func appendRuntimeEventlogs(eventlogs TDEventLogs, data []byte) (TDEventLogs, error) {
+
+	runtimeEventlogs, _, err := fetchEventlogs(trimRuntimeEventlog(data))
 	if err != nil {
-		return "", err
+		log.Println("Error in parsing runtime eventlog")
+		return TDEventLogs{}, err
 	}
 
-	if position+count >= num {
-		return "", pkgerrors.New("Invalid count exceeds event log length")
+	eventlogs.EventLogs = append(eventlogs.EventLogs, runtimeEventlogs.EventLogs...)
+	return eventlogs, nil
+}
+
+/*
+The measurement server writes each runtime event in one write, while the event log is read
+without coordination. A last record running past the end of the data is still being written,
+it is left out and served with a later request.
+*/
+func trimRuntimeEventlog(data []byte) []byte {
+
+	length := getSpecIdEventLength(data)
+	if length == 0 {
+		return nil
+	}
+
+	header, err := getSpecIdHeader(data)
+	if err != nil {
+		/* fetchEventlogs reports the invalid Spec ID event */
+		return data
 	}
 
-	if count != 0 {
-		eventlogs = TDEventLogs{
-			Header:    eventlogs.Header,
-			EventLogs: eventlogs.EventLogs[position : position+count],
+	for length < len(data) {
+		eventLength := getEventLength(data[length:], header.DigestSizes)
+		if eventLength == 0 {
+			log.Println("Ignoring truncated runtime event at offset", length)
+			break
 		}
+		length += eventLength
 	}
 
-	eventlogs_str, err := json.Marshal(eventlogs)
-	if err != nil {
-		log.Println("Error in marshaling event logs")
-		return "", err
+	return data[:length]
+}
+
+/* Length of the Spec ID event at the start of data, 0 if the data ends inside it */
+func getSpecIdEventLength(data []byte) int {
+
+	eventSize, index, err := getUint32Object(data, 8+TPM_SHA1_DIGEST_SIZE)
+	if err != nil || uint64(eventSize) > uint64(len(data)-index) {
+		return 0
 	}
-	return string(eventlogs_str), nil
+	return index + int(eventSize)
 }
 
-func fetchEventlogs() (TDEventLogs, int, error) {
+/*
+Length of the event at the start of data, 0 if the data ends inside it. An unknown digest
+algorithm counts as an empty digest, getEventLog reports it when the event is parsed.
+*/
+func getEventLength(data []byte, digestSizes map[uint16]uint16) int {
 
-	var index int
-	var object *os.File
-	var err error
+	_, _, digestCount, index, err := getBasicInfo(data)
+	if err != nil {
+		return 0
+	}
 
-	/* Check if the ccel file exists in either host or container*/
-	if _, err = os.Stat(CCEL_DATA_MOUNT_LOCATION); err != nil {
-		log.Println("Checking CCEL data in host path")
-		_, err = os.Stat(CCEL_DATA_LOCATION)
+	for i := uint32(0); i < digestCount; i++ {
+		var algId uint16
+		algId, index, err = getUint16Object(data, index)
 		if err != nil {
-			return TDEventLogs{}, 0, err
+			return 0
 		}
+		index += int(digestSizes[algId])
 	}
 
-	/* Open ccel data file to get prepared for event log fetching*/
-	object, err = os.OpenFile(CCEL_DATA_MOUNT_LOCATION, os.O_RDONLY, 0644)
+	eventSize, index, err := getUint32Object(data, index)
+	if err != nil || uint64(eventSize) > uint64(len(data)-index) {
+		return 0
+	}
+	return index + int(eventSize)
+}
+
+func parseTdxEventlogs(data []byte, eventlogData []byte) (TDEventLogs, error) {
+
+	ccelTable, err := ParseCcelTable(data)
 	if err != nil {
-		object, err = os.OpenFile(CCEL_DATA_LOCATION, os.O_RDONLY, 0644)
-		if err != nil {
-			return TDEventLogs{}, 0, CcelTableNotFoundErr
+		log.Println("Error in parsing CCEL table")
+		return TDEventLogs{}, err
+	}
+
+	err = ccelTable.ValidateEventlogData(eventlogData)
+	if err != nil {
+		return TDEventLogs{}, err
+	}
+
+	eventlogs, _, err := fetchEventlogs(eventlogData)
+	if err != nil {
+		return TDEventLogs{}, err
+	}
+	eventlogs.CcelTable = &ccelTable
+
+	return eventlogs, nil
+}
+
+/* Read the ccel file in container first, then in host */
+func readCcelFile(mountPath string, hostPath string) ([]byte, error) {
+
+	location, err := getCcelFileLocation(mountPath, hostPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return readCcelLocation(location)
+}
+
+func getCcelFileLocation(mountPath string, hostPath string) (string, error) {
+
+	if mountPath != "" {
+		if _, err := os.Stat(mountPath); err == nil {
+			return mountPath, nil
 		}
 	}
 
-	data, err := io.ReadAll(object)
+	log.Printf("Checking %s in host path", hostPath)
+	if _, err := os.Stat(hostPath); err != nil {
+		return "", err
+	}
+	return hostPath, nil
+}
+
+func readCcelLocation(location string) ([]byte, error) {
+
+	data, err := os.ReadFile(location)
+	if err != nil || len(data) == 0 {
+		return nil, CcelTableNotFoundErr
+	}
+
+	return data, nil
+}
+
+/* Filter the event logs first, the position and count apply to the matching events */
+func selectEventlogs(eventlogs TDEventLogs, filter EventlogFilter, position int, count int) (TDEventLogs, error) {
+
+	eventlogs, err := FilterEventlogs(eventlogs, filter)
 	if err != nil {
-		return TDEventLogs{}, 0, err
+		return TDEventLogs{}, err
 	}
 
-	if len(data) == 0 {
-		return TDEventLogs{}, 0, CcelTableNotFoundErr
+	return getEventlogsInRange(eventlogs, position, count)
+}
+
+func getEventlogsInRange(eventlogs TDEventLogs, position int, count int) (TDEventLogs, error) {
+
+	start, end, err := GetPageRange(len(eventlogs.EventLogs), position, count)
+	if err != nil {
+		return TDEventLogs{}, err
 	}
 
+	eventlogs.TotalCount = len(eventlogs.EventLogs)
+	eventlogs.EventLogs = eventlogs.EventLogs[start:end]
+
+	return eventlogs, nil
+}
+
+// MarshalRawEventlogs returns the binary event log as read from the CCEL or the TPM event
+// log: the Spec ID event followed by the events, without the unused part of the log area.
+// The events keep all their digests, whatever the algorithm filter.
+func MarshalRawEventlogs(eventlogs TDEventLogs) ([]byte, error) {
+	if len(eventlogs.Header.HeaderData) == 0 {
+		log.Println("Spec ID event data not available")
+		return nil, RawEventlogNotFoundErr
+	}
+
+	data := append([]byte{}, eventlogs.Header.HeaderData...)
+	for i, eventlog := range eventlogs.EventLogs {
+		if len(eventlog.Data) == 0 {
+			log.Println("Binary data not available for event", i)
+			return nil, RawEventlogNotFoundErr
+		}
+		data = append(data, eventlog.Data...)
+	}
+
+	return data, nil
+}
+
+/*
+The CCEL data is untrusted input, so every length field is validated against the
+buffer before use. The first event is the Spec ID event in SHA1 format, the
+following events are crypto agile TCG_PCR_EVENT2 structures, and the unused part
+of the log area is filled with 0xFF.
+*/
+func fetchEventlogs(data []byte) (TDEventLogs, int, error) {
+
+	var err error
+
 	eventLogs := TDEventLogs{}
-	specidHeader := TDEventLogSpecIdHeader{}
-	index = 0
+	index := 0
 	count := 0
 
 	for index < len(data) {
-		var rtmr, etype uint32
+		var rtmr uint32
 		start := index
 
-		rtmr, index, err = getUint32Object(data, index)
+		rtmr, _, err = getUint32Object(data, start)
 		if err != nil {
 			log.Println("Error in getting RTMR value")
-			return TDEventLogs{}, 0, err
+			return TDEventLogs{}, 0, getEventOffsetErr(err, count, start)
 		}
 
 		if rtmr == 0xFFFFFFFF {
 			break
 		}
 
-		etype, _, err = getUint32Object(data, index)
+		if count == 0 {
+			eventLogs.Header, err = getSpecIdHeader(data[start:])
+			if err != nil {
+				log.Println("Error in getting Spec ID header")
+				return TDEventLogs{}, 0, getEventOffsetErr(err, count, start)
+			}
+			index = start + eventLogs.Header.Length
+			count += 1
+			continue
+		}
+
+		eventLog, err := getEventLog(data[start:], eventLogs.Header.DigestSizes)
 		if err != nil {
-			log.Println("Error in getting event type")
-			return TDEventLogs{}, 0, err
+			log.Println("Error in getting event log")
+			return TDEventLogs{}, 0, getEventOffsetErr(err, count, start)
 		}
+		eventLogs.EventLogs = append(eventLogs.EventLogs, eventLog)
+		index = start + eventLog.Length
 
-		if etype == EVENT_TYPE_EV_NO_ACTION {
+		count += 1
+	}
 
-			specidHeader.Rtmr, specidHeader.Etype, specidHeader.DigestCount, index, err = getBasicInfo(data[start:])
-			if err != nil {
-				log.Println("Error in getting basic info")
-				return TDEventLogs{}, 0, err
-			}
+	return eventLogs, count, nil
+}
 
-			index += 20
-			index += 24
+func getSpecIdHeader(data []byte) (TDEventLogSpecIdHeader, error) {
 
-			specidHeader.DigestSizes, index, err = getHeaderDigestInfo(data[start:], index)
-			if err != nil {
-				log.Println("Error in getting header digest info")
-				return TDEventLogs{}, 0, err
-			}
+	var eventSize uint32
+	var vendorSize uint8
+	var err error
 
-			var vendorSize uint8
-			vendorSize, index, err = getUint8Object(data[start:], index)
-			if err != nil {
-				log.Println("Error in getting vendor size")
-				return TDEventLogs{}, 0, err
-			}
+	specidHeader := TDEventLogSpecIdHeader{}
+	specidHeader.Rtmr, specidHeader.Etype, specidHeader.DigestCount, _, err = getBasicInfo(data)
+	if err != nil {
+		log.Println("Error in getting basic info")
+		return TDEventLogSpecIdHeader{}, err
+	}
 
-			index = index + int(vendorSize)
-			specidHeader.Length = int(index)
-			specidHeader.HeaderData = data[0:index]
-			eventLogs.Header = specidHeader
+	if specidHeader.Etype != EVENT_TYPE_EV_NO_ACTION {
+		log.Println("The first event is not a Spec ID event")
+		return TDEventLogSpecIdHeader{}, InvalidCcelEventlogErr
+	}
 
-			index = start + specidHeader.Length
-			count += 1
-			continue
+	/* skip the SHA1 digest of the header event */
+	index := 8 + TPM_SHA1_DIGEST_SIZE
+	eventSize, index, err = getUint32Object(data, index)
+	if err != nil {
+		log.Println("Error in getting event size")
+		return TDEventLogSpecIdHeader{}, err
+	}
 
-		}
+	if uint64(eventSize) > uint64(len(data)-index) {
+		log.Println("Spec ID event size exceeds the eventlog length")
+		return TDEventLogSpecIdHeader{}, InvalidCcelEventlogErr
+	}
+	event := data[:index+int(eventSize)]
 
-		eventLog := TDEventLog{}
-		eventLog.Rtmr, eventLog.Etype, eventLog.DigestCount, index, err = getBasicInfo(data[start:])
-		if err != nil {
-			log.Println("Error in getting basic info")
-			return TDEventLogs{}, 0, err
-		}
+	/* skip signature, platform class, spec version and uintn size */
+	index += 24
 
-		eventLog.Digests, eventLog.AlgorithmId, index, err = getEventLogDigestInfo(data[start:], index, eventLog.DigestCount, eventLogs.Header.DigestSizes)
-		if err != nil {
-			log.Println("Error in getting event log digest info")
-			return TDEventLogs{}, 0, err
-		}
+	specidHeader.DigestSizes, index, err = getHeaderDigestInfo(event, index)
+	if err != nil {
+		log.Println("Error in getting header digest info")
+		return TDEventLogSpecIdHeader{}, err
+	}
 
-		eventLog.EventSize, index, err = getUint32Object(data[start:], index)
-		if err != nil {
-			log.Println("Error in getting event size")
-			return TDEventLogs{}, 0, err
-		}
+	vendorSize, index, err = getUint8Object(event, index)
+	if err != nil {
+		log.Println("Error in getting vendor size")
+		return TDEventLogSpecIdHeader{}, err
+	}
 
-		eventLog.Event = data[int(start)+index : int(start)+index+int(eventLog.EventSize)]
-		index = index + int(eventLog.EventSize)
-		eventLog.Length = index
-		eventLog.Data = data[start : start+index]
-		eventLogs.EventLogs = append(eventLogs.EventLogs, eventLog)
-		index = start + eventLog.Length
+	if int(vendorSize) > len(event)-index {
+		log.Println("Vendor info size exceeds the Spec ID event size")
+		return TDEventLogSpecIdHeader{}, InvalidCcelEventlogErr
+	}
 
-		count += 1
+	index = index + int(vendorSize)
+	specidHeader.Length = index
+	specidHeader.HeaderData = data[0:index]
+
+	return specidHeader, nil
+}
+
+func getEventLog(data []byte, digestSizes map[uint16]uint16) (TDEventLog, error) {
+
+	var index int
+	var err error
+
+	eventLog := TDEventLog{}
+	eventLog.Rtmr, eventLog.Etype, eventLog.DigestCount, index, err = getBasicInfo(data)
+	if err != nil {
+		log.Println("Error in getting basic info")
+		return TDEventLog{}, err
 	}
 
-	return eventLogs, count, nil
+	eventLog.Digests, index, err = getEventLogDigestInfo(data, index, eventLog.DigestCount, digestSizes)
+	if err != nil {
+		log.Println("Error in getting event log digest info")
+		return TDEventLog{}, err
+	}
+
+	eventLog.EventSize, index, err = getUint32Object(data, index)
+	if err != nil {
+		log.Println("Error in getting event size")
+		return TDEventLog{}, err
+	}
+
+	if uint64(eventLog.EventSize) > uint64(len(data)-index) {
+		log.Println("Event size exceeds the eventlog length")
+		return TDEventLog{}, InvalidCcelEventlogErr
+	}
+
+	eventLog.Event = data[index : index+int(eventLog.EventSize)]
+	index = index + int(eventLog.EventSize)
+	eventLog.Length = index
+	eventLog.Data = data[0:index]
+
+	return eventLog, nil
+}
+
+/* Keep the cause of the error, so that callers can still compare it, and add the failed event */
+func getEventOffsetErr(err error, count int, offset int) error {
+	return pkgerrors.WithMessagef(err, "Failed to parse event %d at offset 0x%x", count, offset)
 }
 
 type TDEventLogSpecIdHeader struct {
@@ -254,21 +455,28 @@ type TDEventLogSpecIdHeader struct {
 	DigestSizes map[uint16]uint16
 }
 
+type TDEventLogDigest struct {
+	AlgorithmId uint16
+	Digest      []byte
+}
+
 type TDEventLog struct {
 	Rtmr        uint32
 	Etype       uint32
 	DigestCount uint32
-	Digests     []string
+	Digests     []TDEventLogDigest
 	Data        []byte
 	Event       []byte
 	Length      int
 	EventSize   uint32
-	AlgorithmId uint16
 }
 
 type TDEventLogs struct {
 	Header    TDEventLogSpecIdHeader
 	EventLogs []TDEventLog
+	CcelTable *CcelTable
+	// TotalCount is the number of events matching the filter before the range is applied
+	TotalCount int
 }
 
 func getBasicInfo(data []byte) (uint32, uint32, uint32, int, error) {
@@ -313,6 +521,12 @@ func getHeaderDigestInfo(data []byte, index int) (map[uint16]uint16, int, error)
 		return digestSizes, 0, err
 	}
 
+	/* each algorithm takes 4 bytes, reject counts which cannot fit in the data */
+	if uint64(algNum)*4 > uint64(len(data)-i) {
+		log.Println("Algorithm number exceeds valid length:", algNum)
+		return digestSizes, 0, pkgerrors.New("Exceed valid length")
+	}
+
 	for j := 0; j < int(algNum); j++ {
 		algId, i, err = getUint16Object(data, i)
 		if err != nil {
@@ -332,38 +546,65 @@ func getHeaderDigestInfo(data []byte, index int) (map[uint16]uint16, int, error)
 	return digestSizes, i, nil
 }
 
-func getEventLogDigestInfo(data []byte, index int, digestCount uint32, digestSizes map[uint16]uint16) ([]string, uint16, int, error) {
+func getEventLogDigestInfo(data []byte, index int, digestCount uint32, digestSizes map[uint16]uint16) ([]TDEventLogDigest, int, error) {
 
 	var algId uint16
 	var err error
-	var digests []string
+	var digests []TDEventLogDigest
 
 	i := index
 
+	/* each digest takes at least 2 bytes for the algorithm id */
+	if i < 0 || i > len(data) || uint64(digestCount)*2 > uint64(len(data)-i) {
+		log.Println("Digest count exceeds valid length:", digestCount)
+		return digests, 0, pkgerrors.New("Exceed valid length")
+	}
+
 	for j := 0; j < int(digestCount); j++ {
 		algId, i, err = getUint16Object(data, i)
 		if err != nil {
 			log.Println("Error in getting algorithm id")
-			return digests, uint16(0), 0, err
+			return digests, 0, err
 		}
 
-		for k := range digestSizes {
-			if k == algId {
-				digestSize := digestSizes[k]
-				digestData := data[i : i+int(digestSize)]
-				i = i + int(digestSize)
-				digests = append(digests, fmt.Sprintf("%v", digestData))
-			}
+		digestSize, ok := digestSizes[algId]
+		if !ok {
+			log.Println("Algorithm id not declared in Spec ID event:", algId)
+			return digests, 0, UnknownAlgorithmErr
 		}
+
+		if i+int(digestSize) > len(data) {
+			return digests, 0, pkgerrors.New("Exceed valid length")
+		}
+
+		digestData := make([]byte, digestSize)
+		copy(digestData, data[i:i+int(digestSize)])
+		i = i + int(digestSize)
+		digests = append(digests, TDEventLogDigest{AlgorithmId: algId, Digest: digestData})
+	}
+
+	return digests, i, nil
+}
+
+func getUint64Object(data []byte, index int) (uint64, int, error) {
+	var value uint64
+
+	if index < 0 || index+8 > len(data) {
+		return uint64(0), index, pkgerrors.New("Exceed valid length")
+	}
+	err := binary.Read(bytes.NewReader(data[index:index+8]), binary.LittleEndian, &value)
+	if err != nil {
+		log.Println("Error in reading uint64 object")
+		return uint64(0), index, err
 	}
 
-	return digests, algId, i, nil
+	return value, index + 8, nil
 }
 
 func getUint32Object(data []byte, index int) (uint32, int, error) {
 	var value uint32
 
-	if index+4 > len(data) {
+	if index < 0 || index+4 > len(data) {
 		return uint32(0), index, pkgerrors.New("Exceed valid length")
 	}
 	err := binary.Read(bytes.NewReader(data[index:index+4]), binary.LittleEndian, &value)
@@ -378,7 +619,7 @@ func getUint32Object(data []byte, index int) (uint32, int, error) {
 func getUint16Object(data []byte, index int) (uint16, int, error) {
 	var value uint16
 
-	if index+2 > len(data) {
+	if index < 0 || index+2 > len(data) {
 		return uint16(0), index, pkgerrors.New("Exceed valid length")
 	}
 	err := binary.Read(bytes.NewReader(data[index:index+2]), binary.LittleEndian, &value)
@@ -393,7 +634,7 @@ func getUint16Object(data []byte, index int) (uint16, int, error) {
 func getUint8Object(data []byte, index int) (uint8, int, error) {
 	var value uint8
 
-	if index+1 > len(data) {
+	if index < 0 || index+1 > len(data) {
 		return uint8(0), index, pkgerrors.New("Exceed valid length")
 	}
 	err := binary.Read(bytes.NewReader(data[index:index+1]), binary.LittleEndian, &value)
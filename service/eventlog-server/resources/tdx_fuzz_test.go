@@ -0,0 +1,62 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"testing"
+)
+
+/*
+Fuzz targets for the CCEL parser, which must never panic on untrusted input.
+The seed corpus of malformed event logs is under testdata/fuzz, run with e.g.
+go test ./resources -fuzz=FuzzFetchEventlogs
+*/
+
+func FuzzFetchEventlogs(f *testing.F) {
+	f.Add(buildCcelEventlog(0, 0))
+	f.Add(buildCcelEventlog(3, 16))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		eventlogs, count, err := fetchEventlogs(data)
+		if err != nil {
+			return
+		}
+
+		if count > 0 && count != len(eventlogs.EventLogs)+1 {
+			t.Fatalf("fetchEventlogs() count %d does not match %d events", count, len(eventlogs.EventLogs))
+		}
+		for _, eventlog := range eventlogs.EventLogs {
+			if int(eventlog.EventSize) != len(eventlog.Event) || eventlog.Length != len(eventlog.Data) {
+				t.Fatalf("fetchEventlogs() returned inconsistent event %v", eventlog)
+			}
+		}
+	})
+}
+
+func FuzzGetHeaderDigestInfo(f *testing.F) {
+	f.Add([]byte{0x2, 0, 0, 0, 0x4, 0, 0x14, 0, 0xc, 0, 0x30, 0}, 0)
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0x4, 0}, 0)
+
+	f.Fuzz(func(t *testing.T, data []byte, index int) {
+		digestSizes, i, err := getHeaderDigestInfo(data, index)
+		if err == nil && (i > len(data) || len(digestSizes) > len(data)/4) {
+			t.Fatalf("getHeaderDigestInfo() = %v, %d exceeds data with %d bytes", digestSizes, i, len(data))
+		}
+	})
+}
+
+func FuzzGetEventLogDigestInfo(f *testing.F) {
+	f.Add([]byte{0x4, 0x0, 0xa, 0xc, 0x0, 0xb, 0xc, 0xd}, 0, uint32(2))
+	f.Add([]byte{0xc, 0x0, 0xa}, 0, uint32(0xffffffff))
+
+	digestSizes := map[uint16]uint16{TPM_ALG_SHA1: 1, TPM_ALG_SHA384: 3, TPM_ALG_SHA512: 0xffff}
+	f.Fuzz(func(t *testing.T, data []byte, index int, digestCount uint32) {
+		digests, i, err := getEventLogDigestInfo(data, index, digestCount, digestSizes)
+		if err == nil && (i > len(data) || uint32(len(digests)) != digestCount) {
+			t.Fatalf("getEventLogDigestInfo() = %v, %d exceeds data with %d bytes", digests, i, len(data))
+		}
+	})
+}
@@ -6,8 +6,16 @@
 package resources
 
 import (
-	pkgerrors "github.com/pkg/errors"
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	pkgerrors "github.com/pkg/errors"
 )
 
 const (
@@ -69,30 +77,47 @@ func TestGetUintObjectValidLength(t *testing.T) {
 }
 
 func TestGetEventLogDigestInfo(t *testing.T) {
-	var flag bool
-
-	digestCount := uint32(1)
-	data := make([]byte, 5)
+	digestCount := uint32(2)
+	data := []byte{0x4, 0x0, 0xa, 0xc, 0x0, 0xb, 0xc, 0xd}
 	index := 0
 	digestSizes := make(map[uint16]uint16)
-	digestSizes[uint16(0)] = 1
-	flag = true
+	digestSizes[TPM_ALG_SHA1] = 1
+	digestSizes[TPM_ALG_SHA384] = 3
 
-	digests, algId, index, err := getEventLogDigestInfo(data, index, digestCount, digestSizes)
-	if len(digests) != 1 {
-		flag = false
+	digests, index, err := getEventLogDigestInfo(data, index, digestCount, digestSizes)
+	if err != nil || len(digests) != 2 || index != 8 ||
+		digests[0].AlgorithmId != TPM_ALG_SHA1 || !bytes.Equal(digests[0].Digest, []byte{0xa}) ||
+		digests[1].AlgorithmId != TPM_ALG_SHA384 || !bytes.Equal(digests[1].Digest, []byte{0xb, 0xc, 0xd}) {
+		t.Fatalf(`getEventLogDigestInfo([4,0,10,12,0,11,12,13], 0, 2, map[4]=1,map[12]=3) = %v, %d, %v want match for SHA1 and SHA384 digests, %d, %v`,
+			digests, index, err, 8, nil)
 	}
 
-	for _, item := range digests {
-		if item != "0" {
-			flag = false
-			break
-		}
+	// Algorithm not declared in header
+	delete(digestSizes, TPM_ALG_SHA384)
+	_, _, err = getEventLogDigestInfo(data, 0, digestCount, digestSizes)
+	if err != UnknownAlgorithmErr {
+		t.Fatalf(`getEventLogDigestInfo() = %v want %v`, err, UnknownAlgorithmErr)
+	}
+
+	// Digest exceeds data length
+	digestSizes[TPM_ALG_SHA384] = 4
+	_, _, err = getEventLogDigestInfo(data, 0, digestCount, digestSizes)
+	if err == nil || err.Error() != ERR_MSG {
+		t.Fatalf(`getEventLogDigestInfo() = %v want %v`, err, ERR_MSG)
+	}
+}
+
+func TestGetAlgorithmName(t *testing.T) {
+	if name := GetAlgorithmName(TPM_ALG_SHA384); name != "SHA384" {
+		t.Fatalf(`GetAlgorithmName(0xC) = %s want SHA384`, name)
+	}
+
+	if name := GetAlgorithmName(TPM_ALG_SM3_256); name != "SM3_256" {
+		t.Fatalf(`GetAlgorithmName(0x12) = %s want SM3_256`, name)
 	}
 
-	if err != nil || flag || algId != uint16(0) || index != 3 {
-		t.Fatalf(`getEventLogDigestInfo([0,0,0,0,0], 0, 1, map[0]=1) = %s, %d, %d, %v want match for %s, %d, %d, %v`,
-			digests, algId, index, err, []string{"0"}, uint16(0), 3, nil)
+	if name := GetAlgorithmName(0x27); name != "0x0027" {
+		t.Fatalf(`GetAlgorithmName(0x27) = %s want 0x0027`, name)
 	}
 }
 
@@ -132,8 +157,145 @@ func TestGetBasicInfo(t *testing.T) {
 	}
 }
 
+func buildCcelEventlog(eventNum int, padding int) []byte {
+	return append(buildTpmEventlog(eventNum), bytes.Repeat([]byte{0xff}, padding)...)
+}
+
+func TestFetchEventlogsWithData(t *testing.T) {
+	data := buildCcelEventlog(3, 64)
+
+	eventlogs, count, err := fetchEventlogs(data)
+	if err != nil || count != 4 || len(eventlogs.EventLogs) != 3 {
+		t.Fatalf(`fetchEventlogs(data) = %d, %d, %v want %d, %d, %v`,
+			len(eventlogs.EventLogs), count, err, 3, 4, nil)
+	}
+
+	if eventlogs.Header.Etype != EVENT_TYPE_EV_NO_ACTION || eventlogs.Header.DigestSizes[TPM_ALG_SHA256] != 32 ||
+		eventlogs.Header.Length != len(eventlogs.Header.HeaderData) {
+		t.Fatalf(`fetchEventlogs(data) got invalid Spec ID header %v`, eventlogs.Header)
+	}
+
+	eventlog := eventlogs.EventLogs[2]
+	if eventlog.Rtmr != 1 || len(eventlog.Digests) != 2 || !bytes.Equal(eventlog.Event, []byte{2, 0xab}) ||
+		eventlog.Length != len(eventlog.Data) {
+		t.Fatalf(`fetchEventlogs(data) got invalid event %v`, eventlog)
+	}
+}
+
+func TestMarshalRawEventlogs(t *testing.T) {
+	data := buildTpmEventlog(3)
+
+	eventlogs, _, err := fetchEventlogs(buildCcelEventlog(3, 16))
+	if err != nil {
+		t.Fatalf("fetchEventlogs returned error: %v", err)
+	}
+	tpmEventlogs, _, err := fetchTpmEventlogs(data)
+	if err != nil {
+		t.Fatalf("fetchTpmEventlogs returned error: %v", err)
+	}
+
+	for _, e := range []TDEventLogs{eventlogs, tpmEventlogs} {
+		raw, err := MarshalRawEventlogs(e)
+		if err != nil || !bytes.Equal(raw, data) {
+			t.Fatalf("MarshalRawEventlogs() = %x, %v want %x", raw, err, data)
+		}
+	}
+
+	/* a page holds the Spec ID event and the events of the page */
+	page, _ := getEventlogsInRange(eventlogs, 1, 1)
+	raw, err := MarshalRawEventlogs(page)
+	want := append(append([]byte{}, eventlogs.Header.HeaderData...), eventlogs.EventLogs[1].Data...)
+	if err != nil || !bytes.Equal(raw, want) {
+		t.Fatalf("MarshalRawEventlogs(page) = %x, %v want %x", raw, err, want)
+	}
+
+	/* the event logs read back from JSON keep the binary data */
+	jsonEventlogs, _ := MarshalEventlogs(eventlogs, false)
+	decoded, _ := UnmarshalEventlogs([]byte(jsonEventlogs))
+	if raw, err := MarshalRawEventlogs(decoded); err != nil || !bytes.Equal(raw, data) {
+		t.Fatalf("MarshalRawEventlogs(decoded) = %x, %v want %x", raw, err, data)
+	}
+
+	decoded.EventLogs[1].Data = nil
+	if _, err := MarshalRawEventlogs(decoded); err != RawEventlogNotFoundErr {
+		t.Fatalf("Err -> Want: %v, Got: %v", RawEventlogNotFoundErr, err)
+	}
+}
+
+func TestFetchEventlogsInvalidData(t *testing.T) {
+	valid := buildCcelEventlog(2, 0)
+	headerLen := 32 + 37
+	eventLen := 12 + 2 + 20 + 2 + 32 + 4 + 2
+
+	tests := []struct {
+		name     string
+		data     func() []byte
+		expected error
+		offset   int
+	}{
+		{"truncatedHeader", func() []byte { return valid[:40] }, InvalidCcelEventlogErr, 0},
+		{"truncatedEvent", func() []byte { return valid[:len(valid)-1] }, InvalidCcelEventlogErr, headerLen + eventLen},
+		{"truncatedDigest", func() []byte { return valid[:headerLen+20] }, nil, headerLen},
+		{"notSpecIdEvent", func() []byte {
+			data := append([]byte{}, valid...)
+			binary.LittleEndian.PutUint32(data[4:8], 0x80000008)
+			return data
+		}, InvalidCcelEventlogErr, 0},
+		{"hugeSpecIdEventSize", func() []byte {
+			data := append([]byte{}, valid...)
+			binary.LittleEndian.PutUint32(data[28:32], 0xffffffff)
+			return data
+		}, InvalidCcelEventlogErr, 0},
+		{"hugeAlgorithmNumber", func() []byte {
+			data := append([]byte{}, valid...)
+			binary.LittleEndian.PutUint32(data[56:60], 0xffffffff)
+			return data
+		}, nil, 0},
+		{"hugeVendorSize", func() []byte {
+			data := append([]byte{}, valid...)
+			data[headerLen-1] = 0xff
+			return data
+		}, InvalidCcelEventlogErr, 0},
+		{"hugeDigestCount", func() []byte {
+			data := append([]byte{}, valid...)
+			binary.LittleEndian.PutUint32(data[headerLen+8:headerLen+12], 0xffffffff)
+			return data
+		}, nil, headerLen},
+		{"unknownAlgorithm", func() []byte {
+			data := append([]byte{}, valid...)
+			binary.LittleEndian.PutUint16(data[headerLen+12:headerLen+14], 0x27)
+			return data
+		}, UnknownAlgorithmErr, headerLen},
+		{"hugeEventSize", func() []byte {
+			data := append([]byte{}, valid...)
+			binary.LittleEndian.PutUint32(data[headerLen+eventLen-6:headerLen+eventLen-2], 0xffffffff)
+			return data
+		}, InvalidCcelEventlogErr, headerLen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := fetchEventlogs(tt.data())
+			if err == nil {
+				t.Fatalf(`fetchEventlogs() = nil want error`)
+			}
+			if tt.expected != nil && pkgerrors.Cause(err) != tt.expected {
+				t.Fatalf(`fetchEventlogs() = %v want cause %v`, err, tt.expected)
+			}
+			if !strings.Contains(err.Error(), fmt.Sprintf("offset 0x%x", tt.offset)) {
+				t.Fatalf(`fetchEventlogs() = %v want offset 0x%x`, err, tt.offset)
+			}
+		})
+	}
+}
+
 func TestFetchEventlog(t *testing.T) {
-	_, count, err := fetchEventlogs()
+	data, err := readCcelFile(CCEL_DATA_MOUNT_LOCATION, CCEL_DATA_LOCATION)
+	if err != nil {
+		t.Fatalf(`readCcelFile() = %v want %v`, err, nil)
+	}
+
+	_, count, err := fetchEventlogs(data)
 	if err != nil || count == 0 {
 		t.Fatalf(`fetchEventlog() = %d, %v want %s, %v`, count, err, "large then 0", nil)
 	}
@@ -143,8 +305,113 @@ func TestGetTdxEventlog(t *testing.T) {
 	start_position := 0
 	count := 1
 
-	log, err := GetTdxEventlog(start_position, count)
+	log, err := GetTdxEventlog(start_position, count, EventlogFilter{})
 	if err != nil || log == "" {
 		t.Fatalf(`GetTdxEventlog(0, 1) = %s, %v want %s, %v`, log, err, "eventlogs exist", nil)
 	}
 }
+
+/* Runtime event log as written by the measurement server, with SHA384 EV_IPL events of the RTMRs */
+func buildRuntimeEventlog(rtmrs []uint32, events []string) []byte {
+	var buf bytes.Buffer
+
+	specId := []byte(TPM_SPEC_ID_EVENT_SIGNATURE)
+	specId = binary.LittleEndian.AppendUint32(specId, 0)
+	specId = append(specId, 0, 2, 0, 2)
+	specId = binary.LittleEndian.AppendUint32(specId, 1)
+	specId = binary.LittleEndian.AppendUint16(specId, TPM_ALG_SHA384)
+	specId = binary.LittleEndian.AppendUint16(specId, 48)
+	specId = append(specId, 0)
+
+	_ = binary.Write(&buf, binary.LittleEndian, []uint32{0, EVENT_TYPE_EV_NO_ACTION})
+	buf.Write(make([]byte, TPM_SHA1_DIGEST_SIZE))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(specId)))
+	buf.Write(specId)
+
+	for i, rtmr := range rtmrs {
+		digest := sha512.Sum384([]byte(events[i]))
+		_ = binary.Write(&buf, binary.LittleEndian, []uint32{rtmr + 1, EVENT_TYPE_EV_IPL, 1})
+		_ = binary.Write(&buf, binary.LittleEndian, uint16(TPM_ALG_SHA384))
+		buf.Write(digest[:])
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(events[i])))
+		buf.WriteString(events[i])
+	}
+
+	return buf.Bytes()
+}
+
+func TestGetTdxEventlogsWithRuntimeEventlog(t *testing.T) {
+	defer SetLocations(DefaultLocations())
+
+	fixtures := t.TempDir()
+	data := buildCcelEventlog(3, 64)
+	tableLocation := filepath.Join(fixtures, "CCEL")
+	dataLocation := filepath.Join(fixtures, "CCEL_data")
+	runtimeLocation := filepath.Join(fixtures, "runtime_eventlog")
+	if err := os.WriteFile(tableLocation, buildCcelTable(CC_TYPE_TDX, uint64(len(data)), 0x7e000000), 0600); err != nil {
+		t.Fatalf("Failed to write CCEL table: %v", err)
+	}
+	if err := os.WriteFile(dataLocation, data, 0600); err != nil {
+		t.Fatalf("Failed to write CCEL data: %v", err)
+	}
+	SetLocations(Locations{CcelTable: tableLocation, CcelData: dataLocation, RuntimeEventlog: runtimeLocation})
+
+	/* the runtime event log does not exist before the first RTMR extend */
+	eventlogs, err := GetTdxEventlogs(0, 0, EventlogFilter{})
+	if err != nil || len(eventlogs.EventLogs) != 3 {
+		t.Fatalf("GetTdxEventlogs() = %d, %v want the 3 CCEL events", len(eventlogs.EventLogs), err)
+	}
+
+	rtmrs := []uint32{2, 3, 2}
+	events := []string{"config:/etc/app/config.yaml", "model:resnet50", "image:nginx"}
+	for count := 2; count <= len(rtmrs); count++ {
+		if err := os.WriteFile(runtimeLocation, buildRuntimeEventlog(rtmrs[:count], events[:count]), 0600); err != nil {
+			t.Fatalf("Failed to write runtime eventlog: %v", err)
+		}
+
+		eventlogs, err = GetTdxEventlogs(0, 0, EventlogFilter{})
+		if err != nil || len(eventlogs.EventLogs) != 3+count {
+			t.Fatalf("GetTdxEventlogs() = %d, %v want the CCEL and %d runtime events", len(eventlogs.EventLogs), err, count)
+		}
+	}
+
+	for i, eventlog := range eventlogs.EventLogs[3:] {
+		digest := sha512.Sum384([]byte(events[i]))
+		if eventlog.Rtmr != rtmrs[i] || eventlog.Etype != EVENT_TYPE_EV_IPL || string(eventlog.Event) != events[i] ||
+			len(eventlog.Digests) != 1 || !bytes.Equal(eventlog.Digests[0].Digest, digest[:]) {
+			t.Errorf("Runtime event %d = %+v want the event of RTMR %d", i, eventlog, rtmrs[i])
+		}
+	}
+
+	/* a record still being written is left out of the event log */
+	runtimeData := buildRuntimeEventlog(rtmrs, events)
+	specIdLength := len(buildRuntimeEventlog(nil, nil))
+	invalidHeader := append([]byte{}, runtimeData...)
+	invalidHeader[4] = EVENT_TYPE_EV_IPL
+	tests := []struct {
+		name    string
+		data    []byte
+		count   int
+		wantErr bool
+	}{
+		{"Truncated last event", runtimeData[:len(runtimeData)-1], 2, false},
+		{"Truncated last digest", runtimeData[:len(runtimeData)-len(events[2])-20], 2, false},
+		{"Truncated Spec ID event", runtimeData[:specIdLength-1], 0, false},
+		{"Invalid Spec ID event", invalidHeader, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(runtimeLocation, tt.data, 0600); err != nil {
+				t.Fatalf("Failed to write runtime eventlog: %v", err)
+			}
+			eventlogs, err := GetTdxEventlogs(0, 0, EventlogFilter{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetTdxEventlogs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(eventlogs.EventLogs) != 3+tt.count {
+				t.Errorf("GetTdxEventlogs() = %d events want the CCEL and %d runtime events", len(eventlogs.EventLogs), tt.count)
+			}
+		})
+	}
+}
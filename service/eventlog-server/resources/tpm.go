@@ -6,7 +6,7 @@
 package resources
 
 import (
-	"io"
+	"bytes"
 	"log"
 	"os"
 
@@ -14,53 +14,196 @@ import (
 )
 
 const (
-	//The location of CCEL table
+	//The location of TPM eventlog
 	TPM_EVENT_LOG_LOCATION = "/sys/kernel/security/tpm0/binary_bios_measurements"
 
 	CHUNK_SIZE = 16384
+
+	// The size of the digest in the SHA1 format event header (TCG_PCClientPCREvent)
+	TPM_SHA1_DIGEST_SIZE = 20
+	// The signature of the Spec ID event for crypto agile event logs
+	TPM_SPEC_ID_EVENT_SIGNATURE = "Spec ID Event03\x00"
 )
 
 var (
 	TpmGetEventlogErr      = pkgerrors.New("Failed to get eventlog in TPM.")
 	TpmEventlogNotFoundErr = pkgerrors.New("TPM eventlog not found.")
+	InvalidTpmEventlogErr  = pkgerrors.New("TPM eventlog with invalid data")
 )
 
-func GetTpmEventlog(start_position int, length int) (string, error) {
-
-	var eventlog string
+func GetTpmEventlog(start_position int, length int, filter EventlogFilter) (string, error) {
 
-	/* Check if the tpm eventlog file exists*/
-	if _, err := os.Stat(TPM_EVENT_LOG_LOCATION); err != nil {
+	eventlogs, err := GetTpmEventlogs(start_position, length, filter)
+	if err != nil {
 		return "", err
 	}
 
-	/* Read eventlog data*/
-	object, err := os.OpenFile(TPM_EVENT_LOG_LOCATION, os.O_RDONLY, 0644)
+	return MarshalEventlogs(eventlogs, false)
+}
+
+func GetTpmEventlogs(start_position int, length int, filter EventlogFilter) (TDEventLogs, error) {
+
+	location := locations.TpmEventlog
+	eventlogs, err := tpmEventlogCache.get([]string{location}, func() (TDEventLogs, error) {
+		return loadTpmEventlogs(location)
+	})
 	if err != nil {
-		return "", TpmEventlogNotFoundErr
+		return TDEventLogs{}, err
 	}
 
-	data, err := io.ReadAll(object)
+	return selectEventlogs(eventlogs, filter, start_position, length)
+}
+
+func loadTpmEventlogs(location string) (TDEventLogs, error) {
+
+	/* Read eventlog data, the file is closed once read */
+	data, err := os.ReadFile(location)
 	if err != nil {
-		return "", err
+		log.Println("Error reading TPM eventlog", location)
+		return TDEventLogs{}, TpmEventlogNotFoundErr
 	}
 
 	if len(data) == 0 {
-		return "", TpmEventlogNotFoundErr
+		return TDEventLogs{}, TpmEventlogNotFoundErr
+	}
+
+	eventlogs, _, err := fetchTpmEventlogs(data)
+	return eventlogs, err
+}
+
+/*
+The TPM event log follows the TCG PC Client Platform Firmware Profile Specification:
+the first record is a SHA1 format TCG_PCClientPCREvent carrying the Spec ID event,
+the following records are crypto agile TCG_PCR_EVENT2 structures.
+Reference: https://github.com/tpm2-software/tpm2-tools/blob/master/lib/tpm2_eventlog.c
+*/
+func parseTpmEventlogs(data []byte, position int, length int, filter EventlogFilter) (TDEventLogs, error) {
+
+	eventlogs, _, err := fetchTpmEventlogs(data)
+	if err != nil {
+		return TDEventLogs{}, err
 	}
 
-	eventlog, err = parseTpmEventlog(data, start_position, length)
+	return selectEventlogs(eventlogs, filter, position, length)
+}
+
+func fetchTpmEventlogs(data []byte) (TDEventLogs, int, error) {
+
+	var index int
+	var err error
+
+	eventLogs := TDEventLogs{}
+
+	eventLogs.Header, index, err = getTpmSpecIdHeader(data)
 	if err != nil {
-		return "", err
+		log.Println("Error in getting TPM Spec ID event")
+		return TDEventLogs{}, 0, err
+	}
+	count := 1
+
+	for index < len(data) {
+		start := index
+
+		eventLog := TDEventLog{}
+		eventLog.Rtmr, index, err = getUint32Object(data, start)
+		if err != nil {
+			log.Println("Error in getting PCR index")
+			return TDEventLogs{}, 0, err
+		}
+
+		eventLog.Etype, index, err = getUint32Object(data, index)
+		if err != nil {
+			log.Println("Error in getting event type")
+			return TDEventLogs{}, 0, err
+		}
+
+		eventLog.DigestCount, index, err = getUint32Object(data, index)
+		if err != nil {
+			log.Println("Error in getting digest count")
+			return TDEventLogs{}, 0, err
+		}
+
+		eventLog.Digests, index, err = getEventLogDigestInfo(data, index, eventLog.DigestCount, eventLogs.Header.DigestSizes)
+		if err != nil {
+			log.Println("Error in getting event log digest info")
+			return TDEventLogs{}, 0, err
+		}
+
+		eventLog.EventSize, index, err = getUint32Object(data, index)
+		if err != nil {
+			log.Println("Error in getting event size")
+			return TDEventLogs{}, 0, err
+		}
+
+		if index+int(eventLog.EventSize) > len(data) {
+			log.Println("Event size exceeds the TPM eventlog length")
+			return TDEventLogs{}, 0, InvalidTpmEventlogErr
+		}
+
+		eventLog.Event = data[index : index+int(eventLog.EventSize)]
+		index = index + int(eventLog.EventSize)
+		eventLog.Length = index - start
+		eventLog.Data = data[start:index]
+		eventLogs.EventLogs = append(eventLogs.EventLogs, eventLog)
+
+		count += 1
 	}
 
-	return eventlog, nil
+	return eventLogs, count, nil
 }
 
-func parseTpmEventlog(data []byte, position int, length int) (string, error) {
-	//not implemented, refer to https://github.com/tpm2-software/tpm2-tools/blob/master/tools/misc/tpm2_eventlog.c#L62
-	log.Println("Not implemented.")
+func getTpmSpecIdHeader(data []byte) (TDEventLogSpecIdHeader, int, error) {
+
+	var vendorSize uint8
+	var err error
+
+	specidHeader := TDEventLogSpecIdHeader{}
+	index := 0
+
+	specidHeader.Rtmr, index, err = getUint32Object(data, index)
+	if err != nil {
+		return TDEventLogSpecIdHeader{}, 0, err
+	}
+
+	specidHeader.Etype, index, err = getUint32Object(data, index)
+	if err != nil {
+		return TDEventLogSpecIdHeader{}, 0, err
+	}
+
+	if specidHeader.Etype != EVENT_TYPE_EV_NO_ACTION {
+		log.Println("The first TPM event is not a Spec ID event")
+		return TDEventLogSpecIdHeader{}, 0, InvalidTpmEventlogErr
+	}
+
+	/* skip the SHA1 digest and the event size of the header event */
+	index += TPM_SHA1_DIGEST_SIZE + 4
+	if index+len(TPM_SPEC_ID_EVENT_SIGNATURE) > len(data) ||
+		!bytes.Equal(data[index:index+len(TPM_SPEC_ID_EVENT_SIGNATURE)], []byte(TPM_SPEC_ID_EVENT_SIGNATURE)) {
+		log.Println("Invalid Spec ID event signature, only crypto agile TPM eventlog is supported")
+		return TDEventLogSpecIdHeader{}, 0, InvalidTpmEventlogErr
+	}
+
+	/* skip signature, platform class, spec version and uintn size */
+	index += 24
+
+	specidHeader.DigestSizes, index, err = getHeaderDigestInfo(data, index)
+	if err != nil {
+		return TDEventLogSpecIdHeader{}, 0, err
+	}
+	specidHeader.DigestCount = uint32(len(specidHeader.DigestSizes))
+
+	vendorSize, index, err = getUint8Object(data, index)
+	if err != nil {
+		return TDEventLogSpecIdHeader{}, 0, err
+	}
+
+	index = index + int(vendorSize)
+	if index > len(data) {
+		return TDEventLogSpecIdHeader{}, 0, InvalidTpmEventlogErr
+	}
 
-	return "", nil
+	specidHeader.Length = index
+	specidHeader.HeaderData = data[0:index]
 
+	return specidHeader, index, nil
 }
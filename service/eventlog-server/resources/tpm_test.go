@@ -6,6 +6,8 @@
 package resources
 
 import (
+	"bytes"
+	"encoding/binary"
 	"testing"
 )
 
@@ -17,8 +19,115 @@ func TestGetTpmEventlog(t *testing.T) {
 	start_position := 0
 	count := 1
 
-	_, err := GetTpmEventlog(start_position, count)
+	_, err := GetTpmEventlog(start_position, count, EventlogFilter{})
 	if err.Error() != TPM_ERR_MSG {
 		t.Fatalf(`GetTpmEventlog(0,1) get error %s want %s`, err.Error(), TPM_ERR_MSG)
 	}
 }
+
+func buildTpmEventlog(eventNum int) []byte {
+	var buf bytes.Buffer
+
+	/* SHA1 format Spec ID event with SHA1 and SHA256 banks */
+	specId := []byte(TPM_SPEC_ID_EVENT_SIGNATURE)
+	specId = binary.LittleEndian.AppendUint32(specId, 0)
+	specId = append(specId, 0, 2, 0, 2)
+	specId = binary.LittleEndian.AppendUint32(specId, 2)
+	specId = binary.LittleEndian.AppendUint16(specId, 0x4)
+	specId = binary.LittleEndian.AppendUint16(specId, 20)
+	specId = binary.LittleEndian.AppendUint16(specId, 0xb)
+	specId = binary.LittleEndian.AppendUint16(specId, 32)
+	specId = append(specId, 0)
+
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(EVENT_TYPE_EV_NO_ACTION))
+	buf.Write(make([]byte, TPM_SHA1_DIGEST_SIZE))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(specId)))
+	buf.Write(specId)
+
+	/* crypto agile events */
+	for i := 0; i < eventNum; i++ {
+		event := []byte{uint8(i), 0xab}
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(i%8))
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(0x80000008))
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(2))
+		_ = binary.Write(&buf, binary.LittleEndian, uint16(0x4))
+		buf.Write(bytes.Repeat([]byte{uint8(i)}, 20))
+		_ = binary.Write(&buf, binary.LittleEndian, uint16(0xb))
+		buf.Write(bytes.Repeat([]byte{uint8(i)}, 32))
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(event)))
+		buf.Write(event)
+	}
+
+	return buf.Bytes()
+}
+
+func TestFetchTpmEventlogs(t *testing.T) {
+	data := buildTpmEventlog(3)
+
+	eventlogs, count, err := fetchTpmEventlogs(data)
+	if err != nil || count != 4 || len(eventlogs.EventLogs) != 3 {
+		t.Fatalf(`fetchTpmEventlogs(data) = %d, %d, %v want %d, %d, %v`,
+			len(eventlogs.EventLogs), count, err, 3, 4, nil)
+	}
+
+	if eventlogs.Header.DigestSizes[0x4] != 20 || eventlogs.Header.DigestSizes[0xb] != 32 ||
+		eventlogs.Header.Length != len(eventlogs.Header.HeaderData) {
+		t.Fatalf(`fetchTpmEventlogs(data) got invalid Spec ID header %v`, eventlogs.Header)
+	}
+
+	eventlog := eventlogs.EventLogs[2]
+	if eventlog.Rtmr != 2 || eventlog.Etype != 0x80000008 || eventlog.DigestCount != 2 ||
+		len(eventlog.Digests) != 2 || eventlog.Digests[0].AlgorithmId != TPM_ALG_SHA1 ||
+		eventlog.Digests[1].AlgorithmId != TPM_ALG_SHA256 || !bytes.Equal(eventlog.Digests[1].Digest, bytes.Repeat([]byte{2}, 32)) ||
+		eventlog.EventSize != 2 || !bytes.Equal(eventlog.Event, []byte{2, 0xab}) {
+		t.Fatalf(`fetchTpmEventlogs(data) got invalid event %v`, eventlog)
+	}
+}
+
+func TestFetchTpmEventlogsInvalidData(t *testing.T) {
+	data := buildTpmEventlog(1)
+
+	// Legacy SHA1 event log
+	legacy := append([]byte{}, data...)
+	copy(legacy[32:], []byte("Spec ID Event02"))
+	_, _, err := fetchTpmEventlogs(legacy)
+	if err != InvalidTpmEventlogErr {
+		t.Fatalf(`fetchTpmEventlogs(legacy) = %v want %v`, err, InvalidTpmEventlogErr)
+	}
+
+	// Truncated event data
+	_, _, err = fetchTpmEventlogs(data[:len(data)-1])
+	if err != InvalidTpmEventlogErr {
+		t.Fatalf(`fetchTpmEventlogs(truncated) = %v want %v`, err, InvalidTpmEventlogErr)
+	}
+}
+
+func TestParseTpmEventlogs(t *testing.T) {
+	data := buildTpmEventlog(6)
+
+	eventlogs, err := parseTpmEventlogs(data, 1, 3, EventlogFilter{})
+	if err != nil || len(eventlogs.EventLogs) != 3 || eventlogs.EventLogs[0].Rtmr != 1 {
+		t.Fatalf(`parseTpmEventlogs(data, 1, 3, EventlogFilter{}) = %v, %v want 3 eventlogs start from PCR 1`, eventlogs, err)
+	}
+
+	eventlog, err := MarshalEventlogs(eventlogs, false)
+	if err != nil {
+		t.Fatalf(`MarshalEventlogs(eventlogs, false) = %v want %v`, err, nil)
+	}
+
+	unmarshaled, err := UnmarshalEventlogs([]byte(eventlog))
+	if err != nil || len(unmarshaled.EventLogs) != 3 {
+		t.Fatalf(`UnmarshalEventlogs(%s) = %v want 3 eventlogs`, eventlog, err)
+	}
+
+	eventlogs, err = parseTpmEventlogs(data, 5, 3, EventlogFilter{})
+	if err != nil || len(eventlogs.EventLogs) != 1 || eventlogs.TotalCount != 6 {
+		t.Fatalf(`parseTpmEventlogs(data, 5, 3, EventlogFilter{}) = %v, %v want the last eventlog of 6`, eventlogs, err)
+	}
+
+	_, err = parseTpmEventlogs(data, 7, 3, EventlogFilter{})
+	if err != InvalidEventlogRangeErr {
+		t.Fatalf(`parseTpmEventlogs(data, 7, 3, EventlogFilter{}) = %v want %v`, err, InvalidEventlogRangeErr)
+	}
+}
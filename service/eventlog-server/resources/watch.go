@@ -0,0 +1,33 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/*
+A watch sends the events appended to an event log as they come. The sequence of an event is
+its position in the whole event log of the category, the same as the start position of an
+unfiltered request, or the sequence of a container event. The watch sends the events from
+the start sequence, or only the events appended after the request without a snapshot, so a
+client resumes after a reconnect with the sequence following the last event it received.
+The container event log wakes up its watchers once an event is recorded, the TDX, TPM and
+IMA event logs are read again at the poll interval.
+*/
+const (
+	// The length of the event log when the watch started is returned in the header metadata,
+	// the events with a lower sequence were already in the log
+	EVENTLOG_WATCH_SEQUENCE_KEY = "eventlog-watch-sequence"
+
+	WATCH_POLL_INTERVAL = time.Second
+)
+
+var (
+	InvalidWatchSequenceErr = pkgerrors.New("Sequence beyond the end of the event log")
+)
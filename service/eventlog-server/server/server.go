@@ -7,73 +7,261 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"math"
 	"net"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/reflection"
 
+	config "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/config"
+	policy "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/policy"
 	pb "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/proto"
 	resources "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+	peercred "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/peercred"
+	mpb "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/proto"
 	pkgerrors "github.com/pkg/errors"
 )
 
 var (
-	InvalidRequestErr = pkgerrors.New("Invalid Request")
+	InvalidRequestErr   = pkgerrors.New("Invalid Request")
+	MissingPolicyErr    = pkgerrors.New("No reference value policy in the request or configured")
+	UnauthorizedPeerErr = pkgerrors.New("Container events are only recorded by the container runtime")
 )
 
 const (
-	RUNTIME_EVENT_LOG_DIR  = "/run/ccnp-eventlog/"
-	FILENAME               = "eventlog.log"
 	protocol               = "unix"
-	sockAddr               = "/run/ccnp/uds/eventlog.sock"
 	MAX_CONCURRENT_STREAMS = 100
+
+	// Every request gets its own event log file named eventlog-<random id>.log
+	EVENTLOG_FILE_PREFIX     = "eventlog-"
+	EVENTLOG_FILE_SUFFIX     = ".log"
+	EVENTLOG_TMP_FILE_SUFFIX = ".tmp"
+	EVENTLOG_FILE_ID_LEN     = 16
+	// Event log files are removed once they are older than the lifetime
+	EVENTLOG_FILE_LIFETIME         = 5 * time.Minute
+	EVENTLOG_FILE_CLEANUP_INTERVAL = time.Minute
+
+	// The timeout of extending RTMR 3 with the anchor of a container event
+	MEASUREMENT_EXTEND_TIMEOUT = 5 * time.Second
 )
 
 type eventlogServer struct {
 	pb.UnimplementedEventlogServer
+	// write event logs in the format before schema versioning, kept for one release
+	legacyFormat bool
+	// directory of the event log files written for the requests
+	eventlogDir string
+	// interval the watched TDX, TPM and IMA event logs are read again
+	watchInterval time.Duration
+	// runtime event log of the containers on the node
+	containerStore *resources.ContainerEventStore
+	// reference value policy evaluated if a request carries no policy, nil if not configured
+	policy *policy.Policy
 }
 
-func getContainerLevelEventlog(eventlogReq *pb.GetEventlogRequest) (string, error) {
-	// not implemented
-	return "", nil
+/* The range of the event logs returned for a request, see resources.GetPageRange */
+type eventlogPage struct {
+	totalCount    int
+	startPosition int
+	count         int
+	nextPageToken string
 }
 
-func getPaasLevelEventlog(eventlogReq *pb.GetEventlogRequest) (string, error) {
-	var category pb.CATEGORY
+func newEventlogPage(eventlogReq *pb.GetEventlogRequest, total int, count int) eventlogPage {
+	start := int(eventlogReq.StartPosition)
+	return eventlogPage{
+		totalCount:    total,
+		startPosition: start,
+		count:         count,
+		nextPageToken: resources.EncodePageToken(start+count, total, getEventlogScope(eventlogReq)),
+	}
+}
+
+func (p eventlogPage) metadata() metadata.MD {
+	return metadata.Pairs(
+		resources.EVENTLOG_TOTAL_COUNT_KEY, strconv.Itoa(p.totalCount),
+		resources.EVENTLOG_START_POSITION_KEY, strconv.Itoa(p.startPosition),
+		resources.EVENTLOG_COUNT_KEY, strconv.Itoa(p.count),
+		resources.EVENTLOG_NEXT_PAGE_TOKEN_KEY, p.nextPageToken,
+	)
+}
+
+/* Everything in the request except the range, a page token is only valid for the same scope */
+func getEventlogScope(eventlogReq *pb.GetEventlogRequest) string {
+	eventTypeRange := "-"
+	if eventlogReq.EventTypeRange != nil {
+		eventTypeRange = fmt.Sprintf("%d-%d", eventlogReq.EventTypeRange.Min, eventlogReq.EventTypeRange.Max)
+	}
+
+	return fmt.Sprintf("%s/%s/%q/%v/%v/%s/%d", eventlogReq.EventlogLevel, eventlogReq.EventlogCategory, eventlogReq.ContainerId,
+		eventlogReq.RegisterIndexes, eventlogReq.EventTypes, eventTypeRange, eventlogReq.AlgorithmId)
+}
+
+/* The page token, if any, replaces the start position of the request */
+func resolveStartPosition(eventlogReq *pb.GetEventlogRequest) error {
+	if eventlogReq.PageToken == "" {
+		return nil
+	}
+
+	position, err := resources.DecodePageToken(eventlogReq.PageToken, getEventlogScope(eventlogReq))
+	if err != nil {
+		return err
+	}
+	eventlogReq.StartPosition = int32(position)
+	return nil
+}
+
+func (s *eventlogServer) getContainerLevelEventlog(eventlogReq *pb.GetEventlogRequest) (string, eventlogPage, error) {
+	if eventlogReq.EventlogFormat != pb.FORMAT_DEFAULT {
+		log.Println("Event log format is not supported by the container event log")
+		return "", eventlogPage{}, InvalidRequestErr
+	}
+
+	eventlogs, err := s.containerStore.GetEventlogs(eventlogReq.ContainerId, int(eventlogReq.StartPosition), int(eventlogReq.Count))
+	if err != nil {
+		return "", eventlogPage{}, err
+	}
+
+	eventlog, err := resources.MarshalContainerEventlogs(eventlogs)
+	return eventlog, newEventlogPage(eventlogReq, eventlogs.TotalCount, len(eventlogs.EventLogs)), err
+}
+
+func getPaasLevelEventlog(eventlogReq *pb.GetEventlogRequest, legacyFormat bool) (string, eventlogPage, error) {
+	if eventlogReq.EventlogCategory == pb.CATEGORY_IMA_EVENTLOG {
+		if eventlogReq.EventlogFormat == pb.FORMAT_RAW {
+			log.Println("Binary format is not supported by the IMA event log")
+			return "", eventlogPage{}, InvalidRequestErr
+		}
+
+		eventlogs, err := resources.GetImaEventlogs(int(eventlogReq.StartPosition), int(eventlogReq.Count))
+		if err != nil {
+			return "", eventlogPage{}, err
+		}
+
+		page := newEventlogPage(eventlogReq, eventlogs.TotalCount, len(eventlogs.EventLogs))
+		if eventlogReq.EventlogFormat != pb.FORMAT_DEFAULT {
+			eventlog, err := marshalCelEventlogs(resources.GetImaCelRecords(eventlogs.EventLogs, page.startPosition), eventlogReq.EventlogFormat)
+			return eventlog, page, err
+		}
+
+		eventlog, err := resources.MarshalImaEventlogs(eventlogs)
+		return eventlog, page, err
+	}
+
+	/* the binary events hold all their digests, they cannot be filtered by algorithm */
+	if eventlogReq.EventlogFormat == pb.FORMAT_RAW && eventlogReq.AlgorithmId != 0 {
+		log.Println("Algorithm filter is not supported by the binary format")
+		return "", eventlogPage{}, InvalidRequestErr
+	}
+
+	eventlogs, err := getPaasLevelEventlogs(eventlogReq)
+	if err != nil {
+		return "", eventlogPage{}, err
+	}
+
 	var eventlog string
+	page := newEventlogPage(eventlogReq, eventlogs.TotalCount, len(eventlogs.EventLogs))
+	switch eventlogReq.EventlogFormat {
+	case pb.FORMAT_DEFAULT:
+		eventlog, err = resources.MarshalEventlogs(eventlogs, legacyFormat)
+	case pb.FORMAT_RAW:
+		var data []byte
+		data, err = resources.MarshalRawEventlogs(eventlogs)
+		eventlog = string(data)
+	default:
+		eventlog, err = marshalCelEventlogs(resources.GetCelRecords(eventlogs.EventLogs, page.startPosition), eventlogReq.EventlogFormat)
+	}
+	return eventlog, page, err
+}
+
+/*
+The CEL records are numbered by their position among the events matching the request, the
+CEL-CBOR and CEL-TLV encodings are binary and written to the event log file as they are.
+*/
+func marshalCelEventlogs(records []resources.CelRecord, format pb.FORMAT) (string, error) {
+	data, err := resources.MarshalCel(records, int(format))
+	if err != nil {
+		log.Println("Error in encoding event logs in CEL format", format)
+		return "", err
+	}
+	return string(data), nil
+}
+
+func getPaasLevelEventlogs(eventlogReq *pb.GetEventlogRequest) (resources.TDEventLogs, error) {
+	var category pb.CATEGORY
+	var eventlogs resources.TDEventLogs
 	var err error
 
 	category = eventlogReq.EventlogCategory
+	filter := getEventlogFilter(eventlogReq)
 
 	switch category {
 	case pb.CATEGORY_TPM_EVENTLOG:
-		eventlog, err = resources.GetTpmEventlog(int(eventlogReq.StartPosition), int(eventlogReq.Count))
+		eventlogs, err = resources.GetTpmEventlogs(int(eventlogReq.StartPosition), int(eventlogReq.Count), filter)
 	case pb.CATEGORY_TDX_EVENTLOG:
-		eventlog, err = resources.GetTdxEventlog(int(eventlogReq.StartPosition), int(eventlogReq.Count))
+		eventlogs, err = resources.GetTdxEventlogs(int(eventlogReq.StartPosition), int(eventlogReq.Count), filter)
 	default:
 		log.Println("Invalid eventlog category.")
-		return "", InvalidRequestErr
+		return resources.TDEventLogs{}, InvalidRequestErr
+	}
+	return eventlogs, err
+}
+
+/* The filters apply to the TDX and TPM event logs */
+func getEventlogFilter(eventlogReq *pb.GetEventlogRequest) resources.EventlogFilter {
+	filter := resources.EventlogFilter{
+		RegisterIndexes: eventlogReq.RegisterIndexes,
+		EventTypes:      eventlogReq.EventTypes,
+	}
+
+	if eventlogReq.EventTypeRange != nil {
+		filter.EventTypeRange = &resources.EventTypeRange{
+			Min: eventlogReq.EventTypeRange.Min,
+			Max: eventlogReq.EventTypeRange.Max,
+		}
 	}
-	return eventlog, err
+
+	if eventlogReq.AlgorithmId != 0 {
+		filter.AlgorithmId = uint16(eventlogReq.AlgorithmId)
+	}
+
+	return filter
 }
 
-func (*eventlogServer) GetEventlog(ctx context.Context, eventlogReq *pb.GetEventlogRequest) (*pb.GetEventlogReply, error) {
+func (s *eventlogServer) GetEventlog(ctx context.Context, eventlogReq *pb.GetEventlogRequest) (*pb.GetEventlogReply, error) {
 	var eventlog_level pb.LEVEL
 	var eventlog string
+	var page eventlogPage
 	var err error
 
+	if err = resolveStartPosition(eventlogReq); err != nil {
+		return &pb.GetEventlogReply{}, err
+	}
+
 	eventlog_level = eventlogReq.EventlogLevel
 
 	switch eventlog_level {
 	case pb.LEVEL_SAAS:
-		eventlog, err = getContainerLevelEventlog(eventlogReq)
+		eventlog, page, err = s.getContainerLevelEventlog(eventlogReq)
 	case pb.LEVEL_PAAS:
-		eventlog, err = getPaasLevelEventlog(eventlogReq)
+		eventlog, page, err = getPaasLevelEventlog(eventlogReq, s.legacyFormat)
 	default:
 		log.Println("Invalid eventlog level.")
 		return &pb.GetEventlogReply{}, InvalidRequestErr
@@ -83,27 +271,471 @@ func (*eventlogServer) GetEventlog(ctx context.Context, eventlogReq *pb.GetEvent
 		return &pb.GetEventlogReply{}, err
 	}
 
-	if _, err := os.Stat(RUNTIME_EVENT_LOG_DIR); os.IsNotExist(err) {
-		err := os.MkdirAll(RUNTIME_EVENT_LOG_DIR, os.ModePerm)
+	reply := &pb.GetEventlogReply{
+		TotalCount:    int32(page.totalCount),
+		StartPosition: int32(page.startPosition),
+		Count:         int32(page.count),
+		NextPageToken: page.nextPageToken,
+	}
+
+	/* the binary event log is returned in the reply, the client needs no access to the eventlog directory */
+	if eventlogReq.EventlogFormat == pb.FORMAT_RAW {
+		reply.EventlogData = []byte(eventlog)
+		return reply, nil
+	}
+
+	reply.EventlogDataLoc, reply.EventlogDataDigest, err = writeEventlogFile(s.eventlogDir, eventlog)
+	if err != nil {
+		return &pb.GetEventlogReply{}, err
+	}
+	return reply, nil
+}
+
+/*
+Write the event log into a file unique to the request. The content is written into
+a temporary file first and renamed afterwards, so that the returned location never
+holds a partially written event log.
+*/
+func writeEventlogFile(dir string, eventlog string) (string, string, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		err := os.MkdirAll(dir, os.ModePerm)
 		if err != nil {
-			return &pb.GetEventlogReply{}, err
+			return "", "", err
 		}
 	}
 
-	file, err := os.Create(fmt.Sprintf("%s%s", RUNTIME_EVENT_LOG_DIR, FILENAME))
+	id := make([]byte, EVENTLOG_FILE_ID_LEN)
+	if _, err := rand.Read(id); err != nil {
+		log.Println("Error generating event log file name")
+		return "", "", err
+	}
+
+	location := filepath.Join(dir, EVENTLOG_FILE_PREFIX+hex.EncodeToString(id)+EVENTLOG_FILE_SUFFIX)
+	tmpLocation := location + EVENTLOG_TMP_FILE_SUFFIX
+
+	file, err := os.OpenFile(tmpLocation, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
 	if err != nil {
-		log.Println("Error creating event log file in", RUNTIME_EVENT_LOG_DIR)
-		return &pb.GetEventlogReply{}, err
+		log.Println("Error creating event log file in", dir)
+		return "", "", err
 	}
-	defer file.Close()
 
 	_, err = file.WriteString(eventlog)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
-		log.Println("Error writing event log file in", RUNTIME_EVENT_LOG_DIR+FILENAME)
-		return &pb.GetEventlogReply{}, err
+		log.Println("Error writing event log file", tmpLocation)
+		os.Remove(tmpLocation)
+		return "", "", err
+	}
+
+	if err = os.Rename(tmpLocation, location); err != nil {
+		log.Println("Error renaming event log file", tmpLocation)
+		os.Remove(tmpLocation)
+		return "", "", err
+	}
+
+	digest := sha256.Sum256([]byte(eventlog))
+	return location, hex.EncodeToString(digest[:]), nil
+}
+
+/* Remove the event log files written by previous requests once their lifetime expires */
+func cleanupEventlogFiles(dir string, lifetime time.Duration) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, EVENTLOG_FILE_PREFIX) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		if time.Since(info.ModTime()) > lifetime {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+				log.Println("Error removing expired event log file", name)
+			}
+		}
 	}
+}
 
-	return &pb.GetEventlogReply{EventlogDataLoc: RUNTIME_EVENT_LOG_DIR + FILENAME}, nil
+func startEventlogFileCleanup(dir string, lifetime time.Duration, interval time.Duration) {
+	go func() {
+		for {
+			cleanupEventlogFiles(dir, lifetime)
+			time.Sleep(interval)
+		}
+	}()
+}
+
+func (s *eventlogServer) GetEventlogStream(eventlogReq *pb.GetEventlogRequest, stream pb.Eventlog_GetEventlogStreamServer) error {
+	var eventlog_level pb.LEVEL
+	var eventlogs resources.TDEventLogs
+	var err error
+
+	/* the entries of the stream are decoded events, the format applies to the event log file */
+	if eventlogReq.EventlogFormat != pb.FORMAT_DEFAULT {
+		log.Println("Event log format is not supported by the event log stream")
+		return InvalidRequestErr
+	}
+
+	if err = resolveStartPosition(eventlogReq); err != nil {
+		return err
+	}
+
+	eventlog_level = eventlogReq.EventlogLevel
+
+	switch eventlog_level {
+	case pb.LEVEL_SAAS:
+		return s.sendContainerEventlogs(eventlogReq, stream)
+	case pb.LEVEL_PAAS:
+		if eventlogReq.EventlogCategory == pb.CATEGORY_IMA_EVENTLOG {
+			return sendImaEventlogs(eventlogReq, stream)
+		}
+		eventlogs, err = getPaasLevelEventlogs(eventlogReq)
+	default:
+		log.Println("Invalid eventlog level.")
+		return InvalidRequestErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := sendEventlogPage(stream, newEventlogPage(eventlogReq, eventlogs.TotalCount, len(eventlogs.EventLogs))); err != nil {
+		return err
+	}
+
+	for _, eventlog := range eventlogs.EventLogs {
+		if err := stream.Send(getEventlogEntry(eventlog)); err != nil {
+			log.Println("Error sending event log entry")
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* The page is sent in the header metadata ahead of the event log entries */
+func sendEventlogPage(stream pb.Eventlog_GetEventlogStreamServer, page eventlogPage) error {
+	if err := stream.SendHeader(page.metadata()); err != nil {
+		log.Println("Error sending event log page")
+		return err
+	}
+	return nil
+}
+
+func sendImaEventlogs(eventlogReq *pb.GetEventlogRequest, stream pb.Eventlog_GetEventlogStreamServer) error {
+	eventlogs, err := resources.GetImaEventlogs(int(eventlogReq.StartPosition), int(eventlogReq.Count))
+	if err != nil {
+		return err
+	}
+
+	if err := sendEventlogPage(stream, newEventlogPage(eventlogReq, eventlogs.TotalCount, len(eventlogs.EventLogs))); err != nil {
+		return err
+	}
+
+	for _, eventlog := range eventlogs.EventLogs {
+		if err := stream.Send(getImaEventlogEntry(eventlog)); err != nil {
+			log.Println("Error sending IMA event log entry")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *eventlogServer) sendContainerEventlogs(eventlogReq *pb.GetEventlogRequest, stream pb.Eventlog_GetEventlogStreamServer) error {
+	eventlogs, err := s.containerStore.GetEventlogs(eventlogReq.ContainerId, int(eventlogReq.StartPosition), int(eventlogReq.Count))
+	if err != nil {
+		return err
+	}
+
+	if err := sendEventlogPage(stream, newEventlogPage(eventlogReq, eventlogs.TotalCount, len(eventlogs.EventLogs))); err != nil {
+		return err
+	}
+
+	for _, eventlog := range eventlogs.EventLogs {
+		if err := stream.Send(getContainerEventlogEntry(eventlog)); err != nil {
+			log.Println("Error sending container event log entry")
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* Container events are not extended into a register, the register index is always 0 */
+func getContainerEventlogEntry(eventlog resources.ContainerEventLog) *pb.EventlogEntry {
+	return &pb.EventlogEntry{
+		EventType: eventlog.EventType,
+		Digests: []*pb.EventlogDigest{{
+			AlgorithmId: uint32(eventlog.AlgorithmId),
+			Digest:      eventlog.Digest,
+		}},
+		EventSize: uint32(len(eventlog.Event)),
+		Event:     eventlog.Event,
+		ContainerEntry: &pb.ContainerEventlogEntry{
+			Sequence:    eventlog.Sequence,
+			ContainerId: eventlog.ContainerId,
+			PodId:       eventlog.PodId,
+			Timestamp:   eventlog.Timestamp.UnixNano(),
+		},
+	}
+}
+
+/* Only connections accepted on the socket of the container runtime carry its credentials */
+func (s *eventlogServer) RecordContainerEvent(ctx context.Context, req *pb.RecordContainerEventRequest) (*pb.RecordContainerEventReply, error) {
+	if p, ok := peer.FromContext(ctx); !ok || p.AuthInfo == nil || p.AuthInfo.AuthType() != peercred.AUTH_TYPE {
+		log.Println("Container event not sent by the container runtime")
+		return &pb.RecordContainerEventReply{}, UnauthorizedPeerErr
+	}
+
+	eventlog, err := s.containerStore.Record(req.ContainerId, req.PodId, uint32(req.EventType), req.Event)
+	if err != nil {
+		return &pb.RecordContainerEventReply{}, err
+	}
+
+	return &pb.RecordContainerEventReply{Sequence: eventlog.Sequence, Digest: eventlog.Digest}, nil
+}
+
+/* The anchors of the container events are extended into RTMR 3 by the measurement server */
+func newMeasurementExtender(client mpb.MeasurementClient) resources.ContainerEventExtender {
+	return func(digest []byte, anchor []byte) error {
+		ctx, cancel := context.WithTimeout(context.Background(), MEASUREMENT_EXTEND_TIMEOUT)
+		defer cancel()
+
+		_, err := client.ExtendMeasurement(ctx, &mpb.ExtendMeasurementRequest{
+			RegisterIndex: resources.CONTAINER_EVENT_RTMR_INDEX,
+			Digest:        digest,
+			Event:         anchor,
+		})
+		return err
+	}
+}
+
+func getEventlogEntry(eventlog resources.TDEventLog) *pb.EventlogEntry {
+	return &pb.EventlogEntry{
+		RegisterIndex: eventlog.Rtmr,
+		EventType:     eventlog.Etype,
+		Digests:       getEventDigests(eventlog),
+		EventSize:     eventlog.EventSize,
+		Event:         eventlog.Event,
+	}
+}
+
+/*
+watchReader returns the entries of the watched event log from the sequence, the number of
+events in the log and, for the container event log, a channel closed once an event is
+recorded. The other event logs are read again at the poll interval.
+*/
+type watchReader func(sequence int) ([]*pb.WatchEventlogReply, int, <-chan struct{}, error)
+
+func (s *eventlogServer) getWatchReader(req *pb.WatchEventlogRequest) (watchReader, error) {
+	switch req.EventlogLevel {
+	case pb.LEVEL_SAAS:
+		return func(sequence int) ([]*pb.WatchEventlogReply, int, <-chan struct{}, error) {
+			var replies []*pb.WatchEventlogReply
+			eventlogs, length, recorded := s.containerStore.WatchEventlogs(req.ContainerId, sequence)
+			for _, eventlog := range eventlogs {
+				replies = append(replies, &pb.WatchEventlogReply{Sequence: uint64(eventlog.Sequence), Entry: getContainerEventlogEntry(eventlog)})
+			}
+			return replies, length, recorded, nil
+		}, nil
+	case pb.LEVEL_PAAS:
+	default:
+		log.Println("Invalid eventlog level.")
+		return nil, InvalidRequestErr
+	}
+
+	switch req.EventlogCategory {
+	case pb.CATEGORY_TDX_EVENTLOG, pb.CATEGORY_TPM_EVENTLOG:
+		return func(sequence int) ([]*pb.WatchEventlogReply, int, <-chan struct{}, error) {
+			var replies []*pb.WatchEventlogReply
+			eventlogs, err := getPaasLevelEventlogs(&pb.GetEventlogRequest{EventlogCategory: req.EventlogCategory})
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			for i := sequence; i < len(eventlogs.EventLogs); i++ {
+				replies = append(replies, &pb.WatchEventlogReply{Sequence: uint64(i), Entry: getEventlogEntry(eventlogs.EventLogs[i])})
+			}
+			return replies, len(eventlogs.EventLogs), nil, nil
+		}, nil
+	case pb.CATEGORY_IMA_EVENTLOG:
+		return func(sequence int) ([]*pb.WatchEventlogReply, int, <-chan struct{}, error) {
+			var replies []*pb.WatchEventlogReply
+			eventlogs, err := resources.GetImaEventlogs(0, 0)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			for i := sequence; i < len(eventlogs.EventLogs); i++ {
+				replies = append(replies, &pb.WatchEventlogReply{Sequence: uint64(i), Entry: getImaEventlogEntry(eventlogs.EventLogs[i])})
+			}
+			return replies, len(eventlogs.EventLogs), nil, nil
+		}, nil
+	default:
+		log.Println("Invalid eventlog category.")
+		return nil, InvalidRequestErr
+	}
+}
+
+// WatchEventlog sends the events of the event log from the start sequence of a resumed watch, or
+// from the start with a snapshot, then the events appended to the log until the client cancels
+// the watch, see resources.WATCH_POLL_INTERVAL.
+func (s *eventlogServer) WatchEventlog(req *pb.WatchEventlogRequest, stream pb.Eventlog_WatchEventlogServer) error {
+	read, err := s.getWatchReader(req)
+	if err != nil {
+		return err
+	}
+
+	/* the start sequence is only read to resume a watch, 0 included */
+	if !req.Resume && req.StartSequence != 0 {
+		log.Println("Start sequence without resume", req.StartSequence)
+		return InvalidRequestErr
+	}
+	if req.StartSequence > math.MaxInt32 {
+		log.Println("Invalid start sequence", req.StartSequence)
+		return resources.InvalidWatchSequenceErr
+	}
+	sequence := int(req.StartSequence)
+
+	replies, length, recorded, err := read(sequence)
+	if err != nil {
+		return err
+	}
+	if sequence > length {
+		log.Printf("Start sequence %d beyond the %d events of the event log", sequence, length)
+		return resources.InvalidWatchSequenceErr
+	}
+
+	/* without a snapshot or a resume, only the events appended after the request are sent */
+	if !req.Snapshot && !req.Resume {
+		replies = nil
+	}
+
+	if err := stream.SendHeader(metadata.Pairs(resources.EVENTLOG_WATCH_SEQUENCE_KEY, strconv.Itoa(length))); err != nil {
+		log.Println("Error sending event log watch sequence")
+		return err
+	}
+
+	var poll <-chan time.Time
+	if recorded == nil {
+		ticker := time.NewTicker(s.watchInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		for _, reply := range replies {
+			if err := stream.Send(reply); err != nil {
+				log.Println("Error sending watched event log entry")
+				return err
+			}
+		}
+		sequence = length
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-recorded:
+		case <-poll:
+		}
+
+		replies, length, recorded, err = read(sequence)
+		if err != nil {
+			return err
+		}
+		/* the event logs only grow, a shorter event log was replaced since the watch started */
+		if length < sequence {
+			log.Printf("Event log of %d events shorter than the %d events watched", length, sequence)
+			return resources.InvalidWatchSequenceErr
+		}
+	}
+}
+
+/*
+Evaluate the whole TDX or TPM event log against the policy of the request, or the policy
+configured for the server if the request carries none. The result is returned in JSON.
+The event log is not replayed against the measured registers, so passed says nothing about
+the authenticity of the event log: a verifier replays it against the RTMRs or PCRs of a quote.
+*/
+func (s *eventlogServer) EvaluatePolicy(ctx context.Context, req *pb.EvaluatePolicyRequest) (*pb.EvaluatePolicyReply, error) {
+	var referencePolicy policy.Policy
+	var err error
+
+	switch {
+	case req.Policy != "":
+		referencePolicy, err = policy.Load([]byte(req.Policy))
+		if err != nil {
+			return &pb.EvaluatePolicyReply{}, err
+		}
+	case s.policy != nil:
+		referencePolicy = *s.policy
+	default:
+		return &pb.EvaluatePolicyReply{}, MissingPolicyErr
+	}
+
+	eventlogs, err := getPaasLevelEventlogs(&pb.GetEventlogRequest{EventlogCategory: req.EventlogCategory})
+	if err != nil {
+		return &pb.EvaluatePolicyReply{}, err
+	}
+
+	result, err := policy.Evaluate(referencePolicy, eventlogs.EventLogs)
+	if err != nil {
+		return &pb.EvaluatePolicyReply{}, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		log.Println("Error in encoding policy result")
+		return &pb.EvaluatePolicyReply{}, err
+	}
+
+	return &pb.EvaluatePolicyReply{Passed: result.Passed, Result: string(data)}, nil
+}
+
+/* The IMA entry is extended into the PCR with its SHA1 template hash */
+func getImaEventlogEntry(eventlog resources.ImaEventLog) *pb.EventlogEntry {
+	return &pb.EventlogEntry{
+		RegisterIndex: eventlog.PcrIndex,
+		Digests: []*pb.EventlogDigest{{
+			AlgorithmId: uint32(resources.TPM_ALG_SHA1),
+			Digest:      eventlog.TemplateHash,
+		}},
+		EventSize: uint32(len(eventlog.TemplateData)),
+		Event:     eventlog.TemplateData,
+		ImaEntry: &pb.ImaEventlogEntry{
+			PcrIndex:            eventlog.PcrIndex,
+			RtmrIndex:           int32(eventlog.RtmrIndex),
+			TemplateName:        eventlog.TemplateName,
+			FileDigestAlgorithm: eventlog.FileDigestAlgorithm,
+			FileDigest:          eventlog.FileDigest,
+			FileName:            eventlog.FileName,
+			Signature:           eventlog.Signature,
+		},
+	}
+}
+
+func getEventDigests(eventlog resources.TDEventLog) []*pb.EventlogDigest {
+	var digests []*pb.EventlogDigest
+
+	for _, digest := range eventlog.Digests {
+		digests = append(digests, &pb.EventlogDigest{
+			AlgorithmId: uint32(digest.AlgorithmId),
+			Digest:      digest.Digest,
+		})
+	}
+
+	return digests
 }
 
 func (*eventlogServer) Check(ctx context.Context, in *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
@@ -116,19 +748,55 @@ func (*eventlogServer) Watch(in *grpc_health_v1.HealthCheckRequest, stream grpc_
 	return nil
 }
 
-func newServer() *eventlogServer {
-	s := &eventlogServer{}
+func newServer(cfg config.Config, containerStore *resources.ContainerEventStore) *eventlogServer {
+	s := &eventlogServer{legacyFormat: cfg.LegacyEventlogFormat, eventlogDir: cfg.EventlogDir,
+		watchInterval: resources.WATCH_POLL_INTERVAL, containerStore: containerStore}
 	return s
 }
 
 func main() {
-	if _, err := os.Stat(sockAddr); !os.IsNotExist(err) {
-		if err := os.RemoveAll(sockAddr); err != nil {
-			log.Fatal(err)
+	cfg, err := config.Load(os.Args[0], os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		log.Fatalf("failed to read configuration: %v", err)
+	}
+	resources.SetLocations(cfg.Locations())
+
+	var referencePolicy *policy.Policy
+	if cfg.PolicyFile != "" {
+		loaded, err := policy.LoadFile(cfg.PolicyFile)
+		if err != nil {
+			log.Fatalf("failed to read reference value policy: %v", err)
 		}
+		referencePolicy = &loaded
 	}
 
-	lis, err := net.Listen(protocol, sockAddr)
+	measurementConn, err := grpc.Dial(protocol+":"+cfg.MeasurementSocket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect to measurement server: %v", err)
+	}
+	defer measurementConn.Close()
+
+	containerStore, err := resources.NewContainerEventStore(cfg.ContainerEventlogFile,
+		newMeasurementExtender(mpb.NewMeasurementClient(measurementConn)))
+	if err != nil {
+		log.Fatalf("failed to open container event log: %v", err)
+	}
+	defer containerStore.Close()
+
+	for _, socket := range []string{cfg.Socket, cfg.ContainerRuntimeSocket} {
+		if _, err := os.Stat(socket); socket != "" && !os.IsNotExist(err) {
+			if err := os.RemoveAll(socket); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	startEventlogFileCleanup(cfg.EventlogDir, EVENTLOG_FILE_LIFETIME, EVENTLOG_FILE_CLEANUP_INTERVAL)
+
+	lis, err := net.Listen(protocol, cfg.Socket)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
@@ -140,9 +808,29 @@ func main() {
 	grpcServer := grpc.NewServer(opts...)
 	healthServer := health.NewServer()
 
-	pb.RegisterEventlogServer(grpcServer, newServer())
+	server := newServer(cfg, containerStore)
+	server.policy = referencePolicy
+	pb.RegisterEventlogServer(grpcServer, server)
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 
+	if !cfg.RecordsContainerEvents() {
+		log.Println("container events are not recorded, container-runtime-socket and container-runtime-uid must be set")
+	} else {
+		runtimeLis, err := net.Listen(protocol, cfg.ContainerRuntimeSocket)
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		runtimeServer := grpc.NewServer(append(opts, grpc.Creds(peercred.NewCredentials(cfg.ContainerRuntimePeerUid())))...)
+		pb.RegisterEventlogServer(runtimeServer, server)
+		log.Printf("container runtime server listening at %v", runtimeLis.Addr())
+		go func() {
+			if err := runtimeServer.Serve(runtimeLis); err != nil {
+				log.Fatalf("failed to serve container runtime: %v", err)
+			}
+		}()
+	}
+
 	log.Printf("server listening at %v", lis.Addr())
 	reflection.Register(grpcServer)
 	if err = grpcServer.Serve(lis); err != nil {
@@ -5,17 +5,33 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"io"
 	"log"
 	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/test/bufconn"
 
+	config "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/config"
+	policy "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/policy"
 	pb "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/proto"
+	resources "github.com/intel/confidential-cloud-native-primitives/service/eventlog-server/resources"
+	peercred "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/peercred"
+	pkgerrors "github.com/pkg/errors"
 )
 
 const (
@@ -25,17 +41,52 @@ const (
 
 var lis *bufconn.Listener
 
+/* The container runtime socket, served to the user running the tests */
+var runtimeSocket string
+
 func initTestServer(ctx context.Context) {
 	buffer := 1024 * 1024
 	lis = bufconn.Listen(buffer)
 
+	containerStore, _ := resources.NewContainerEventStore("", func([]byte, []byte) error { return nil })
+
+	s := newServer(config.Default(), containerStore)
+	s.watchInterval = 10 * time.Millisecond
+
 	server := grpc.NewServer()
-	pb.RegisterEventlogServer(server, &eventlogServer{})
+	pb.RegisterEventlogServer(server, s)
 	go func() {
 		if err := server.Serve(lis); err != nil {
 			log.Printf("error serving server: %v", err)
 		}
 	}()
+
+	dir, err := os.MkdirTemp("", "ccnp-eventlog-test")
+	if err != nil {
+		log.Fatalf("failed to create socket directory: %v", err)
+	}
+	runtimeSocket = filepath.Join(dir, "container-runtime.sock")
+	runtimeLis, err := net.Listen(protocol, runtimeSocket)
+	if err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+
+	runtimeServer := grpc.NewServer(grpc.Creds(peercred.NewCredentials(uint32(os.Getuid()))))
+	pb.RegisterEventlogServer(runtimeServer, s)
+	go func() {
+		if err := runtimeServer.Serve(runtimeLis); err != nil {
+			log.Printf("error serving container runtime server: %v", err)
+		}
+	}()
+}
+
+func dialContainerRuntime(t *testing.T, ctx context.Context) pb.EventlogClient {
+	conn, err := grpc.DialContext(ctx, protocol+":"+runtimeSocket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect to container runtime socket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewEventlogClient(conn)
 }
 
 func TestEventlogServerGetEventlog(t *testing.T) {
@@ -66,7 +117,7 @@ func TestEventlogServerGetEventlog(t *testing.T) {
 			in: &pb.GetEventlogRequest{},
 			expected: expectation{
 				out: &pb.GetEventlogReply{
-					EventlogDataLoc: "/run/ccnp-eventlog/eventlog.log",
+					EventlogDataLoc: config.RUNTIME_EVENT_LOG_DIR,
 				},
 				err: nil,
 			},
@@ -96,7 +147,7 @@ func TestEventlogServerGetEventlog(t *testing.T) {
 			},
 			expected: expectation{
 				out: &pb.GetEventlogReply{
-					EventlogDataLoc: "/run/ccnp-eventlog/eventlog.log",
+					EventlogDataLoc: config.RUNTIME_EVENT_LOG_DIR,
 				},
 				err: nil,
 			},
@@ -130,7 +181,7 @@ func TestEventlogServerGetEventlog(t *testing.T) {
 			},
 			expected: expectation{
 				out: &pb.GetEventlogReply{
-					EventlogDataLoc: "/run/ccnp-eventlog/eventlog.log",
+					EventlogDataLoc: config.RUNTIME_EVENT_LOG_DIR,
 				},
 				err: nil,
 			},
@@ -144,7 +195,7 @@ func TestEventlogServerGetEventlog(t *testing.T) {
 			},
 			expected: expectation{
 				out: &pb.GetEventlogReply{
-					EventlogDataLoc: "/run/ccnp-eventlog/eventlog.log",
+					EventlogDataLoc: config.RUNTIME_EVENT_LOG_DIR,
 				},
 				err: nil,
 			},
@@ -163,11 +214,769 @@ func TestEventlogServerGetEventlog(t *testing.T) {
 					}
 				}
 			} else {
-				if tt.expected.out.EventlogDataLoc != out.EventlogDataLoc {
+				if !strings.HasPrefix(out.EventlogDataLoc, tt.expected.out.EventlogDataLoc) {
 					t.Errorf("Out -> \nWant: %q\nGot : %q", tt.expected.out, out)
 				}
+
+				data, err := os.ReadFile(out.EventlogDataLoc)
+				digest := sha256.Sum256(data)
+				if err != nil || hex.EncodeToString(digest[:]) != out.EventlogDataDigest {
+					t.Errorf("Out -> \nWant: eventlog file with digest %q\nGot : %v", out.EventlogDataDigest, err)
+				}
 			}
 
 		})
 	}
 }
+
+func TestEventlogServerGetEventlogStream(t *testing.T) {
+	ctx := context.Background()
+	initTestServer(ctx)
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(
+		func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("failed to connect to server: %v", err)
+		return
+	}
+	defer conn.Close()
+	client := pb.NewEventlogClient(conn)
+
+	type expectation struct {
+		count int
+		err   error
+	}
+
+	tests := map[string]struct {
+		in       *pb.GetEventlogRequest
+		expected expectation
+	}{
+		"Invalid_Eventlog_Level": {
+			in: &pb.GetEventlogRequest{
+				EventlogLevel: INVALID_EVENTLOG_LEVEL,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = Unknown desc = Invalid Request"),
+			},
+		},
+		"Invalid_Eventlog_Category": {
+			in: &pb.GetEventlogRequest{
+				EventlogLevel:    pb.LEVEL_PAAS,
+				EventlogCategory: INVALID_EVENTLOG_CATEGORY,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = Unknown desc = Invalid Request"),
+			},
+		},
+		"Request_on_SAAS_Level_Eventlog": {
+			in: &pb.GetEventlogRequest{
+				EventlogLevel: pb.LEVEL_SAAS,
+			},
+			expected: expectation{
+				count: 0,
+				err:   nil,
+			},
+		},
+		"Request_on_TPM_Eventlog_without_TPM_Support": {
+			in: &pb.GetEventlogRequest{
+				EventlogLevel:    pb.LEVEL_PAAS,
+				EventlogCategory: pb.CATEGORY_TPM_EVENTLOG,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = Unknown desc = stat /sys/kernel/security/tpm0/binary_bios_measurements: no such file or directory"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			stream, err := client.GetEventlogStream(ctx, tt.in)
+			if err != nil {
+				t.Fatalf("Err -> \nWant: stream\nGot: %q\n", err)
+			}
+
+			count := 0
+			for {
+				_, err = stream.Recv()
+				if err != nil {
+					break
+				}
+				count++
+			}
+
+			if err == io.EOF {
+				if tt.expected.err != nil {
+					t.Errorf("Err -> \nWant: %q\nGot: nil\n", tt.expected.err)
+				}
+				if tt.expected.count != count {
+					t.Errorf("Out -> \nWant: %d entries\nGot : %d entries", tt.expected.count, count)
+				}
+			} else {
+				if tt.expected.err == nil {
+					t.Errorf("Err -> \nWant: nil\nGot: %q\n", err)
+				} else if tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> \nWant: %q\nGot: %q\n", tt.expected.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestEventlogServerRecordContainerEvent(t *testing.T) {
+	ctx := context.Background()
+	initTestServer(ctx)
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(
+		func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("failed to connect to server: %v", err)
+		return
+	}
+	defer conn.Close()
+	client := pb.NewEventlogClient(conn)
+
+	requests := []*pb.RecordContainerEventRequest{
+		{ContainerId: "c1", PodId: "default/nginx", EventType: pb.CONTAINER_EVENT_TYPE_CONTAINER_IMAGE, Event: []byte("nginx@sha256:00")},
+		{ContainerId: "c1", PodId: "default/nginx", EventType: pb.CONTAINER_EVENT_TYPE_CONTAINER_START, Event: []byte("nginx")},
+		{ContainerId: "c2", PodId: "default/redis", EventType: pb.CONTAINER_EVENT_TYPE_CONTAINER_START, Event: []byte("redis")},
+	}
+	/* the events are only recorded on the socket of the container runtime */
+	if _, err := client.RecordContainerEvent(ctx, requests[0]); err == nil || !strings.Contains(err.Error(), UnauthorizedPeerErr.Error()) {
+		t.Errorf("Err -> \nWant: %q\nGot: %v\n", UnauthorizedPeerErr, err)
+	}
+
+	runtimeClient := dialContainerRuntime(t, ctx)
+	for i, req := range requests {
+		out, err := runtimeClient.RecordContainerEvent(ctx, req)
+		if err != nil || out.Sequence != uint32(i) || len(out.Digest) != 48 {
+			t.Fatalf("RecordContainerEvent(%v) = %v, %v want sequence %d", req, out, err, i)
+		}
+	}
+
+	_, err = runtimeClient.RecordContainerEvent(ctx, &pb.RecordContainerEventRequest{EventType: pb.CONTAINER_EVENT_TYPE_CONTAINER_START})
+	if err == nil || err.Error() != "rpc error: code = Unknown desc = Invalid container event" {
+		t.Errorf("Err -> \nWant: %q\nGot: %v\n", resources.InvalidContainerEventErr, err)
+	}
+
+	stream, err := client.GetEventlogStream(ctx, &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, ContainerId: "default/nginx"})
+	if err != nil {
+		t.Fatalf("Err -> \nWant: stream\nGot: %q\n", err)
+	}
+	var entries []*pb.EventlogEntry
+	for {
+		entry, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 || entries[0].EventType != uint32(pb.CONTAINER_EVENT_TYPE_CONTAINER_IMAGE) ||
+		entries[1].ContainerEntry.Sequence != 1 || string(entries[1].Event) != "nginx" {
+		t.Errorf("GetEventlogStream(default/nginx) = %v want 2 events of container c1", entries)
+	}
+
+	out, err := client.GetEventlog(ctx, &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, ContainerId: "c2"})
+	if err != nil {
+		t.Fatalf("Err -> \nWant: nil\nGot: %q\n", err)
+	}
+	if out.TotalCount != 1 {
+		t.Errorf("TotalCount -> \nWant: 1\nGot: %d\n", out.TotalCount)
+	}
+	data, _ := os.ReadFile(out.EventlogDataLoc)
+	eventlogs, err := resources.UnmarshalContainerEventlogs(data)
+	if err != nil || len(eventlogs.EventLogs) != 1 || eventlogs.EventLogs[0].ContainerId != "c2" {
+		t.Errorf("GetEventlog(c2) = %v, %v want 1 event of container c2", eventlogs, err)
+	}
+
+	_, err = client.GetEventlog(ctx, &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, ContainerId: "c3"})
+	if err == nil || err.Error() != "rpc error: code = Unknown desc = Container eventlog not found." {
+		t.Errorf("Err -> \nWant: %q\nGot: %v\n", resources.ContainerEventlogNotFoundErr, err)
+	}
+}
+
+func receiveWatchedSequences(t *testing.T, stream pb.Eventlog_WatchEventlogClient, count int) []uint64 {
+	var sequences []uint64
+	for len(sequences) < count {
+		reply, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv() after %v returned error: %v", sequences, err)
+		}
+		sequences = append(sequences, reply.Sequence)
+	}
+	return sequences
+}
+
+func TestEventlogServerWatchEventlog(t *testing.T) {
+	ctx := context.Background()
+	initTestServer(ctx)
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(
+		func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("failed to connect to server: %v", err)
+		return
+	}
+	defer conn.Close()
+	client := pb.NewEventlogClient(conn)
+
+	runtimeClient := dialContainerRuntime(t, ctx)
+	record := func(containerId string) {
+		req := &pb.RecordContainerEventRequest{ContainerId: containerId, EventType: pb.CONTAINER_EVENT_TYPE_CONTAINER_START}
+		if _, err := runtimeClient.RecordContainerEvent(ctx, req); err != nil {
+			t.Fatalf("RecordContainerEvent(%s) returned error: %v", containerId, err)
+		}
+	}
+	record("c1")
+	record("c2")
+
+	tests := []struct {
+		name         string
+		in           *pb.WatchEventlogRequest
+		record       []string
+		wantStart    string
+		wantSequence []uint64
+	}{
+		{"Snapshot", &pb.WatchEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, Snapshot: true},
+			[]string{"c1"}, "2", []uint64{0, 1, 2}},
+		{"Appended events only", &pb.WatchEventlogRequest{EventlogLevel: pb.LEVEL_SAAS},
+			[]string{"c2", "c1"}, "3", []uint64{3, 4}},
+		{"Resume from sequence", &pb.WatchEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, Resume: true, StartSequence: 4},
+			[]string{"c2"}, "5", []uint64{4, 5}},
+		{"Resume at the end of the log", &pb.WatchEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, Resume: true, StartSequence: 6},
+			[]string{"c1"}, "6", []uint64{6}},
+		{"Resume from the start of the log", &pb.WatchEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, Resume: true},
+			[]string{"c2"}, "7", []uint64{0, 1, 2, 3, 4, 5, 6, 7}},
+		{"Container", &pb.WatchEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, ContainerId: "c2", Snapshot: true},
+			[]string{"c1", "c2"}, "8", []uint64{1, 3, 5, 7, 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watchCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			stream, err := client.WatchEventlog(watchCtx, tt.in)
+			if err != nil {
+				t.Fatalf("Err -> \nWant: stream\nGot: %q\n", err)
+			}
+			header, err := stream.Header()
+			if err != nil || len(header.Get(resources.EVENTLOG_WATCH_SEQUENCE_KEY)) != 1 ||
+				header.Get(resources.EVENTLOG_WATCH_SEQUENCE_KEY)[0] != tt.wantStart {
+				t.Fatalf("Header -> \nWant: %s\nGot: %v, %v\n", tt.wantStart, header, err)
+			}
+
+			for _, containerId := range tt.record {
+				record(containerId)
+			}
+			sequences := receiveWatchedSequences(t, stream, len(tt.wantSequence))
+			if !reflect.DeepEqual(sequences, tt.wantSequence) {
+				t.Errorf("Sequences -> \nWant: %v\nGot: %v\n", tt.wantSequence, sequences)
+			}
+		})
+	}
+
+	invalid := map[string]struct {
+		in  *pb.WatchEventlogRequest
+		err string
+	}{
+		"Invalid_Eventlog_Level":    {&pb.WatchEventlogRequest{EventlogLevel: INVALID_EVENTLOG_LEVEL}, "Invalid Request"},
+		"Invalid_Eventlog_Category": {&pb.WatchEventlogRequest{EventlogCategory: INVALID_EVENTLOG_CATEGORY}, "Invalid Request"},
+		"Sequence_Beyond_The_Log":   {&pb.WatchEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, Resume: true, StartSequence: 100}, resources.InvalidWatchSequenceErr.Error()},
+		"Sequence_Without_Resume":   {&pb.WatchEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, StartSequence: 1}, "Invalid Request"},
+	}
+	for scenario, tt := range invalid {
+		t.Run(scenario, func(t *testing.T) {
+			stream, err := client.WatchEventlog(ctx, tt.in)
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			if err == nil || err.Error() != "rpc error: code = Unknown desc = "+tt.err {
+				t.Errorf("Err -> \nWant: %q\nGot: %v\n", tt.err, err)
+			}
+		})
+	}
+}
+
+/* The IMA event log is read again at the poll interval */
+func TestEventlogServerWatchImaEventlog(t *testing.T) {
+	defer resources.SetLocations(resources.DefaultLocations())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	initTestServer(ctx)
+
+	location := filepath.Join(t.TempDir(), "ascii_runtime_measurements")
+	measurements := []string{
+		"10 91f34b5c671d73504b274a919661cf80dab1e127 ima-ng sha1:1801e1be3e65ef1eaa5c16617bec8f1274eaf6b3 boot_aggregate\n",
+		"10 8b1683287f61f96e5448f40bdef6df32be86486a ima-ng sha1:6a4a3b3bb0a8a2c5d8a0a3f37e7a1f0b6c1a3d2e /usr/lib/systemd/systemd\n",
+		"11 d3c1f3a2b4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9 ima-ng sha1:5b0d5d4b7b3c1b1d6c0d5e0a9b3f2e1d00112233 /etc/hosts\n",
+	}
+	if err := os.WriteFile(location, []byte(measurements[0]+measurements[1]), 0600); err != nil {
+		t.Fatalf("Failed to write IMA eventlog: %v", err)
+	}
+	resources.SetLocations(resources.Locations{ImaAsciiEventlog: location})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(
+		func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("failed to connect to server: %v", err)
+		return
+	}
+	defer conn.Close()
+	client := pb.NewEventlogClient(conn)
+
+	stream, err := client.WatchEventlog(ctx, &pb.WatchEventlogRequest{EventlogCategory: pb.CATEGORY_IMA_EVENTLOG, Resume: true, StartSequence: 1})
+	if err != nil {
+		t.Fatalf("Err -> \nWant: stream\nGot: %q\n", err)
+	}
+	if sequences := receiveWatchedSequences(t, stream, 1); sequences[0] != 1 {
+		t.Fatalf("Sequences -> \nWant: [1]\nGot: %v\n", sequences)
+	}
+
+	if err := os.WriteFile(location, []byte(measurements[0]+measurements[1]+measurements[2]), 0600); err != nil {
+		t.Fatalf("Failed to write IMA eventlog: %v", err)
+	}
+	reply, err := stream.Recv()
+	if err != nil || reply.Sequence != 2 || reply.Entry.RegisterIndex != 11 || reply.Entry.ImaEntry.FileName != "/etc/hosts" {
+		t.Fatalf("Recv() = %v, %v want the appended measurement", reply, err)
+	}
+
+	/* a replaced event log ends the watch */
+	if err := os.WriteFile(location, []byte(measurements[0]), 0600); err != nil {
+		t.Fatalf("Failed to write IMA eventlog: %v", err)
+	}
+	if _, err := stream.Recv(); err == nil || err.Error() != "rpc error: code = Unknown desc = "+resources.InvalidWatchSequenceErr.Error() {
+		t.Errorf("Err -> \nWant: %q\nGot: %v\n", resources.InvalidWatchSequenceErr, err)
+	}
+}
+
+func TestEventlogServerPagination(t *testing.T) {
+	ctx := context.Background()
+	initTestServer(ctx)
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(
+		func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("failed to connect to server: %v", err)
+		return
+	}
+	defer conn.Close()
+	client := pb.NewEventlogClient(conn)
+
+	runtimeClient := dialContainerRuntime(t, ctx)
+	for i := 0; i < 5; i++ {
+		_, err := runtimeClient.RecordContainerEvent(ctx, &pb.RecordContainerEventRequest{ContainerId: "c1", EventType: pb.CONTAINER_EVENT_TYPE_CONTAINER_MOUNT})
+		if err != nil {
+			t.Fatalf("RecordContainerEvent() = %v", err)
+		}
+	}
+
+	/* walk the log with pages of 2 events through the page token */
+	var sequences []uint32
+	request := &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, ContainerId: "c1", Count: 2}
+	for pages := 0; ; pages++ {
+		out, err := client.GetEventlog(ctx, request)
+		if err != nil || out.TotalCount != 5 || out.StartPosition != int32(len(sequences)) {
+			t.Fatalf("GetEventlog(page %d) = %v, %v want page from %d of 5 events", pages, out, err, len(sequences))
+		}
+
+		data, _ := os.ReadFile(out.EventlogDataLoc)
+		eventlogs, _ := resources.UnmarshalContainerEventlogs(data)
+		if len(eventlogs.EventLogs) != int(out.Count) {
+			t.Fatalf("GetEventlog(page %d) returned %d events, reply count %d", pages, len(eventlogs.EventLogs), out.Count)
+		}
+		for _, eventlog := range eventlogs.EventLogs {
+			sequences = append(sequences, eventlog.Sequence)
+		}
+
+		if out.NextPageToken == "" {
+			break
+		}
+		request.PageToken = out.NextPageToken
+	}
+	if len(sequences) != 5 || sequences[0] != 0 || sequences[4] != 4 {
+		t.Fatalf("GetEventlog(all pages) = %v want 5 events in order", sequences)
+	}
+
+	/* the page of the stream is returned in the header metadata */
+	stream, err := client.GetEventlogStream(ctx, &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, ContainerId: "c1", StartPosition: 4, Count: 2})
+	if err != nil {
+		t.Fatalf("Err -> \nWant: stream\nGot: %q\n", err)
+	}
+	header, err := stream.Header()
+	if err != nil || header.Get(resources.EVENTLOG_TOTAL_COUNT_KEY)[0] != "5" || header.Get(resources.EVENTLOG_START_POSITION_KEY)[0] != "4" ||
+		header.Get(resources.EVENTLOG_COUNT_KEY)[0] != "1" || header.Get(resources.EVENTLOG_NEXT_PAGE_TOKEN_KEY)[0] != "" {
+		t.Errorf("GetEventlogStream() header = %v, %v want last page of 1 event", header, err)
+	}
+
+	/* a page token is only valid for the request it was returned for */
+	out, _ := client.GetEventlog(ctx, &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, ContainerId: "c1", Count: 2})
+	_, err = client.GetEventlog(ctx, &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, ContainerId: "c2", Count: 2, PageToken: out.NextPageToken})
+	if err == nil || err.Error() != "rpc error: code = Unknown desc = Invalid page token" {
+		t.Errorf("Err -> \nWant: %q\nGot: %v\n", resources.InvalidPageTokenErr, err)
+	}
+
+	_, err = client.GetEventlog(ctx, &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, ContainerId: "c1", StartPosition: 6})
+	if err == nil || err.Error() != "rpc error: code = Unknown desc = "+resources.InvalidEventlogRangeErr.Error() {
+		t.Errorf("Err -> \nWant: %q\nGot: %v\n", resources.InvalidEventlogRangeErr, err)
+	}
+}
+
+func TestEventlogServerCelFormat(t *testing.T) {
+	ctx := context.Background()
+	initTestServer(ctx)
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(
+		func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("failed to connect to server: %v", err)
+		return
+	}
+	defer conn.Close()
+	client := pb.NewEventlogClient(conn)
+
+	/* CEL covers the TDX, TPM and IMA event logs only */
+	_, err = client.GetEventlog(ctx, &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, EventlogFormat: pb.FORMAT_CEL_JSON})
+	if err == nil || err.Error() != "rpc error: code = Unknown desc = "+InvalidRequestErr.Error() {
+		t.Errorf("Err -> \nWant: %q\nGot: %v\n", InvalidRequestErr, err)
+	}
+
+	stream, err := client.GetEventlogStream(ctx, &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_PAAS, EventlogFormat: pb.FORMAT_CEL_CBOR})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if err == nil || err.Error() != "rpc error: code = Unknown desc = "+InvalidRequestErr.Error() {
+		t.Errorf("Err -> \nWant: %q\nGot: %v\n", InvalidRequestErr, err)
+	}
+}
+
+func TestEventlogServerRawFormat(t *testing.T) {
+	ctx := context.Background()
+	initTestServer(ctx)
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(
+		func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("failed to connect to server: %v", err)
+		return
+	}
+	defer conn.Close()
+	client := pb.NewEventlogClient(conn)
+
+	/* the binary event log is returned in the reply, byte for byte */
+	defer resources.SetLocations(resources.DefaultLocations())
+	location := writeTpmEventlog(t, resources.TDEventLog{Rtmr: 0, Etype: resources.EVENT_TYPE_EV_EFI_PLATFORM_FIRMWARE_BLOB,
+		Digests: []resources.TDEventLogDigest{{AlgorithmId: resources.TPM_ALG_SHA256, Digest: make([]byte, 32)}}, Event: make([]byte, 16)})
+	resources.SetLocations(resources.Locations{TpmEventlog: location})
+	expected, _ := os.ReadFile(location)
+
+	out, err := client.GetEventlog(ctx, &pb.GetEventlogRequest{EventlogCategory: pb.CATEGORY_TPM_EVENTLOG, EventlogFormat: pb.FORMAT_RAW})
+	if err != nil || !bytes.Equal(out.EventlogData, expected) || out.EventlogDataLoc != "" || out.Count != 1 {
+		t.Errorf("GetEventlog() in RAW format = %d bytes at %q, %v want the %d bytes of the event log", len(out.GetEventlogData()),
+			out.GetEventlogDataLoc(), err, len(expected))
+	}
+
+	/* the binary event log is only kept for the TDX and TPM event logs, with all digests */
+	tests := []struct {
+		name string
+		req  *pb.GetEventlogRequest
+	}{
+		{"Container event log", &pb.GetEventlogRequest{EventlogLevel: pb.LEVEL_SAAS, EventlogFormat: pb.FORMAT_RAW}},
+		{"IMA event log", &pb.GetEventlogRequest{EventlogCategory: pb.CATEGORY_IMA_EVENTLOG, EventlogFormat: pb.FORMAT_RAW}},
+		{"Algorithm filter", &pb.GetEventlogRequest{AlgorithmId: resources.TPM_ALG_SHA384, EventlogFormat: pb.FORMAT_RAW}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetEventlog(ctx, tt.req)
+			if err == nil || err.Error() != "rpc error: code = Unknown desc = "+InvalidRequestErr.Error() {
+				t.Errorf("Err -> \nWant: %q\nGot: %v\n", InvalidRequestErr, err)
+			}
+		})
+	}
+}
+
+func TestMarshalCelEventlogs(t *testing.T) {
+	eventlogs := []resources.TDEventLog{
+		{Rtmr: 1, Etype: resources.EVENT_TYPE_EV_IPL, Digests: []resources.TDEventLogDigest{{AlgorithmId: resources.TPM_ALG_SHA384, Digest: make([]byte, 48)}},
+			Event: []byte("grub")},
+	}
+
+	for _, format := range []pb.FORMAT{pb.FORMAT_CEL_JSON, pb.FORMAT_CEL_CBOR, pb.FORMAT_CEL_TLV} {
+		eventlog, err := marshalCelEventlogs(resources.GetCelRecords(eventlogs, 5), format)
+		if err != nil {
+			t.Fatalf("marshalCelEventlogs(%s) returned error: %v", format, err)
+		}
+
+		records, err := resources.UnmarshalCel([]byte(eventlog), int(format))
+		if err != nil || len(records) != 1 || records[0].RecNum != 5 || string(records[0].EventData) != "grub" {
+			t.Errorf("marshalCelEventlogs(%s) = %v, %v want record 5", format, records, err)
+		}
+	}
+
+	if _, err := marshalCelEventlogs(nil, pb.FORMAT_DEFAULT); err != resources.UnsupportedCelFormatErr {
+		t.Errorf("Err -> Want: %v, Got: %v", resources.UnsupportedCelFormatErr, err)
+	}
+}
+
+func TestGetEventlogFilter(t *testing.T) {
+	filter := getEventlogFilter(&pb.GetEventlogRequest{})
+	if !filter.IsEmpty() {
+		t.Fatalf("getEventlogFilter(empty request) = %+v want empty filter", filter)
+	}
+
+	filter = getEventlogFilter(&pb.GetEventlogRequest{
+		RegisterIndexes: []uint32{1, 2},
+		EventTypes:      []uint32{resources.EVENT_TYPE_EV_EFI_VARIABLE_AUTHORITY},
+		EventTypeRange:  &pb.EventTypeRange{Min: resources.EVENT_TYPE_EV_EFI_EVENT_BASE, Max: resources.EVENT_TYPE_EV_EFI_HCRTM_EVENT},
+		AlgorithmId:     uint32(resources.TPM_ALG_SHA384),
+	})
+	if len(filter.RegisterIndexes) != 2 || len(filter.EventTypes) != 1 || filter.EventTypeRange == nil ||
+		filter.EventTypeRange.Min != 0x80000000 || filter.EventTypeRange.Max != 0x80000010 || filter.AlgorithmId != 0xc {
+		t.Fatalf("getEventlogFilter(request) = %+v want all criteria", filter)
+	}
+}
+
+func TestGetContainerEventlogEntry(t *testing.T) {
+	eventlog := resources.ContainerEventLog{
+		Sequence:    3,
+		ContainerId: "c1",
+		PodId:       "default/nginx",
+		EventType:   resources.CONTAINER_EVENT_MOUNT,
+		Timestamp:   time.Unix(1700000000, 5),
+		AlgorithmId: resources.TPM_ALG_SHA384,
+		Digest:      []byte{0xa, 0xb},
+		Event:       []byte("/data:ro"),
+	}
+
+	entry := getContainerEventlogEntry(eventlog)
+	if entry.EventType != uint32(pb.CONTAINER_EVENT_TYPE_CONTAINER_MOUNT) || len(entry.Digests) != 1 ||
+		entry.Digests[0].AlgorithmId != 0xc || entry.EventSize != 8 {
+		t.Fatalf("getContainerEventlogEntry(eventlog) = %v want mount event with SHA384 digest", entry)
+	}
+
+	containerEntry := entry.ContainerEntry
+	if containerEntry.Sequence != 3 || containerEntry.ContainerId != "c1" || containerEntry.PodId != "default/nginx" ||
+		containerEntry.Timestamp != 1700000000000000005 {
+		t.Fatalf("getContainerEventlogEntry(eventlog) = %v want container entry", containerEntry)
+	}
+}
+
+func TestGetEventDigests(t *testing.T) {
+	eventlog := resources.TDEventLog{
+		DigestCount: 2,
+		Digests: []resources.TDEventLogDigest{
+			{AlgorithmId: resources.TPM_ALG_SHA1, Digest: []byte{0xa, 0xb}},
+			{AlgorithmId: resources.TPM_ALG_SHA384, Digest: []byte{0x1, 0x2, 0x3}},
+		},
+	}
+
+	digests := getEventDigests(eventlog)
+	if len(digests) != 2 || digests[0].AlgorithmId != 0x4 || !bytes.Equal(digests[0].Digest, []byte{0xa, 0xb}) ||
+		digests[1].AlgorithmId != 0xc || !bytes.Equal(digests[1].Digest, []byte{0x1, 0x2, 0x3}) {
+		t.Fatalf("getEventDigests(eventlog) = %v want SHA1 and SHA384 digests", digests)
+	}
+}
+
+func TestGetImaEventlogEntry(t *testing.T) {
+	eventlog := resources.ImaEventLog{
+		PcrIndex:            10,
+		RtmrIndex:           2,
+		TemplateHash:        []byte{0xa, 0xb},
+		TemplateName:        resources.IMA_TEMPLATE_IMA_SIG,
+		FileDigestAlgorithm: "sha256",
+		FileDigest:          []byte{0x1, 0x2},
+		FileName:            "/usr/bin/ls",
+		Signature:           []byte{0x3, 0x2},
+		TemplateData:        []byte{0x1, 0x2, 0x3},
+	}
+
+	entry := getImaEventlogEntry(eventlog)
+	if entry.RegisterIndex != 10 || len(entry.Digests) != 1 || entry.Digests[0].AlgorithmId != 0x4 ||
+		!bytes.Equal(entry.Digests[0].Digest, []byte{0xa, 0xb}) || entry.EventSize != 3 {
+		t.Fatalf("getImaEventlogEntry(eventlog) = %v want PCR 10 entry with SHA1 template hash", entry)
+	}
+
+	imaEntry := entry.ImaEntry
+	if imaEntry.RtmrIndex != 2 || imaEntry.TemplateName != "ima-sig" || imaEntry.FileName != "/usr/bin/ls" ||
+		!bytes.Equal(imaEntry.Signature, []byte{0x3, 0x2}) {
+		t.Fatalf("getImaEventlogEntry(eventlog) = %v want IMA entry", imaEntry)
+	}
+}
+
+func TestWriteEventlogFile(t *testing.T) {
+	dir := t.TempDir()
+
+	location1, digest1, err := writeEventlogFile(dir, "eventlog1")
+	if err != nil {
+		t.Fatalf("writeEventlogFile(dir, eventlog1) = %v want nil", err)
+	}
+
+	location2, digest2, err := writeEventlogFile(dir, "eventlog2")
+	if err != nil {
+		t.Fatalf("writeEventlogFile(dir, eventlog2) = %v want nil", err)
+	}
+
+	if location1 == location2 || digest1 == digest2 {
+		t.Fatalf("writeEventlogFile() returns %s, %s for different requests want unique locations", location1, location2)
+	}
+
+	data, err := os.ReadFile(location1)
+	digest := sha256.Sum256(data)
+	if err != nil || string(data) != "eventlog1" || hex.EncodeToString(digest[:]) != digest1 {
+		t.Fatalf("os.ReadFile(%s) = %s, %v want eventlog1 with digest %s", location1, data, err, digest1)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("writeEventlogFile() leaves %d files want 2", len(entries))
+	}
+}
+
+func TestCleanupEventlogFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	expired, _, _ := writeEventlogFile(dir, "expired")
+	valid, _, _ := writeEventlogFile(dir, "valid")
+	other := filepath.Join(dir, "other.log")
+	_ = os.WriteFile(other, []byte("other"), 0644)
+
+	past := time.Now().Add(-2 * EVENTLOG_FILE_LIFETIME)
+	_ = os.Chtimes(expired, past, past)
+	_ = os.Chtimes(other, past, past)
+
+	cleanupEventlogFiles(dir, EVENTLOG_FILE_LIFETIME)
+
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Fatalf("cleanupEventlogFiles() keeps expired file %s", expired)
+	}
+	if _, err := os.Stat(valid); err != nil {
+		t.Fatalf("cleanupEventlogFiles() removes valid file %s", valid)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("cleanupEventlogFiles() removes file %s not owned by the server", other)
+	}
+}
+
+/* TPM event log with a SHA1 format Spec ID event and crypto agile events with a SHA256 bank */
+func writeTpmEventlog(t *testing.T, events ...resources.TDEventLog) string {
+	var buf bytes.Buffer
+
+	specId := []byte(resources.TPM_SPEC_ID_EVENT_SIGNATURE)
+	specId = binary.LittleEndian.AppendUint32(specId, 0)
+	specId = append(specId, 0, 2, 0, 2)
+	specId = binary.LittleEndian.AppendUint32(specId, 1)
+	specId = binary.LittleEndian.AppendUint16(specId, resources.TPM_ALG_SHA256)
+	specId = binary.LittleEndian.AppendUint16(specId, 32)
+	specId = append(specId, 0)
+
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(resources.EVENT_TYPE_EV_NO_ACTION))
+	buf.Write(make([]byte, resources.TPM_SHA1_DIGEST_SIZE))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(specId)))
+	buf.Write(specId)
+
+	for _, event := range events {
+		_ = binary.Write(&buf, binary.LittleEndian, event.Rtmr)
+		_ = binary.Write(&buf, binary.LittleEndian, event.Etype)
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(1))
+		_ = binary.Write(&buf, binary.LittleEndian, uint16(resources.TPM_ALG_SHA256))
+		buf.Write(event.Digests[0].Digest)
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(event.Event)))
+		buf.Write(event.Event)
+	}
+
+	location := filepath.Join(t.TempDir(), "binary_bios_measurements")
+	if err := os.WriteFile(location, buf.Bytes(), 0600); err != nil {
+		t.Fatalf("Failed to write TPM eventlog: %v", err)
+	}
+	return location
+}
+
+func TestEventlogServerEvaluatePolicy(t *testing.T) {
+	defer resources.SetLocations(resources.DefaultLocations())
+
+	digest := bytes.Repeat([]byte{0x1}, 32)
+	cmdlineDigest := sha256.Sum256([]byte("/vmlinuz console=hvc0"))
+	resources.SetLocations(resources.Locations{TpmEventlog: writeTpmEventlog(t,
+		resources.TDEventLog{Rtmr: 0, Etype: resources.EVENT_TYPE_EV_EFI_PLATFORM_FIRMWARE_BLOB,
+			Digests: []resources.TDEventLogDigest{{AlgorithmId: resources.TPM_ALG_SHA256, Digest: digest}}, Event: make([]byte, 16)},
+		resources.TDEventLog{Rtmr: 8, Etype: resources.EVENT_TYPE_EV_IPL,
+			Digests: []resources.TDEventLogDigest{{AlgorithmId: resources.TPM_ALG_SHA256, Digest: cmdlineDigest[:]}},
+			Event:   []byte(policy.KERNEL_CMDLINE_PREFIX + "/vmlinuz console=hvc0\x00")},
+	)})
+
+	referencePolicy := "digests:\n  - {register_index: 0, event_type: EV_EFI_PLATFORM_FIRMWARE_BLOB, algorithm: SHA256, digests: ['" +
+		hex.EncodeToString(digest) + "']}\nkernel_cmdlines: ['/vmlinuz console=hvc0']\n"
+	configuredPolicy, err := policy.Load([]byte(`{"kernel_cmdlines": ["/vmlinuz console=ttyS0"]}`))
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	containerStore, err := resources.NewContainerEventStore("", func([]byte, []byte) error { return nil })
+	if err != nil {
+		t.Fatalf("NewContainerEventStore returned error: %v", err)
+	}
+	s := newServer(config.Default(), containerStore)
+
+	tests := []struct {
+		name       string
+		policy     *policy.Policy
+		req        *pb.EvaluatePolicyRequest
+		passed     bool
+		violations []policy.Violation
+		err        error
+	}{
+		{"Request policy", nil, &pb.EvaluatePolicyRequest{EventlogCategory: pb.CATEGORY_TPM_EVENTLOG, Policy: referencePolicy}, true, nil, nil},
+		{"Request policy over configured policy", &configuredPolicy,
+			&pb.EvaluatePolicyRequest{EventlogCategory: pb.CATEGORY_TPM_EVENTLOG, Policy: referencePolicy}, true, nil, nil},
+		{"Configured policy", &configuredPolicy, &pb.EvaluatePolicyRequest{EventlogCategory: pb.CATEGORY_TPM_EVENTLOG}, false,
+			[]policy.Violation{{EventIndex: 1, RegisterIndex: 8, EventType: "EV_IPL", Measured: "/vmlinuz console=hvc0",
+				Reason: "Kernel command line not allowed"}}, nil},
+		{"Without policy", nil, &pb.EvaluatePolicyRequest{EventlogCategory: pb.CATEGORY_TPM_EVENTLOG}, false, nil, MissingPolicyErr},
+		{"Invalid policy", nil, &pb.EvaluatePolicyRequest{EventlogCategory: pb.CATEGORY_TPM_EVENTLOG, Policy: "kernel_cmdline: []"},
+			false, nil, policy.InvalidPolicyErr},
+		{"IMA event log", nil, &pb.EvaluatePolicyRequest{EventlogCategory: pb.CATEGORY_IMA_EVENTLOG, Policy: referencePolicy},
+			false, nil, InvalidRequestErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.policy = tt.policy
+			reply, err := s.EvaluatePolicy(context.Background(), tt.req)
+			if pkgerrors.Cause(err) != tt.err {
+				t.Fatalf("Err -> \nWant: %v\nGot: %v\n", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			var result policy.Result
+			if err := json.Unmarshal([]byte(reply.Result), &result); err != nil {
+				t.Fatalf("Failed to decode result %q: %v", reply.Result, err)
+			}
+
+			var violations []policy.Violation
+			for _, check := range result.Checks {
+				violations = append(violations, check.Violations...)
+			}
+			if reply.Passed != tt.passed || result.Passed != tt.passed || !reflect.DeepEqual(violations, tt.violations) {
+				t.Errorf("EvaluatePolicy() -> \nWant: %v %+v\nGot: %v %+v\n", tt.passed, tt.violations, reply.Passed, result)
+			}
+		})
+	}
+}
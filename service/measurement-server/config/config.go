@@ -0,0 +1,194 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	resources "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/resources"
+	pkgerrors "github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SOCKET_LOCATION = "/run/ccnp/uds/measurement.sock"
+	// RTMR 3 is only extended on the extend socket, in a directory no workload mounts
+	EXTEND_SOCKET_LOCATION = "/run/ccnp/runtime/measurement-extend.sock"
+	// The ccnp user the eventlog server runs as
+	EXTEND_UID = "1000"
+
+	// The configuration file is given with the -config flag or the environment variable
+	CONFIG_FILE_ENV = "CCNP_MEASUREMENT_CONFIG"
+	// Every setting can be given with the environment variable CCNP_MEASUREMENT_<FLAG NAME>
+	ENV_PREFIX = "CCNP_MEASUREMENT_"
+)
+
+var (
+	InvalidConfigErr = pkgerrors.New("Invalid configuration")
+)
+
+/*
+Config holds the settings of the measurement server. The settings are read from the
+defaults, the optional YAML file, the environment variables and the flags, a later
+source overriding an earlier one.
+*/
+type Config struct {
+	Socket       string `yaml:"socket"`
+	ExtendSocket string `yaml:"extend_socket"`
+	ExtendUid    string `yaml:"extend_uid"`
+
+	TdxDeprecatedDevice string `yaml:"tdx_deprecated_device"`
+	Tdx10Device         string `yaml:"tdx_1_0_device"`
+	Tdx15Device         string `yaml:"tdx_1_5_device"`
+	SevGuestDevice      string `yaml:"sev_guest_device"`
+	SevDevice           string `yaml:"sev_device"`
+	TpmDevice           string `yaml:"tpm_device"`
+	TpmRawDevice        string `yaml:"tpm_raw_device"`
+
+	// The persistent handle of the TPM attestation key in hexadecimal, empty for a key derived for every quote
+	TpmAkHandle string `yaml:"tpm_ak_handle"`
+	// The runtime event log the RTMR extends are recorded in, read by the eventlog server
+	RuntimeEventlog string `yaml:"runtime_eventlog"`
+}
+
+type option struct {
+	// the flag name, the YAML key and the environment variable use the same name
+	name  string
+	usage string
+	str   *string
+}
+
+func Default() Config {
+	nodes := resources.DefaultDeviceNodes()
+	return Config{
+		Socket:              SOCKET_LOCATION,
+		ExtendSocket:        EXTEND_SOCKET_LOCATION,
+		ExtendUid:           EXTEND_UID,
+		TdxDeprecatedDevice: nodes.TdxDeprecated,
+		Tdx10Device:         nodes.Tdx10,
+		Tdx15Device:         nodes.Tdx15,
+		SevGuestDevice:      nodes.SevGuest,
+		SevDevice:           nodes.Sev,
+		TpmDevice:           nodes.Tpm,
+		TpmRawDevice:        nodes.TpmRaw,
+		RuntimeEventlog:     resources.RUNTIME_EVENT_LOG_LOCATION,
+	}
+}
+
+func (c *Config) options() []option {
+	return []option{
+		{name: "socket", usage: "UDS path the server listens on", str: &c.Socket},
+		{name: "extend-socket", usage: "UDS path the eventlog server extends RTMR 3 on, RTMR 3 is not extended " +
+			"if empty", str: &c.ExtendSocket},
+		{name: "extend-uid", usage: "user id of the eventlog server, the only peer allowed on the extend socket",
+			str: &c.ExtendUid},
+		{name: "tdx-deprecated-device", usage: "deprecated TDX device node, refused if present", str: &c.TdxDeprecatedDevice},
+		{name: "tdx-1-0-device", usage: "TDX 1.0 device node", str: &c.Tdx10Device},
+		{name: "tdx-1-5-device", usage: "TDX 1.5 device node", str: &c.Tdx15Device},
+		{name: "sev-guest-device", usage: "AMD SEV guest device node", str: &c.SevGuestDevice},
+		{name: "sev-device", usage: "AMD SEV device node", str: &c.SevDevice},
+		{name: "tpm-device", usage: "TPM resource manager device node", str: &c.TpmDevice},
+		{name: "tpm-raw-device", usage: "raw TPM device node, used without the TPM resource manager", str: &c.TpmRawDevice},
+		{name: "tpm-ak-handle", usage: "persistent handle of the TPM attestation key, created if missing", str: &c.TpmAkHandle},
+		{name: "runtime-eventlog", usage: "runtime event log of the RTMR extends, empty to refuse extends", str: &c.RuntimeEventlog},
+	}
+}
+
+func getEnvName(name string) string {
+	return ENV_PREFIX + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Load reads the configuration, args are the command line arguments without the program name.
+func Load(name string, args []string) (Config, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv(CONFIG_FILE_ENV),
+		"optional YAML configuration file, also given with "+CONFIG_FILE_ENV)
+
+	/* the flags are parsed first to find the configuration file, and applied last */
+	defaults := Default()
+	for _, o := range defaults.options() {
+		flags.StringVar(o.str, o.name, *o.str, o.usage+", also given with "+getEnvName(o.name))
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+	if flags.NArg() > 0 {
+		return Config{}, pkgerrors.Wrapf(InvalidConfigErr, "unexpected arguments %v", flags.Args())
+	}
+
+	config := Default()
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return Config{}, err
+		}
+
+		/* unknown keys are rejected, a misspelt path would otherwise be silently ignored */
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&config); err != nil && err != io.EOF {
+			return Config{}, pkgerrors.Wrapf(InvalidConfigErr, "%s: %v", *configFile, err)
+		}
+	}
+
+	options := config.options()
+	for _, o := range options {
+		if value, ok := os.LookupEnv(getEnvName(o.name)); ok {
+			*o.str = value
+		}
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		for _, o := range options {
+			if o.name == f.Name {
+				*o.str = f.Value.String()
+			}
+		}
+	})
+
+	if config.Socket == "" {
+		return Config{}, pkgerrors.Wrap(InvalidConfigErr, "socket must not be empty")
+	}
+	if _, err := strconv.ParseUint(config.ExtendUid, 10, 32); err != nil {
+		return Config{}, pkgerrors.Wrapf(InvalidConfigErr, "extend-uid is not a user id: %q", config.ExtendUid)
+	}
+	if config.TpmAkHandle != "" {
+		handle, err := strconv.ParseUint(config.TpmAkHandle, 0, 32)
+		if err != nil || !resources.IsTpmPersistentHandle(uint32(handle)) {
+			return Config{}, pkgerrors.Wrapf(InvalidConfigErr, "tpm-ak-handle is not a persistent handle: %q", config.TpmAkHandle)
+		}
+	}
+	return config, nil
+}
+
+// ExtendPeerUid returns the user id allowed on the extend socket, validated on load.
+func (c Config) ExtendPeerUid() uint32 {
+	uid, _ := strconv.ParseUint(c.ExtendUid, 10, 32)
+	return uint32(uid)
+}
+
+// AkHandle returns the persistent handle of the TPM attestation key, 0 if not configured.
+func (c Config) AkHandle() uint32 {
+	handle, _ := strconv.ParseUint(c.TpmAkHandle, 0, 32)
+	return uint32(handle)
+}
+
+func (c Config) DeviceNodes() resources.DeviceNodes {
+	return resources.DeviceNodes{
+		TdxDeprecated: c.TdxDeprecatedDevice,
+		Tdx10:         c.Tdx10Device,
+		Tdx15:         c.Tdx15Device,
+		SevGuest:      c.SevGuestDevice,
+		Sev:           c.SevDevice,
+		Tpm:           c.TpmDevice,
+		TpmRaw:        c.TpmRawDevice,
+	}
+}
@@ -0,0 +1,108 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func writeConfigFile(t *testing.T, data string) string {
+	location := filepath.Join(t.TempDir(), "measurement-server.yaml")
+	if err := os.WriteFile(location, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return location
+}
+
+func TestLoad(t *testing.T) {
+	configFile := writeConfigFile(t, "socket: /tmp/yaml.sock\ntdx_1_5_device: /tmp/tdx_guest\n")
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want func(*Config)
+	}{
+		{"Defaults", nil, nil, func(c *Config) {}},
+		{"Flags", []string{"-socket", "/tmp/flag.sock", "-tpm-device", "/tmp/tpmrm0", "-tpm-raw-device", "/tmp/tpm0"}, nil,
+			func(c *Config) { c.Socket, c.TpmDevice, c.TpmRawDevice = "/tmp/flag.sock", "/tmp/tpmrm0", "/tmp/tpm0" }},
+		{"Environment", nil, map[string]string{"CCNP_MEASUREMENT_SEV_GUEST_DEVICE": "/tmp/sev-guest", "CCNP_MEASUREMENT_SEV_DEVICE": ""},
+			func(c *Config) { c.SevGuestDevice, c.SevDevice = "/tmp/sev-guest", "" }},
+		{"Config file", []string{"-config", configFile}, nil,
+			func(c *Config) { c.Socket, c.Tdx15Device = "/tmp/yaml.sock", "/tmp/tdx_guest" }},
+		{"Config file from environment", nil, map[string]string{CONFIG_FILE_ENV: configFile},
+			func(c *Config) { c.Socket, c.Tdx15Device = "/tmp/yaml.sock", "/tmp/tdx_guest" }},
+		{"Environment overrides config file", []string{"-config", configFile},
+			map[string]string{"CCNP_MEASUREMENT_SOCKET": "/tmp/env.sock"},
+			func(c *Config) { c.Socket, c.Tdx15Device = "/tmp/env.sock", "/tmp/tdx_guest" }},
+		{"TPM attestation key handle", []string{"-tpm-ak-handle", "0x81010002"}, nil,
+			func(c *Config) { c.TpmAkHandle = "0x81010002" }},
+		{"Runtime eventlog", nil, map[string]string{"CCNP_MEASUREMENT_RUNTIME_EVENTLOG": "/tmp/runtime_eventlog"},
+			func(c *Config) { c.RuntimeEventlog = "/tmp/runtime_eventlog" }},
+		{"Extend socket", []string{"-extend-socket", "/tmp/extend.sock", "-extend-uid", "1001"}, nil,
+			func(c *Config) { c.ExtendSocket, c.ExtendUid = "/tmp/extend.sock", "1001" }},
+		{"Flags override environment", []string{"-config", configFile, "-socket=/tmp/flag.sock"},
+			map[string]string{"CCNP_MEASUREMENT_SOCKET": "/tmp/env.sock"},
+			func(c *Config) { c.Socket, c.Tdx15Device = "/tmp/flag.sock", "/tmp/tdx_guest" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			got, err := Load("measurement-server", tt.args)
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+			want := Default()
+			tt.want(&want)
+			if got != want {
+				t.Errorf("Config -> Want: %+v, Got: %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestAkHandle(t *testing.T) {
+	if handle := Default().AkHandle(); handle != 0 {
+		t.Errorf("AkHandle() -> Want: 0, Got: 0x%x", handle)
+	}
+
+	cfg, err := Load("measurement-server", []string{"-tpm-ak-handle", "0x81010002"})
+	if err != nil || cfg.AkHandle() != 0x81010002 {
+		t.Errorf("AkHandle() -> Want: 0x81010002, Got: 0x%x, %v", cfg.AkHandle(), err)
+	}
+}
+
+func TestLoadInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"Unknown key", []string{"-config", writeConfigFile(t, "tpm_devcie: /tmp/tpm0\n")}},
+		{"Invalid YAML", []string{"-config", writeConfigFile(t, "socket: [\n")}},
+		{"Empty socket", []string{"-socket="}},
+		{"Unexpected argument", []string{"/tmp/measurement.sock"}},
+		{"Invalid TPM attestation key handle", []string{"-tpm-ak-handle", "ak"}},
+		{"Transient TPM attestation key handle", []string{"-tpm-ak-handle", "0x80000000"}},
+		{"Invalid extend uid", []string{"-extend-uid", "eventlog"}},
+		{"Empty extend uid", []string{"-extend-uid="}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load("measurement-server", tt.args); pkgerrors.Cause(err) != InvalidConfigErr {
+				t.Errorf("Err -> Want: %v, Got: %v", InvalidConfigErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package peercred
+
+import (
+	"context"
+	"log"
+	"net"
+	"syscall"
+
+	pkgerrors "github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	AUTH_TYPE = "peercred"
+)
+
+var (
+	UnauthorizedPeerErr = pkgerrors.New("Peer refused by its credentials")
+)
+
+/*
+Credentials accepts the connections of a single user on a unix socket. The user id of the
+peer is read with SO_PEERCRED when the connection is accepted, other peers are refused.
+*/
+type Credentials struct {
+	uid uint32
+}
+
+/* AuthInfo carries the user id of the peer to the handlers of the connection */
+type AuthInfo struct {
+	credentials.CommonAuthInfo
+	Uid uint32
+}
+
+func NewCredentials(uid uint32) Credentials {
+	return Credentials{uid: uid}
+}
+
+func (AuthInfo) AuthType() string {
+	return AUTH_TYPE
+}
+
+func (c Credentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, nil, UnauthorizedPeerErr
+	}
+
+	rawConn, err := unixConn.SyscallConn()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ucred *syscall.Ucred
+	var ucredErr error
+	err = rawConn.Control(func(fd uintptr) {
+		ucred, ucredErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err == nil {
+		err = ucredErr
+	}
+	if err != nil {
+		log.Println("Error getting the credentials of the peer")
+		return nil, nil, err
+	}
+
+	if ucred.Uid != c.uid {
+		log.Printf("Refused peer with uid %d pid %d on %s", ucred.Uid, ucred.Pid, conn.LocalAddr())
+		return nil, nil, UnauthorizedPeerErr
+	}
+
+	return conn, AuthInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity},
+		Uid: ucred.Uid}, nil
+}
+
+func (Credentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, UnauthorizedPeerErr
+}
+
+func (Credentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: AUTH_TYPE}
+}
+
+func (c Credentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (Credentials) OverrideServerName(string) error {
+	return nil
+}
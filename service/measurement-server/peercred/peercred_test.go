@@ -0,0 +1,61 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package peercred
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCredentialsServerHandshake(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "peercred.sock")
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer lis.Close()
+
+	uid := uint32(os.Getuid())
+	tests := []struct {
+		name string
+		uid  uint32
+		err  error
+	}{
+		{"Accepted user", uid, nil},
+		{"Other user", uid + 1, UnauthorizedPeerErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := net.Dial("unix", socket)
+			if err != nil {
+				t.Fatalf("failed to connect: %v", err)
+			}
+			defer client.Close()
+			conn, err := lis.Accept()
+			if err != nil {
+				t.Fatalf("failed to accept: %v", err)
+			}
+			defer conn.Close()
+
+			_, authInfo, err := NewCredentials(tt.uid).ServerHandshake(conn)
+			if err != tt.err {
+				t.Fatalf("ServerHandshake() = %v want %v", err, tt.err)
+			}
+			if err == nil && authInfo.(AuthInfo).Uid != uid {
+				t.Errorf("ServerHandshake() uid = %d want %d", authInfo.(AuthInfo).Uid, uid)
+			}
+		})
+	}
+}
+
+func TestCredentialsClientHandshake(t *testing.T) {
+	if _, _, err := NewCredentials(0).ClientHandshake(nil, "", nil); err != UnauthorizedPeerErr {
+		t.Errorf("ClientHandshake() = %v want %v", err, UnauthorizedPeerErr)
+	}
+}
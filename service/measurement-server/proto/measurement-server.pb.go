@@ -73,11 +73,115 @@ func (CATEGORY) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_52ee6f800ca253e4, []int{1}
 }
 
+type TEE int32
+
+const (
+	TEE_TDX     TEE = 0
+	TEE_SEV_SNP TEE = 1
+)
+
+var TEE_name = map[int32]string{
+	0: "TDX",
+	1: "SEV_SNP",
+}
+
+var TEE_value = map[string]int32{
+	"TDX":     0,
+	"SEV_SNP": 1,
+}
+
+func (x TEE) String() string {
+	return proto.EnumName(TEE_name, int32(x))
+}
+
+func (TEE) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_52ee6f800ca253e4, []int{2}
+}
+
+type PCR_BANK int32
+
+const (
+	PCR_BANK_SHA256 PCR_BANK = 0
+	PCR_BANK_SHA1   PCR_BANK = 1
+	PCR_BANK_SHA384 PCR_BANK = 2
+)
+
+var PCR_BANK_name = map[int32]string{
+	0: "SHA256",
+	1: "SHA1",
+	2: "SHA384",
+}
+
+var PCR_BANK_value = map[string]int32{
+	"SHA256": 0,
+	"SHA1":   1,
+	"SHA384": 2,
+}
+
+func (x PCR_BANK) String() string {
+	return proto.EnumName(PCR_BANK_name, int32(x))
+}
+
+func (PCR_BANK) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_52ee6f800ca253e4, []int{3}
+}
+
+type PcrValue struct {
+	Index                int32    `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Digest               []byte   `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PcrValue) Reset()         { *m = PcrValue{} }
+func (m *PcrValue) String() string { return proto.CompactTextString(m) }
+func (*PcrValue) ProtoMessage()    {}
+func (*PcrValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_52ee6f800ca253e4, []int{0}
+}
+
+func (m *PcrValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PcrValue.Unmarshal(m, b)
+}
+func (m *PcrValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PcrValue.Marshal(b, m, deterministic)
+}
+func (m *PcrValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PcrValue.Merge(m, src)
+}
+func (m *PcrValue) XXX_Size() int {
+	return xxx_messageInfo_PcrValue.Size(m)
+}
+func (m *PcrValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_PcrValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PcrValue proto.InternalMessageInfo
+
+func (m *PcrValue) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *PcrValue) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
 type GetMeasurementRequest struct {
 	MeasurementType      TYPE     `protobuf:"varint,1,opt,name=measurement_type,json=measurementType,proto3,enum=measurement.TYPE" json:"measurement_type,omitempty"`
 	MeasurementCategory  CATEGORY `protobuf:"varint,2,opt,name=measurement_category,json=measurementCategory,proto3,enum=measurement.CATEGORY" json:"measurement_category,omitempty"`
 	ReportData           string   `protobuf:"bytes,3,opt,name=report_data,json=reportData,proto3" json:"report_data,omitempty"`
 	RegisterIndex        int32    `protobuf:"varint,4,opt,name=register_index,json=registerIndex,proto3" json:"register_index,omitempty"`
+	Vmpl                 uint32   `protobuf:"varint,5,opt,name=vmpl,proto3" json:"vmpl,omitempty"`
+	ExtendedReport       bool     `protobuf:"varint,6,opt,name=extended_report,json=extendedReport,proto3" json:"extended_report,omitempty"`
+	PcrBank              PCR_BANK `protobuf:"varint,7,opt,name=pcr_bank,json=pcrBank,proto3,enum=measurement.PCR_BANK" json:"pcr_bank,omitempty"`
+	PcrIndexes           []int32  `protobuf:"varint,8,rep,packed,name=pcr_indexes,json=pcrIndexes,proto3" json:"pcr_indexes,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -87,7 +191,7 @@ func (m *GetMeasurementRequest) Reset()         { *m = GetMeasurementRequest{} }
 func (m *GetMeasurementRequest) String() string { return proto.CompactTextString(m) }
 func (*GetMeasurementRequest) ProtoMessage()    {}
 func (*GetMeasurementRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_52ee6f800ca253e4, []int{0}
+	return fileDescriptor_52ee6f800ca253e4, []int{1}
 }
 
 func (m *GetMeasurementRequest) XXX_Unmarshal(b []byte) error {
@@ -136,18 +240,49 @@ func (m *GetMeasurementRequest) GetRegisterIndex() int32 {
 	return 0
 }
 
+func (m *GetMeasurementRequest) GetVmpl() uint32 {
+	if m != nil {
+		return m.Vmpl
+	}
+	return 0
+}
+
+func (m *GetMeasurementRequest) GetExtendedReport() bool {
+	if m != nil {
+		return m.ExtendedReport
+	}
+	return false
+}
+
+func (m *GetMeasurementRequest) GetPcrBank() PCR_BANK {
+	if m != nil {
+		return m.PcrBank
+	}
+	return PCR_BANK_SHA256
+}
+
+func (m *GetMeasurementRequest) GetPcrIndexes() []int32 {
+	if m != nil {
+		return m.PcrIndexes
+	}
+	return nil
+}
+
 type GetMeasurementReply struct {
-	Measurement          string   `protobuf:"bytes,1,opt,name=measurement,proto3" json:"measurement,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Measurement          string      `protobuf:"bytes,1,opt,name=measurement,proto3" json:"measurement,omitempty"`
+	TeeType              TEE         `protobuf:"varint,2,opt,name=tee_type,json=teeType,proto3,enum=measurement.TEE" json:"tee_type,omitempty"`
+	CertTable            []byte      `protobuf:"bytes,3,opt,name=cert_table,json=certTable,proto3" json:"cert_table,omitempty"`
+	PcrValues            []*PcrValue `protobuf:"bytes,4,rep,name=pcr_values,json=pcrValues,proto3" json:"pcr_values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
 }
 
 func (m *GetMeasurementReply) Reset()         { *m = GetMeasurementReply{} }
 func (m *GetMeasurementReply) String() string { return proto.CompactTextString(m) }
 func (*GetMeasurementReply) ProtoMessage()    {}
 func (*GetMeasurementReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_52ee6f800ca253e4, []int{1}
+	return fileDescriptor_52ee6f800ca253e4, []int{2}
 }
 
 func (m *GetMeasurementReply) XXX_Unmarshal(b []byte) error {
@@ -175,39 +310,272 @@ func (m *GetMeasurementReply) GetMeasurement() string {
 	return ""
 }
 
+func (m *GetMeasurementReply) GetTeeType() TEE {
+	if m != nil {
+		return m.TeeType
+	}
+	return TEE_TDX
+}
+
+func (m *GetMeasurementReply) GetCertTable() []byte {
+	if m != nil {
+		return m.CertTable
+	}
+	return nil
+}
+
+func (m *GetMeasurementReply) GetPcrValues() []*PcrValue {
+	if m != nil {
+		return m.PcrValues
+	}
+	return nil
+}
+
+type GetTpmQuoteRequest struct {
+	PcrBank              PCR_BANK `protobuf:"varint,1,opt,name=pcr_bank,json=pcrBank,proto3,enum=measurement.PCR_BANK" json:"pcr_bank,omitempty"`
+	PcrIndexes           []int32  `protobuf:"varint,2,rep,packed,name=pcr_indexes,json=pcrIndexes,proto3" json:"pcr_indexes,omitempty"`
+	QualifyingData       []byte   `protobuf:"bytes,3,opt,name=qualifying_data,json=qualifyingData,proto3" json:"qualifying_data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTpmQuoteRequest) Reset()         { *m = GetTpmQuoteRequest{} }
+func (m *GetTpmQuoteRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTpmQuoteRequest) ProtoMessage()    {}
+func (*GetTpmQuoteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_52ee6f800ca253e4, []int{3}
+}
+
+func (m *GetTpmQuoteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTpmQuoteRequest.Unmarshal(m, b)
+}
+func (m *GetTpmQuoteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTpmQuoteRequest.Marshal(b, m, deterministic)
+}
+func (m *GetTpmQuoteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTpmQuoteRequest.Merge(m, src)
+}
+func (m *GetTpmQuoteRequest) XXX_Size() int {
+	return xxx_messageInfo_GetTpmQuoteRequest.Size(m)
+}
+func (m *GetTpmQuoteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTpmQuoteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTpmQuoteRequest proto.InternalMessageInfo
+
+func (m *GetTpmQuoteRequest) GetPcrBank() PCR_BANK {
+	if m != nil {
+		return m.PcrBank
+	}
+	return PCR_BANK_SHA256
+}
+
+func (m *GetTpmQuoteRequest) GetPcrIndexes() []int32 {
+	if m != nil {
+		return m.PcrIndexes
+	}
+	return nil
+}
+
+func (m *GetTpmQuoteRequest) GetQualifyingData() []byte {
+	if m != nil {
+		return m.QualifyingData
+	}
+	return nil
+}
+
+type GetTpmQuoteReply struct {
+	Quote                string   `protobuf:"bytes,1,opt,name=quote,proto3" json:"quote,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTpmQuoteReply) Reset()         { *m = GetTpmQuoteReply{} }
+func (m *GetTpmQuoteReply) String() string { return proto.CompactTextString(m) }
+func (*GetTpmQuoteReply) ProtoMessage()    {}
+func (*GetTpmQuoteReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_52ee6f800ca253e4, []int{4}
+}
+
+func (m *GetTpmQuoteReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTpmQuoteReply.Unmarshal(m, b)
+}
+func (m *GetTpmQuoteReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTpmQuoteReply.Marshal(b, m, deterministic)
+}
+func (m *GetTpmQuoteReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTpmQuoteReply.Merge(m, src)
+}
+func (m *GetTpmQuoteReply) XXX_Size() int {
+	return xxx_messageInfo_GetTpmQuoteReply.Size(m)
+}
+func (m *GetTpmQuoteReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTpmQuoteReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTpmQuoteReply proto.InternalMessageInfo
+
+func (m *GetTpmQuoteReply) GetQuote() string {
+	if m != nil {
+		return m.Quote
+	}
+	return ""
+}
+
+type ExtendMeasurementRequest struct {
+	RegisterIndex        int32    `protobuf:"varint,1,opt,name=register_index,json=registerIndex,proto3" json:"register_index,omitempty"`
+	Digest               []byte   `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	Event                []byte   `protobuf:"bytes,3,opt,name=event,proto3" json:"event,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExtendMeasurementRequest) Reset()         { *m = ExtendMeasurementRequest{} }
+func (m *ExtendMeasurementRequest) String() string { return proto.CompactTextString(m) }
+func (*ExtendMeasurementRequest) ProtoMessage()    {}
+func (*ExtendMeasurementRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_52ee6f800ca253e4, []int{5}
+}
+
+func (m *ExtendMeasurementRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExtendMeasurementRequest.Unmarshal(m, b)
+}
+func (m *ExtendMeasurementRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExtendMeasurementRequest.Marshal(b, m, deterministic)
+}
+func (m *ExtendMeasurementRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExtendMeasurementRequest.Merge(m, src)
+}
+func (m *ExtendMeasurementRequest) XXX_Size() int {
+	return xxx_messageInfo_ExtendMeasurementRequest.Size(m)
+}
+func (m *ExtendMeasurementRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExtendMeasurementRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExtendMeasurementRequest proto.InternalMessageInfo
+
+func (m *ExtendMeasurementRequest) GetRegisterIndex() int32 {
+	if m != nil {
+		return m.RegisterIndex
+	}
+	return 0
+}
+
+func (m *ExtendMeasurementRequest) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
+func (m *ExtendMeasurementRequest) GetEvent() []byte {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+type ExtendMeasurementReply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExtendMeasurementReply) Reset()         { *m = ExtendMeasurementReply{} }
+func (m *ExtendMeasurementReply) String() string { return proto.CompactTextString(m) }
+func (*ExtendMeasurementReply) ProtoMessage()    {}
+func (*ExtendMeasurementReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_52ee6f800ca253e4, []int{6}
+}
+
+func (m *ExtendMeasurementReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExtendMeasurementReply.Unmarshal(m, b)
+}
+func (m *ExtendMeasurementReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExtendMeasurementReply.Marshal(b, m, deterministic)
+}
+func (m *ExtendMeasurementReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExtendMeasurementReply.Merge(m, src)
+}
+func (m *ExtendMeasurementReply) XXX_Size() int {
+	return xxx_messageInfo_ExtendMeasurementReply.Size(m)
+}
+func (m *ExtendMeasurementReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExtendMeasurementReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExtendMeasurementReply proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterEnum("measurement.TYPE", TYPE_name, TYPE_value)
 	proto.RegisterEnum("measurement.CATEGORY", CATEGORY_name, CATEGORY_value)
+	proto.RegisterEnum("measurement.TEE", TEE_name, TEE_value)
+	proto.RegisterEnum("measurement.PCR_BANK", PCR_BANK_name, PCR_BANK_value)
+	proto.RegisterType((*PcrValue)(nil), "measurement.PcrValue")
 	proto.RegisterType((*GetMeasurementRequest)(nil), "measurement.GetMeasurementRequest")
 	proto.RegisterType((*GetMeasurementReply)(nil), "measurement.GetMeasurementReply")
+	proto.RegisterType((*GetTpmQuoteRequest)(nil), "measurement.GetTpmQuoteRequest")
+	proto.RegisterType((*GetTpmQuoteReply)(nil), "measurement.GetTpmQuoteReply")
+	proto.RegisterType((*ExtendMeasurementRequest)(nil), "measurement.ExtendMeasurementRequest")
+	proto.RegisterType((*ExtendMeasurementReply)(nil), "measurement.ExtendMeasurementReply")
 }
 
-func init() { proto.RegisterFile("proto/measurement-server.proto", fileDescriptor_52ee6f800ca253e4) }
+func init() {
+	proto.RegisterFile("proto/measurement-server.proto", fileDescriptor_52ee6f800ca253e4)
+}
 
 var fileDescriptor_52ee6f800ca253e4 = []byte{
-	// 379 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x92, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0x86, 0xe3, 0x36, 0x40, 0x3a, 0x01, 0x63, 0xb6, 0x54, 0xb2, 0x7a, 0x00, 0xcb, 0x12, 0x52,
-	0x54, 0xc9, 0xb6, 0x28, 0x07, 0x2e, 0x5c, 0x42, 0x6b, 0x15, 0x0e, 0x51, 0xad, 0xed, 0x1e, 0x12,
-	0x2e, 0xd6, 0xc6, 0x1e, 0xcc, 0x4a, 0xfe, 0x62, 0xbd, 0x8e, 0xf0, 0x2f, 0xe6, 0x6f, 0x20, 0x3b,
-	0x44, 0xac, 0x51, 0xd4, 0xdb, 0xe8, 0x99, 0x8f, 0x9d, 0x77, 0xf6, 0x85, 0x37, 0xb5, 0xac, 0x54,
-	0x15, 0x14, 0xc8, 0x9b, 0x56, 0x62, 0x81, 0xa5, 0xf2, 0x1a, 0x94, 0x3b, 0x94, 0xfe, 0x90, 0x20,
-	0x73, 0x2d, 0xe3, 0xfe, 0x36, 0xe0, 0xe2, 0x0e, 0xd5, 0xea, 0x1f, 0xa2, 0xf8, 0xb3, 0xc5, 0x46,
-	0x91, 0x4f, 0x60, 0x69, 0x85, 0xb1, 0xea, 0x6a, 0xb4, 0x0d, 0xc7, 0x58, 0x98, 0xd7, 0xaf, 0x7c,
-	0x2d, 0xe1, 0xb3, 0x4d, 0x14, 0xd2, 0x97, 0x1a, 0x61, 0x5d, 0x8d, 0xe4, 0x0b, 0xbc, 0xd6, 0xbb,
-	0x13, 0xae, 0x30, 0xab, 0x64, 0x67, 0x9f, 0x0c, 0x13, 0x2e, 0x46, 0x13, 0x6e, 0x96, 0x2c, 0xbc,
-	0xbb, 0xa7, 0x1b, 0x7a, 0xae, 0xd1, 0x9b, 0xbf, 0x1d, 0xe4, 0x2d, 0xcc, 0x25, 0xd6, 0x95, 0x54,
-	0x71, 0xca, 0x15, 0xb7, 0x4f, 0x1d, 0x63, 0x71, 0x46, 0x61, 0x8f, 0x6e, 0xb9, 0xe2, 0xe4, 0x1d,
-	0x98, 0x12, 0x33, 0xd1, 0x28, 0x94, 0xb1, 0x28, 0x53, 0xfc, 0x65, 0x4f, 0x1d, 0x63, 0xf1, 0x84,
-	0xbe, 0x38, 0xd0, 0xaf, 0x3d, 0x74, 0x3f, 0xc2, 0xf9, 0xff, 0x42, 0xeb, 0xbc, 0x23, 0x0e, 0xe8,
-	0xf7, 0x18, 0x14, 0x9e, 0x51, 0x1d, 0x5d, 0x5d, 0xc2, 0xb4, 0xd7, 0x48, 0x66, 0x30, 0x8d, 0x96,
-	0xcb, 0x07, 0x6b, 0xd2, 0x47, 0x0f, 0x7d, 0x64, 0x5c, 0xbd, 0x87, 0xd9, 0x61, 0x7b, 0x62, 0x02,
-	0xb0, 0x30, 0x8c, 0x69, 0x18, 0xdd, 0x53, 0x66, 0x4d, 0xc8, 0x33, 0x38, 0x65, 0xd1, 0xca, 0x32,
-	0xc8, 0x73, 0x98, 0xb1, 0xdb, 0x75, 0x4c, 0xd9, 0x8a, 0x5a, 0x27, 0xd7, 0x19, 0xcc, 0xb5, 0x25,
-	0xc8, 0x1a, 0xcc, 0xf1, 0x5a, 0xc4, 0x1d, 0x1d, 0xe7, 0xe8, 0xe7, 0x5c, 0x3a, 0x8f, 0xd6, 0xd4,
-	0x79, 0xe7, 0x4e, 0x3e, 0x67, 0xdf, 0x30, 0x13, 0xea, 0x47, 0xbb, 0xf5, 0x93, 0xaa, 0x08, 0x44,
-	0xa9, 0x30, 0x0f, 0x92, 0xaa, 0xfc, 0x2e, 0x52, 0x2c, 0x95, 0xe0, 0xb9, 0x97, 0xe4, 0x55, 0x9b,
-	0x7a, 0x25, 0x57, 0x62, 0x87, 0x5e, 0x2d, 0x45, 0x21, 0xfa, 0xa8, 0x09, 0x7a, 0xb7, 0x88, 0x04,
-	0x8f, 0x38, 0x28, 0xd8, 0x5b, 0x2b, 0x1b, 0xbd, 0xb7, 0x7d, 0x3a, 0xd0, 0x0f, 0x7f, 0x02, 0x00,
-	0x00, 0xff, 0xff, 0x72, 0x3f, 0x43, 0x37, 0x79, 0x02, 0x00, 0x00,
+	// 739 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0x4f, 0x4f, 0xfb, 0x46,
+	0x10, 0x8d, 0xf3, 0xd7, 0x99, 0x84, 0x60, 0x96, 0x3f, 0xb2, 0x52, 0x51, 0x2c, 0x57, 0xa8, 0x56,
+	0xda, 0x24, 0x25, 0xd0, 0x8a, 0x43, 0x2f, 0x01, 0x2c, 0xa8, 0x2a, 0x20, 0xdd, 0x58, 0x08, 0x7a,
+	0xb1, 0x1c, 0x67, 0x48, 0x2d, 0x1c, 0xdb, 0xd8, 0x9b, 0x88, 0x7c, 0x8b, 0xde, 0xfb, 0x49, 0xfa,
+	0xc9, 0x7a, 0xad, 0xd6, 0x76, 0x84, 0x13, 0x42, 0x2b, 0xfd, 0x6e, 0x33, 0x6f, 0x77, 0x67, 0x66,
+	0xdf, 0x7b, 0xbb, 0xf0, 0x75, 0x10, 0xfa, 0xcc, 0xef, 0x4e, 0xd1, 0x8a, 0x66, 0x21, 0x4e, 0xd1,
+	0x63, 0xed, 0x08, 0xc3, 0x39, 0x86, 0x9d, 0x78, 0x81, 0xd4, 0x32, 0x2b, 0xea, 0x39, 0x88, 0x03,
+	0x3b, 0x7c, 0xb0, 0xdc, 0x19, 0x92, 0x3d, 0x28, 0x39, 0xde, 0x18, 0xdf, 0x64, 0x41, 0x11, 0xb4,
+	0x12, 0x4d, 0x12, 0x72, 0x00, 0xe5, 0xb1, 0x33, 0xc1, 0x88, 0xc9, 0x79, 0x45, 0xd0, 0xea, 0x34,
+	0xcd, 0xd4, 0x7f, 0xf2, 0xb0, 0x7f, 0x8d, 0xec, 0xf6, 0xbd, 0x18, 0xc5, 0xd7, 0x19, 0x46, 0x8c,
+	0xfc, 0x0c, 0x52, 0xa6, 0x85, 0xc9, 0x16, 0x01, 0xc6, 0x25, 0x1b, 0xbd, 0x9d, 0x4e, 0x66, 0xa1,
+	0x63, 0x3c, 0x0d, 0x74, 0xba, 0x9d, 0x41, 0x8c, 0x45, 0x80, 0xe4, 0x06, 0xf6, 0xb2, 0xa7, 0x6d,
+	0x8b, 0xe1, 0xc4, 0x0f, 0x17, 0x71, 0xf7, 0x46, 0x6f, 0x7f, 0xa5, 0xc2, 0x65, 0xdf, 0xd0, 0xaf,
+	0xef, 0xe9, 0x13, 0xdd, 0xcd, 0xa0, 0x97, 0xe9, 0x09, 0x72, 0x04, 0xb5, 0x10, 0x03, 0x3f, 0x64,
+	0xe6, 0xd8, 0x62, 0x96, 0x5c, 0x50, 0x04, 0xad, 0x4a, 0x21, 0x81, 0xae, 0x2c, 0x66, 0x91, 0x63,
+	0x68, 0x84, 0x38, 0x71, 0x22, 0x86, 0xa1, 0x99, 0xdc, 0xbc, 0x18, 0xdf, 0x7c, 0x6b, 0x89, 0xfe,
+	0x12, 0x33, 0x40, 0xa0, 0x38, 0x9f, 0x06, 0xae, 0x5c, 0x52, 0x04, 0x6d, 0x8b, 0xc6, 0x31, 0xf9,
+	0x16, 0xb6, 0xf1, 0x8d, 0xa1, 0x37, 0xc6, 0xb1, 0x99, 0x54, 0x94, 0xcb, 0x8a, 0xa0, 0x89, 0xb4,
+	0xb1, 0x84, 0x69, 0x8c, 0x92, 0x1f, 0x40, 0x0c, 0xec, 0xd0, 0x1c, 0x59, 0xde, 0x8b, 0x5c, 0xd9,
+	0x70, 0x85, 0xc1, 0x25, 0x35, 0x2f, 0xfa, 0x77, 0xbf, 0xd2, 0x4a, 0x60, 0x87, 0x17, 0x96, 0xf7,
+	0xc2, 0xc7, 0x0e, 0xec, 0x74, 0x20, 0x8c, 0x64, 0x51, 0x29, 0x68, 0x25, 0x0a, 0x81, 0x9d, 0x4c,
+	0x83, 0x91, 0xfa, 0xb7, 0x00, 0xbb, 0xeb, 0xcc, 0x07, 0xee, 0x82, 0x28, 0x90, 0x95, 0x36, 0xa6,
+	0xbc, 0x4a, 0xb3, 0x10, 0xf9, 0x0e, 0x44, 0x86, 0x98, 0x28, 0x92, 0xf0, 0x29, 0xad, 0x2a, 0xa2,
+	0xeb, 0xb4, 0xc2, 0x10, 0x63, 0x21, 0x0e, 0x01, 0x6c, 0x0c, 0x99, 0xc9, 0xac, 0x91, 0x8b, 0x31,
+	0x7b, 0x75, 0x5a, 0xe5, 0x88, 0xc1, 0x01, 0x72, 0x06, 0x7c, 0x26, 0x73, 0xce, 0xad, 0x13, 0xc9,
+	0x45, 0xa5, 0xa0, 0xd5, 0xd6, 0xaf, 0x96, 0x1a, 0x8b, 0x56, 0x83, 0x34, 0x8a, 0xd4, 0x3f, 0x05,
+	0x20, 0xd7, 0xc8, 0x8c, 0x60, 0xfa, 0xdb, 0xcc, 0x67, 0xb8, 0xb4, 0x4c, 0x96, 0x25, 0xe1, 0x4b,
+	0x58, 0xca, 0xaf, 0xb3, 0xc4, 0x15, 0x7a, 0x9d, 0x59, 0xae, 0xf3, 0xbc, 0x70, 0xbc, 0xc9, 0xbb,
+	0x03, 0xea, 0xb4, 0xf1, 0x0e, 0x73, 0x17, 0xa8, 0x1a, 0x48, 0x2b, 0x13, 0x71, 0x2a, 0xf7, 0xa0,
+	0xf4, 0xca, 0xb3, 0x94, 0xc4, 0x24, 0x51, 0x7d, 0x90, 0xf5, 0x58, 0xdd, 0x0d, 0xa6, 0xff, 0xe8,
+	0x25, 0x61, 0x93, 0x97, 0x3e, 0x79, 0x4d, 0xbc, 0x21, 0xce, 0xb9, 0x6a, 0xc9, 0x8c, 0x49, 0xa2,
+	0xca, 0x70, 0xb0, 0xa1, 0x61, 0xe0, 0x2e, 0x5a, 0x4d, 0x28, 0xf2, 0xe7, 0x43, 0x44, 0x28, 0x0e,
+	0xfa, 0xfd, 0xa1, 0x94, 0xe3, 0xd1, 0x90, 0x47, 0x42, 0xeb, 0x04, 0xc4, 0xe5, 0xc3, 0x20, 0x0d,
+	0x00, 0x43, 0xd7, 0x4d, 0xaa, 0x0f, 0xee, 0xa9, 0x21, 0xe5, 0x48, 0x05, 0x0a, 0xc6, 0xe0, 0x56,
+	0x12, 0x48, 0x1d, 0x44, 0xe3, 0xea, 0xd1, 0xa4, 0xc6, 0x2d, 0x95, 0xf2, 0xad, 0xaf, 0xa0, 0x60,
+	0xe8, 0x7a, 0xbc, 0x7a, 0xf5, 0x28, 0xe5, 0x48, 0x0d, 0x2a, 0x43, 0xfd, 0xc1, 0x1c, 0xde, 0x0d,
+	0x24, 0xa1, 0xf5, 0x3d, 0x88, 0x4b, 0xfe, 0x09, 0x40, 0x79, 0x78, 0xd3, 0xef, 0xfd, 0xf8, 0x53,
+	0xda, 0xf1, 0xa6, 0x7f, 0x22, 0x09, 0x29, 0x7a, 0x7a, 0x7e, 0x26, 0xe5, 0x7b, 0x7f, 0xe5, 0xa1,
+	0x96, 0x19, 0x97, 0x3c, 0x42, 0x63, 0xd5, 0xac, 0x44, 0x5d, 0x91, 0x76, 0xe3, 0x1f, 0xd2, 0x54,
+	0xfe, 0x73, 0x4f, 0xe0, 0x2e, 0xd4, 0x1c, 0xb9, 0x87, 0x5a, 0x46, 0x38, 0x72, 0xb4, 0x7e, 0x64,
+	0xcd, 0x64, 0xcd, 0xc3, 0xcf, 0x37, 0x24, 0x05, 0x2d, 0xd8, 0xf9, 0x40, 0x37, 0x39, 0x5e, 0x39,
+	0xf5, 0x99, 0xfe, 0xcd, 0x6f, 0xfe, 0x6f, 0x5b, 0xdc, 0xe2, 0x62, 0xf2, 0x3b, 0x4e, 0x1c, 0xf6,
+	0xc7, 0x6c, 0xd4, 0xb1, 0xfd, 0x69, 0xd7, 0xf1, 0x18, 0xba, 0x5d, 0xdb, 0xf7, 0x9e, 0x9d, 0x31,
+	0x7a, 0xcc, 0xb1, 0xdc, 0xb6, 0xed, 0xfa, 0xb3, 0x71, 0xdb, 0xb3, 0x98, 0x33, 0xc7, 0x76, 0x10,
+	0x3a, 0x53, 0x87, 0x47, 0x51, 0x97, 0x7f, 0xe1, 0x8e, 0x8d, 0x1b, 0xbe, 0xf5, 0x6e, 0xf2, 0xdf,
+	0x4f, 0x56, 0x38, 0x1a, 0x95, 0x63, 0xf4, 0xf4, 0xdf, 0x01, 0x00, 0x1e, 0x2b, 0x74, 0xb6, 0x0e,
+	0x06, 0x00, 0x00,
 }
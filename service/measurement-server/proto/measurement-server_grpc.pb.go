@@ -23,6 +23,8 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MeasurementClient interface {
 	GetMeasurement(ctx context.Context, in *GetMeasurementRequest, opts ...grpc.CallOption) (*GetMeasurementReply, error)
+	GetTpmQuote(ctx context.Context, in *GetTpmQuoteRequest, opts ...grpc.CallOption) (*GetTpmQuoteReply, error)
+	ExtendMeasurement(ctx context.Context, in *ExtendMeasurementRequest, opts ...grpc.CallOption) (*ExtendMeasurementReply, error)
 }
 
 type measurementClient struct {
@@ -42,11 +44,31 @@ func (c *measurementClient) GetMeasurement(ctx context.Context, in *GetMeasureme
 	return out, nil
 }
 
+func (c *measurementClient) GetTpmQuote(ctx context.Context, in *GetTpmQuoteRequest, opts ...grpc.CallOption) (*GetTpmQuoteReply, error) {
+	out := new(GetTpmQuoteReply)
+	err := c.cc.Invoke(ctx, "/measurement.Measurement/GetTpmQuote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *measurementClient) ExtendMeasurement(ctx context.Context, in *ExtendMeasurementRequest, opts ...grpc.CallOption) (*ExtendMeasurementReply, error) {
+	out := new(ExtendMeasurementReply)
+	err := c.cc.Invoke(ctx, "/measurement.Measurement/ExtendMeasurement", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MeasurementServer is the server API for Measurement service.
 // All implementations must embed UnimplementedMeasurementServer
 // for forward compatibility
 type MeasurementServer interface {
 	GetMeasurement(context.Context, *GetMeasurementRequest) (*GetMeasurementReply, error)
+	GetTpmQuote(context.Context, *GetTpmQuoteRequest) (*GetTpmQuoteReply, error)
+	ExtendMeasurement(context.Context, *ExtendMeasurementRequest) (*ExtendMeasurementReply, error)
 	mustEmbedUnimplementedMeasurementServer()
 }
 
@@ -57,6 +79,12 @@ type UnimplementedMeasurementServer struct {
 func (UnimplementedMeasurementServer) GetMeasurement(context.Context, *GetMeasurementRequest) (*GetMeasurementReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMeasurement not implemented")
 }
+func (UnimplementedMeasurementServer) GetTpmQuote(context.Context, *GetTpmQuoteRequest) (*GetTpmQuoteReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTpmQuote not implemented")
+}
+func (UnimplementedMeasurementServer) ExtendMeasurement(context.Context, *ExtendMeasurementRequest) (*ExtendMeasurementReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExtendMeasurement not implemented")
+}
 func (UnimplementedMeasurementServer) mustEmbedUnimplementedMeasurementServer() {}
 
 // UnsafeMeasurementServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +116,42 @@ func _Measurement_GetMeasurement_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Measurement_GetTpmQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTpmQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MeasurementServer).GetTpmQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/measurement.Measurement/GetTpmQuote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MeasurementServer).GetTpmQuote(ctx, req.(*GetTpmQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Measurement_ExtendMeasurement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendMeasurementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MeasurementServer).ExtendMeasurement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/measurement.Measurement/ExtendMeasurement",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MeasurementServer).ExtendMeasurement(ctx, req.(*ExtendMeasurementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Measurement_ServiceDesc is the grpc.ServiceDesc for Measurement service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,6 +163,14 @@ var Measurement_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMeasurement",
 			Handler:    _Measurement_GetMeasurement_Handler,
 		},
+		{
+			MethodName: "GetTpmQuote",
+			Handler:    _Measurement_GetTpmQuote_Handler,
+		},
+		{
+			MethodName: "ExtendMeasurement",
+			Handler:    _Measurement_ExtendMeasurement_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/measurement-server.proto",
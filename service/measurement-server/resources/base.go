@@ -25,6 +25,8 @@ type BaseTeeInterface interface {
 
 type BaseTeeResource struct {
 	Type string
+	// VMPL the SEV-SNP report is requested for, not used by TDX
+	Vmpl uint32
 }
 
 func NewBaseTeeResource() BaseTeeResource {
@@ -58,14 +60,15 @@ func (r *BaseTeeResource) GetReport(device string, data string) (string, error)
 	var report string
 	var err error
 
-	if strings.Contains(device, TDX_FLAG) {
+	if IsTdxDevice(device) {
 		tdx := NewTdxResource()
 		report, err = tdx.GetReport(device, data)
 		if err != nil {
 			return "", err
 		}
-	} else if strings.Contains(device, SEV_FLAG) {
+	} else if IsSevDevice(device) {
 		sev := NewSevResource()
+		sev.Vmpl = r.Vmpl
 		report, err = sev.GetReport(device, data)
 		if err != nil {
 			return "", err
@@ -74,3 +77,23 @@ func (r *BaseTeeResource) GetReport(device string, data string) (string, error)
 
 	return report, nil
 }
+
+// IsTdxDevice tells whether the device found by FindDeviceAvailable is a TDX device.
+func IsTdxDevice(device string) bool {
+	return isDeviceOf(device, TDX_FLAG, deviceNodes.Tdx10, deviceNodes.Tdx15)
+}
+
+// IsSevDevice tells whether the device found by FindDeviceAvailable is a SEV device.
+func IsSevDevice(device string) bool {
+	return isDeviceOf(device, SEV_FLAG, deviceNodes.SevGuest, deviceNodes.Sev)
+}
+
+// isDeviceOf tells whether the device is one of the configured nodes or named after the TEE.
+func isDeviceOf(device string, flag string, nodes ...string) bool {
+	for _, node := range nodes {
+		if node != "" && device == node {
+			return true
+		}
+	}
+	return strings.Contains(device, flag)
+}
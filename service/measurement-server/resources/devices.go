@@ -0,0 +1,61 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"os"
+)
+
+/*
+DeviceNodes are the device nodes the measurements are read from. The defaults are the
+nodes created by the guest kernel drivers, the server configuration can point them to
+custom locations. An empty device node is never opened.
+*/
+type DeviceNodes struct {
+	TdxDeprecated string
+	Tdx10         string
+	Tdx15         string
+	SevGuest      string
+	Sev           string
+	Tpm           string
+	TpmRaw        string
+}
+
+var deviceNodes = DefaultDeviceNodes()
+
+func DefaultDeviceNodes() DeviceNodes {
+	return DeviceNodes{
+		TdxDeprecated: DEVICE_NODE_NAME_DEPRECATED,
+		Tdx10:         DEVICE_NODE_NAME_1_0,
+		Tdx15:         DEVICE_NODE_NAME_1_5,
+		SevGuest:      DEVICE_NODE_NAME_1,
+		Sev:           DEVICE_NODE_NAME_2,
+		Tpm:           DEVICE_NODE_NAME_TPM,
+		TpmRaw:        DEVICE_NODE_NAME_TPM_RAW,
+	}
+}
+
+// SetDeviceNodes changes the device nodes, it is called once before serving requests.
+func SetDeviceNodes(nodes DeviceNodes) {
+	deviceNodes = nodes
+}
+
+func GetDeviceNodes() DeviceNodes {
+	return deviceNodes
+}
+
+// findDeviceNode returns the first of the device nodes that exists.
+func findDeviceNode(nodes ...string) (string, error) {
+	for _, node := range nodes {
+		if node == "" {
+			continue
+		}
+		if _, err := os.Stat(node); err == nil {
+			return node, nil
+		}
+	}
+	return "", DeviceNotFoundErr
+}
@@ -0,0 +1,69 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetDeviceNodes(t *testing.T) {
+	defer SetDeviceNodes(DefaultDeviceNodes())
+
+	dir := t.TempDir()
+	for _, name := range []string{"tdx", "sev", "tpmrm", "tpm"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatalf("Failed to create device node: %v", err)
+		}
+	}
+	SetDeviceNodes(DeviceNodes{
+		Tdx10:    filepath.Join(dir, "missing"),
+		Tdx15:    filepath.Join(dir, "tdx"),
+		SevGuest: filepath.Join(dir, "sev"),
+		Tpm:      filepath.Join(dir, "tpmrm"),
+		TpmRaw:   filepath.Join(dir, "tpm"),
+	})
+
+	tdx := NewTdxResource()
+	sev := NewSevResource()
+	tests := []struct {
+		name string
+		find func() (string, error)
+		want string
+	}{
+		{"TDX", tdx.FindDeviceAvailable, filepath.Join(dir, "tdx")},
+		{"SEV", sev.FindDeviceAvailable, filepath.Join(dir, "sev")},
+		{"TPM", findDeviceAvailable, filepath.Join(dir, "tpmrm")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device, err := tt.find()
+			if err != nil || device != tt.want {
+				t.Errorf("FindDeviceAvailable() = %s, %v want %s", device, err, tt.want)
+			}
+		})
+	}
+
+	/* the configured nodes are dispatched to their TEE whatever their names */
+	if !isDeviceOf(filepath.Join(dir, "sev"), TDX_FLAG, GetDeviceNodes().SevGuest) ||
+		isDeviceOf("/dev/sev-guest", TDX_FLAG, GetDeviceNodes().Tdx15) {
+		t.Errorf("isDeviceOf() does not match the configured device nodes")
+	}
+
+	/* the raw TPM device is only used without the resource manager */
+	SetDeviceNodes(DeviceNodes{Tpm: filepath.Join(dir, "missing"), TpmRaw: filepath.Join(dir, "tpm")})
+	if device, err := findDeviceAvailable(); err != nil || device != filepath.Join(dir, "tpm") {
+		t.Errorf("FindDeviceAvailable() = %s, %v want %s", device, err, filepath.Join(dir, "tpm"))
+	}
+
+	/* empty device nodes are never opened */
+	SetDeviceNodes(DeviceNodes{})
+	if _, err := sev.FindDeviceAvailable(); err != DeviceNotFoundErr {
+		t.Errorf("Err -> Want: %v, Got: %v", DeviceNotFoundErr, err)
+	}
+}
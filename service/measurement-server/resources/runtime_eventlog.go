@@ -0,0 +1,306 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"log"
+	"os"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+/*
+The runtime event log records the RTMR extends requested by the workloads, in the format of
+the CCEL event log: a Spec ID event followed by TCG_PCR_EVENT2 events with the SHA384 digest
+extended. The eventlog server serves its events after the events of the CCEL, so that the
+replay of the event log matches the RTMRs.
+*/
+const (
+	// The runtime event log is shared with the eventlog server in the host-only runtime directory,
+	// never mounted by workloads
+	RUNTIME_EVENT_LOG_LOCATION = "/run/ccnp/runtime/runtime_eventlog"
+	// The pending record of the extend in progress is kept next to the runtime event log
+	RUNTIME_EVENT_PENDING_SUFFIX = ".pending"
+
+	// Workloads extend RTMR 2, RTMR 3 anchors the container event log of the eventlog server
+	WORKLOAD_RTMR_INDEX = 2
+
+	// The event types of the Spec ID event and of the runtime events, whose data is a string
+	EVENT_TYPE_EV_NO_ACTION = 0x3
+	EVENT_TYPE_EV_IPL       = 0xd
+
+	// The event describes the measured data, not the data itself
+	MAX_RUNTIME_EVENT_LEN = 4096
+
+	TPM_SHA1_DIGEST_LEN = 20
+)
+
+var InvalidRuntimeEventErr = pkgerrors.New("Runtime event with invalid length.")
+var RuntimeEventlogNotConfiguredErr = pkgerrors.New("Runtime eventlog not configured.")
+var InvalidPendingRuntimeEventErr = pkgerrors.New("Invalid pending runtime event record.")
+
+var runtimeEventlogLocation = RUNTIME_EVENT_LOG_LOCATION
+
+/* The events are appended in the order of the extends, one extend at a time */
+var runtimeEventlogMutex sync.Mutex
+
+// SetRuntimeEventlog sets the location of the runtime event log, it is called once before serving requests.
+func SetRuntimeEventlog(location string) {
+	runtimeEventlogLocation = location
+}
+
+/*
+ExtendRuntimeMeasurement appends the event describing the measured data to the runtime event
+log and extends RTMR 2 or 3 with the SHA384 digest. The event is written and synced before the
+RTMR is extended, and removed again if the extend fails, so that the RTMR never holds an extend
+the event log misses. A record in one write is read whole or ignored by the eventlog server.
+
+A crash of the server between the sync of the event and the extend leaves an event in the log
+whose extend never happened. The pending record written before the event keeps the position of
+the event, the digest and the value of the RTMR before the extend, and is removed once the
+extend returns. RecoverRuntimeEventlog checks the RTMR against a pending record left by a crash
+on the next start.
+*/
+func ExtendRuntimeMeasurement(index int, digest []byte, event []byte) error {
+
+	if runtimeEventlogLocation == "" {
+		return RuntimeEventlogNotConfiguredErr
+	}
+
+	if len(event) > MAX_RUNTIME_EVENT_LEN {
+		return InvalidRuntimeEventErr
+	}
+
+	if !extendableRtmrs[index] {
+		return InvalidRtmrIndexErr
+	}
+
+	if len(digest) != TDX_EXTEND_RTMR_DATA_LEN {
+		return InvalidExtendDataErr
+	}
+
+	r := NewTdxResource()
+	device, err := r.FindDeviceAvailable()
+	if err != nil {
+		return err
+	}
+
+	runtimeEventlogMutex.Lock()
+	defer runtimeEventlogMutex.Unlock()
+
+	deviceNode, err := openTdxGuestDevice(device)
+	if err != nil {
+		return err
+	}
+	defer deviceNode.Close()
+
+	rtmr, err := deviceNode.ReadRtmr(index)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(runtimeEventlogLocation, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		log.Println("Error opening runtime eventlog file", runtimeEventlogLocation)
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	pending := runtimeEventPending{Offset: uint64(info.Size()), Index: uint32(index)}
+	copy(pending.Rtmr[:], rtmr)
+	copy(pending.Digest[:], digest)
+	if err := writeRuntimeEventPending(pending); err != nil {
+		log.Println("Error writing pending runtime event record")
+		return err
+	}
+
+	/* a new event log starts with the Spec ID event */
+	var data []byte
+	if info.Size() == 0 {
+		data = marshalSpecIdEvent()
+	}
+	data = append(data, marshalRuntimeEvent(index, digest, event)...)
+
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = deviceNode.ExtendRtmr(index, digest)
+	}
+	if err != nil {
+		/* the pending record is kept for an event that could not be removed */
+		if removeRuntimeEvent(file, info.Size()) == nil {
+			removeRuntimeEventPending()
+		}
+		return err
+	}
+	removeRuntimeEventPending()
+	return nil
+}
+
+/* The extend in progress, the RTMR is the value before the extend */
+type runtimeEventPending struct {
+	Offset uint64
+	Index  uint32
+	Rtmr   [RTMR_LEN]byte
+	Digest [TDX_EXTEND_RTMR_DATA_LEN]byte
+}
+
+func getRuntimeEventPendingLocation() string {
+	return runtimeEventlogLocation + RUNTIME_EVENT_PENDING_SUFFIX
+}
+
+func writeRuntimeEventPending(pending runtimeEventPending) error {
+	file, err := os.OpenFile(getRuntimeEventPendingLocation(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err = binary.Write(file, binary.LittleEndian, pending); err == nil {
+		err = file.Sync()
+	}
+	return err
+}
+
+func removeRuntimeEventPending() {
+	if err := os.Remove(getRuntimeEventPendingLocation()); err != nil {
+		log.Printf("Error removing pending runtime event record: %v", err)
+	}
+}
+
+/*
+RecoverRuntimeEventlog checks the extend of the pending record left by a crash during
+ExtendRuntimeMeasurement, it is called once before serving requests. The event is removed from
+the runtime event log if the RTMR still holds the value before the extend, and kept if the RTMR
+holds the value after the extend. Otherwise the RTMR was extended by another process as well,
+the event is kept and logged, and the replay of the event log shows whether it was extended.
+*/
+func RecoverRuntimeEventlog() error {
+
+	if runtimeEventlogLocation == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(getRuntimeEventPendingLocation())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pending := runtimeEventPending{}
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &pending); err != nil ||
+		len(data) != binary.Size(pending) || !extendableRtmrs[int(pending.Index)] {
+		log.Println("Invalid pending runtime event record of", len(data), "bytes")
+		return InvalidPendingRuntimeEventErr
+	}
+
+	r := NewTdxResource()
+	device, err := r.FindDeviceAvailable()
+	if err != nil {
+		return err
+	}
+
+	deviceNode, err := openTdxGuestDevice(device)
+	if err != nil {
+		return err
+	}
+	defer deviceNode.Close()
+
+	rtmr, err := deviceNode.ReadRtmr(int(pending.Index))
+	if err != nil {
+		return err
+	}
+
+	extended := sha512.New384()
+	extended.Write(pending.Rtmr[:])
+	extended.Write(pending.Digest[:])
+
+	switch {
+	case bytes.Equal(rtmr, pending.Rtmr[:]):
+		log.Printf("Removing the runtime event at offset %d, RTMR %d was not extended", pending.Offset, pending.Index)
+		file, err := os.OpenFile(runtimeEventlogLocation, os.O_WRONLY, 0644)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		/* the server may have crashed before the event was written */
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() > int64(pending.Offset) {
+			if err := removeRuntimeEvent(file, int64(pending.Offset)); err != nil {
+				return err
+			}
+		}
+	case bytes.Equal(rtmr, extended.Sum(nil)):
+		log.Printf("Keeping the runtime event at offset %d, RTMR %d was extended", pending.Offset, pending.Index)
+	default:
+		log.Printf("RTMR %d changed since the runtime event at offset %d, the event may not be extended",
+			pending.Index, pending.Offset)
+	}
+
+	removeRuntimeEventPending()
+	return nil
+}
+
+/* Remove the event of a failed extend, the log is left with an event the RTMR misses otherwise */
+func removeRuntimeEvent(file *os.File, size int64) error {
+	err := file.Truncate(size)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		log.Printf("Runtime eventlog %s holds an event not extended: %v", file.Name(), err)
+	}
+	return err
+}
+
+/* TCG_PCClientPCREvent with the TCG_EfiSpecIDEvent declaring SHA384 digests */
+func marshalSpecIdEvent() []byte {
+	spec := new(bytes.Buffer)
+	spec.WriteString("Spec ID Event03\x00")
+	/* platform class, spec version 2.0 errata 0 and UINT64 as UINTN */
+	binary.Write(spec, binary.LittleEndian, uint32(0))
+	spec.Write([]byte{0, 2, 0, 2})
+	binary.Write(spec, binary.LittleEndian, uint32(1))
+	binary.Write(spec, binary.LittleEndian, []uint16{TPM_ALG_SHA384, TDX_EXTEND_RTMR_DATA_LEN})
+	spec.WriteByte(0)
+
+	event := new(bytes.Buffer)
+	binary.Write(event, binary.LittleEndian, []uint32{0, EVENT_TYPE_EV_NO_ACTION})
+	event.Write(make([]byte, TPM_SHA1_DIGEST_LEN))
+	binary.Write(event, binary.LittleEndian, uint32(spec.Len()))
+	event.Write(spec.Bytes())
+	return event.Bytes()
+}
+
+/* TCG_PCR_EVENT2 of the RTMR, whose index in the event log is shifted by MRTD at index 0 */
+func marshalRuntimeEvent(index int, digest []byte, data []byte) []byte {
+	event := new(bytes.Buffer)
+	binary.Write(event, binary.LittleEndian, []uint32{uint32(index + 1), EVENT_TYPE_EV_IPL, 1})
+	binary.Write(event, binary.LittleEndian, uint16(TPM_ALG_SHA384))
+	event.Write(digest)
+	binary.Write(event, binary.LittleEndian, uint32(len(data)))
+	event.Write(data)
+	return event.Bytes()
+}
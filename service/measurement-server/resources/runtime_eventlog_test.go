@@ -0,0 +1,259 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/* Simulates the RTMRs extended through the TDX guest driver */
+type fakeTdxGuestDevice struct {
+	rtmrs   [4][]byte
+	extends int
+	err     error
+}
+
+func (d *fakeTdxGuestDevice) ExtendRtmr(index int, data []byte) error {
+	if d.err != nil {
+		return d.err
+	}
+	d.rtmrs[index] = replayRtmr(d.rtmrs[index], data)
+	d.extends += 1
+	return nil
+}
+
+func (d *fakeTdxGuestDevice) ReadRtmr(index int) ([]byte, error) {
+	if d.rtmrs[index] == nil {
+		return make([]byte, RTMR_LEN), nil
+	}
+	return d.rtmrs[index], nil
+}
+
+func (d *fakeTdxGuestDevice) Close() error {
+	return nil
+}
+
+func replayRtmr(rtmr []byte, digest []byte) []byte {
+	if rtmr == nil {
+		rtmr = make([]byte, RTMR_LEN)
+	}
+	h := sha512.New384()
+	h.Write(rtmr)
+	h.Write(digest)
+	return h.Sum(nil)
+}
+
+func useFakeTdxGuestDevice(t *testing.T, device *fakeTdxGuestDevice) string {
+	node := filepath.Join(t.TempDir(), "tdx_guest")
+	if err := os.WriteFile(node, nil, 0600); err != nil {
+		t.Fatalf("Failed to create device node: %v", err)
+	}
+	SetDeviceNodes(DeviceNodes{Tdx15: node})
+	SetTdxGuestDeviceOpener(func(string) (TdxGuestDevice, error) {
+		return device, nil
+	})
+
+	location := filepath.Join(t.TempDir(), "runtime_eventlog")
+	SetRuntimeEventlog(location)
+
+	t.Cleanup(func() {
+		SetDeviceNodes(DefaultDeviceNodes())
+		SetTdxGuestDeviceOpener(OpenTdxGuestDevice)
+		SetRuntimeEventlog(RUNTIME_EVENT_LOG_LOCATION)
+	})
+	return location
+}
+
+/* Replay the events of the runtime event log after its Spec ID event */
+func replayRuntimeEventlog(t *testing.T, data []byte) ([4][]byte, []string) {
+	var rtmrs [4][]byte
+	var events []string
+
+	specSize := binary.LittleEndian.Uint32(data[8+TPM_SHA1_DIGEST_LEN:])
+	if !bytes.HasPrefix(data[12+TPM_SHA1_DIGEST_LEN:], []byte("Spec ID Event03")) {
+		t.Fatalf("Runtime eventlog without Spec ID event")
+	}
+
+	for index := 12 + TPM_SHA1_DIGEST_LEN + int(specSize); index < len(data); {
+		mr := binary.LittleEndian.Uint32(data[index:])
+		etype := binary.LittleEndian.Uint32(data[index+4:])
+		count := binary.LittleEndian.Uint32(data[index+8:])
+		algorithm := binary.LittleEndian.Uint16(data[index+12:])
+		if etype != EVENT_TYPE_EV_IPL || count != 1 || algorithm != TPM_ALG_SHA384 {
+			t.Fatalf("Unexpected runtime event at offset %d", index)
+		}
+
+		digest := data[index+14 : index+14+RTMR_LEN]
+		size := int(binary.LittleEndian.Uint32(data[index+14+RTMR_LEN:]))
+		start := index + 18 + RTMR_LEN
+		rtmrs[mr-1] = replayRtmr(rtmrs[mr-1], digest)
+		events = append(events, string(data[start:start+size]))
+		index = start + size
+	}
+
+	return rtmrs, events
+}
+
+func TestExtendRuntimeMeasurement(t *testing.T) {
+	device := &fakeTdxGuestDevice{}
+	location := useFakeTdxGuestDevice(t, device)
+
+	extends := []struct {
+		index int
+		event string
+	}{
+		{2, "config:/etc/app/config.yaml"},
+		{3, "model:resnet50"},
+		{2, ""},
+	}
+	for i, e := range extends {
+		digest := sha512.Sum384([]byte(e.event))
+		if err := ExtendRuntimeMeasurement(e.index, digest[:], []byte(e.event)); err != nil {
+			t.Fatalf("ExtendRuntimeMeasurement(%d) %d = %v want nil", e.index, i, err)
+		}
+	}
+
+	data, err := os.ReadFile(location)
+	if err != nil {
+		t.Fatalf("Failed to read runtime eventlog: %v", err)
+	}
+	if !bytes.HasPrefix(data, marshalSpecIdEvent()) || bytes.Count(data, []byte("Spec ID Event03")) != 1 {
+		t.Fatalf("Runtime eventlog does not start with one Spec ID event")
+	}
+
+	rtmrs, events := replayRuntimeEventlog(t, data)
+	if len(events) != len(extends) || events[0] != extends[0].event || events[1] != extends[1].event {
+		t.Fatalf("Runtime events = %q want the events of the extends", events)
+	}
+	for i := range rtmrs {
+		if !bytes.Equal(rtmrs[i], device.rtmrs[i]) {
+			t.Errorf("Replayed RTMR %d = %x want %x", i, rtmrs[i], device.rtmrs[i])
+		}
+	}
+
+	/* the event written before a failed extend is removed */
+	device.err = TdxExtendRtmrErr
+	digest := sha512.Sum384([]byte("model:vgg16"))
+	if err := ExtendRuntimeMeasurement(3, digest[:], []byte("model:vgg16")); err != TdxExtendRtmrErr {
+		t.Fatalf("ExtendRuntimeMeasurement() with failed extend = %v want %v", err, TdxExtendRtmrErr)
+	}
+	if after, _ := os.ReadFile(location); !bytes.Equal(after, data) {
+		t.Errorf("Runtime eventlog of %d bytes after a failed extend want %d", len(after), len(data))
+	}
+}
+
+func TestExtendRuntimeMeasurementInvalid(t *testing.T) {
+	device := &fakeTdxGuestDevice{}
+	location := useFakeTdxGuestDevice(t, device)
+	digest := make([]byte, TDX_EXTEND_RTMR_DATA_LEN)
+
+	tests := []struct {
+		name   string
+		index  int
+		digest []byte
+		event  []byte
+		err    error
+	}{
+		{"RTMR 0", 0, digest, nil, InvalidRtmrIndexErr},
+		{"RTMR 1", 1, digest, nil, InvalidRtmrIndexErr},
+		{"RTMR 4", 4, digest, nil, InvalidRtmrIndexErr},
+		{"SHA256 digest", 2, digest[:32], nil, InvalidExtendDataErr},
+		{"Too long event", 3, digest, make([]byte, MAX_RUNTIME_EVENT_LEN+1), InvalidRuntimeEventErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ExtendRuntimeMeasurement(tt.index, tt.digest, tt.event); err != tt.err {
+				t.Errorf("ExtendRuntimeMeasurement() = %v want %v", err, tt.err)
+			}
+		})
+	}
+
+	device.err = TdxExtendRtmrErr
+	if err := ExtendRuntimeMeasurement(2, digest, nil); err != TdxExtendRtmrErr {
+		t.Errorf("ExtendRuntimeMeasurement() with failed extend = %v want %v", err, TdxExtendRtmrErr)
+	}
+
+	/* no event is recorded for an RTMR not extended */
+	if data, _ := os.ReadFile(location); device.extends != 0 || len(data) != 0 {
+		t.Errorf("Runtime eventlog of %d bytes after %d extends want empty", len(data), device.extends)
+	}
+
+	SetRuntimeEventlog("")
+	if err := ExtendRuntimeMeasurement(2, digest, nil); err != RuntimeEventlogNotConfiguredErr {
+		t.Errorf("ExtendRuntimeMeasurement() without runtime eventlog = %v want %v", err, RuntimeEventlogNotConfiguredErr)
+	}
+}
+
+/* A crash between the sync of the event and the extend leaves the event and its pending record */
+func TestRecoverRuntimeEventlog(t *testing.T) {
+	device := &fakeTdxGuestDevice{}
+	location := useFakeTdxGuestDevice(t, device)
+
+	digest := sha512.Sum384([]byte("config:/etc/app/config.yaml"))
+	if err := ExtendRuntimeMeasurement(2, digest[:], []byte("config:/etc/app/config.yaml")); err != nil {
+		t.Fatalf("ExtendRuntimeMeasurement() = %v want nil", err)
+	}
+	data, _ := os.ReadFile(location)
+	if _, err := os.Stat(location + RUNTIME_EVENT_PENDING_SUFFIX); !os.IsNotExist(err) {
+		t.Fatalf("Pending runtime event record left after the extend: %v", err)
+	}
+
+	crash := func(t *testing.T, index int, event string) []byte {
+		digest := sha512.Sum384([]byte(event))
+		pending := runtimeEventPending{Offset: uint64(len(data)), Index: uint32(index)}
+		rtmr, _ := device.ReadRtmr(index)
+		copy(pending.Rtmr[:], rtmr)
+		copy(pending.Digest[:], digest[:])
+		if err := writeRuntimeEventPending(pending); err != nil {
+			t.Fatalf("Failed to write pending runtime event record: %v", err)
+		}
+		if err := os.WriteFile(location, append(append([]byte{}, data...), marshalRuntimeEvent(index, digest[:], []byte(event))...), 0644); err != nil {
+			t.Fatalf("Failed to write runtime eventlog: %v", err)
+		}
+		return digest[:]
+	}
+
+	tests := []struct {
+		name   string
+		extend func(digest []byte)
+		kept   bool
+	}{
+		{"Not extended", func([]byte) {}, false},
+		{"Extended", func(digest []byte) { device.ExtendRtmr(3, digest) }, true},
+		{"Extended by another process", func(digest []byte) { device.ExtendRtmr(3, make([]byte, RTMR_LEN)) }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.extend(crash(t, 3, "model:resnet50"))
+			if err := RecoverRuntimeEventlog(); err != nil {
+				t.Fatalf("RecoverRuntimeEventlog() = %v want nil", err)
+			}
+
+			after, _ := os.ReadFile(location)
+			if kept := len(after) > len(data); kept != tt.kept || !bytes.HasPrefix(after, data) {
+				t.Errorf("Runtime eventlog of %d bytes after recovery, event kept %v want %v", len(after), kept, tt.kept)
+			}
+			if _, err := os.Stat(location + RUNTIME_EVENT_PENDING_SUFFIX); !os.IsNotExist(err) {
+				t.Errorf("Pending runtime event record left after recovery: %v", err)
+			}
+			data = after
+		})
+	}
+
+	if err := os.WriteFile(location+RUNTIME_EVENT_PENDING_SUFFIX, []byte{1, 2, 3}, 0600); err != nil {
+		t.Fatalf("Failed to write pending runtime event record: %v", err)
+	}
+	if err := RecoverRuntimeEventlog(); err != InvalidPendingRuntimeEventErr {
+		t.Errorf("RecoverRuntimeEventlog() with invalid record = %v want %v", err, InvalidPendingRuntimeEventErr)
+	}
+}
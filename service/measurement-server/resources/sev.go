@@ -6,16 +6,189 @@
 package resources
 
 import (
+	"encoding/base64"
+	"encoding/binary"
 	"log"
 	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	pkgerrors "github.com/pkg/errors"
 )
 
 const (
 	// The device fd for AMD SEV
 	DEVICE_NODE_NAME_1 = "/dev/sev-guest"
 	DEVICE_NODE_NAME_2 = "/dev/sev"
+
+	/* The device operators for SEV-SNP guests
+	   Reference: SNP_GET_REPORT = _IOWR('S', 0x0, struct snp_guest_request_ioctl)
+	   defined in include/uapi/linux/sev-guest.h in kernel source
+	*/
+	SNP_GET_REPORT = 0xc0205300
+	/* Reference: SNP_GET_EXT_REPORT = _IOWR('S', 0x2, struct snp_guest_request_ioctl) */
+	SNP_GET_EXT_REPORT = 0xc0205302
+	// The version of the guest request messages
+	SNP_GUEST_MSG_VERSION = 1
+
+	// The length of the user data in the report request
+	SNP_REPORT_DATA_LEN = 64
+	// The length of ATTESTATION_REPORT defined in the SEV-SNP firmware ABI specification
+	SNP_REPORT_LEN = 1184
+	// The length of the response buffer of struct snp_report_resp
+	SNP_REPORT_RESP_LEN = 4000
+	// MSG_REPORT_RSP starts with the status, the report size and reserved bytes before the report
+	SNP_REPORT_RESP_HEADER_LEN = 32
+	// The highest VMPL, a report can be requested for the VMPL of the guest or a less privileged one
+	SNP_MAX_VMPL = 3
+
+	// The certificate buffer is made of pages, the driver accepts up to 4 pages
+	SNP_CERTS_PAGE_SIZE  = 4096
+	SNP_CERTS_BUFFER_LEN = 4 * SNP_CERTS_PAGE_SIZE
+	// The VMM error in the exit info telling the certificate buffer is too small
+	SNP_GUEST_VMM_ERR_INVALID_LEN = 1
+	// A certificate table entry is a GUID, an offset and a length, the last entry is all zero
+	SNP_CERT_TABLE_ENTRY_LEN = 24
+)
+
+/*
+GUIDs of the certificate table entries defined in the GHCB specification, in the byte order
+of RFC 4122
+*/
+var (
+	SNP_VCEK_GUID = [16]byte{0x63, 0xda, 0x75, 0x8d, 0xe6, 0x64, 0x45, 0x64, 0xad, 0xc5, 0xf4, 0xb9, 0x3b, 0xe8, 0xac, 0xcd}
+	SNP_VLEK_GUID = [16]byte{0xa8, 0x07, 0x4b, 0xc2, 0xa2, 0x5a, 0x48, 0x3e, 0xaa, 0xe6, 0x39, 0xc0, 0x45, 0xa0, 0xb8, 0xa1}
+	SNP_ASK_GUID  = [16]byte{0x4a, 0xb7, 0xb3, 0x79, 0xbb, 0xac, 0x4f, 0xe4, 0xa0, 0x2f, 0x05, 0xae, 0xf3, 0x27, 0xc7, 0x82}
+	SNP_ARK_GUID  = [16]byte{0xc0, 0xb4, 0x06, 0xa4, 0xa8, 0x03, 0x49, 0x52, 0x97, 0x43, 0x3f, 0xb6, 0x01, 0x4c, 0xd0, 0xae}
 )
 
+var SevGetReportErr = pkgerrors.New("Failed to get SEV-SNP report.")
+var InvalidVmplErr = pkgerrors.New("Invalid VMPL used.")
+var SevCertsBufferTooSmallErr = pkgerrors.New("SEV-SNP certificate buffer too small.")
+var InvalidSnpCertTableErr = pkgerrors.New("Invalid SEV-SNP certificate table.")
+
+/* struct snp_report_req */
+type SnpReportReq struct {
+	UserData [SNP_REPORT_DATA_LEN]byte
+	Vmpl     uint32
+	Reserved [28]byte
+}
+
+/* struct snp_ext_report_req */
+type snpExtReportReq struct {
+	Data         SnpReportReq
+	CertsAddress uint64
+	CertsLen     uint32
+	_            uint32
+}
+
+/* An entry of the certificate table returned with the extended report */
+type SnpCertTableEntry struct {
+	Guid   [16]byte
+	Offset uint32
+	Length uint32
+}
+
+/* struct snp_guest_request_ioctl */
+type snpGuestRequestIoctl struct {
+	MsgVersion uint8
+	_          [7]byte
+	ReqData    uint64
+	RespData   uint64
+	// the firmware error in the lower and the VMM error in the upper 32 bits
+	ExitInfo2 uint64
+}
+
+/*
+SevGuestDevice issues the guest requests of the SEV guest driver. The driver is opened with
+OpenSevGuestDevice, tests replace it with SetSevGuestDeviceOpener to replay captured responses.
+*/
+type SevGuestDevice interface {
+	// GetReport issues SNP_GET_REPORT and returns the response buffer with MSG_REPORT_RSP
+	GetReport(req SnpReportReq) ([]byte, error)
+	/*
+		GetExtReport issues SNP_GET_EXT_REPORT with the certificate buffer and returns the
+		response buffer. If the buffer is too small, it returns SevCertsBufferTooSmallErr
+		with the length needed.
+	*/
+	GetExtReport(req SnpReportReq, certs []byte) ([]byte, int, error)
+	Close() error
+}
+
+type sevGuestDevice struct {
+	file *os.File
+}
+
+var openSevGuestDevice = OpenSevGuestDevice
+
+// SetSevGuestDeviceOpener changes how the SEV guest device is opened.
+func SetSevGuestDeviceOpener(open func(device string) (SevGuestDevice, error)) {
+	openSevGuestDevice = open
+}
+
+// OpenSevGuestDevice opens the SEV guest driver.
+func OpenSevGuestDevice(device string) (SevGuestDevice, error) {
+	file, err := os.OpenFile(device, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &sevGuestDevice{file: file}, nil
+}
+
+func (d *sevGuestDevice) GetReport(req SnpReportReq) ([]byte, error) {
+	resp := make([]byte, SNP_REPORT_RESP_LEN)
+	ioctlReq := snpGuestRequestIoctl{
+		MsgVersion: SNP_GUEST_MSG_VERSION,
+		ReqData:    uint64(uintptr(unsafe.Pointer(&req))),
+		RespData:   uint64(uintptr(unsafe.Pointer(&resp[0]))),
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(d.file.Fd()),
+		uintptr(SNP_GET_REPORT), uintptr(unsafe.Pointer(&ioctlReq)))
+	runtime.KeepAlive(&req)
+	runtime.KeepAlive(resp)
+	if errno != 0 {
+		log.Printf("SNP_GET_REPORT failed: %v, exit info 0x%x", errno, ioctlReq.ExitInfo2)
+		return nil, SevGetReportErr
+	}
+
+	return resp, nil
+}
+
+func (d *sevGuestDevice) GetExtReport(req SnpReportReq, certs []byte) ([]byte, int, error) {
+	resp := make([]byte, SNP_REPORT_RESP_LEN)
+	extReq := snpExtReportReq{Data: req, CertsLen: uint32(len(certs))}
+	if len(certs) > 0 {
+		extReq.CertsAddress = uint64(uintptr(unsafe.Pointer(&certs[0])))
+	}
+	ioctlReq := snpGuestRequestIoctl{
+		MsgVersion: SNP_GUEST_MSG_VERSION,
+		ReqData:    uint64(uintptr(unsafe.Pointer(&extReq))),
+		RespData:   uint64(uintptr(unsafe.Pointer(&resp[0]))),
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(d.file.Fd()),
+		uintptr(SNP_GET_EXT_REPORT), uintptr(unsafe.Pointer(&ioctlReq)))
+	runtime.KeepAlive(&extReq)
+	runtime.KeepAlive(certs)
+	runtime.KeepAlive(resp)
+	if errno != 0 {
+		/* the driver updates the length to the one needed by the host certificates */
+		if ioctlReq.ExitInfo2>>32 == SNP_GUEST_VMM_ERR_INVALID_LEN {
+			return nil, int(extReq.CertsLen), SevCertsBufferTooSmallErr
+		}
+		log.Printf("SNP_GET_EXT_REPORT failed: %v, exit info 0x%x", errno, ioctlReq.ExitInfo2)
+		return nil, 0, SevGetReportErr
+	}
+
+	return resp, len(certs), nil
+}
+
+func (d *sevGuestDevice) Close() error {
+	return d.file.Close()
+}
+
 type SevResource struct {
 	BaseTeeResource
 }
@@ -30,31 +203,154 @@ func NewSevResource() *SevResource {
 
 func (r *SevResource) FindDeviceAvailable() (string, error) {
 
-	if _, err := os.Stat(DEVICE_NODE_NAME_1); err == nil {
-		return DEVICE_NODE_NAME_1, nil
+	return findDeviceNode(deviceNodes.SevGuest, deviceNodes.Sev)
+}
+
+// GetReport returns the SEV-SNP ATTESTATION_REPORT with the data as report data, requested
+// for the VMPL of the resource.
+func (r *SevResource) GetReport(device string, data string) (string, error) {
+
+	req, err := r.getReportReq(data)
+	if err != nil {
+		return "", err
+	}
+
+	deviceNode, err := openSevGuestDevice(device)
+	if err != nil {
+		return "", err
 	}
+	defer deviceNode.Close()
 
-	if _, err := os.Stat(DEVICE_NODE_NAME_2); err == nil {
-		return DEVICE_NODE_NAME_2, nil
+	resp, err := deviceNode.GetReport(req)
+	if err != nil {
+		return "", err
 	}
 
-	return "", DeviceNotFoundErr
+	report, err := getSnpReport(resp)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(report), nil
 }
 
-func (r *SevResource) GetReport(device string, data string) (string, error) {
+/*
+GetExtendedReport returns the SEV-SNP ATTESTATION_REPORT like GetReport, along with the
+certificate table the host provides to verify it, with the VCEK, ASK and ARK certificates.
+*/
+func (r *SevResource) GetExtendedReport(device string, data string) (string, []byte, error) {
 
-	/*
-	   // Open SEV device fd to get prepared
-	   deviceNode, err := os.OpenFile(device, os.O_RDWR, 0644)
-	   if err != nil {
-	       return "", err
-	   }
-
-	   // TODO: check if there can be a data attached to SEV report
-	   if len(data) > REPORT_DATA_LEN {
-	       err = pkgerrors.New("Report data with invalid length.")
-	       return "", err
-	   }*/
-	log.Println("Not implemented")
-	return "", nil
+	req, err := r.getReportReq(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	deviceNode, err := openSevGuestDevice(device)
+	if err != nil {
+		return "", nil, err
+	}
+	defer deviceNode.Close()
+
+	/* the request is issued again once with the length needed by the host certificates */
+	certs := make([]byte, SNP_CERTS_BUFFER_LEN)
+	resp, certsLen, err := deviceNode.GetExtReport(req, certs)
+	if err == SevCertsBufferTooSmallErr && certsLen > len(certs) {
+		certs = make([]byte, (certsLen+SNP_CERTS_PAGE_SIZE-1)/SNP_CERTS_PAGE_SIZE*SNP_CERTS_PAGE_SIZE)
+		resp, _, err = deviceNode.GetExtReport(req, certs)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	report, err := getSnpReport(resp)
+	if err != nil {
+		return "", nil, err
+	}
+
+	certTable, err := GetSnpCertTable(certs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return base64.StdEncoding.EncodeToString(report), certTable, nil
+}
+
+func (r *SevResource) getReportReq(data string) (SnpReportReq, error) {
+
+	if len(data) > SNP_REPORT_DATA_LEN {
+		err := pkgerrors.New("Report data with invalid length.")
+		return SnpReportReq{}, err
+	}
+
+	if r.Vmpl > SNP_MAX_VMPL {
+		return SnpReportReq{}, InvalidVmplErr
+	}
+
+	req := SnpReportReq{Vmpl: r.Vmpl}
+	copy(req.UserData[:], []byte(data))
+	return req, nil
+}
+
+// ParseSnpCertTable returns the entries of the certificate table up to the all zero entry.
+func ParseSnpCertTable(certs []byte) ([]SnpCertTableEntry, error) {
+	var entries []SnpCertTableEntry
+
+	for index := 0; ; index += SNP_CERT_TABLE_ENTRY_LEN {
+		if index+SNP_CERT_TABLE_ENTRY_LEN > len(certs) {
+			return nil, InvalidSnpCertTableErr
+		}
+
+		entry := SnpCertTableEntry{
+			Offset: binary.LittleEndian.Uint32(certs[index+16 : index+20]),
+			Length: binary.LittleEndian.Uint32(certs[index+20 : index+24]),
+		}
+		copy(entry.Guid[:], certs[index:index+16])
+		if entry == (SnpCertTableEntry{}) {
+			return entries, nil
+		}
+
+		if uint64(entry.Offset)+uint64(entry.Length) > uint64(len(certs)) {
+			log.Printf("SEV-SNP certificate %x beyond the end of the certificate buffer", entry.Guid)
+			return nil, InvalidSnpCertTableErr
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// GetSnpCertTable returns the certificate table without the unused end of the certificate buffer.
+func GetSnpCertTable(certs []byte) ([]byte, error) {
+	entries, err := ParseSnpCertTable(certs)
+	if err != nil {
+		return nil, err
+	}
+
+	end := (len(entries) + 1) * SNP_CERT_TABLE_ENTRY_LEN
+	for _, entry := range entries {
+		if int(entry.Offset+entry.Length) > end {
+			end = int(entry.Offset + entry.Length)
+		}
+	}
+	return certs[:end], nil
+}
+
+/* Get the ATTESTATION_REPORT out of MSG_REPORT_RSP */
+func getSnpReport(resp []byte) ([]byte, error) {
+
+	if len(resp) < SNP_REPORT_RESP_HEADER_LEN {
+		return nil, SevGetReportErr
+	}
+
+	status := binary.LittleEndian.Uint32(resp[0:4])
+	if status != 0 {
+		log.Printf("SEV-SNP report request failed with status 0x%x", status)
+		return nil, SevGetReportErr
+	}
+
+	size := binary.LittleEndian.Uint32(resp[4:8])
+	if size != SNP_REPORT_LEN || len(resp) < SNP_REPORT_RESP_HEADER_LEN+SNP_REPORT_LEN {
+		log.Printf("SEV-SNP report with invalid size %d", size)
+		return nil, SevGetReportErr
+	}
+
+	return resp[SNP_REPORT_RESP_HEADER_LEN : SNP_REPORT_RESP_HEADER_LEN+SNP_REPORT_LEN], nil
 }
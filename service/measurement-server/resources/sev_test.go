@@ -6,9 +6,69 @@
 package resources
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"path/filepath"
 	"testing"
+
+	pkgerrors "github.com/pkg/errors"
 )
 
+/*
+Replays a captured SNP_GET_REPORT or SNP_GET_EXT_REPORT response and keeps the last request.
+The certificate table is refused if the buffer is shorter than its length.
+*/
+type fakeSevGuestDevice struct {
+	resp      []byte
+	certs     []byte
+	req       SnpReportReq
+	certsLens []int
+	closed    bool
+}
+
+func (d *fakeSevGuestDevice) GetReport(req SnpReportReq) ([]byte, error) {
+	d.req = req
+	return d.resp, nil
+}
+
+func (d *fakeSevGuestDevice) GetExtReport(req SnpReportReq, certs []byte) ([]byte, int, error) {
+	d.req = req
+	d.certsLens = append(d.certsLens, len(certs))
+	if len(d.certs) > len(certs) {
+		return nil, len(d.certs), SevCertsBufferTooSmallErr
+	}
+	copy(certs, d.certs)
+	return d.resp, len(certs), nil
+}
+
+func (d *fakeSevGuestDevice) Close() error {
+	d.closed = true
+	return nil
+}
+
+func useFakeSevGuestDevice(t *testing.T, device *fakeSevGuestDevice) {
+	SetSevGuestDeviceOpener(func(string) (SevGuestDevice, error) {
+		return device, nil
+	})
+	t.Cleanup(func() { SetSevGuestDeviceOpener(OpenSevGuestDevice) })
+}
+
+/* MSG_REPORT_RSP with an ATTESTATION_REPORT of version 2 for the VMPL */
+func buildSnpReportResp(status uint32, size uint32, vmpl uint32) ([]byte, []byte) {
+	report := make([]byte, SNP_REPORT_LEN)
+	binary.LittleEndian.PutUint32(report[0x0:], 2)
+	binary.LittleEndian.PutUint32(report[0x30:], vmpl)
+	copy(report[0x90:0xc0], bytes.Repeat([]byte{0xab}, 48))
+
+	resp := make([]byte, SNP_REPORT_RESP_LEN)
+	binary.LittleEndian.PutUint32(resp[0:], status)
+	binary.LittleEndian.PutUint32(resp[4:], size)
+	copy(resp[SNP_REPORT_RESP_HEADER_LEN:], report)
+	return resp, report
+}
+
 func TestFindSEVDeviceAvailable(t *testing.T) {
 	r := NewSevResource()
 
@@ -20,13 +80,182 @@ func TestFindSEVDeviceAvailable(t *testing.T) {
 }
 
 func TestGetSEVReport(t *testing.T) {
+	resp, want := buildSnpReportResp(0, SNP_REPORT_LEN, 1)
+	device := &fakeSevGuestDevice{resp: resp}
+	useFakeSevGuestDevice(t, device)
+
 	r := NewSevResource()
-	device := DEVICE_NODE_NAME_1
-	data := ""
+	r.Vmpl = 1
+	data := "test"
 
-	_, err := r.GetReport(device, data)
+	report, err := r.GetReport(DEVICE_NODE_NAME_1, data)
 	if err != nil {
 		t.Fatalf(`GetReport() = %v want %v`,
 			err, nil)
 	}
+
+	value, err := base64.StdEncoding.DecodeString(report)
+	if err != nil || !bytes.Equal(value, want) {
+		t.Fatalf(`GetReport() = %x, %v want the ATTESTATION_REPORT of the response`, value, err)
+	}
+
+	if device.req.Vmpl != 1 || !bytes.Equal(device.req.UserData[:], append([]byte(data), make([]byte, SNP_REPORT_DATA_LEN-len(data))...)) {
+		t.Errorf(`GetReport() requested %+v want VMPL 1 and the report data`, device.req)
+	}
+	if !device.closed {
+		t.Errorf(`GetReport() did not close the device`)
+	}
+}
+
+func TestGetSEVReportInvalid(t *testing.T) {
+	validResp, _ := buildSnpReportResp(0, SNP_REPORT_LEN, 0)
+	failedResp, _ := buildSnpReportResp(0x16, SNP_REPORT_LEN, 0)
+	shortResp, _ := buildSnpReportResp(0, SNP_REPORT_LEN-1, 0)
+
+	tests := []struct {
+		name string
+		resp []byte
+		data string
+		vmpl uint32
+		err  error
+	}{
+		{"Invalid VMPL", validResp, "", SNP_MAX_VMPL + 1, InvalidVmplErr},
+		{"Failed request", failedResp, "", 0, SevGetReportErr},
+		{"Invalid report size", shortResp, "", 0, SevGetReportErr},
+		{"Truncated response", validResp[:SNP_REPORT_RESP_HEADER_LEN+10], "", 0, SevGetReportErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeSevGuestDevice(t, &fakeSevGuestDevice{resp: tt.resp})
+
+			r := NewSevResource()
+			r.Vmpl = tt.vmpl
+			if _, err := r.GetReport(DEVICE_NODE_NAME_1, tt.data); pkgerrors.Cause(err) != tt.err {
+				t.Errorf(`GetReport() = %v want %v`, err, tt.err)
+			}
+		})
+	}
+
+	r := NewSevResource()
+	if _, err := r.GetReport(DEVICE_NODE_NAME_1, string(make([]byte, SNP_REPORT_DATA_LEN+1))); err == nil {
+		t.Errorf(`GetReport() with too long report data = nil want error`)
+	}
+}
+
+/* A SNP node without TDX device gets the SEV-SNP report from the base resource */
+func TestGetReportOnSevNode(t *testing.T) {
+	defer SetDeviceNodes(DefaultDeviceNodes())
+
+	node := filepath.Join(t.TempDir(), "sev-guest")
+	if err := os.WriteFile(node, nil, 0600); err != nil {
+		t.Fatalf("Failed to create device node: %v", err)
+	}
+	SetDeviceNodes(DeviceNodes{SevGuest: node})
+
+	resp, want := buildSnpReportResp(0, SNP_REPORT_LEN, 2)
+	device := &fakeSevGuestDevice{resp: resp}
+	useFakeSevGuestDevice(t, device)
+
+	r := NewBaseTeeResource()
+	r.Vmpl = 2
+	found, err := r.FindDeviceAvailable()
+	if err != nil || found != node || !IsSevDevice(found) || IsTdxDevice(found) {
+		t.Fatalf(`FindDeviceAvailable() = %s, %v want SEV device %s`, found, err, node)
+	}
+
+	report, err := r.GetReport(found, "")
+	if err != nil || report != base64.StdEncoding.EncodeToString(want) || device.req.Vmpl != 2 {
+		t.Fatalf(`GetReport() = %s, %v want the SEV-SNP report for VMPL 2`, report, err)
+	}
+}
+
+/* A certificate table with the entries and the certificates after the all zero entry */
+func buildSnpCertTable(guids [][16]byte, certs [][]byte) []byte {
+	offset := (len(guids) + 1) * SNP_CERT_TABLE_ENTRY_LEN
+	table := make([]byte, offset)
+	for i, guid := range guids {
+		entry := table[i*SNP_CERT_TABLE_ENTRY_LEN:]
+		copy(entry, guid[:])
+		binary.LittleEndian.PutUint32(entry[16:], uint32(offset))
+		binary.LittleEndian.PutUint32(entry[20:], uint32(len(certs[i])))
+		table = append(table, certs[i]...)
+		offset += len(certs[i])
+	}
+	return table
+}
+
+func TestGetSEVExtendedReport(t *testing.T) {
+	resp, want := buildSnpReportResp(0, SNP_REPORT_LEN, 0)
+	guids := [][16]byte{SNP_VCEK_GUID, SNP_ASK_GUID, SNP_ARK_GUID}
+
+	tests := []struct {
+		name      string
+		certs     [][]byte
+		certsLens []int
+	}{
+		{"No certificates", nil, []int{SNP_CERTS_BUFFER_LEN}},
+		{"Certificates", [][]byte{bytes.Repeat([]byte{1}, 1300), bytes.Repeat([]byte{2}, 1600), bytes.Repeat([]byte{3}, 1400)},
+			[]int{SNP_CERTS_BUFFER_LEN}},
+		{"Certificate buffer too small", [][]byte{bytes.Repeat([]byte{1}, SNP_CERTS_BUFFER_LEN), {2}, {3}},
+			[]int{SNP_CERTS_BUFFER_LEN, SNP_CERTS_BUFFER_LEN + SNP_CERTS_PAGE_SIZE}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := buildSnpCertTable(guids[:len(tt.certs)], tt.certs)
+			device := &fakeSevGuestDevice{resp: resp, certs: table}
+			useFakeSevGuestDevice(t, device)
+
+			r := NewSevResource()
+			report, certTable, err := r.GetExtendedReport(DEVICE_NODE_NAME_1, "test")
+			if err != nil {
+				t.Fatalf(`GetExtendedReport() = %v want %v`, err, nil)
+			}
+			if report != base64.StdEncoding.EncodeToString(want) {
+				t.Errorf(`GetExtendedReport() = %s want the ATTESTATION_REPORT of the response`, report)
+			}
+			if !bytes.Equal(certTable, table) {
+				t.Errorf(`GetExtendedReport() certificate table of %d bytes want %d bytes`, len(certTable), len(table))
+			}
+			if len(device.certsLens) != len(tt.certsLens) || device.certsLens[len(device.certsLens)-1] != tt.certsLens[len(tt.certsLens)-1] {
+				t.Errorf(`GetExtendedReport() requested certificate buffers %v want %v`, device.certsLens, tt.certsLens)
+			}
+
+			entries, err := ParseSnpCertTable(certTable)
+			if err != nil || len(entries) != len(tt.certs) {
+				t.Fatalf(`ParseSnpCertTable() = %v, %v want %d entries`, entries, err, len(tt.certs))
+			}
+			for i, entry := range entries {
+				if entry.Guid != guids[i] || !bytes.Equal(certTable[entry.Offset:entry.Offset+entry.Length], tt.certs[i]) {
+					t.Errorf(`ParseSnpCertTable() entry %d = %+v want %x`, i, entry, guids[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseSnpCertTableInvalid(t *testing.T) {
+	valid := buildSnpCertTable([][16]byte{SNP_VCEK_GUID}, [][]byte{{1, 2, 3}})
+	beyondEnd := append([]byte{}, valid...)
+	binary.LittleEndian.PutUint32(beyondEnd[20:], 4)
+	noEnd := append([]byte{}, valid[:SNP_CERT_TABLE_ENTRY_LEN+10]...)
+	binary.LittleEndian.PutUint32(noEnd[16:], 0)
+
+	tests := []struct {
+		name  string
+		certs []byte
+	}{
+		{"Empty buffer", nil},
+		{"No terminating entry", noEnd},
+		{"Certificate beyond the end", beyondEnd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSnpCertTable(tt.certs); err != InvalidSnpCertTableErr {
+				t.Errorf(`ParseSnpCertTable() = %v want %v`, err, InvalidSnpCertTableErr)
+			}
+		})
+	}
 }
@@ -9,6 +9,7 @@ import (
 	"encoding/base64"
 	"log"
 	"os"
+	"runtime"
 	"syscall"
 	"unsafe"
 
@@ -41,6 +42,14 @@ const (
 	*/
 	TDX_CMD_GET_REPORT0_V1_5 = 0xc4405401
 
+	/* The device operators to extend RTMR
+	   Reference: TDX_CMD_EXTEND_RTMR = _IOW('T', 0x03, __u64) for tdx v1.0
+	   and TDX_CMD_EXTEND_RTMR = _IOW('T', 3, struct tdx_extend_rtmr_req) for tdx v1.5,
+	   both taking the 48 bytes data followed by the RTMR index
+	*/
+	TDX_CMD_EXTEND_RTMR_V1_0 = 0x40085403
+	TDX_CMD_EXTEND_RTMR_V1_5 = 0x40315403
+
 	RTMR_0_OFFSET = 0x2d0
 	RTMR_1_OFFSET = 0x300
 	RTMR_2_OFFSET = 0x330
@@ -51,6 +60,14 @@ const (
 
 var TdxGetReportErr = pkgerrors.New("Failed to get TDX report.")
 var InvalidRtmrIndexErr = pkgerrors.New("Invalid RTMR index used.")
+var TdxExtendRtmrErr = pkgerrors.New("Failed to extend RTMR.")
+var InvalidExtendDataErr = pkgerrors.New("Extend data with invalid length.")
+
+/*
+Only RTMR 2 and 3 are extended at runtime. RTMR 0 and 1 hold the measurements of the firmware
+and the boot chain, an event extended there would break their reference values.
+*/
+var extendableRtmrs = map[int]bool{2: true, 3: true}
 
 type TdxReportReq struct {
 	SubType    uint8
@@ -64,6 +81,79 @@ type TdxResource struct {
 	BaseTeeResource
 }
 
+/*
+TdxGuestDevice extends and reads the RTMRs through the TDX guest driver. The driver is opened with
+OpenTdxGuestDevice, tests replace it with SetTdxGuestDeviceOpener to record the extended data.
+*/
+type TdxGuestDevice interface {
+	// ExtendRtmr extends the RTMR with the 48 bytes data
+	ExtendRtmr(index int, data []byte) error
+	// ReadRtmr returns the value of the RTMR from the TD report
+	ReadRtmr(index int) ([]byte, error)
+	Close() error
+}
+
+type tdxGuestDevice struct {
+	file  *os.File
+	cmd   uintptr
+	tdx10 bool
+}
+
+var openTdxGuestDevice = OpenTdxGuestDevice
+
+// SetTdxGuestDeviceOpener changes how the TDX guest device is opened.
+func SetTdxGuestDeviceOpener(open func(device string) (TdxGuestDevice, error)) {
+	openTdxGuestDevice = open
+}
+
+// OpenTdxGuestDevice opens the TDX guest driver.
+func OpenTdxGuestDevice(device string) (TdxGuestDevice, error) {
+	file, err := os.OpenFile(device, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := uintptr(TDX_CMD_EXTEND_RTMR_V1_5)
+	if device == deviceNodes.Tdx10 {
+		cmd = TDX_CMD_EXTEND_RTMR_V1_0
+	}
+	return &tdxGuestDevice{file: file, cmd: cmd, tdx10: device == deviceNodes.Tdx10}, nil
+}
+
+func (d *tdxGuestDevice) ExtendRtmr(index int, data []byte) error {
+	req := make([]byte, TDX_EXTEND_RTMR_DATA_LEN+1)
+	copy(req, data)
+	req[TDX_EXTEND_RTMR_DATA_LEN] = uint8(index)
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(d.file.Fd()), d.cmd, uintptr(unsafe.Pointer(&req[0])))
+	runtime.KeepAlive(req)
+	if errno != 0 {
+		log.Printf("TDX_CMD_EXTEND_RTMR of RTMR %d failed: %v", index, errno)
+		return TdxExtendRtmrErr
+	}
+	return nil
+}
+
+func (d *tdxGuestDevice) ReadRtmr(index int) ([]byte, error) {
+	var report string
+	var err error
+
+	if d.tdx10 {
+		report, err = getTdxReport(d.file, "")
+	} else {
+		report, err = getTdxReport0(d.file, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return collectRtmrMeasurement(report, index)
+}
+
+func (d *tdxGuestDevice) Close() error {
+	return d.file.Close()
+}
+
 func NewTdxResource() *TdxResource {
 	return &TdxResource{
 		BaseTeeResource{
@@ -95,21 +185,13 @@ func NewTdxReportReq0(data string) []byte {
 
 func (r *TdxResource) FindDeviceAvailable() (string, error) {
 
-	if _, err := os.Stat(DEVICE_NODE_NAME_DEPRECATED); err == nil {
+	if _, err := findDeviceNode(deviceNodes.TdxDeprecated); err == nil {
 		log.Printf("Deprecated device node %s, please upgrade to use %s or %s",
-			DEVICE_NODE_NAME_DEPRECATED, DEVICE_NODE_NAME_1_0, DEVICE_NODE_NAME_1_5)
+			deviceNodes.TdxDeprecated, deviceNodes.Tdx10, deviceNodes.Tdx15)
 		return "", DeviceNotFoundErr
 	}
 
-	if _, err := os.Stat(DEVICE_NODE_NAME_1_0); err == nil {
-		return DEVICE_NODE_NAME_1_0, nil
-	}
-
-	if _, err := os.Stat(DEVICE_NODE_NAME_1_5); err == nil {
-		return DEVICE_NODE_NAME_1_5, nil
-	}
-
-	return "", DeviceNotFoundErr
+	return findDeviceNode(deviceNodes.Tdx10, deviceNodes.Tdx15)
 }
 
 func (r *TdxResource) GetReport(device string, data string) (string, error) {
@@ -127,7 +209,7 @@ func (r *TdxResource) GetReport(device string, data string) (string, error) {
 		return "", err
 	}
 
-	if device == DEVICE_NODE_NAME_1_0 {
+	if device == deviceNodes.Tdx10 {
 		report, err = getTdxReport(deviceNode, data)
 		if err != nil {
 			return "", err
@@ -210,3 +292,23 @@ func collectRtmrMeasurement(report string, index int) ([]byte, error) {
 	}
 	return value, nil
 }
+
+// ExtendRTMR extends RTMR 2 or 3 with the SHA384 digest.
+func (r *TdxResource) ExtendRTMR(device string, index int, digest []byte) error {
+
+	if !extendableRtmrs[index] {
+		return InvalidRtmrIndexErr
+	}
+
+	if len(digest) != TDX_EXTEND_RTMR_DATA_LEN {
+		return InvalidExtendDataErr
+	}
+
+	deviceNode, err := openTdxGuestDevice(device)
+	if err != nil {
+		return err
+	}
+	defer deviceNode.Close()
+
+	return deviceNode.ExtendRtmr(index, digest)
+}
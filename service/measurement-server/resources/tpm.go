@@ -6,39 +6,360 @@
 package resources
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
 	"log"
 	"os"
+	"sync"
+
+	pkgerrors "github.com/pkg/errors"
 )
 
 const (
-	// The device fd for AMD SEV
-	DEVICE_NODE_NAME_TPM = "/dev/tpm0"
+	// The TPM resource manager of the kernel, it flushes the transient objects of a closed client
+	DEVICE_NODE_NAME_TPM = "/dev/tpmrm0"
+	// The raw TPM device, only used on kernels without the resource manager
+	DEVICE_NODE_NAME_TPM_RAW = "/dev/tpm0"
+
+	/* TPM 2.0 command and response header, defined in the TPM 2.0 specification Part 2 */
+	TPM_ST_NO_SESSIONS = 0x8001
+	TPM_ST_SESSIONS    = 0x8002
+	TPM_HEADER_LEN     = 10
+	TPM_RC_SUCCESS     = 0
+	// The password session authorizing the commands with the empty authorization value
+	TPM_RS_PW = 0x40000009
+	// The largest response read from the TPM, MAX_RESPONSE_SIZE of the reference implementation
+	TPM_MAX_RESPONSE_LEN = 4096
+
+	TPM_CC_PCR_READ = 0x0000017e
+
+	// The hash algorithms of the PCR banks
+	TPM_ALG_SHA1   = 0x0004
+	TPM_ALG_SHA256 = 0x000b
+	TPM_ALG_SHA384 = 0x000c
+
+	// PCR 0 to 23 of the PC client platform, selected with a bitmap of 3 bytes
+	TPM_PCR_COUNT      = 24
+	TPM_PCR_SELECT_LEN = 3
 )
 
-func findDeviceAvailable() (string, error) {
+var tpmDigestLens = map[uint16]int{
+	TPM_ALG_SHA1:   20,
+	TPM_ALG_SHA256: 32,
+	TPM_ALG_SHA384: 48,
+}
+
+var TpmCommandErr = pkgerrors.New("TPM command failed.")
+var InvalidPcrIndexErr = pkgerrors.New("Invalid PCR index.")
+var UnsupportedPcrBankErr = pkgerrors.New("PCR bank not supported.")
+
+/*
+TpmTransport sends TPM commands and receives their responses, a command is written at once
+and its response read at once. The TPM device is opened with OpenTpmDevice, tests replace it
+with SetTpmTransportOpener to run against a TPM simulator.
+*/
+type TpmTransport interface {
+	io.ReadWriteCloser
+}
+
+var openTpmTransport = OpenTpmDevice
+
+/* The requests use the TPM in turn, the raw device is also opened by one process at a time */
+var tpmMutex sync.Mutex
+
+// SetTpmTransportOpener changes how the TPM transport is opened.
+func SetTpmTransportOpener(open func(device string) (TpmTransport, error)) {
+	openTpmTransport = open
+}
 
-	if _, err := os.Stat(DEVICE_NODE_NAME_TPM); err == nil {
-		return DEVICE_NODE_NAME_TPM, nil
+// OpenTpmDevice opens the TPM character device.
+func OpenTpmDevice(device string) (TpmTransport, error) {
+	file, err := os.OpenFile(device, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
 	}
+	return file, nil
+}
+
+// PcrValue is the digest of a PCR in a bank.
+type PcrValue struct {
+	Index  int
+	Digest []byte
+}
+
+func findDeviceAvailable() (string, error) {
 
-	return "", DeviceNotFoundErr
+	return findDeviceNode(deviceNodes.Tpm, deviceNodes.TpmRaw)
 }
 
+// GetTpmMeasurement returns the SHA256 digest of the PCR.
 func GetTpmMeasurement(index int) (string, error) {
 
-	_, err := findDeviceAvailable()
+	values, err := GetTpmPcrs(TPM_ALG_SHA256, []int{index})
 	if err != nil {
 		return "", err
 	}
 
-	/*
-	   // Open TPM device fd to get prepared
-	   deviceNode, err := os.OpenFile(device, os.O_RDWR, 0644)
-	   if err != nil {
-	       return "", err
-	   }
-	*/
-	log.Println("Not implemented")
-	return "", nil
+	return base64.StdEncoding.EncodeToString(values[0].Digest), nil
+}
+
+// GetTpmPcrs returns the digests of the PCRs in the bank of the hash algorithm, in the order of the indexes.
+func GetTpmPcrs(algorithm uint16, indexes []int) ([]PcrValue, error) {
+
+	device, err := findDeviceAvailable()
+	if err != nil {
+		return nil, err
+	}
+
+	tpmMutex.Lock()
+	defer tpmMutex.Unlock()
+
+	transport, err := openTpmTransport(device)
+	if err != nil {
+		return nil, err
+	}
+	defer transport.Close()
+
+	return ReadPcrs(transport, algorithm, indexes)
+}
+
+/*
+ReadPcrs reads the PCRs with TPM2_PCR_Read. A TPM returns up to 8 digests for a command, the
+command is sent again for the PCRs not read yet.
+*/
+func ReadPcrs(rw io.ReadWriter, algorithm uint16, indexes []int) ([]PcrValue, error) {
+
+	pending, err := getPcrSelection(algorithm, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	digests := map[int][]byte{}
+	for len(pending) > 0 {
+		read, err := readPcrs(rw, algorithm, pending)
+		if err != nil {
+			return nil, err
+		}
+
+		/* an empty selection is returned for a bank the TPM does not allocate */
+		if len(read) == 0 {
+			return nil, UnsupportedPcrBankErr
+		}
+
+		for index, digest := range read {
+			digests[index] = digest
+			delete(pending, index)
+		}
+	}
+
+	values := make([]PcrValue, 0, len(indexes))
+	for _, index := range indexes {
+		values = append(values, PcrValue{Index: index, Digest: digests[index]})
+	}
+	return values, nil
+}
+
+/* Check the bank and the PCRs and return the set of PCRs */
+func getPcrSelection(algorithm uint16, indexes []int) (map[int]bool, error) {
+
+	if _, ok := tpmDigestLens[algorithm]; !ok {
+		return nil, UnsupportedPcrBankErr
+	}
+
+	if len(indexes) == 0 {
+		return nil, InvalidPcrIndexErr
+	}
+
+	selection := map[int]bool{}
+	for _, index := range indexes {
+		if index < 0 || index >= TPM_PCR_COUNT {
+			return nil, InvalidPcrIndexErr
+		}
+		selection[index] = true
+	}
+	return selection, nil
+}
+
+/* Send TPM2_PCR_Read for the PCRs and return the digests of the PCRs in the response selection */
+func readPcrs(rw io.ReadWriter, algorithm uint16, indexes map[int]bool) (map[int][]byte, error) {
+
+	params := new(bytes.Buffer)
+	binary.Write(params, binary.BigEndian, uint32(1))
+	params.Write(marshalPcrSelection(algorithm, indexes))
+
+	resp, err := runTpmCommand(rw, TPM_CC_PCR_READ, params.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	var updateCounter, count uint32
+	reader := bytes.NewReader(resp)
+	if err := binary.Read(reader, binary.BigEndian, &updateCounter); err != nil {
+		return nil, TpmCommandErr
+	}
+
+	var selected []int
+	if err := binary.Read(reader, binary.BigEndian, &count); err != nil {
+		return nil, TpmCommandErr
+	}
+	for i := uint32(0); i < count; i++ {
+		bank, pcrs, err := unmarshalPcrSelection(reader)
+		if err != nil {
+			return nil, err
+		}
+		if bank == algorithm {
+			selected = append(selected, pcrs...)
+		}
+	}
+
+	if err := binary.Read(reader, binary.BigEndian, &count); err != nil || int(count) != len(selected) {
+		log.Printf("TPM2_PCR_Read returned digests not matching the selection")
+		return nil, TpmCommandErr
+	}
+
+	digests := map[int][]byte{}
+	for _, index := range selected {
+		digest, err := unmarshalTpm2b(reader)
+		if err != nil || len(digest) != tpmDigestLens[algorithm] {
+			return nil, TpmCommandErr
+		}
+		digests[index] = digest
+	}
+
+	return digests, nil
+}
+
+/* TPMS_PCR_SELECTION of the PCRs in the bank */
+func marshalPcrSelection(algorithm uint16, indexes map[int]bool) []byte {
+	selection := make([]byte, 3+TPM_PCR_SELECT_LEN)
+	binary.BigEndian.PutUint16(selection[0:], algorithm)
+	selection[2] = TPM_PCR_SELECT_LEN
+	for index := range indexes {
+		selection[3+index/8] |= 1 << (index % 8)
+	}
+	return selection
+}
+
+/* Read a TPMS_PCR_SELECTION and return the bank and the PCRs selected in increasing order */
+func unmarshalPcrSelection(reader *bytes.Reader) (uint16, []int, error) {
+	var algorithm uint16
+	var size uint8
+	if err := binary.Read(reader, binary.BigEndian, &algorithm); err != nil {
+		return 0, nil, TpmCommandErr
+	}
+	if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+		return 0, nil, TpmCommandErr
+	}
+
+	bitmap := make([]byte, size)
+	if _, err := io.ReadFull(reader, bitmap); err != nil {
+		return 0, nil, TpmCommandErr
+	}
+
+	var indexes []int
+	for index := 0; index < int(size)*8; index++ {
+		if bitmap[index/8]&(1<<(index%8)) != 0 {
+			indexes = append(indexes, index)
+		}
+	}
+	return algorithm, indexes, nil
+}
+
+/* Read a TPM2B, a buffer with its 16 bit size */
+func unmarshalTpm2b(reader *bytes.Reader) ([]byte, error) {
+	var size uint16
+	if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+		return nil, TpmCommandErr
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, TpmCommandErr
+	}
+	return data, nil
+}
+
+/* Marshal a TPM2B, a buffer with its 16 bit size */
+func marshalTpm2b(buffer *bytes.Buffer, data []byte) {
+	binary.Write(buffer, binary.BigEndian, uint16(len(data)))
+	buffer.Write(data)
+}
+
+/*
+Send the command without sessions and return the response parameters. A response code other
+than TPM_RC_SUCCESS is logged and returned as TpmCommandErr.
+*/
+func runTpmCommand(rw io.ReadWriter, code uint32, params []byte) ([]byte, error) {
+	return sendTpmCommand(rw, TPM_ST_NO_SESSIONS, code, params)
+}
+
+/*
+Send the command with the handles authorized by a password session with the empty
+authorization value, and return the handles and the parameters of the response.
+*/
+func runTpmAuthCommand(rw io.ReadWriter, code uint32, handles []uint32, params []byte, responseHandles int) ([]uint32, []byte, error) {
+
+	body := new(bytes.Buffer)
+	binary.Write(body, binary.BigEndian, handles)
+	/* TPMS_AUTH_COMMAND with the session handle, an empty nonce, no attributes and an empty password */
+	binary.Write(body, binary.BigEndian, uint32(9))
+	binary.Write(body, binary.BigEndian, uint32(TPM_RS_PW))
+	marshalTpm2b(body, nil)
+	body.WriteByte(0)
+	marshalTpm2b(body, nil)
+	body.Write(params)
+
+	resp, err := sendTpmCommand(rw, TPM_ST_SESSIONS, code, body.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outHandles := make([]uint32, responseHandles)
+	var size uint32
+	reader := bytes.NewReader(resp)
+	if err := binary.Read(reader, binary.BigEndian, outHandles); err != nil {
+		return nil, nil, TpmCommandErr
+	}
+	if err := binary.Read(reader, binary.BigEndian, &size); err != nil || int(size) > reader.Len() {
+		return nil, nil, TpmCommandErr
+	}
+
+	/* the response authorization follows the parameters */
+	offset := len(resp) - reader.Len()
+	return outHandles, resp[offset : offset+int(size)], nil
+}
+
+func sendTpmCommand(rw io.ReadWriter, tag uint16, code uint32, body []byte) ([]byte, error) {
+
+	cmd := make([]byte, TPM_HEADER_LEN, TPM_HEADER_LEN+len(body))
+	binary.BigEndian.PutUint16(cmd[0:], tag)
+	binary.BigEndian.PutUint32(cmd[2:], uint32(TPM_HEADER_LEN+len(body)))
+	binary.BigEndian.PutUint32(cmd[6:], code)
+	cmd = append(cmd, body...)
+
+	if _, err := rw.Write(cmd); err != nil {
+		return nil, err
+	}
+
+	resp := make([]byte, TPM_MAX_RESPONSE_LEN)
+	n, err := rw.Read(resp)
+	if err != nil {
+		return nil, err
+	}
+	resp = resp[:n]
+
+	if len(resp) < TPM_HEADER_LEN || int(binary.BigEndian.Uint32(resp[2:6])) != len(resp) {
+		log.Printf("TPM command 0x%x with invalid response of %d bytes", code, len(resp))
+		return nil, TpmCommandErr
+	}
+
+	rc := binary.BigEndian.Uint32(resp[6:10])
+	if rc != TPM_RC_SUCCESS {
+		log.Printf("TPM command 0x%x failed with response code 0x%x", code, rc)
+		return nil, TpmCommandErr
+	}
 
+	return resp[TPM_HEADER_LEN:], nil
 }
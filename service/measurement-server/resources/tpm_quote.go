@@ -0,0 +1,223 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"log"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	TPM_CC_EVICT_CONTROL  = 0x00000120
+	TPM_CC_CREATE_PRIMARY = 0x00000131
+	TPM_CC_QUOTE          = 0x00000158
+	TPM_CC_FLUSH_CONTEXT  = 0x00000165
+	TPM_CC_READ_PUBLIC    = 0x00000173
+
+	TPM_RH_OWNER       = 0x40000001
+	TPM_RH_ENDORSEMENT = 0x4000000b
+
+	// The handles of the persistent objects of the owner
+	TPM_PERSISTENT_FIRST = 0x81000000
+	TPM_PERSISTENT_LAST  = 0x817fffff
+
+	TPM_ALG_NULL      = 0x0010
+	TPM_ALG_ECDSA     = 0x0018
+	TPM_ALG_ECC       = 0x0023
+	TPM_ECC_NIST_P256 = 0x0003
+
+	/* fixedTPM, fixedParent, sensitiveDataOrigin, userWithAuth, restricted and sign */
+	TPM_AK_ATTRIBUTES = 0x00050072
+
+	// The qualifying data of a quote is up to the size of a SHA512 digest
+	TPM_MAX_QUALIFYING_DATA_LEN = 64
+)
+
+var InvalidQualifyingDataErr = pkgerrors.New("Qualifying data with invalid length.")
+
+/*
+The attestation key is loaded from the persistent handle, and created there if missing. Without
+a handle, the key is derived from the endorsement hierarchy for every quote, the same seed
+deriving the same key.
+*/
+var tpmAkHandle uint32
+
+// SetTpmAkHandle sets the persistent handle of the attestation key, 0 for a key derived for every quote.
+func SetTpmAkHandle(handle uint32) {
+	tpmAkHandle = handle
+}
+
+// IsTpmPersistentHandle tells whether the handle is a persistent handle of the owner.
+func IsTpmPersistentHandle(handle uint32) bool {
+	return handle >= TPM_PERSISTENT_FIRST && handle <= TPM_PERSISTENT_LAST
+}
+
+// TpmQuote is the TPM2_Quote of PCRs signed by the attestation key.
+type TpmQuote struct {
+	Attest    []byte // TPMS_ATTEST with TPMS_QUOTE_INFO
+	Signature []byte // TPMT_SIGNATURE
+	AkPublic  []byte // TPMT_PUBLIC of the attestation key
+}
+
+// Marshal returns the TPM2B_ATTEST, the TPMT_SIGNATURE and the TPM2B_PUBLIC of the attestation key.
+func (q TpmQuote) Marshal() []byte {
+	quote := new(bytes.Buffer)
+	marshalTpm2b(quote, q.Attest)
+	quote.Write(q.Signature)
+	marshalTpm2b(quote, q.AkPublic)
+	return quote.Bytes()
+}
+
+// GetTpmQuote returns the quote of the PCRs in the bank of the hash algorithm, with the qualifying data.
+func GetTpmQuote(algorithm uint16, indexes []int, qualifyingData []byte) (TpmQuote, error) {
+
+	device, err := findDeviceAvailable()
+	if err != nil {
+		return TpmQuote{}, err
+	}
+
+	tpmMutex.Lock()
+	defer tpmMutex.Unlock()
+
+	transport, err := openTpmTransport(device)
+	if err != nil {
+		return TpmQuote{}, err
+	}
+	defer transport.Close()
+
+	return Quote(transport, algorithm, indexes, qualifyingData)
+}
+
+// Quote signs the PCRs and the qualifying data with TPM2_Quote and the attestation key.
+func Quote(rw io.ReadWriter, algorithm uint16, indexes []int, qualifyingData []byte) (TpmQuote, error) {
+
+	if len(qualifyingData) > TPM_MAX_QUALIFYING_DATA_LEN {
+		return TpmQuote{}, InvalidQualifyingDataErr
+	}
+
+	selection, err := getPcrSelection(algorithm, indexes)
+	if err != nil {
+		return TpmQuote{}, err
+	}
+
+	handle, public, transient, err := loadTpmAk(rw)
+	if err != nil {
+		return TpmQuote{}, err
+	}
+	if transient {
+		defer flushTpmContext(rw, handle)
+	}
+
+	/* the signing scheme of the key is used */
+	params := new(bytes.Buffer)
+	marshalTpm2b(params, qualifyingData)
+	binary.Write(params, binary.BigEndian, uint16(TPM_ALG_NULL))
+	binary.Write(params, binary.BigEndian, uint32(1))
+	params.Write(marshalPcrSelection(algorithm, selection))
+
+	_, resp, err := runTpmAuthCommand(rw, TPM_CC_QUOTE, []uint32{handle}, params.Bytes(), 0)
+	if err != nil {
+		return TpmQuote{}, err
+	}
+
+	reader := bytes.NewReader(resp)
+	attest, err := unmarshalTpm2b(reader)
+	if err != nil {
+		return TpmQuote{}, err
+	}
+
+	return TpmQuote{Attest: attest, Signature: resp[len(resp)-reader.Len():], AkPublic: public}, nil
+}
+
+/*
+Return the handle and the public area of the attestation key, and whether it is to be flushed.
+A transient key left by a crash is flushed by the resource manager, not on the raw device.
+*/
+func loadTpmAk(rw io.ReadWriter) (uint32, []byte, bool, error) {
+
+	if tpmAkHandle != 0 {
+		public, err := readTpmPublic(rw, tpmAkHandle)
+		if err == nil {
+			return tpmAkHandle, public, false, nil
+		}
+		log.Printf("Creating TPM attestation key at handle 0x%x", tpmAkHandle)
+	}
+
+	handle, public, err := createTpmAk(rw)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	if tpmAkHandle == 0 {
+		return handle, public, true, nil
+	}
+
+	defer flushTpmContext(rw, handle)
+	params := new(bytes.Buffer)
+	binary.Write(params, binary.BigEndian, tpmAkHandle)
+	if _, _, err := runTpmAuthCommand(rw, TPM_CC_EVICT_CONTROL, []uint32{TPM_RH_OWNER, handle}, params.Bytes(), 0); err != nil {
+		return 0, nil, false, err
+	}
+
+	return tpmAkHandle, public, false, nil
+}
+
+/* Create the ECDSA P256 attestation key as primary key of the endorsement hierarchy */
+func createTpmAk(rw io.ReadWriter) (uint32, []byte, error) {
+
+	template := new(bytes.Buffer)
+	binary.Write(template, binary.BigEndian, []uint16{TPM_ALG_ECC, TPM_ALG_SHA256})
+	binary.Write(template, binary.BigEndian, uint32(TPM_AK_ATTRIBUTES))
+	marshalTpm2b(template, nil)
+	/* TPMS_ECC_PARMS without symmetric algorithm and key derivation function */
+	binary.Write(template, binary.BigEndian, []uint16{TPM_ALG_NULL, TPM_ALG_ECDSA, TPM_ALG_SHA256, TPM_ECC_NIST_P256, TPM_ALG_NULL})
+	marshalTpm2b(template, nil)
+	marshalTpm2b(template, nil)
+
+	params := new(bytes.Buffer)
+	/* TPM2B_SENSITIVE_CREATE with an empty authorization value and no data */
+	marshalTpm2b(params, make([]byte, 4))
+	marshalTpm2b(params, template.Bytes())
+	marshalTpm2b(params, nil)
+	binary.Write(params, binary.BigEndian, uint32(0))
+
+	handles, resp, err := runTpmAuthCommand(rw, TPM_CC_CREATE_PRIMARY, []uint32{TPM_RH_ENDORSEMENT}, params.Bytes(), 1)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	public, err := unmarshalTpm2b(bytes.NewReader(resp))
+	if err != nil {
+		return 0, nil, err
+	}
+	return handles[0], public, nil
+}
+
+/* Return the TPMT_PUBLIC of the object */
+func readTpmPublic(rw io.ReadWriter, handle uint32) ([]byte, error) {
+
+	params := make([]byte, 4)
+	binary.BigEndian.PutUint32(params, handle)
+
+	resp, err := runTpmCommand(rw, TPM_CC_READ_PUBLIC, params)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalTpm2b(bytes.NewReader(resp))
+}
+
+func flushTpmContext(rw io.ReadWriter, handle uint32) {
+
+	params := make([]byte, 4)
+	binary.BigEndian.PutUint32(params, handle)
+
+	if _, err := runTpmCommand(rw, TPM_CC_FLUSH_CONTEXT, params); err != nil {
+		log.Printf("Failed to flush TPM object 0x%x: %v", handle, err)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+* Copyright (c) 2023, Intel Corporation. All rights reserved.<BR>
+* SPDX-License-Identifier: Apache-2.0
+ */
+
+package resources
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"sort"
+	"testing"
+
+	"github.com/google/go-tpm/legacy/tpm2"
+)
+
+/* Decode the quote with go-tpm, verify its signature and return its PCR selection and digest */
+func verifyTpmQuote(t *testing.T, quote TpmQuote, qualifyingData []byte) *tpm2.QuoteInfo {
+	public, err := tpm2.DecodePublic(quote.AkPublic)
+	if err != nil {
+		t.Fatalf("Failed to decode attestation key: %v", err)
+	}
+	key, err := public.Key()
+	if err != nil {
+		t.Fatalf("Failed to decode attestation key: %v", err)
+	}
+
+	signature, err := tpm2.DecodeSignature(bytes.NewBuffer(quote.Signature))
+	if err != nil || signature.ECC == nil {
+		t.Fatalf("Failed to decode signature: %v", err)
+	}
+	digest := sha256.Sum256(quote.Attest)
+	if !ecdsa.Verify(key.(*ecdsa.PublicKey), digest[:], signature.ECC.R, signature.ECC.S) {
+		t.Fatalf("Quote signature not verified by the attestation key")
+	}
+
+	attest, err := tpm2.DecodeAttestationData(quote.Attest)
+	if err != nil || attest.Type != tpm2.TagAttestQuote || !bytes.Equal(attest.ExtraData, qualifyingData) {
+		t.Fatalf("Failed to decode quote: %v, %+v", err, attest)
+	}
+	return attest.AttestedQuoteInfo
+}
+
+func TestGetTpmQuote(t *testing.T) {
+	defer SetTpmAkHandle(0)
+	sim := useTpmSimulator(t)
+	extendPcrs(t, sim)
+
+	tests := []struct {
+		name           string
+		akHandle       uint32
+		algorithm      uint16
+		indexes        []int
+		qualifyingData []byte
+	}{
+		{"Transient key", 0, TPM_ALG_SHA256, []int{0, 7}, []byte("nonce")},
+		{"Persistent key created", 0x81010002, TPM_ALG_SHA384, []int{10}, bytes.Repeat([]byte{1}, TPM_MAX_QUALIFYING_DATA_LEN)},
+		{"Persistent key loaded", 0x81010002, TPM_ALG_SHA1, []int{23, 1, 2, 3, 4, 5, 6, 7, 8, 9}, nil},
+	}
+
+	var akPublic []byte
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTpmAkHandle(tt.akHandle)
+			quote, err := GetTpmQuote(tt.algorithm, tt.indexes, tt.qualifyingData)
+			if err != nil {
+				t.Fatalf(`GetTpmQuote() = %v want %v`, err, nil)
+			}
+
+			info := verifyTpmQuote(t, quote, tt.qualifyingData)
+			if info.PCRSelection.Hash != tpm2.Algorithm(tt.algorithm) || len(info.PCRSelection.PCRs) != len(tt.indexes) {
+				t.Errorf(`GetTpmQuote() PCR selection = %+v want %v`, info.PCRSelection, tt.indexes)
+			}
+
+			/* the PCR digest is the SHA256 digest of the key scheme over the PCRs in increasing order */
+			indexes := append([]int{}, tt.indexes...)
+			sort.Ints(indexes)
+			values, err := ReadPcrs(sim, tt.algorithm, indexes)
+			if err != nil {
+				t.Fatalf("Failed to read PCRs: %v", err)
+			}
+			hash := sha256.New()
+			for _, value := range values {
+				hash.Write(value.Digest)
+			}
+			if !bytes.Equal(info.PCRDigest, hash.Sum(nil)) {
+				t.Errorf(`GetTpmQuote() PCR digest = %x want %x`, info.PCRDigest, hash.Sum(nil))
+			}
+
+			if tt.akHandle != 0 {
+				if akPublic != nil && !bytes.Equal(akPublic, quote.AkPublic) {
+					t.Errorf(`GetTpmQuote() used another attestation key than the persistent one`)
+				}
+				akPublic = quote.AkPublic
+			}
+		})
+	}
+
+	if _, _, _, err := tpm2.ReadPublic(sim, 0x81010002); err != nil {
+		t.Errorf("Attestation key not persisted: %v", err)
+	}
+
+	/* the transient keys are flushed after the quotes */
+	handles, _, err := tpm2.GetCapability(sim, tpm2.CapabilityHandles, 1, uint32(tpm2.HandleTypeTransient)<<24)
+	if err != nil || len(handles) != 0 {
+		t.Errorf("Transient objects left in the TPM: %v, %v", handles, err)
+	}
+}
+
+func TestGetTpmQuoteInvalid(t *testing.T) {
+	useTpmSimulator(t)
+
+	tests := []struct {
+		name           string
+		algorithm      uint16
+		indexes        []int
+		qualifyingData []byte
+		err            error
+	}{
+		{"No PCR", TPM_ALG_SHA256, nil, nil, InvalidPcrIndexErr},
+		{"PCR out of range", TPM_ALG_SHA256, []int{TPM_PCR_COUNT}, nil, InvalidPcrIndexErr},
+		{"Unsupported bank", 0x0012, []int{0}, nil, UnsupportedPcrBankErr},
+		{"Too long qualifying data", TPM_ALG_SHA256, []int{0}, make([]byte, TPM_MAX_QUALIFYING_DATA_LEN+1), InvalidQualifyingDataErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GetTpmQuote(tt.algorithm, tt.indexes, tt.qualifyingData); err != tt.err {
+				t.Errorf(`GetTpmQuote() = %v want %v`, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestTpmQuoteMarshal(t *testing.T) {
+	quote := TpmQuote{Attest: []byte{1, 2}, Signature: []byte{3}, AkPublic: []byte{4, 5, 6}}
+	want := []byte{0, 2, 1, 2, 3, 0, 3, 4, 5, 6}
+
+	if got := quote.Marshal(); !bytes.Equal(got, want) {
+		t.Errorf(`Marshal() = %x want %x`, got, want)
+	}
+}
@@ -6,9 +6,67 @@
 package resources
 
 import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/google/go-tpm-tools/simulator"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"github.com/google/go-tpm/tpmutil"
 )
 
+/* The simulator stays open while the measurement server closes the transport after each request */
+type simulatorTransport struct {
+	*simulator.Simulator
+}
+
+func (simulatorTransport) Close() error {
+	return nil
+}
+
+/* Run the TPM requests against a TPM simulator, found at a TPM device node in a temporary directory */
+func useTpmSimulator(t *testing.T) *simulator.Simulator {
+	sim, err := simulator.Get()
+	if err != nil {
+		t.Fatalf("Failed to start TPM simulator: %v", err)
+	}
+
+	node := filepath.Join(t.TempDir(), "tpm0")
+	if err := os.WriteFile(node, nil, 0600); err != nil {
+		t.Fatalf("Failed to create device node: %v", err)
+	}
+	SetDeviceNodes(DeviceNodes{Tpm: node})
+	SetTpmTransportOpener(func(string) (TpmTransport, error) {
+		return simulatorTransport{sim}, nil
+	})
+
+	t.Cleanup(func() {
+		SetTpmTransportOpener(OpenTpmDevice)
+		SetDeviceNodes(DefaultDeviceNodes())
+		sim.Close()
+	})
+	return sim
+}
+
+/* Extend the PCRs of the banks resettable from locality 0 with a digest of their index */
+func extendPcrs(t *testing.T, sim *simulator.Simulator) {
+	for index := 0; index < TPM_PCR_COUNT; index++ {
+		if index >= 17 && index <= 22 {
+			continue
+		}
+		digest := sha512.Sum384([]byte{byte(index)})
+		for _, algorithm := range []tpm2.Algorithm{tpm2.AlgSHA1, tpm2.AlgSHA256, tpm2.AlgSHA384} {
+			size := tpmDigestLens[uint16(algorithm)]
+			if err := tpm2.PCRExtend(sim, tpmutil.Handle(index), algorithm, digest[:size], ""); err != nil {
+				t.Fatalf("Failed to extend PCR %d: %v", index, err)
+			}
+		}
+	}
+}
+
 func TestFindTPMDeviceAvailable(t *testing.T) {
 
 	_, err := findDeviceAvailable()
@@ -27,3 +85,83 @@ func TestGetTpmMeasurement(t *testing.T) {
 			err, DeviceNotFoundErr)
 	}
 }
+
+func TestGetTpmMeasurementOnSimulator(t *testing.T) {
+	sim := useTpmSimulator(t)
+	extendPcrs(t, sim)
+
+	want, err := tpm2.ReadPCR(sim, 7, tpm2.AlgSHA256)
+	if err != nil {
+		t.Fatalf("Failed to read PCR: %v", err)
+	}
+
+	measurement, err := GetTpmMeasurement(7)
+	if err != nil || measurement != base64.StdEncoding.EncodeToString(want) {
+		t.Fatalf(`GetTpmMeasurement() = %s, %v want %x`, measurement, err, want)
+	}
+}
+
+func TestReadPcrs(t *testing.T) {
+	sim := useTpmSimulator(t)
+	extendPcrs(t, sim)
+
+	all := make([]int, TPM_PCR_COUNT)
+	for index := range all {
+		all[index] = index
+	}
+
+	tests := []struct {
+		name      string
+		algorithm uint16
+		indexes   []int
+	}{
+		{"SHA1 PCR", TPM_ALG_SHA1, []int{0}},
+		{"SHA256 PCRs", TPM_ALG_SHA256, []int{23, 0, 10}},
+		{"SHA384 PCRs", TPM_ALG_SHA384, []int{1, 2, 3, 4}},
+		{"All SHA384 PCRs", TPM_ALG_SHA384, all},
+		{"Repeated PCR", TPM_ALG_SHA256, []int{5, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := GetTpmPcrs(tt.algorithm, tt.indexes)
+			if err != nil || len(values) != len(tt.indexes) {
+				t.Fatalf(`GetTpmPcrs() = %v, %v want %d PCRs`, values, err, len(tt.indexes))
+			}
+
+			for i, value := range values {
+				want, err := tpm2.ReadPCR(sim, tt.indexes[i], tpm2.Algorithm(tt.algorithm))
+				if err != nil {
+					t.Fatalf("Failed to read PCR: %v", err)
+				}
+				if value.Index != tt.indexes[i] || !bytes.Equal(value.Digest, want) {
+					t.Errorf(`GetTpmPcrs() PCR %d = %x want %x`, value.Index, value.Digest, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadPcrsInvalid(t *testing.T) {
+	useTpmSimulator(t)
+
+	tests := []struct {
+		name      string
+		algorithm uint16
+		indexes   []int
+		err       error
+	}{
+		{"No PCR", TPM_ALG_SHA256, nil, InvalidPcrIndexErr},
+		{"Negative PCR", TPM_ALG_SHA256, []int{-1}, InvalidPcrIndexErr},
+		{"PCR out of range", TPM_ALG_SHA256, []int{0, TPM_PCR_COUNT}, InvalidPcrIndexErr},
+		{"Unsupported bank", 0x0012, []int{0}, UnsupportedPcrBankErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GetTpmPcrs(tt.algorithm, tt.indexes); err != tt.err {
+				t.Errorf(`GetTpmPcrs() = %v want %v`, err, tt.err)
+			}
+		})
+	}
+}
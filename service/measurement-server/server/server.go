@@ -7,6 +7,8 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"flag"
 	"log"
 	"net"
 	"os"
@@ -14,20 +16,23 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/reflection"
 
+	config "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/config"
+	peercred "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/peercred"
 	pb "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/proto"
 	resources "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/resources"
 	pkgerrors "github.com/pkg/errors"
 )
 
 var (
-	InvalidRequestErr = pkgerrors.New("Invalid Request")
+	InvalidRequestErr   = pkgerrors.New("Invalid Request")
+	UnauthorizedPeerErr = pkgerrors.New("RTMR 3 is only extended by the eventlog server")
 )
 
 const (
 	protocol               = "unix"
-	sockAddr               = "/run/ccnp/uds/measurement.sock"
 	MAX_CONCURRENT_STREAMS = 100
 )
 
@@ -41,7 +46,7 @@ func getContainerMeasurement(measurementReq *pb.GetMeasurementRequest) (string,
 	return "", nil
 }
 
-func getPaasMeasurement(measurementReq *pb.GetMeasurementRequest) (string, error) {
+func getPaasMeasurement(measurementReq *pb.GetMeasurementRequest) (*pb.GetMeasurementReply, error) {
 	var category pb.CATEGORY
 	var measurement string
 	var err error
@@ -50,54 +55,117 @@ func getPaasMeasurement(measurementReq *pb.GetMeasurementRequest) (string, error
 
 	switch category {
 	case pb.CATEGORY_TEE_REPORT:
-		measurement, err = getTeeReport(measurementReq)
+		return getTeeReport(measurementReq)
 	case pb.CATEGORY_TDX_RTMR:
 		var device string
 		r := resources.NewTdxResource()
 		device, err = r.FindDeviceAvailable()
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		measurement, err = r.GetRTMRMeasurement(device, measurementReq.ReportData, int(measurementReq.RegisterIndex))
 	case pb.CATEGORY_TPM:
-		measurement, err = resources.GetTpmMeasurement(int(measurementReq.RegisterIndex))
+		return getTpmMeasurement(measurementReq)
 	default:
 		log.Println("Invalid measurement category.")
-		return "", InvalidRequestErr
+		return nil, InvalidRequestErr
 	}
-	return measurement, err
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetMeasurementReply{Measurement: measurement}, nil
 }
 
-func getTeeReport(measurementReq *pb.GetMeasurementRequest) (string, error) {
+var pcrBankAlgorithms = map[pb.PCR_BANK]uint16{
+	pb.PCR_BANK_SHA1:   resources.TPM_ALG_SHA1,
+	pb.PCR_BANK_SHA256: resources.TPM_ALG_SHA256,
+	pb.PCR_BANK_SHA384: resources.TPM_ALG_SHA384,
+}
+
+/*
+Read the PCRs of the request, or the PCR of the register index if no PCR is given. The
+measurement is the concatenation of the digests in the order of the request.
+*/
+func getTpmMeasurement(measurementReq *pb.GetMeasurementRequest) (*pb.GetMeasurementReply, error) {
+
+	algorithm, ok := pcrBankAlgorithms[measurementReq.PcrBank]
+	if !ok {
+		log.Println("Invalid PCR bank.")
+		return nil, InvalidRequestErr
+	}
+
+	indexes := []int{int(measurementReq.RegisterIndex)}
+	if len(measurementReq.PcrIndexes) > 0 {
+		indexes = make([]int, 0, len(measurementReq.PcrIndexes))
+		for _, index := range measurementReq.PcrIndexes {
+			indexes = append(indexes, int(index))
+		}
+	}
+
+	values, err := resources.GetTpmPcrs(algorithm, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &pb.GetMeasurementReply{}
+	var digests []byte
+	for _, value := range values {
+		reply.PcrValues = append(reply.PcrValues, &pb.PcrValue{Index: int32(value.Index), Digest: value.Digest})
+		digests = append(digests, value.Digest...)
+	}
+	reply.Measurement = base64.StdEncoding.EncodeToString(digests)
+
+	return reply, nil
+}
+
+func getTeeReport(measurementReq *pb.GetMeasurementRequest) (*pb.GetMeasurementReply, error) {
 
 	reportData := measurementReq.ReportData
 
 	r := resources.NewBaseTeeResource()
+	r.Vmpl = measurementReq.Vmpl
 	device, err := r.FindDeviceAvailable()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	/* the TEE tells the client how to decode the report */
+	reply := &pb.GetMeasurementReply{TeeType: pb.TEE_TDX}
+	if resources.IsSevDevice(device) {
+		reply.TeeType = pb.TEE_SEV_SNP
 	}
 
-	report, err := r.GetReport(device, reportData)
+	if !measurementReq.ExtendedReport {
+		reply.Measurement, err = r.GetReport(device, reportData)
+	} else if reply.TeeType == pb.TEE_SEV_SNP {
+		sev := resources.NewSevResource()
+		sev.Vmpl = measurementReq.Vmpl
+		reply.Measurement, reply.CertTable, err = sev.GetExtendedReport(device, reportData)
+	} else {
+		log.Println("Extended report is only available on SEV-SNP.")
+		return nil, InvalidRequestErr
+	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return report, nil
+	return reply, nil
 }
 
 func (*measurementServer) GetMeasurement(ctx context.Context, measurementReq *pb.GetMeasurementRequest) (*pb.GetMeasurementReply, error) {
 	var measurement_type pb.TYPE
-	var measurement string
+	var reply *pb.GetMeasurementReply
 	var err error
 
 	measurement_type = measurementReq.MeasurementType
 
 	switch measurement_type {
 	case pb.TYPE_SAAS:
+		var measurement string
 		measurement, err = getContainerMeasurement(measurementReq)
+		reply = &pb.GetMeasurementReply{Measurement: measurement}
 	case pb.TYPE_PAAS:
-		measurement, err = getPaasMeasurement(measurementReq)
+		reply, err = getPaasMeasurement(measurementReq)
 	default:
 		log.Println("Invalid measurement type.")
 		return &pb.GetMeasurementReply{}, InvalidRequestErr
@@ -107,7 +175,49 @@ func (*measurementServer) GetMeasurement(ctx context.Context, measurementReq *pb
 	if err != nil {
 		return &pb.GetMeasurementReply{}, err
 	}
-	return &pb.GetMeasurementReply{Measurement: measurement}, nil
+	return reply, nil
+}
+
+func (*measurementServer) GetTpmQuote(ctx context.Context, quoteReq *pb.GetTpmQuoteRequest) (*pb.GetTpmQuoteReply, error) {
+
+	algorithm, ok := pcrBankAlgorithms[quoteReq.PcrBank]
+	if !ok {
+		log.Println("Invalid PCR bank.")
+		return &pb.GetTpmQuoteReply{}, InvalidRequestErr
+	}
+
+	indexes := make([]int, 0, len(quoteReq.PcrIndexes))
+	for _, index := range quoteReq.PcrIndexes {
+		indexes = append(indexes, int(index))
+	}
+
+	quote, err := resources.GetTpmQuote(algorithm, indexes, quoteReq.QualifyingData)
+	if err != nil {
+		return &pb.GetTpmQuoteReply{}, err
+	}
+
+	return &pb.GetTpmQuoteReply{Quote: base64.StdEncoding.EncodeToString(quote.Marshal())}, nil
+}
+
+/*
+Extend RTMR 2 or 3 and record the event in the runtime event log served by the eventlog server.
+Workloads on the shared socket only extend RTMR 2, the other RTMRs are only extended by the
+peer of the extend socket.
+*/
+func (*measurementServer) ExtendMeasurement(ctx context.Context, extendReq *pb.ExtendMeasurementRequest) (*pb.ExtendMeasurementReply, error) {
+
+	if p, ok := peer.FromContext(ctx); extendReq.RegisterIndex != resources.WORKLOAD_RTMR_INDEX &&
+		(!ok || p.AuthInfo == nil || p.AuthInfo.AuthType() != peercred.AUTH_TYPE) {
+		log.Printf("RTMR %d not extended by the peer of the extend socket", extendReq.RegisterIndex)
+		return &pb.ExtendMeasurementReply{}, UnauthorizedPeerErr
+	}
+
+	err := resources.ExtendRuntimeMeasurement(int(extendReq.RegisterIndex), extendReq.Digest, extendReq.Event)
+	if err != nil {
+		return &pb.ExtendMeasurementReply{}, err
+	}
+
+	return &pb.ExtendMeasurementReply{}, nil
 }
 
 func (*measurementServer) Check(ctx context.Context, in *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
@@ -126,13 +236,31 @@ func newServer() *measurementServer {
 }
 
 func main() {
-	if _, err := os.Stat(sockAddr); !os.IsNotExist(err) {
-		if err := os.RemoveAll(sockAddr); err != nil {
-			log.Fatal(err)
+	cfg, err := config.Load(os.Args[0], os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		log.Fatalf("failed to read configuration: %v", err)
+	}
+	resources.SetDeviceNodes(cfg.DeviceNodes())
+	resources.SetTpmAkHandle(cfg.AkHandle())
+	resources.SetRuntimeEventlog(cfg.RuntimeEventlog)
+
+	/* an extend interrupted by a crash is checked against the RTMR before new extends */
+	if err := resources.RecoverRuntimeEventlog(); err != nil {
+		log.Printf("failed to recover runtime event log: %v", err)
+	}
+
+	for _, socket := range []string{cfg.Socket, cfg.ExtendSocket} {
+		if _, err := os.Stat(socket); socket != "" && !os.IsNotExist(err) {
+			if err := os.RemoveAll(socket); err != nil {
+				log.Fatal(err)
+			}
 		}
 	}
 
-	lis, err := net.Listen(protocol, sockAddr)
+	lis, err := net.Listen(protocol, cfg.Socket)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
@@ -144,9 +272,26 @@ func main() {
 	grpcServer := grpc.NewServer(opts...)
 	healthServer := health.NewServer()
 
-	pb.RegisterMeasurementServer(grpcServer, newServer())
+	server := newServer()
+	pb.RegisterMeasurementServer(grpcServer, server)
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 
+	if cfg.ExtendSocket != "" {
+		extendLis, err := net.Listen(protocol, cfg.ExtendSocket)
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+
+		extendServer := grpc.NewServer(append(opts, grpc.Creds(peercred.NewCredentials(cfg.ExtendPeerUid())))...)
+		pb.RegisterMeasurementServer(extendServer, server)
+		log.Printf("extend server listening at %v", extendLis.Addr())
+		go func() {
+			if err := extendServer.Serve(extendLis); err != nil {
+				log.Fatalf("failed to serve extend socket: %v", err)
+			}
+		}()
+	}
+
 	log.Printf("server listening at %v", lis.Addr())
 	reflection.Register(grpcServer)
 	if err = grpcServer.Serve(lis); err != nil {
@@ -5,18 +5,26 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"log"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/google/go-tpm-tools/simulator"
+	"github.com/google/go-tpm/legacy/tpm2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/test/bufconn"
 
+	peercred "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/peercred"
 	pb "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/proto"
+	resources "github.com/intel/confidential-cloud-native-primitives/service/measurement-server/resources"
 )
 
 const (
@@ -158,3 +166,278 @@ func TestMeasurementServerGetMeasurement(t *testing.T) {
 		})
 	}
 }
+
+/* Replays a SNP_GET_REPORT response with an empty report, and a VCEK certificate table */
+type fakeSevGuestDevice struct {
+	vmpl uint32
+}
+
+func (d *fakeSevGuestDevice) GetReport(req resources.SnpReportReq) ([]byte, error) {
+	d.vmpl = req.Vmpl
+	resp := make([]byte, resources.SNP_REPORT_RESP_LEN)
+	binary.LittleEndian.PutUint32(resp[4:], resources.SNP_REPORT_LEN)
+	return resp, nil
+}
+
+func (d *fakeSevGuestDevice) GetExtReport(req resources.SnpReportReq, certs []byte) ([]byte, int, error) {
+	copy(certs, fakeSevCertTable)
+	resp, err := d.GetReport(req)
+	return resp, len(certs), err
+}
+
+var fakeSevCertTable = func() []byte {
+	table := make([]byte, 3*resources.SNP_CERT_TABLE_ENTRY_LEN)
+	copy(table, resources.SNP_VCEK_GUID[:])
+	binary.LittleEndian.PutUint32(table[16:], 2*resources.SNP_CERT_TABLE_ENTRY_LEN)
+	binary.LittleEndian.PutUint32(table[20:], resources.SNP_CERT_TABLE_ENTRY_LEN)
+	return table
+}()
+
+func (d *fakeSevGuestDevice) Close() error {
+	return nil
+}
+
+func TestMeasurementServerGetSevReport(t *testing.T) {
+	defer resources.SetDeviceNodes(resources.DefaultDeviceNodes())
+	defer resources.SetSevGuestDeviceOpener(resources.OpenSevGuestDevice)
+
+	node := filepath.Join(t.TempDir(), "sev-guest")
+	if err := os.WriteFile(node, nil, 0600); err != nil {
+		t.Fatalf("Failed to create device node: %v", err)
+	}
+	resources.SetDeviceNodes(resources.DeviceNodes{SevGuest: node})
+	device := &fakeSevGuestDevice{}
+	resources.SetSevGuestDeviceOpener(func(string) (resources.SevGuestDevice, error) {
+		return device, nil
+	})
+
+	out, err := newServer().GetMeasurement(context.Background(), &pb.GetMeasurementRequest{
+		MeasurementType:     pb.TYPE_PAAS,
+		MeasurementCategory: pb.CATEGORY_TEE_REPORT,
+		Vmpl:                1,
+	})
+	if err != nil {
+		t.Fatalf("Err -> \nWant: nil\nGot: %q\n", err)
+	}
+
+	report, err := base64.StdEncoding.DecodeString(out.Measurement)
+	if err != nil || len(report) != resources.SNP_REPORT_LEN || out.TeeType != pb.TEE_SEV_SNP || device.vmpl != 1 {
+		t.Errorf("Out -> \nWant SEV-SNP report for VMPL 1\nGot: %v, %d bytes, VMPL %d\n", out.TeeType, len(report), device.vmpl)
+	}
+
+	out, err = newServer().GetMeasurement(context.Background(), &pb.GetMeasurementRequest{
+		MeasurementType:     pb.TYPE_PAAS,
+		MeasurementCategory: pb.CATEGORY_TEE_REPORT,
+		ExtendedReport:      true,
+	})
+	if err != nil {
+		t.Fatalf("Err -> \nWant: nil\nGot: %q\n", err)
+	}
+	if out.TeeType != pb.TEE_SEV_SNP || !bytes.Equal(out.CertTable, fakeSevCertTable) {
+		t.Errorf("Out -> \nWant: certificate table %x\nGot: %x\n", fakeSevCertTable, out.CertTable)
+	}
+}
+
+func TestMeasurementServerExtendedReportOnTdx(t *testing.T) {
+	defer resources.SetDeviceNodes(resources.DefaultDeviceNodes())
+
+	node := filepath.Join(t.TempDir(), "tdx_guest")
+	if err := os.WriteFile(node, nil, 0600); err != nil {
+		t.Fatalf("Failed to create device node: %v", err)
+	}
+	resources.SetDeviceNodes(resources.DeviceNodes{Tdx15: node})
+
+	_, err := newServer().GetMeasurement(context.Background(), &pb.GetMeasurementRequest{
+		MeasurementType:     pb.TYPE_PAAS,
+		MeasurementCategory: pb.CATEGORY_TEE_REPORT,
+		ExtendedReport:      true,
+	})
+	if err != InvalidRequestErr {
+		t.Errorf("Err -> \nWant: %q\nGot: %q\n", InvalidRequestErr, err)
+	}
+}
+
+/* The simulator stays open while the server closes the transport after each request */
+type simulatorTransport struct {
+	*simulator.Simulator
+}
+
+func (simulatorTransport) Close() error {
+	return nil
+}
+
+func useTpmSimulator(t *testing.T) *simulator.Simulator {
+	sim, err := simulator.Get()
+	if err != nil {
+		t.Fatalf("Failed to start TPM simulator: %v", err)
+	}
+
+	node := filepath.Join(t.TempDir(), "tpm0")
+	if err := os.WriteFile(node, nil, 0600); err != nil {
+		t.Fatalf("Failed to create device node: %v", err)
+	}
+	resources.SetDeviceNodes(resources.DeviceNodes{Tpm: node})
+	resources.SetTpmTransportOpener(func(string) (resources.TpmTransport, error) {
+		return simulatorTransport{sim}, nil
+	})
+
+	t.Cleanup(func() {
+		resources.SetTpmTransportOpener(resources.OpenTpmDevice)
+		resources.SetDeviceNodes(resources.DefaultDeviceNodes())
+		sim.Close()
+	})
+	return sim
+}
+
+func TestMeasurementServerGetTpmMeasurement(t *testing.T) {
+	sim := useTpmSimulator(t)
+
+	if err := tpm2.PCRExtend(sim, 10, tpm2.AlgSHA384, bytes.Repeat([]byte{1}, 48), ""); err != nil {
+		t.Fatalf("Failed to extend PCR: %v", err)
+	}
+	pcr0, _ := tpm2.ReadPCR(sim, 0, tpm2.AlgSHA384)
+	pcr10, _ := tpm2.ReadPCR(sim, 10, tpm2.AlgSHA384)
+
+	out, err := newServer().GetMeasurement(context.Background(), &pb.GetMeasurementRequest{
+		MeasurementType:     pb.TYPE_PAAS,
+		MeasurementCategory: pb.CATEGORY_TPM,
+		PcrBank:             pb.PCR_BANK_SHA384,
+		PcrIndexes:          []int32{10, 0},
+	})
+	if err != nil {
+		t.Fatalf("Err -> \nWant: nil\nGot: %q\n", err)
+	}
+
+	if out.Measurement != base64.StdEncoding.EncodeToString(append(append([]byte{}, pcr10...), pcr0...)) ||
+		len(out.PcrValues) != 2 || out.PcrValues[0].Index != 10 || !bytes.Equal(out.PcrValues[0].Digest, pcr10) ||
+		out.PcrValues[1].Index != 0 || !bytes.Equal(out.PcrValues[1].Digest, pcr0) {
+		t.Errorf("Out -> \nWant: PCR 10 %x and PCR 0 %x\nGot: %v\n", pcr10, pcr0, out)
+	}
+
+	_, err = newServer().GetMeasurement(context.Background(), &pb.GetMeasurementRequest{
+		MeasurementType:     pb.TYPE_PAAS,
+		MeasurementCategory: pb.CATEGORY_TPM,
+		PcrBank:             9,
+	})
+	if err != InvalidRequestErr {
+		t.Errorf("Err -> \nWant: %q\nGot: %q\n", InvalidRequestErr, err)
+	}
+}
+
+func TestMeasurementServerGetTpmQuote(t *testing.T) {
+	useTpmSimulator(t)
+
+	out, err := newServer().GetTpmQuote(context.Background(), &pb.GetTpmQuoteRequest{
+		PcrBank:        pb.PCR_BANK_SHA256,
+		PcrIndexes:     []int32{0, 7},
+		QualifyingData: []byte("nonce"),
+	})
+	if err != nil {
+		t.Fatalf("Err -> \nWant: nil\nGot: %q\n", err)
+	}
+
+	quote, err := base64.StdEncoding.DecodeString(out.Quote)
+	if err != nil {
+		t.Fatalf("Err -> \nWant: base64 quote\nGot: %q\n", err)
+	}
+	size := binary.BigEndian.Uint16(quote)
+	attest, err := tpm2.DecodeAttestationData(quote[2 : 2+size])
+	if err != nil || attest.Type != tpm2.TagAttestQuote || string(attest.ExtraData) != "nonce" {
+		t.Errorf("Out -> \nWant: quote with the qualifying data\nGot: %+v, %v\n", attest, err)
+	}
+
+	for _, in := range []*pb.GetTpmQuoteRequest{{PcrBank: 9, PcrIndexes: []int32{0}}, {PcrIndexes: []int32{24}}} {
+		if _, err := newServer().GetTpmQuote(context.Background(), in); err == nil {
+			t.Errorf("Err -> \nWant: error for %v\nGot: nil\n", in)
+		}
+	}
+}
+
+/* Keeps the data extended into the RTMRs */
+type fakeTdxGuestDevice struct {
+	extended map[int][]byte
+}
+
+func (d *fakeTdxGuestDevice) ExtendRtmr(index int, data []byte) error {
+	d.extended[index] = append(d.extended[index], data...)
+	return nil
+}
+
+func (d *fakeTdxGuestDevice) ReadRtmr(index int) ([]byte, error) {
+	return make([]byte, resources.RTMR_LEN), nil
+}
+
+func (d *fakeTdxGuestDevice) Close() error {
+	return nil
+}
+
+/* Serve the extend socket to the user running the tests */
+func dialExtendSocket(t *testing.T) pb.MeasurementClient {
+	socket := filepath.Join(t.TempDir(), "measurement-extend.sock")
+	extendLis, err := net.Listen(protocol, socket)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	extendServer := grpc.NewServer(grpc.Creds(peercred.NewCredentials(uint32(os.Getuid()))))
+	pb.RegisterMeasurementServer(extendServer, newServer())
+	go extendServer.Serve(extendLis)
+	t.Cleanup(extendServer.Stop)
+
+	conn, err := grpc.Dial(protocol+":"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect to extend socket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewMeasurementClient(conn)
+}
+
+func TestMeasurementServerExtendMeasurement(t *testing.T) {
+	defer resources.SetDeviceNodes(resources.DefaultDeviceNodes())
+	defer resources.SetTdxGuestDeviceOpener(resources.OpenTdxGuestDevice)
+	defer resources.SetRuntimeEventlog(resources.RUNTIME_EVENT_LOG_LOCATION)
+
+	node := filepath.Join(t.TempDir(), "tdx_guest")
+	if err := os.WriteFile(node, nil, 0600); err != nil {
+		t.Fatalf("Failed to create device node: %v", err)
+	}
+	resources.SetDeviceNodes(resources.DeviceNodes{Tdx15: node})
+
+	device := &fakeTdxGuestDevice{extended: map[int][]byte{}}
+	resources.SetTdxGuestDeviceOpener(func(string) (resources.TdxGuestDevice, error) {
+		return device, nil
+	})
+	location := filepath.Join(t.TempDir(), "runtime_eventlog")
+	resources.SetRuntimeEventlog(location)
+
+	digest := bytes.Repeat([]byte{0xab}, resources.TDX_EXTEND_RTMR_DATA_LEN)
+	_, err := newServer().ExtendMeasurement(context.Background(), &pb.ExtendMeasurementRequest{
+		RegisterIndex: 2,
+		Digest:        digest,
+		Event:         []byte("config:/etc/app/config.yaml"),
+	})
+	if err != nil {
+		t.Fatalf("Err -> \nWant: nil\nGot: %q\n", err)
+	}
+
+	/* RTMR 3 anchors the container event log and is refused to the workloads */
+	in := &pb.ExtendMeasurementRequest{RegisterIndex: 3, Digest: digest, Event: []byte("model:resnet50")}
+	if _, err := newServer().ExtendMeasurement(context.Background(), in); err != UnauthorizedPeerErr {
+		t.Fatalf("Err -> \nWant: %q\nGot: %v\n", UnauthorizedPeerErr, err)
+	}
+	if _, err := dialExtendSocket(t).ExtendMeasurement(context.Background(), in); err != nil {
+		t.Fatalf("Err -> \nWant: nil\nGot: %q\n", err)
+	}
+
+	data, err := os.ReadFile(location)
+	if err != nil || !bytes.Equal(device.extended[2], digest) || !bytes.Equal(device.extended[3], digest) ||
+		!bytes.HasSuffix(data, []byte("model:resnet50")) || !bytes.Contains(data, []byte("config:/etc/app/config.yaml")) {
+		t.Errorf("Out -> \nWant: RTMR 2 and 3 extended and recorded\nGot: %x, %x, %v\n", device.extended[2], device.extended[3], err)
+	}
+
+	for _, in := range []*pb.ExtendMeasurementRequest{{RegisterIndex: 0, Digest: digest}, {RegisterIndex: 2, Digest: digest[:32]}} {
+		if _, err := newServer().ExtendMeasurement(context.Background(), in); err == nil {
+			t.Errorf("Err -> \nWant: error for RTMR %d with %d bytes\nGot: nil\n", in.RegisterIndex, len(in.Digest))
+		}
+	}
+}